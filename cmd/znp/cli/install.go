@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -284,6 +285,34 @@ func (w *InstallWizard) configureOptionalFeaturesStep() error {
 	w.cfg.Webhook.Stripe.SigningSecret = ""
 	w.cfg.Webhook.Stripe.ToleranceSeconds = 300
 
+	// Node callback configuration
+	w.cfg.Node.AllowCIDRs = []string{}
+	w.cfg.Node.Normalize()
+
+	// Background jobs
+	w.cfg.Jobs.AutoRenew.Enable = w.promptYesNo("Enable auto-renewal scheduler", true)
+	w.cfg.Jobs.TrafficReset.Enable = w.promptYesNo("Enable traffic reset scheduler", true)
+	w.cfg.Jobs.SubscriptionLifecycle.Enable = w.promptYesNo("Enable subscription lifecycle scheduler", true)
+	w.cfg.Jobs.AccessAudit.Enable = w.promptYesNo("Enable subscription access audit scheduler", true)
+	w.cfg.Jobs.Normalize()
+
+	// Invoice configuration
+	w.cfg.Invoice.SellerName = w.prompt("Invoice seller name", w.cfg.Project.Name)
+	w.cfg.Invoice.SellerEmail = w.prompt("Invoice seller email", "")
+	w.cfg.Invoice.Normalize()
+
+	// Registration and affiliate program
+	w.cfg.Registration.Enable = w.promptYesNo("Allow self-service registration", false)
+	w.cfg.Affiliate.Enable = w.promptYesNo("Enable affiliate commissions", false)
+	w.cfg.Affiliate.CommissionPercent = 10
+	w.cfg.Affiliate.MinWithdrawCents = 1000
+	if w.cfg.Affiliate.Enable {
+		if percent, err := strconv.ParseFloat(w.prompt("Commission percent", "10"), 64); err == nil {
+			w.cfg.Affiliate.CommissionPercent = percent
+		}
+	}
+	w.cfg.Affiliate.Normalize()
+
 	// gRPC configuration
 	enableGRPC := w.promptYesNo("Enable gRPC server", true)
 	w.cfg.GRPC.Enable = &enableGRPC
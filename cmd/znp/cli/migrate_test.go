@@ -86,10 +86,68 @@ Webhook:
     SigningSecret: ""
     ToleranceSeconds: 300
 
+Node:
+  AllowCIDRs: []
+  OnlineWindow: 3m
+  DeviceLimitAction: deny
+
+GeoIP:
+  File: ""
+
+Template:
+  RenderTimeout: 2s
+  MaxOutputBytes: 2097152
+  RenderCacheTTL: 5m
+  DefaultClientType: ""
+
 GRPCServer:
   Enable: true
   ListenOn: 127.0.0.1:0
   Reflection: true
+
+Jobs:
+  AutoRenew:
+    Enable: false
+    Interval: 10m
+    LeadDays: 3
+    GraceDays: 3
+    RetryInterval: 6h
+    MaxAttempts: 3
+    BatchSize: 100
+  TrafficReset:
+    Enable: false
+    Interval: 5m
+    BatchSize: 200
+  SubscriptionLifecycle:
+    Enable: false
+    Interval: 1m
+    BatchSize: 200
+    MaxEventAttempts: 5
+  AccessAudit:
+    Enable: false
+    Interval: 10m
+    Window: 24h
+    SuspendDistinctIPs: 0
+    Retention: 720h
+    BatchSize: 100
+
+Invoice:
+  SellerName: ""
+  SellerAddress: ""
+  SellerTaxID: ""
+  SellerEmail: ""
+  NumberPrefix: INV
+  CreditNotePrefix: CN
+  TemplateFile: ""
+
+Registration:
+  Enable: false
+  RequireInviteCode: false
+
+Affiliate:
+  Enable: false
+  CommissionPercent: 0
+  MinWithdrawCents: 0
 `, dsn)
 
 	path := filepath.Join(dir, "config.yaml")
@@ -14,6 +14,7 @@ import (
 
 	"github.com/zero-net-panel/zero-net-panel/internal/config"
 	"github.com/zero-net-panel/zero-net-panel/internal/handler"
+	"github.com/zero-net-panel/zero-net-panel/internal/scheduler"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 )
 
@@ -22,7 +23,7 @@ func RunAPIServer(ctx context.Context, cfg config.Config) error {
 	return RunServices(ctx, cfg)
 }
 
-// RunServices 启动 HTTP 与 gRPC 服务，并在任一退出或外部取消时统一回收资源。
+// RunServices 启动 HTTP、gRPC 服务与后台定时任务，并在任一退出或外部取消时统一回收资源。
 func RunServices(ctx context.Context, cfg config.Config) error {
 	runCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -37,7 +38,7 @@ func RunServices(ctx context.Context, cfg config.Config) error {
 		cancel()
 	})
 
-	errCh := make(chan error, 3)
+	errCh := make(chan error, 4)
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -68,6 +69,19 @@ func RunServices(ctx context.Context, cfg config.Config) error {
 		}()
 	}
 
+	jobs := scheduler.New(svcCtx.Cache)
+	scheduler.RegisterDefaultJobs(jobs, svcCtx)
+	if jobs.Len() > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fmt.Printf("Starting background scheduler with %d job(s)...\n", jobs.Len())
+			if err := jobs.Run(runCtx); err != nil {
+				errCh <- err
+			}
+		}()
+	}
+
 	var runErr error
 	select {
 	case <-runCtx.Done():
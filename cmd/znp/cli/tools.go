@@ -10,6 +10,8 @@ func NewToolsCommand(opts *GlobalOptions) *cobra.Command {
 
 	cmd.AddCommand(
 		NewToolsCheckConfigCommand(opts),
+		NewToolsReconcileCommand(opts),
+		NewToolsTemplatesCommand(opts),
 	)
 
 	return cmd
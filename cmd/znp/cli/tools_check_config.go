@@ -94,6 +94,13 @@ func NewToolsCheckConfigCommand(opts *GlobalOptions) *cobra.Command {
 			} else {
 				cmd.Println("Webhook Stripe signature: disabled")
 			}
+			cmd.Println(fmt.Sprintf("Node API: per-node tokens (online window=%s, device limit action=%s)", cfg.Node.OnlineWindow, cfg.Node.DeviceLimitAction))
+			if cfg.GeoIP.File != "" {
+				cmd.Println(fmt.Sprintf("GeoIP: %s", cfg.GeoIP.File))
+			} else {
+				cmd.Println("GeoIP: disabled")
+			}
+			cmd.Println(fmt.Sprintf("Template render limits: timeout=%s max_output=%d bytes cache_ttl=%s default_client_type=%q", cfg.Template.RenderTimeout, cfg.Template.MaxOutputBytes, cfg.Template.RenderCacheTTL, cfg.Template.DefaultClientType))
 			return nil
 		},
 	}
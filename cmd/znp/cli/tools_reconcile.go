@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/billing"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/pkg/database"
+	"github.com/zero-net-panel/zero-net-panel/pkg/payment"
+)
+
+func NewToolsReconcileCommand(opts *GlobalOptions) *cobra.Command {
+	var (
+		file     string
+		format   string
+		provider string
+		from     string
+		to       string
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Reconcile a gateway settlement file against order payments",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(file) == "" {
+				return errors.New("--file is required")
+			}
+
+			cfg, err := loadConfig(opts.ConfigFile)
+			if err != nil {
+				return err
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("read settlement file: %w", err)
+			}
+			if strings.TrimSpace(format) == "" {
+				format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+			}
+			settlements, err := payment.ParseSettlements(format, content)
+			if err != nil {
+				return err
+			}
+
+			reconcileOpts := billing.ReconcileOptions{
+				Provider: provider,
+				Source:   billing.ReconcileSourceCLI,
+				Operator: "cli",
+				DryRun:   dryRun,
+			}
+			if from != "" || to != "" {
+				if from == "" || to == "" {
+					return errors.New("--from and --to must be used together")
+				}
+				if reconcileOpts.From, err = parseReconcileTime(from); err != nil {
+					return err
+				}
+				if reconcileOpts.To, err = parseReconcileTime(to); err != nil {
+					return err
+				}
+			}
+
+			db, closeFn, err := database.NewGorm(cfg.Database)
+			if err != nil {
+				return fmt.Errorf("connect database: %w", err)
+			}
+			defer closeFn()
+			if db == nil {
+				return errors.New("database configuration is required")
+			}
+
+			repos, err := repository.NewRepositories(db)
+			if err != nil {
+				return err
+			}
+			svcCtx := &svc.ServiceContext{Config: cfg, DB: db, Repositories: repos}
+
+			run, issues, err := billing.NewReconcileLogic(cmd.Context(), svcCtx).Run(
+				payment.NewStaticProvider(provider, settlements), reconcileOpts)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				cmd.Println("Dry run: results not saved.")
+			} else {
+				cmd.Printf("Reconciliation run #%d saved.\n", run.ID)
+			}
+			cmd.Printf("Settlement rows: %d, matched: %d, issues: %d\n", run.SettlementRows, run.MatchedCount, run.IssueCount)
+
+			kinds := make([]string, 0, len(run.Summary))
+			for kind := range run.Summary {
+				kinds = append(kinds, kind)
+			}
+			sort.Strings(kinds)
+			for _, kind := range kinds {
+				cmd.Printf("  %s: %d\n", kind, run.Summary[kind])
+			}
+			for _, issue := range issues {
+				cmd.Printf("- [%s] order=%d payment=%d intent=%s reference=%s: %s\n",
+					issue.Kind, issue.OrderID, issue.PaymentID, issue.IntentID, issue.Reference, issue.Detail)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Settlement file exported from the payment gateway (CSV or JSON)")
+	cmd.Flags().StringVar(&format, "format", "", "Settlement file format: csv or json (default: file extension)")
+	cmd.Flags().StringVar(&provider, "provider", "", "Gateway name; also filters local payments by provider")
+	cmd.Flags().StringVar(&from, "from", "", "Period start (RFC3339 or YYYY-MM-DD) used to detect unsettled payments")
+	cmd.Flags().StringVar(&to, "to", "", "Period end (exclusive, RFC3339 or YYYY-MM-DD)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print results without saving the reconciliation run")
+
+	return cmd
+}
+
+func parseReconcileTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t.UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected RFC3339 or YYYY-MM-DD)", value)
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/pkg/database"
+)
+
+func NewToolsTemplatesCommand(opts *GlobalOptions) *cobra.Command {
+	cmd := &cobra.Command{Use: "templates", Short: "Export and import subscription template bundles"}
+	cmd.AddCommand(newToolsTemplatesExportCommand(opts), newToolsTemplatesImportCommand(opts))
+	return cmd
+}
+
+func newToolsTemplatesExportCommand(opts *GlobalOptions) *cobra.Command {
+	var (
+		output         string
+		format         string
+		clientType     string
+		includeHistory bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export subscription templates and their variables as a versioned bundle",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(format) == "" {
+				format = bundleFormatFromPath(output)
+			}
+			format, err := subscriptionutil.NormalizeBundleFormat(format)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := loadConfig(opts.ConfigFile)
+			if err != nil {
+				return err
+			}
+			repos, closeFn, err := openTemplateRepositories(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			bundle, err := subscriptionutil.ExportBundle(cmd.Context(), repos, subscriptionutil.ExportOptions{
+				ClientType:     clientType,
+				IncludeHistory: includeHistory,
+			}, time.Now())
+			if err != nil {
+				return err
+			}
+			content, err := subscriptionutil.EncodeBundle(bundle, format)
+			if err != nil {
+				return err
+			}
+
+			if output == "" {
+				_, err = cmd.OutOrStdout().Write(content)
+				return err
+			}
+			if err := os.WriteFile(output, content, 0o644); err != nil {
+				return fmt.Errorf("write bundle: %w", err)
+			}
+			cmd.Printf("Exported %d template(s) to %s\n", len(bundle.Templates), output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Bundle file to write (default: stdout)")
+	cmd.Flags().StringVar(&format, "format", "", "Bundle format: json or yaml (default: output extension, then json)")
+	cmd.Flags().StringVar(&clientType, "client-type", "", "Only export templates of this client type")
+	cmd.Flags().BoolVar(&includeHistory, "include-history", false, "Include the publish history of each template")
+
+	return cmd
+}
+
+func newToolsTemplatesImportCommand(opts *GlobalOptions) *cobra.Command {
+	var (
+		file     string
+		format   string
+		dryRun   bool
+		publish  bool
+		operator string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a subscription template bundle, matching templates by client type and name",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if strings.TrimSpace(file) == "" {
+				return errors.New("--file is required")
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("read bundle: %w", err)
+			}
+			if strings.TrimSpace(format) == "" {
+				format = bundleFormatFromPath(file)
+			}
+			bundle, err := subscriptionutil.DecodeBundle(content, format)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := loadConfig(opts.ConfigFile)
+			if err != nil {
+				return err
+			}
+			repos, closeFn, err := openTemplateRepositories(cfg)
+			if err != nil {
+				return err
+			}
+			defer closeFn()
+
+			results, err := subscriptionutil.ImportBundle(cmd.Context(), repos, bundle, subscriptionutil.ImportOptions{
+				DryRun:   dryRun,
+				Publish:  publish,
+				Operator: operator,
+				Limits:   subscriptionutil.RenderLimits(cfg.Template),
+			}, time.Now())
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				cmd.Println("Dry run: no changes saved.")
+			}
+			for _, result := range results {
+				line := fmt.Sprintf("- [%s] %s/%s", result.Action, result.ClientType, result.Name)
+				if result.TemplateID != 0 {
+					line += fmt.Sprintf(" id=%d", result.TemplateID)
+				}
+				if result.HistoryImported > 0 {
+					line += fmt.Sprintf(" history=%d", result.HistoryImported)
+				}
+				if result.Published {
+					line += " published"
+				}
+				cmd.Println(line)
+				if !dryRun || result.Action == subscriptionutil.BundleActionUnchanged {
+					continue
+				}
+				if result.ContentDiff != "" {
+					cmd.Print(result.ContentDiff)
+				}
+				for _, change := range result.Variables {
+					cmd.Printf("  variable %s: %s\n", change.Name, change.Change)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&file, "file", "", "Bundle file produced by 'tools templates export'")
+	cmd.Flags().StringVar(&format, "format", "", "Bundle format: json or yaml (default: file extension, then content sniffing)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the planned changes and content diffs without saving")
+	cmd.Flags().BoolVar(&publish, "publish", false, "Publish templates whose draft differs from the latest published version")
+	cmd.Flags().StringVar(&operator, "operator", "cli", "Operator recorded in the publish history")
+
+	return cmd
+}
+
+// bundleFormatFromPath 按扩展名推断模板包格式，无法识别时返回空字符串。
+func bundleFormatFromPath(path string) string {
+	switch ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."); ext {
+	case subscriptionutil.BundleFormatJSON, subscriptionutil.BundleFormatYAML, "yml":
+		return ext
+	default:
+		return ""
+	}
+}
+
+func openTemplateRepositories(cfg config.Config) (*repository.Repositories, func(), error) {
+	db, closeFn, err := database.NewGorm(cfg.Database)
+	if err != nil {
+		return nil, nil, fmt.Errorf("connect database: %w", err)
+	}
+	if db == nil {
+		closeFn()
+		return nil, nil, errors.New("database configuration is required")
+	}
+
+	repos, err := repository.NewRepositories(db)
+	if err != nil {
+		closeFn()
+		return nil, nil, err
+	}
+	return repos, closeFn, nil
+}
@@ -0,0 +1,148 @@
+// Package apierror 定义对外 API 的结构化错误：稳定的错误码、HTTP 状态映射、本地化提示与字段级校验明细。
+package apierror
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// Code 机器可读的错误码，一经发布保持不变。
+type Code string
+
+// 错误码。
+const (
+	CodeInvalidArgument          Code = "invalid_argument"
+	CodeTemplateVariablesInvalid Code = "template_variables_invalid"
+	CodeUnauthorized             Code = "unauthorized"
+	CodeTokenMissing             Code = "token_missing"
+	CodeTokenInvalid             Code = "token_invalid"
+	CodeSignatureInvalid         Code = "signature_invalid"
+	CodeNodeTokenInvalid         Code = "node_token_invalid"
+	CodeInsufficientBalance      Code = "insufficient_balance"
+	CodeForbidden                Code = "forbidden"
+	CodeUserDisabled             Code = "user_disabled"
+	CodeAccessDenied             Code = "access_denied"
+	CodeNotFound                 Code = "not_found"
+	CodeRequestCanceled          Code = "request_canceled"
+	CodeConflict                 Code = "conflict"
+	CodeInvalidState             Code = "invalid_state"
+	CodeRateLimited              Code = "rate_limited"
+	CodeInternal                 Code = "internal"
+	CodeNotImplemented           Code = "not_implemented"
+	CodeTimeout                  Code = "timeout"
+)
+
+// 字段校验失败原因。
+const (
+	ReasonRequired      = "required"
+	ReasonInvalidType   = "invalid_type"
+	ReasonInvalidValue  = "invalid_value"
+	ReasonInvalidFormat = "invalid_format"
+)
+
+// FieldError 描述单个请求字段的校验失败，Field 为请求中的字段名。
+type FieldError struct {
+	Field  string
+	Reason string
+	Detail string
+}
+
+// Error 对外返回的结构化错误。Detail 为面向开发者的补充说明，不做本地化。
+type Error struct {
+	Code      Code
+	Detail    string
+	Fields    []FieldError
+	Variables *subtemplate.VariableError
+	cause     error
+}
+
+// New 按错误码构造错误。
+func New(code Code, detail string) *Error {
+	return &Error{Code: code, Detail: detail}
+}
+
+// Invalid 构造携带字段明细的参数错误，仍可通过 errors.Is 匹配 repository.ErrInvalidArgument。
+func Invalid(fields ...FieldError) *Error {
+	return &Error{Code: CodeInvalidArgument, Fields: fields, cause: repository.ErrInvalidArgument}
+}
+
+// Status 返回错误码对应的 HTTP 状态码，未登记的错误码按 500 处理。
+func (e *Error) Status() int {
+	if def, ok := definitions[e.Code]; ok {
+		return def.status
+	}
+	return http.StatusInternalServerError
+}
+
+func (e *Error) Error() string {
+	msg := string(e.Code)
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	for _, field := range e.Fields {
+		msg += "; " + field.Field + " " + field.Reason
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.cause }
+
+// domainErrors 领域错误到错误码的映射，按顺序匹配。
+var domainErrors = []struct {
+	err  error
+	code Code
+}{
+	{repository.ErrNotFound, CodeNotFound},
+	{kernel.ErrNotFound, CodeNotFound},
+	{repository.ErrInvalidArgument, CodeInvalidArgument},
+	{kernel.ErrProviderNotFound, CodeInvalidArgument},
+	{repository.ErrConflict, CodeConflict},
+	{repository.ErrInvalidState, CodeInvalidState},
+	{repository.ErrInsufficientBalance, CodeInsufficientBalance},
+	{repository.ErrForbidden, CodeForbidden},
+	{repository.ErrUnauthorized, CodeUnauthorized},
+	{kernel.ErrNotImplemented, CodeNotImplemented},
+	{context.Canceled, CodeRequestCanceled},
+	{context.DeadlineExceeded, CodeTimeout},
+}
+
+// From 将任意错误转换为结构化错误：已是 *Error 时原样返回，领域错误按映射表转换，其余视为内部错误且不暴露原始信息。
+func From(err error) *Error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var varErr *subtemplate.VariableError
+	if errors.As(err, &varErr) {
+		return &Error{Code: CodeTemplateVariablesInvalid, Variables: varErr, cause: err}
+	}
+
+	for _, known := range domainErrors {
+		if errors.Is(err, known.err) {
+			return &Error{Code: known.code, Detail: stripSentinels(err.Error()), cause: err}
+		}
+	}
+
+	return &Error{Code: CodeInternal, cause: err}
+}
+
+// stripSentinels 去掉错误信息中的哨兵错误文本（如 "repository: invalid argument"），只保留调用方补充的上下文。
+func stripSentinels(msg string) string {
+	for _, known := range domainErrors {
+		sentinel := known.err.Error()
+		msg = strings.ReplaceAll(msg, sentinel+": ", "")
+		msg = strings.ReplaceAll(msg, sentinel, "")
+	}
+	return strings.Trim(strings.TrimSpace(msg), ":; ")
+}
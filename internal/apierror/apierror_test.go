@@ -0,0 +1,93 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+func writeError(t *testing.T, err error, acceptLanguage string) (int, Body) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/test", nil)
+	if acceptLanguage != "" {
+		r.Header.Set("Accept-Language", acceptLanguage)
+	}
+	w := httptest.NewRecorder()
+	Write(w, r, err)
+
+	var body Body
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestWriteMapsDomainErrors(t *testing.T) {
+	cases := []struct {
+		err    error
+		status int
+		code   Code
+	}{
+		{repository.ErrNotFound, http.StatusNotFound, CodeNotFound},
+		{repository.ErrInvalidArgument, http.StatusBadRequest, CodeInvalidArgument},
+		{repository.ErrConflict, http.StatusConflict, CodeConflict},
+		{repository.ErrInvalidState, http.StatusConflict, CodeInvalidState},
+		{repository.ErrInsufficientBalance, http.StatusPaymentRequired, CodeInsufficientBalance},
+		{repository.ErrForbidden, http.StatusForbidden, CodeForbidden},
+		{repository.ErrUnauthorized, http.StatusUnauthorized, CodeUnauthorized},
+		{New(CodeRateLimited, ""), http.StatusTooManyRequests, CodeRateLimited},
+	}
+	for _, tc := range cases {
+		status, body := writeError(t, fmt.Errorf("wrapped: %w", tc.err), "")
+		require.Equal(t, tc.status, status, tc.code)
+		require.Equal(t, tc.code, body.Code)
+		require.Equal(t, Message(tc.code, LangEnglish), body.Message)
+	}
+}
+
+func TestWriteHidesInternalDetails(t *testing.T) {
+	status, body := writeError(t, fmt.Errorf("%w: no exchange rate for currency", repository.ErrInvalidArgument), "")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "no exchange rate for currency", body.Detail)
+
+	status, body = writeError(t, errors.New("dial tcp 10.0.0.1:3306: connection refused"), "zh-CN,zh;q=0.9")
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, CodeInternal, body.Code)
+	require.Equal(t, "服务器内部错误", body.Message)
+	require.Empty(t, body.Detail)
+}
+
+func TestWriteLocalizesFieldDetails(t *testing.T) {
+	var req struct {
+		Pattern    string `json:"pattern"`
+		ClientType string `json:"client_type"`
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"client_type": "clash"}`))
+	r.Header.Set("Content-Type", "application/json")
+	parseErr := httpx.Parse(r, &req)
+	require.Error(t, parseErr)
+
+	apiErr := FromParse(parseErr)
+	require.ErrorIs(t, apiErr, repository.ErrInvalidArgument)
+	status, body := writeError(t, apiErr, "zh-CN")
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "请求参数不合法", body.Message)
+	require.Len(t, body.Fields, 1)
+	require.Equal(t, "pattern", body.Fields[0].Field)
+	require.Equal(t, ReasonRequired, body.Fields[0].Reason)
+	require.Equal(t, "pattern 为必填项", body.Fields[0].Message)
+
+	_, body = writeError(t, &subtemplate.VariableError{Undeclared: []string{"vars.port"}}, "en-US")
+	require.Equal(t, CodeTemplateVariablesInvalid, body.Code)
+	require.NotNil(t, body.Variables)
+	require.Equal(t, []string{"vars.port"}, body.Variables.Undeclared)
+}
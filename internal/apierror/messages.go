@@ -0,0 +1,83 @@
+package apierror
+
+import (
+	"net/http"
+
+	"golang.org/x/text/language"
+)
+
+// 支持的提示语言，Accept-Language 无法匹配时使用英文。
+const (
+	LangEnglish = "en"
+	LangChinese = "zh"
+)
+
+var langMatcher = language.NewMatcher([]language.Tag{language.English, language.Chinese})
+
+type definition struct {
+	status   int
+	messages map[string]string
+}
+
+var definitions = map[Code]definition{
+	CodeInvalidArgument:          {http.StatusBadRequest, map[string]string{LangEnglish: "Invalid request parameters", LangChinese: "请求参数不合法"}},
+	CodeTemplateVariablesInvalid: {http.StatusBadRequest, map[string]string{LangEnglish: "Template variables are invalid", LangChinese: "模板变量校验失败"}},
+	CodeUnauthorized:             {http.StatusUnauthorized, map[string]string{LangEnglish: "Authentication required", LangChinese: "未登录或登录已失效"}},
+	CodeTokenMissing:             {http.StatusUnauthorized, map[string]string{LangEnglish: "Missing authorization header", LangChinese: "缺少访问令牌"}},
+	CodeTokenInvalid:             {http.StatusUnauthorized, map[string]string{LangEnglish: "Invalid or expired token", LangChinese: "访问令牌无效或已过期"}},
+	CodeSignatureInvalid:         {http.StatusUnauthorized, map[string]string{LangEnglish: "Request signature verification failed", LangChinese: "请求签名校验失败"}},
+	CodeNodeTokenInvalid:         {http.StatusUnauthorized, map[string]string{LangEnglish: "Invalid node token", LangChinese: "节点令牌无效"}},
+	CodeInsufficientBalance:      {http.StatusPaymentRequired, map[string]string{LangEnglish: "Insufficient balance", LangChinese: "余额不足"}},
+	CodeForbidden:                {http.StatusForbidden, map[string]string{LangEnglish: "Permission denied", LangChinese: "权限不足"}},
+	CodeUserDisabled:             {http.StatusForbidden, map[string]string{LangEnglish: "User is disabled", LangChinese: "用户已被禁用"}},
+	CodeAccessDenied:             {http.StatusForbidden, map[string]string{LangEnglish: "Access denied from this address", LangChinese: "来源地址不允许访问"}},
+	CodeNotFound:                 {http.StatusNotFound, map[string]string{LangEnglish: "Resource not found", LangChinese: "资源不存在"}},
+	CodeRequestCanceled:          {http.StatusRequestTimeout, map[string]string{LangEnglish: "Request was canceled", LangChinese: "请求已取消"}},
+	CodeConflict:                 {http.StatusConflict, map[string]string{LangEnglish: "Resource conflict", LangChinese: "资源冲突"}},
+	CodeInvalidState:             {http.StatusConflict, map[string]string{LangEnglish: "Current state does not allow this operation", LangChinese: "当前状态不允许该操作"}},
+	CodeRateLimited:              {http.StatusTooManyRequests, map[string]string{LangEnglish: "Rate limit exceeded", LangChinese: "请求过于频繁"}},
+	CodeInternal:                 {http.StatusInternalServerError, map[string]string{LangEnglish: "Internal server error", LangChinese: "服务器内部错误"}},
+	CodeNotImplemented:           {http.StatusNotImplemented, map[string]string{LangEnglish: "Operation not implemented", LangChinese: "暂不支持该操作"}},
+	CodeTimeout:                  {http.StatusGatewayTimeout, map[string]string{LangEnglish: "Request timed out", LangChinese: "请求超时"}},
+}
+
+var reasonMessages = map[string]map[string]string{
+	ReasonRequired:      {LangEnglish: "is required", LangChinese: "为必填项"},
+	ReasonInvalidType:   {LangEnglish: "has an invalid type", LangChinese: "类型不正确"},
+	ReasonInvalidValue:  {LangEnglish: "has an invalid value", LangChinese: "取值不合法"},
+	ReasonInvalidFormat: {LangEnglish: "has an invalid format", LangChinese: "格式不正确"},
+}
+
+// Language 按 Accept-Language 选择提示语言。
+func Language(r *http.Request) string {
+	tag, _ := language.MatchStrings(langMatcher, r.Header.Get("Accept-Language"))
+	if base, _ := tag.Base(); base.String() == LangChinese {
+		return LangChinese
+	}
+	return LangEnglish
+}
+
+// Message 返回错误码在指定语言下的提示。
+func Message(code Code, lang string) string {
+	def, ok := definitions[code]
+	if !ok {
+		def = definitions[CodeInternal]
+	}
+	return localize(def.messages, lang)
+}
+
+// FieldMessage 返回字段校验失败原因在指定语言下的提示。
+func FieldMessage(field, reason, lang string) string {
+	messages, ok := reasonMessages[reason]
+	if !ok {
+		messages = reasonMessages[ReasonInvalidValue]
+	}
+	return field + " " + localize(messages, lang)
+}
+
+func localize(messages map[string]string, lang string) string {
+	if msg, ok := messages[lang]; ok {
+		return msg
+	}
+	return messages[LangEnglish]
+}
@@ -0,0 +1,88 @@
+package apierror
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// Body 错误响应体。
+type Body struct {
+	Code      Code                       `json:"code"`
+	Message   string                     `json:"message"`
+	Detail    string                     `json:"detail,omitempty"`
+	Fields    []FieldBody                `json:"fields,omitempty"`
+	Variables *subtemplate.VariableError `json:"variables,omitempty"`
+}
+
+// FieldBody 响应体中的字段校验明细。
+type FieldBody struct {
+	Field   string `json:"field"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// Write 将错误写为 JSON 响应，提示语言取自 Accept-Language；内部错误只记录日志，不向调用方暴露原始信息。
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := From(err)
+	if apiErr == nil {
+		apiErr = New(CodeInternal, "")
+	}
+
+	lang := Language(r)
+	body := Body{
+		Code:      apiErr.Code,
+		Message:   Message(apiErr.Code, lang),
+		Detail:    apiErr.Detail,
+		Variables: apiErr.Variables,
+	}
+	status := apiErr.Status()
+	if status >= http.StatusInternalServerError {
+		logx.WithContext(r.Context()).Errorf("request %s %s failed: %v", r.Method, r.URL.Path, err)
+		body.Detail = ""
+	}
+	for _, field := range apiErr.Fields {
+		body.Fields = append(body.Fields, FieldBody{
+			Field:   field.Field,
+			Reason:  field.Reason,
+			Message: FieldMessage(field.Field, field.Reason, lang),
+			Detail:  field.Detail,
+		})
+	}
+
+	w.Header().Set("Content-Language", lang)
+	httpx.WriteJsonCtx(r.Context(), w, status, body)
+}
+
+var parseFieldPatterns = []struct {
+	pattern *regexp.Regexp
+	reason  string
+}{
+	{regexp.MustCompile(`value ".*" for field "([^"]+)" is not defined in options`), ReasonInvalidValue},
+	{regexp.MustCompile(`type mismatch for field "([^"]+)"`), ReasonInvalidType},
+	{regexp.MustCompile(`"([^"]+)" is not (?:fully )?set`), ReasonRequired},
+}
+
+// FromParse 将 httpx.Parse 的失败转换为参数错误，并尽量识别出错字段；请求类型自行校验返回的 *Error 原样保留。
+func FromParse(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	invalid := Invalid()
+	invalid.Detail = err.Error()
+	for _, candidate := range parseFieldPatterns {
+		if match := candidate.pattern.FindStringSubmatch(err.Error()); match != nil {
+			invalid.Fields = append(invalid.Fields, FieldError{Field: match[1], Reason: candidate.reason})
+			break
+		}
+	}
+	return invalid
+}
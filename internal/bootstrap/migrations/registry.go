@@ -7,9 +7,11 @@ import (
 	"sort"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
 )
 
 // SchemaMigration stores executed migration metadata.
@@ -216,6 +218,401 @@ var migrationRegistry = []Migration{
 			return nil
 		},
 	},
+	{
+		Version: 2026030101,
+		Name:    "subscription-plan-binding",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.Subscription{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.Subscription{}, "plan_id") {
+				if err := migrator.DropColumn(&repository.Subscription{}, "plan_id"); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026031501,
+		Name:    "subscription-auto-renew",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.Subscription{},
+				&repository.Notification{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.Notification{}) {
+				if err := migrator.DropTable(&repository.Notification{}); err != nil {
+					return err
+				}
+			}
+			columns := []string{"auto_renew", "auto_renew_failures", "auto_renew_next_at", "auto_renew_last_error"}
+			for _, column := range columns {
+				if migrator.HasColumn(&repository.Subscription{}, column) {
+					if err := migrator.DropColumn(&repository.Subscription{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026040101,
+		Name:    "multi-currency-pricing",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.PlanPrice{},
+				&repository.ExchangeRate{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, model := range []any{&repository.ExchangeRate{}, &repository.PlanPrice{}} {
+				if migrator.HasTable(model) {
+					if err := migrator.DropTable(model); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026041501,
+		Name:    "invoices",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.BillingProfile{},
+				&repository.Invoice{},
+				&repository.InvoiceSequence{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, model := range []any{&repository.InvoiceSequence{}, &repository.Invoice{}, &repository.BillingProfile{}} {
+				if migrator.HasTable(model) {
+					if err := migrator.DropTable(model); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026050101,
+		Name:    "payment-reconciliation",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.ReconciliationRun{},
+				&repository.ReconciliationIssue{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, model := range []any{&repository.ReconciliationIssue{}, &repository.ReconciliationRun{}} {
+				if migrator.HasTable(model) {
+					if err := migrator.DropTable(model); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026051501,
+		Name:    "affiliate-commissions",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.InviteCode{},
+				&repository.Referral{},
+				&repository.CommissionAccount{},
+				&repository.CommissionEntry{},
+				&repository.CommissionPayout{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, model := range []any{
+				&repository.CommissionPayout{},
+				&repository.CommissionEntry{},
+				&repository.CommissionAccount{},
+				&repository.Referral{},
+				&repository.InviteCode{},
+			} {
+				if migrator.HasTable(model) {
+					if err := migrator.DropTable(model); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026060101,
+		Name:    "redeem-codes",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.RedeemBatch{},
+				&repository.RedeemCode{},
+				&repository.RedeemRedemption{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, model := range []any{
+				&repository.RedeemRedemption{},
+				&repository.RedeemCode{},
+				&repository.RedeemBatch{},
+			} {
+				if migrator.HasTable(model) {
+					if err := migrator.DropTable(model); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026061501,
+		Name:    "traffic-reset-policies",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.Plan{},
+				&repository.Subscription{},
+				&repository.SubscriptionTrafficPeriod{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.SubscriptionTrafficPeriod{}) {
+				if err := migrator.DropTable(&repository.SubscriptionTrafficPeriod{}); err != nil {
+					return err
+				}
+			}
+			subscriptionColumns := []string{
+				"traffic_reset_policy",
+				"traffic_reset_day",
+				"traffic_extra_bytes",
+				"traffic_period_start_at",
+				"traffic_next_reset_at",
+			}
+			for _, column := range subscriptionColumns {
+				if migrator.HasColumn(&repository.Subscription{}, column) {
+					if err := migrator.DropColumn(&repository.Subscription{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			planColumns := []string{"traffic_reset_policy", "traffic_addon_bytes", "traffic_addon_price_cents"}
+			for _, column := range planColumns {
+				if migrator.HasColumn(&repository.Plan{}, column) {
+					if err := migrator.DropColumn(&repository.Plan{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026070101,
+		Name:    "subscription-lifecycle",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.Subscription{},
+				&repository.SubscriptionEvent{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.SubscriptionEvent{}) {
+				if err := migrator.DropTable(&repository.SubscriptionEvent{}); err != nil {
+					return err
+				}
+			}
+			for _, column := range []string{"status_reason", "status_changed_at"} {
+				if migrator.HasColumn(&repository.Subscription{}, column) {
+					if err := migrator.DropColumn(&repository.Subscription{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026070501,
+		Name:    "subscription-access-tokens",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.Subscription{},
+				&repository.SubscriptionToken{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.SubscriptionToken{}) {
+				return migrator.DropTable(&repository.SubscriptionToken{})
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026071001,
+		Name:    "subscription-access-logs",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.SubscriptionAccessLog{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.SubscriptionAccessLog{}) {
+				return migrator.DropTable(&repository.SubscriptionAccessLog{})
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026071501,
+		Name:    "node-entitlements",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.Node{},
+				&repository.PlanNode{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.PlanNode{}) {
+				if err := migrator.DropTable(&repository.PlanNode{}); err != nil {
+					return err
+				}
+			}
+			if migrator.HasColumn(&repository.Node{}, "sort_order") {
+				return migrator.DropColumn(&repository.Node{}, "sort_order")
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026072001,
+		Name:    "node-groups",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(
+				&repository.NodeGroup{},
+				&repository.NodeGroupNode{},
+				&repository.PlanNodeGroup{},
+			)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, model := range []any{&repository.PlanNodeGroup{}, &repository.NodeGroupNode{}, &repository.NodeGroup{}} {
+				if migrator.HasTable(model) {
+					if err := migrator.DropTable(model); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026080101,
+		Name:    "client-type-rules",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.ClientTypeRule{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasTable(&repository.ClientTypeRule{}) {
+				return migrator.DropTable(&repository.ClientTypeRule{})
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026081001,
+		Name:    "subscription-billing-period",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.Subscription{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, column := range []string{"period_paid_cents", "period_currency", "period_started_at"} {
+				if migrator.HasColumn(&repository.Subscription{}, column) {
+					if err := migrator.DropColumn(&repository.Subscription{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026081101,
+		Name:    "node-agent-token",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			return db.WithContext(ctx).AutoMigrate(&repository.Node{})
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			for _, column := range []string{"agent_token_hash", "agent_token_rotated_at"} {
+				if migrator.HasColumn(&repository.Node{}, column) {
+					if err := migrator.DropColumn(&repository.Node{}, column); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		},
+	},
+	{
+		Version: 2026081102,
+		Name:    "subscription-proxy-uuid",
+		Up: func(ctx context.Context, db *gorm.DB) error {
+			if err := db.WithContext(ctx).AutoMigrate(&repository.Subscription{}); err != nil {
+				return err
+			}
+			return backfillProxyUUIDs(ctx, db)
+		},
+		Down: func(ctx context.Context, db *gorm.DB) error {
+			migrator := db.WithContext(ctx).Migrator()
+			if migrator.HasColumn(&repository.Subscription{}, "proxy_uuid") {
+				return migrator.DropColumn(&repository.Subscription{}, "proxy_uuid")
+			}
+			return nil
+		},
+	},
+}
+
+// backfillProxyUUIDs 为存量订阅写入与旧版一致的 token 派生 UUID，升级后 vmess/vless 客户端无需重新配置。
+func backfillProxyUUIDs(ctx context.Context, db *gorm.DB) error {
+	var subs []repository.Subscription
+	return db.WithContext(ctx).
+		Select("id", "token").
+		Where("proxy_uuid IS NULL OR proxy_uuid = ''").
+		FindInBatches(&subs, 500, func(_ *gorm.DB, _ int) error {
+			for _, sub := range subs {
+				proxyUUID := subtemplate.SubscriptionUUID(sub.Token)
+				if proxyUUID == "" {
+					proxyUUID = uuid.NewString()
+				}
+				if err := db.WithContext(ctx).Model(&repository.Subscription{}).Where("id = ?", sub.ID).Update("proxy_uuid", proxyUUID).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
 }
 
 func init() {
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	adminroutes "github.com/zero-net-panel/zero-net-panel/internal/admin/routes"
@@ -174,6 +175,7 @@ func seedNodes(tx *gorm.DB) error {
 		Tags:         []string{"premium", "asia"},
 		Protocols:    []string{"http", "grpc"},
 		CapacityMbps: 1000,
+		SortOrder:    10,
 		Description:  "香港高带宽边缘节点示例",
 		CreatedAt:    now.Add(-36 * time.Hour),
 		UpdatedAt:    now.Add(-30 * time.Minute),
@@ -220,6 +222,7 @@ func seedNodes(tx *gorm.DB) error {
 		Tags:         []string{"standard", "america"},
 		Protocols:    []string{"http"},
 		CapacityMbps: 600,
+		SortOrder:    20,
 		Description:  "北美标准线路示例节点",
 		CreatedAt:    now.Add(-72 * time.Hour),
 		UpdatedAt:    now.Add(-6 * time.Hour),
@@ -260,10 +263,20 @@ func seedTemplates(tx *gorm.DB) error {
 		Description: "提供 Clash Premium YAML 订阅示例",
 		ClientType:  "clash",
 		Format:      "go_template",
-		Content:     `# Clash Premium subscription\nproxies:\n  - name: {{ .subscription.name }}\n    type: trojan\n    server: {{ index .nodes 0 "hostname" }}\n    port: {{ index .nodes 0 "port" }}\n    password: {{ .subscription.token }}\n`,
+		Content: `# Clash Premium subscription
+proxies:
+{{- range .nodes }}{{ $node := . }}{{ range .kernels }}
+  - name: {{ $node.name }}-{{ .protocol }}
+    type: trojan
+    server: {{ .host }}
+    port: {{ .port }}
+    password: {{ $.subscription.uuid }}
+{{- end }}{{ end }}
+`,
 		Variables: map[string]repository.TemplateVariable{
-			"subscription.name":  {ValueType: "string", Description: "订阅展示名称"},
-			"subscription.token": {ValueType: "string", Description: "鉴权密钥", Required: true},
+			"subscription.name": {ValueType: "string", Description: "订阅展示名称"},
+			"subscription.uuid": {ValueType: "string", Description: "节点代理凭据", Required: true},
+			"nodes":             {ValueType: "array", Description: "订阅可用节点及各协议接入点"},
 		},
 		IsDefault:       true,
 		Version:         1,
@@ -371,6 +384,7 @@ func seedSubscriptions(tx *gorm.DB) error {
 		TemplateID:           defaultTemplateID,
 		AvailableTemplateIDs: allowed,
 		Token:                "demo-token-123",
+		ProxyUUID:            uuid.NewString(),
 		ExpiresAt:            now.Add(30 * 24 * time.Hour),
 		TrafficTotalBytes:    1 << 40,
 		TrafficUsedBytes:     256 << 30,
@@ -21,7 +21,15 @@ type Config struct {
 	Metrics  MetricsConfig    `json:"metrics" yaml:"Metrics"`
 	Admin    AdminConfig      `json:"admin" yaml:"Admin"`
 	Webhook  WebhookConfig    `json:"webhook" yaml:"Webhook"`
+	Node     NodeConfig       `json:"node" yaml:"Node"`
+	GeoIP    GeoIPConfig      `json:"geoip" yaml:"GeoIP"`
+	Template TemplateConfig   `json:"template" yaml:"Template"`
 	GRPC     GRPCServerConfig `json:"grpcServer" yaml:"GRPCServer"`
+	Jobs     JobsConfig       `json:"jobs" yaml:"Jobs"`
+	Invoice  InvoiceConfig    `json:"invoice" yaml:"Invoice"`
+
+	Registration RegistrationConfig `json:"registration" yaml:"Registration"`
+	Affiliate    AffiliateConfig    `json:"affiliate" yaml:"Affiliate"`
 }
 
 type ProjectConfig struct {
@@ -163,6 +171,30 @@ func (s *StripeWebhookConfig) Normalize() {
 	}
 }
 
+// NodeConfig 控制节点回调接口（拉取用户列表、上报在线 IP）的来源限制与设备数限制；回调凭据按节点在管理端签发。
+type NodeConfig struct {
+	AllowCIDRs        []string      `json:"allowCidrs" yaml:"AllowCIDRs"`
+	OnlineWindow      time.Duration `json:"onlineWindow" yaml:"OnlineWindow"`
+	DeviceLimitAction string        `json:"deviceLimitAction" yaml:"DeviceLimitAction"`
+}
+
+// 在线设备数超过订阅限制时的处理方式。
+const (
+	DeviceLimitActionDeny = "deny"
+	DeviceLimitActionFlag = "flag"
+)
+
+// Normalize 设置在线窗口与超限处理方式默认值。
+func (n *NodeConfig) Normalize() {
+	if n.OnlineWindow <= 0 {
+		n.OnlineWindow = 3 * time.Minute
+	}
+	n.DeviceLimitAction = strings.ToLower(strings.TrimSpace(n.DeviceLimitAction))
+	if n.DeviceLimitAction != DeviceLimitActionFlag {
+		n.DeviceLimitAction = DeviceLimitActionDeny
+	}
+}
+
 // GRPCServerConfig 控制内建 gRPC 服务监听配置。
 type GRPCServerConfig struct {
 	Enable     *bool  `json:"enable" yaml:"Enable"`
@@ -204,12 +236,215 @@ func (g GRPCServerConfig) ReflectionEnabled() bool {
 	return *g.Reflection
 }
 
+// JobsConfig 控制内建后台定时任务。
+type JobsConfig struct {
+	AutoRenew             AutoRenewJobConfig             `json:"autoRenew" yaml:"AutoRenew"`
+	TrafficReset          TrafficResetJobConfig          `json:"trafficReset" yaml:"TrafficReset"`
+	SubscriptionLifecycle SubscriptionLifecycleJobConfig `json:"subscriptionLifecycle" yaml:"SubscriptionLifecycle"`
+	AccessAudit           AccessAuditJobConfig           `json:"accessAudit" yaml:"AccessAudit"`
+}
+
+// Normalize 设置各任务默认值。
+func (j *JobsConfig) Normalize() {
+	j.AutoRenew.Normalize()
+	j.TrafficReset.Normalize()
+	j.SubscriptionLifecycle.Normalize()
+	j.AccessAudit.Normalize()
+}
+
+// AutoRenewJobConfig 自动续费调度配置。
+type AutoRenewJobConfig struct {
+	Enable        bool          `json:"enable" yaml:"Enable"`
+	Interval      time.Duration `json:"interval" yaml:"Interval"`
+	LeadDays      int           `json:"leadDays" yaml:"LeadDays"`
+	GraceDays     int           `json:"graceDays" yaml:"GraceDays"`
+	RetryInterval time.Duration `json:"retryInterval" yaml:"RetryInterval"`
+	MaxAttempts   int           `json:"maxAttempts" yaml:"MaxAttempts"`
+	BatchSize     int           `json:"batchSize" yaml:"BatchSize"`
+}
+
+// Normalize 设置扫描周期、提前天数、宽限期与重试策略的默认值。
+func (a *AutoRenewJobConfig) Normalize() {
+	if a.Interval <= 0 {
+		a.Interval = 10 * time.Minute
+	}
+	if a.LeadDays <= 0 {
+		a.LeadDays = 3
+	}
+	if a.GraceDays <= 0 {
+		a.GraceDays = 3
+	}
+	if a.RetryInterval <= 0 {
+		a.RetryInterval = 6 * time.Hour
+	}
+	if a.MaxAttempts <= 0 {
+		a.MaxAttempts = 3
+	}
+	if a.BatchSize <= 0 {
+		a.BatchSize = 100
+	}
+}
+
+// TrafficResetJobConfig 订阅流量周期重置调度配置。
+type TrafficResetJobConfig struct {
+	Enable    bool          `json:"enable" yaml:"Enable"`
+	Interval  time.Duration `json:"interval" yaml:"Interval"`
+	BatchSize int           `json:"batchSize" yaml:"BatchSize"`
+}
+
+// Normalize 设置扫描周期与批量大小的默认值。
+func (t *TrafficResetJobConfig) Normalize() {
+	if t.Interval <= 0 {
+		t.Interval = 5 * time.Minute
+	}
+	if t.BatchSize <= 0 {
+		t.BatchSize = 200
+	}
+}
+
+// SubscriptionLifecycleJobConfig 订阅到期/耗尽/暂停流转与状态事件投递的调度配置。
+type SubscriptionLifecycleJobConfig struct {
+	Enable           bool          `json:"enable" yaml:"Enable"`
+	Interval         time.Duration `json:"interval" yaml:"Interval"`
+	BatchSize        int           `json:"batchSize" yaml:"BatchSize"`
+	MaxEventAttempts int           `json:"maxEventAttempts" yaml:"MaxEventAttempts"`
+}
+
+// Normalize 设置扫描周期、批量大小与事件重投次数的默认值。
+func (s *SubscriptionLifecycleJobConfig) Normalize() {
+	if s.Interval <= 0 {
+		s.Interval = time.Minute
+	}
+	if s.BatchSize <= 0 {
+		s.BatchSize = 200
+	}
+	if s.MaxEventAttempts <= 0 {
+		s.MaxEventAttempts = 5
+	}
+}
+
+// AccessAuditJobConfig 订阅拉取日志巡检：按窗口统计 token 的不同来源 IP 数，超过阈值时自动暂停，并清理过期日志。
+type AccessAuditJobConfig struct {
+	Enable             bool          `json:"enable" yaml:"Enable"`
+	Interval           time.Duration `json:"interval" yaml:"Interval"`
+	Window             time.Duration `json:"window" yaml:"Window"`
+	SuspendDistinctIPs int           `json:"suspendDistinctIps" yaml:"SuspendDistinctIPs"`
+	Retention          time.Duration `json:"retention" yaml:"Retention"`
+	BatchSize          int           `json:"batchSize" yaml:"BatchSize"`
+}
+
+// Normalize 设置巡检周期、统计窗口与日志保留时长默认值；SuspendDistinctIPs 为 0 时只统计不暂停。
+func (a *AccessAuditJobConfig) Normalize() {
+	if a.Interval <= 0 {
+		a.Interval = 10 * time.Minute
+	}
+	if a.Window <= 0 {
+		a.Window = 24 * time.Hour
+	}
+	if a.SuspendDistinctIPs < 0 {
+		a.SuspendDistinctIPs = 0
+	}
+	if a.Retention <= 0 {
+		a.Retention = 30 * 24 * time.Hour
+	}
+	if a.BatchSize <= 0 {
+		a.BatchSize = 100
+	}
+}
+
+// GeoIPConfig 离线 IP 归属地库，用于订阅拉取日志的国家字段。
+type GeoIPConfig struct {
+	File string `json:"file" yaml:"File"`
+}
+
+// TemplateConfig 订阅模板渲染（试渲染、发布前检查与用户拉取）的沙箱限制，以及用户订阅渲染缓存时长。
+type TemplateConfig struct {
+	RenderTimeout  time.Duration `json:"renderTimeout" yaml:"RenderTimeout"`
+	MaxOutputBytes int           `json:"maxOutputBytes" yaml:"MaxOutputBytes"`
+	RenderCacheTTL time.Duration `json:"renderCacheTtl" yaml:"RenderCacheTTL"`
+	// DefaultClientType 为 User-Agent 未匹配任何规则或匹配的客户端类型没有默认模板时回退使用的客户端类型，为空时使用订阅自身的模板。
+	DefaultClientType string `json:"defaultClientType" yaml:"DefaultClientType"`
+}
+
+// Normalize 设置渲染超时、输出大小上限与渲染缓存时长默认值。
+func (t *TemplateConfig) Normalize() {
+	if t.RenderTimeout <= 0 {
+		t.RenderTimeout = 2 * time.Second
+	}
+	if t.MaxOutputBytes <= 0 {
+		t.MaxOutputBytes = 2 << 20
+	}
+	if t.RenderCacheTTL <= 0 {
+		t.RenderCacheTTL = 5 * time.Minute
+	}
+	t.DefaultClientType = strings.ToLower(strings.TrimSpace(t.DefaultClientType))
+}
+
+// InvoiceConfig 发票开具配置，卖方信息会快照到每张发票。
+type InvoiceConfig struct {
+	SellerName       string `json:"sellerName" yaml:"SellerName"`
+	SellerAddress    string `json:"sellerAddress" yaml:"SellerAddress"`
+	SellerTaxID      string `json:"sellerTaxId" yaml:"SellerTaxID"`
+	SellerEmail      string `json:"sellerEmail" yaml:"SellerEmail"`
+	NumberPrefix     string `json:"numberPrefix" yaml:"NumberPrefix"`
+	CreditNotePrefix string `json:"creditNotePrefix" yaml:"CreditNotePrefix"`
+	TemplateFile     string `json:"templateFile" yaml:"TemplateFile"`
+}
+
+// Normalize 设置发票编号前缀默认值。
+func (i *InvoiceConfig) Normalize() {
+	i.SellerName = strings.TrimSpace(i.SellerName)
+	i.NumberPrefix = strings.ToUpper(strings.TrimSpace(i.NumberPrefix))
+	if i.NumberPrefix == "" {
+		i.NumberPrefix = "INV"
+	}
+	i.CreditNotePrefix = strings.ToUpper(strings.TrimSpace(i.CreditNotePrefix))
+	if i.CreditNotePrefix == "" {
+		i.CreditNotePrefix = "CN"
+	}
+	i.TemplateFile = strings.TrimSpace(i.TemplateFile)
+}
+
+// RegistrationConfig 控制用户自助注册。
+type RegistrationConfig struct {
+	Enable            bool `json:"enable" yaml:"Enable"`
+	RequireInviteCode bool `json:"requireInviteCode" yaml:"RequireInviteCode"`
+}
+
+// AffiliateConfig 推广返佣配置。
+type AffiliateConfig struct {
+	Enable            bool    `json:"enable" yaml:"Enable"`
+	CommissionPercent float64 `json:"commissionPercent" yaml:"CommissionPercent"`
+	MinWithdrawCents  int64   `json:"minWithdrawCents" yaml:"MinWithdrawCents"`
+}
+
+// Normalize 将返佣比例限制在 0-100 之间。
+func (a *AffiliateConfig) Normalize() {
+	if a.CommissionPercent < 0 {
+		a.CommissionPercent = 0
+	}
+	if a.CommissionPercent > 100 {
+		a.CommissionPercent = 100
+	}
+	if a.MinWithdrawCents < 0 {
+		a.MinWithdrawCents = 0
+	}
+}
+
 // Normalize 将配置补齐默认值。
 func (c *Config) Normalize() {
 	c.Metrics.Normalize()
 	c.Admin.Normalize()
 	c.Webhook.Normalize()
+	c.Node.Normalize()
 	c.GRPC.Normalize()
+	c.Jobs.Normalize()
+	c.Template.Normalize()
+	c.Invoice.Normalize()
+	c.Affiliate.Normalize()
+	if c.Invoice.SellerName == "" {
+		c.Invoice.SellerName = c.Project.Name
+	}
 	c.Middlewares.Prometheus = c.Metrics.Enabled()
 	c.Middlewares.Metrics = c.Metrics.Enabled()
 }
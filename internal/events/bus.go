@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// SubscriptionHandler 处理订阅状态变更事件。投递为至少一次语义，处理函数需保证幂等；
+// 返回错误时事件会在下一轮调度中重投给全部订阅者。
+type SubscriptionHandler func(ctx context.Context, event repository.SubscriptionEvent) error
+
+type namedSubscriptionHandler struct {
+	name    string
+	handler SubscriptionHandler
+}
+
+// Bus 进程内事件分发器，按注册顺序调用订阅者。
+type Bus struct {
+	mu           sync.RWMutex
+	subscription []namedSubscriptionHandler
+}
+
+// NewBus 创建事件分发器。
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// OnSubscription 注册订阅状态变更处理函数；同名处理函数会被替换，便于重复初始化。
+func (b *Bus) OnSubscription(name string, handler SubscriptionHandler) {
+	if b == nil || handler == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, existing := range b.subscription {
+		if existing.name == name {
+			b.subscription[i].handler = handler
+			return
+		}
+	}
+	b.subscription = append(b.subscription, namedSubscriptionHandler{name: name, handler: handler})
+}
+
+// DispatchSubscription 将事件依次交给全部订阅者，汇总各订阅者返回的错误。
+func (b *Bus) DispatchSubscription(ctx context.Context, event repository.SubscriptionEvent) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.RLock()
+	handlers := append([]namedSubscriptionHandler(nil), b.subscription...)
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, h := range handlers {
+		if err := h.handler(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,72 @@
+package affiliate
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	adminaffiliate "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/affiliate"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// AdminListPayoutsHandler lists commission payout requests.
+func AdminListPayoutsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminListPayoutsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminaffiliate.NewPayoutsLogic(r.Context(), svcCtx)
+		resp, err := logic.List(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminApprovePayoutHandler approves a pending payout request.
+func AdminApprovePayoutHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminReviewPayoutRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminaffiliate.NewPayoutsLogic(r.Context(), svcCtx)
+		resp, err := logic.Approve(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRejectPayoutHandler rejects a pending payout request and returns the held commission.
+func AdminRejectPayoutHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminReviewPayoutRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminaffiliate.NewPayoutsLogic(r.Context(), svcCtx)
+		resp, err := logic.Reject(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -0,0 +1,106 @@
+package clienttyperules
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	adminclienttyperules "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/clienttyperules"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// AdminListClientTypeRulesHandler lists User-Agent routing rules in match order.
+func AdminListClientTypeRulesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logic := adminclienttyperules.NewClientTypeRulesLogic(r.Context(), svcCtx)
+		resp, err := logic.List()
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminCreateClientTypeRuleHandler creates a User-Agent routing rule.
+func AdminCreateClientTypeRuleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminCreateClientTypeRuleRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminclienttyperules.NewClientTypeRulesLogic(r.Context(), svcCtx)
+		resp, err := logic.Create(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminUpdateClientTypeRuleHandler updates a User-Agent routing rule.
+func AdminUpdateClientTypeRuleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpdateClientTypeRuleRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminclienttyperules.NewClientTypeRulesLogic(r.Context(), svcCtx)
+		resp, err := logic.Update(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminDeleteClientTypeRuleHandler deletes a User-Agent routing rule.
+func AdminDeleteClientTypeRuleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminDeleteClientTypeRuleRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminclienttyperules.NewClientTypeRulesLogic(r.Context(), svcCtx)
+		resp, err := logic.Delete(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminTestClientTypeRuleHandler reports how a User-Agent is classified and routed.
+func AdminTestClientTypeRuleHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminTestClientTypeRuleRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminclienttyperules.NewClientTypeRulesLogic(r.Context(), svcCtx)
+		resp, err := logic.Test(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
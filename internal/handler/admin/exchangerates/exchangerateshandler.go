@@ -0,0 +1,72 @@
+package exchangerates
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	adminrates "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/exchangerates"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// AdminListExchangeRatesHandler lists configured exchange rates.
+func AdminListExchangeRatesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminListExchangeRatesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminrates.NewListLogic(r.Context(), svcCtx)
+		resp, err := logic.List(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminUpsertExchangeRatesHandler creates or updates exchange rates in bulk.
+func AdminUpsertExchangeRatesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpsertExchangeRatesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminrates.NewUpsertLogic(r.Context(), svcCtx)
+		resp, err := logic.Upsert(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminImportExchangeRatesHandler imports exchange rates from CSV or JSON content.
+func AdminImportExchangeRatesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminImportExchangeRatesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminrates.NewImportLogic(r.Context(), svcCtx)
+		resp, err := logic.Import(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
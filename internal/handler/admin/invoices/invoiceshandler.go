@@ -0,0 +1,52 @@
+package invoices
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	admininvoices "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/invoices"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// AdminListInvoicesHandler lists invoices and credit notes.
+func AdminListInvoicesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminListInvoicesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admininvoices.NewListLogic(r.Context(), svcCtx)
+		resp, err := logic.List(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminDownloadInvoiceHandler renders any invoice as HTML or PDF.
+func AdminDownloadInvoiceHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.DownloadInvoiceRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admininvoices.NewDownloadLogic(r.Context(), svcCtx)
+		file, err := logic.Download(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		handlercommon.WriteAttachment(w, file.Filename, file.ContentType, file.Content)
+	}
+}
@@ -0,0 +1,86 @@
+package nodegroups
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	adminnodegroups "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/nodegroups"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// AdminListNodeGroupsHandler lists node groups with their resolved members.
+func AdminListNodeGroupsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logic := adminnodegroups.NewNodeGroupsLogic(r.Context(), svcCtx)
+		resp, err := logic.List()
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminCreateNodeGroupHandler creates a node group.
+func AdminCreateNodeGroupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminCreateNodeGroupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminnodegroups.NewNodeGroupsLogic(r.Context(), svcCtx)
+		resp, err := logic.Create(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminUpdateNodeGroupHandler updates a node group.
+func AdminUpdateNodeGroupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpdateNodeGroupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminnodegroups.NewNodeGroupsLogic(r.Context(), svcCtx)
+		resp, err := logic.Update(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminDeleteNodeGroupHandler deletes a node group that no plan references.
+func AdminDeleteNodeGroupHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminDeleteNodeGroupRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminnodegroups.NewNodeGroupsLogic(r.Context(), svcCtx)
+		resp, err := logic.Delete(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
 	adminnodes "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/nodes"
-	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -17,7 +16,7 @@ func AdminListNodesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminListNodesRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -32,12 +31,32 @@ func AdminListNodesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	}
 }
 
+// AdminUpdateNodeHandler updates sort order and status of a node.
+func AdminUpdateNodeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpdateNodeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewUpdateLogic(r.Context(), svcCtx)
+		resp, err := logic.Update(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
 // AdminNodeKernelsHandler shows kernel status for a specific node.
 func AdminNodeKernelsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminNodeKernelsRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -52,12 +71,32 @@ func AdminNodeKernelsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	}
 }
 
+// AdminRotateNodeTokenHandler issues a new callback token for the node and revokes the previous one.
+func AdminRotateNodeTokenHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRotateNodeTokenRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminnodes.NewTokenLogic(r.Context(), svcCtx)
+		resp, err := logic.Rotate(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
 // AdminSyncNodeKernelHandler triggers immediate kernel synchronization on the node.
 func AdminSyncNodeKernelHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminSyncNodeKernelRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
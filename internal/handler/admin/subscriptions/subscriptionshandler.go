@@ -0,0 +1,72 @@
+package subscriptions
+
+import (
+	"net/http"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	adminsubscriptions "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/subscriptions"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// AdminUpdateSubscriptionStatusHandler suspends, resumes or cancels a subscription.
+func AdminUpdateSubscriptionStatusHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminUpdateSubscriptionStatusRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminsubscriptions.NewStatusLogic(r.Context(), svcCtx)
+		resp, err := logic.Update(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminSubscriptionAccessLogsHandler lists fetch logs of a subscription.
+func AdminSubscriptionAccessLogsHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSubscriptionAccessLogsRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminsubscriptions.NewAccessLogic(r.Context(), svcCtx)
+		resp, err := logic.Logs(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminSubscriptionAccessAnomaliesHandler reports tokens fetched from unusually many IPs.
+func AdminSubscriptionAccessAnomaliesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSubscriptionAccessAnomaliesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := adminsubscriptions.NewAccessLogic(r.Context(), svcCtx)
+		resp, err := logic.Anomalies(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
 	admintemplates "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/templates"
-	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -17,7 +16,7 @@ func AdminListSubscriptionTemplatesHandler(svcCtx *svc.ServiceContext) http.Hand
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminListSubscriptionTemplatesRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -37,7 +36,7 @@ func AdminCreateSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.Han
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminCreateSubscriptionTemplateRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -57,7 +56,7 @@ func AdminUpdateSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.Han
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminUpdateSubscriptionTemplateRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -77,7 +76,7 @@ func AdminPublishSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.Ha
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminPublishSubscriptionTemplateRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -97,7 +96,7 @@ func AdminSubscriptionTemplateHistoryHandler(svcCtx *svc.ServiceContext) http.Ha
 	return func(w http.ResponseWriter, r *http.Request) {
 		var req types.AdminSubscriptionTemplateHistoryRequest
 		if err := httpx.Parse(r, &req); err != nil {
-			handlercommon.RespondError(w, r, repository.ErrInvalidArgument)
+			handlercommon.RespondParseError(w, r, err)
 			return
 		}
 
@@ -111,3 +110,103 @@ func AdminSubscriptionTemplateHistoryHandler(svcCtx *svc.ServiceContext) http.Ha
 		httpx.OkJsonCtx(r.Context(), w, resp)
 	}
 }
+
+// AdminRenderSubscriptionTemplateHandler dry-runs a draft or history version against a subscription or fixture.
+func AdminRenderSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRenderSubscriptionTemplateRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewRenderLogic(r.Context(), svcCtx)
+		resp, err := logic.Render(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminRollbackSubscriptionTemplateHandler republishes a historical version as a new version.
+func AdminRollbackSubscriptionTemplateHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminRollbackSubscriptionTemplateRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewRollbackLogic(r.Context(), svcCtx)
+		resp, err := logic.Rollback(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminSubscriptionTemplateDiffHandler compares two template versions.
+func AdminSubscriptionTemplateDiffHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminSubscriptionTemplateDiffRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewDiffLogic(r.Context(), svcCtx)
+		resp, err := logic.Diff(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
+
+// AdminExportSubscriptionTemplatesHandler downloads subscription templates as a JSON or YAML bundle.
+func AdminExportSubscriptionTemplatesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminExportSubscriptionTemplatesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewBundleLogic(r.Context(), svcCtx)
+		filename, contentType, content, err := logic.Export(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		handlercommon.WriteAttachment(w, filename, contentType, content)
+	}
+}
+
+// AdminImportSubscriptionTemplatesHandler imports a template bundle, optionally as a dry run.
+func AdminImportSubscriptionTemplatesHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.AdminImportSubscriptionTemplatesRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := admintemplates.NewBundleLogic(r.Context(), svcCtx)
+		resp, err := logic.Import(&req)
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		httpx.OkJsonCtx(r.Context(), w, resp)
+	}
+}
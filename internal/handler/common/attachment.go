@@ -0,0 +1,16 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// WriteAttachment writes raw file content with download headers.
+func WriteAttachment(w http.ResponseWriter, filename, contentType string, content []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
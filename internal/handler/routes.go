@@ -5,19 +5,33 @@ import (
 
 	"github.com/zeromicro/go-zero/rest"
 
+	adminAffiliate "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/affiliate"
 	adminAnnouncements "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/announcements"
+	adminClientTypeRules "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/clienttyperules"
 	adminDashboard "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/dashboard"
+	adminExchangeRates "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/exchangerates"
+	adminInvoices "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/invoices"
+	adminNodeGroups "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/nodegroups"
 	adminNodes "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/nodes"
 	adminOrders "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/orders"
 	adminPlans "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/plans"
+	adminReconciliations "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/reconciliations"
+	adminRedeemCodes "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/redeemcodes"
 	adminSecurity "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/security"
+	adminSubscriptions "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/subscriptions"
 	adminTemplates "github.com/zero-net-panel/zero-net-panel/internal/handler/admin/templates"
 	authhandlers "github.com/zero-net-panel/zero-net-panel/internal/handler/auth"
+	nodehandlers "github.com/zero-net-panel/zero-net-panel/internal/handler/node"
 	sharedhandlers "github.com/zero-net-panel/zero-net-panel/internal/handler/shared"
+	subscribehandlers "github.com/zero-net-panel/zero-net-panel/internal/handler/subscribe"
 	userAccount "github.com/zero-net-panel/zero-net-panel/internal/handler/user/account"
+	userAffiliate "github.com/zero-net-panel/zero-net-panel/internal/handler/user/affiliate"
 	userAnnouncements "github.com/zero-net-panel/zero-net-panel/internal/handler/user/announcements"
+	userInvoices "github.com/zero-net-panel/zero-net-panel/internal/handler/user/invoices"
+	userNotifications "github.com/zero-net-panel/zero-net-panel/internal/handler/user/notifications"
 	userOrders "github.com/zero-net-panel/zero-net-panel/internal/handler/user/orders"
 	userPlans "github.com/zero-net-panel/zero-net-panel/internal/handler/user/plans"
+	userRedeem "github.com/zero-net-panel/zero-net-panel/internal/handler/user/redeem"
 	userSubscriptions "github.com/zero-net-panel/zero-net-panel/internal/handler/user/subscriptions"
 	"github.com/zero-net-panel/zero-net-panel/internal/middleware"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -28,6 +42,7 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 	thirdPartyMiddleware := middleware.NewThirdPartyMiddleware(svcCtx.Repositories.Security)
 	accessMiddleware := middleware.NewAccessMiddleware(svcCtx.Config.Admin.Access)
 	webhookMiddleware := middleware.NewWebhookMiddleware(svcCtx.Config.Webhook)
+	nodeMiddleware := middleware.NewNodeMiddleware(svcCtx.Config.Node, svcCtx.Repositories.Node)
 
 	server.Use(middleware.HTTPMetricsMiddleware{}.Handler)
 
@@ -49,6 +64,11 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 				Path:    "/login",
 				Handler: authhandlers.AuthLoginHandler(svcCtx),
 			},
+			{
+				Method:  http.MethodPost,
+				Path:    "/register",
+				Handler: authhandlers.AuthRegisterHandler(svcCtx),
+			},
 			{
 				Method:  http.MethodPost,
 				Path:    "/refresh",
@@ -58,6 +78,17 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 		rest.WithPrefix("/api/v1/auth"),
 	)
 
+	server.AddRoutes(
+		[]rest.Route{
+			{
+				Method:  http.MethodGet,
+				Path:    "/:token",
+				Handler: subscribehandlers.SubscribeHandler(svcCtx),
+			},
+		},
+		rest.WithPrefix("/api/v1/subscribe"),
+	)
+
 	adminRoutes := []rest.Route{
 		{
 			Method:  http.MethodGet,
@@ -69,6 +100,11 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/nodes",
 			Handler: adminNodes.AdminListNodesHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/nodes/:id",
+			Handler: adminNodes.AdminUpdateNodeHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/nodes/:id/kernels",
@@ -79,6 +115,56 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/nodes/:id/kernels/sync",
 			Handler: adminNodes.AdminSyncNodeKernelHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/nodes/:id/token",
+			Handler: adminNodes.AdminRotateNodeTokenHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/node-groups",
+			Handler: adminNodeGroups.AdminListNodeGroupsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/node-groups",
+			Handler: adminNodeGroups.AdminCreateNodeGroupHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/node-groups/:id",
+			Handler: adminNodeGroups.AdminUpdateNodeGroupHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/node-groups/:id/delete",
+			Handler: adminNodeGroups.AdminDeleteNodeGroupHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/client-type-rules",
+			Handler: adminClientTypeRules.AdminListClientTypeRulesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/client-type-rules",
+			Handler: adminClientTypeRules.AdminCreateClientTypeRuleHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/client-type-rules/test",
+			Handler: adminClientTypeRules.AdminTestClientTypeRuleHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/client-type-rules/:id",
+			Handler: adminClientTypeRules.AdminUpdateClientTypeRuleHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/client-type-rules/:id/delete",
+			Handler: adminClientTypeRules.AdminDeleteClientTypeRuleHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/subscription-templates",
@@ -89,6 +175,16 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/subscription-templates",
 			Handler: adminTemplates.AdminCreateSubscriptionTemplateHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscription-templates/export",
+			Handler: adminTemplates.AdminExportSubscriptionTemplatesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscription-templates/import",
+			Handler: adminTemplates.AdminImportSubscriptionTemplatesHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodPatch,
 			Path:    "/subscription-templates/:id",
@@ -99,6 +195,21 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/subscription-templates/:id/publish",
 			Handler: adminTemplates.AdminPublishSubscriptionTemplateHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscription-templates/:id/rollback",
+			Handler: adminTemplates.AdminRollbackSubscriptionTemplateHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscription-templates/:id/diff",
+			Handler: adminTemplates.AdminSubscriptionTemplateDiffHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscription-templates/:id/render",
+			Handler: adminTemplates.AdminRenderSubscriptionTemplateHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/subscription-templates/:id/history",
@@ -119,6 +230,21 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/plans/:id",
 			Handler: adminPlans.AdminUpdatePlanHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/exchange-rates",
+			Handler: adminExchangeRates.AdminListExchangeRatesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/exchange-rates",
+			Handler: adminExchangeRates.AdminUpsertExchangeRatesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/exchange-rates/import",
+			Handler: adminExchangeRates.AdminImportExchangeRatesHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/announcements",
@@ -169,6 +295,91 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/orders/:id/refund",
 			Handler: adminOrders.AdminRefundOrderHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/invoices",
+			Handler: adminInvoices.AdminListInvoicesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/invoices/:id/download",
+			Handler: adminInvoices.AdminDownloadInvoiceHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/reconciliations",
+			Handler: adminReconciliations.AdminListReconciliationsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/reconciliations",
+			Handler: adminReconciliations.AdminReconcileHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/reconciliations/:id",
+			Handler: adminReconciliations.AdminGetReconciliationHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/affiliate/payouts",
+			Handler: adminAffiliate.AdminListPayoutsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/affiliate/payouts/:id/approve",
+			Handler: adminAffiliate.AdminApprovePayoutHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/affiliate/payouts/:id/reject",
+			Handler: adminAffiliate.AdminRejectPayoutHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/redeem-codes/batches",
+			Handler: adminRedeemCodes.AdminListRedeemBatchesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/redeem-codes/batches",
+			Handler: adminRedeemCodes.AdminCreateRedeemBatchHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/redeem-codes/batches/:id/export",
+			Handler: adminRedeemCodes.AdminExportRedeemBatchHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/redeem-codes",
+			Handler: adminRedeemCodes.AdminListRedeemCodesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/redeem-codes/:id",
+			Handler: adminRedeemCodes.AdminUpdateRedeemCodeHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/redeem-codes/redemptions",
+			Handler: adminRedeemCodes.AdminListRedemptionsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscriptions/:id/status",
+			Handler: adminSubscriptions.AdminUpdateSubscriptionStatusHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscriptions/:id/access-logs",
+			Handler: adminSubscriptions.AdminSubscriptionAccessLogsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscription-access/anomalies",
+			Handler: adminSubscriptions.AdminSubscriptionAccessAnomaliesHandler(svcCtx),
+		},
 	}
 	adminRoutes = rest.WithMiddlewares([]rest.Middleware{accessMiddleware.Handler, authMiddleware.RequireRoles("admin")}, adminRoutes...)
 	adminPrefix := svcCtx.Config.Admin.RoutePrefix
@@ -188,6 +399,21 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 	webhookRoutes = rest.WithMiddlewares([]rest.Middleware{webhookMiddleware.Handler}, webhookRoutes...)
 	server.AddRoutes(webhookRoutes, rest.WithPrefix(adminBase))
 
+	nodeRoutes := []rest.Route{
+		{
+			Method:  http.MethodGet,
+			Path:    "/:id/users",
+			Handler: nodehandlers.NodeUsersHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/:id/online",
+			Handler: nodehandlers.NodeOnlineReportHandler(svcCtx),
+		},
+	}
+	nodeRoutes = rest.WithMiddlewares([]rest.Middleware{nodeMiddleware.Handler}, nodeRoutes...)
+	server.AddRoutes(nodeRoutes, rest.WithPrefix("/api/v1/node"))
+
 	userRoutes := []rest.Route{
 		{
 			Method:  http.MethodGet,
@@ -204,6 +430,46 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/subscriptions/:id/template",
 			Handler: userSubscriptions.UserUpdateSubscriptionTemplateHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscriptions/:id/auto-renew",
+			Handler: userSubscriptions.UserUpdateSubscriptionAutoRenewHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscriptions/:id/traffic-periods",
+			Handler: userSubscriptions.UserSubscriptionTrafficPeriodsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscriptions/:id/devices",
+			Handler: userSubscriptions.UserSubscriptionDevicesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscriptions/:id/token/reset",
+			Handler: userSubscriptions.UserResetSubscriptionTokenHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscriptions/:id/proxy-uuid/reset",
+			Handler: userSubscriptions.UserResetSubscriptionProxyUUIDHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/subscriptions/:id/tokens",
+			Handler: userSubscriptions.UserListSubscriptionTokensHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscriptions/:id/tokens",
+			Handler: userSubscriptions.UserCreateSubscriptionTokenHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/subscriptions/:id/tokens/:token_id/revoke",
+			Handler: userSubscriptions.UserRevokeSubscriptionTokenHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodGet,
 			Path:    "/plans",
@@ -219,11 +485,86 @@ func RegisterHandlers(server *rest.Server, svcCtx *svc.ServiceContext) {
 			Path:    "/account/balance",
 			Handler: userAccount.UserBalanceHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/account/billing-profile",
+			Handler: userAccount.UserGetBillingProfileHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPatch,
+			Path:    "/account/billing-profile",
+			Handler: userAccount.UserUpdateBillingProfileHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/invoices",
+			Handler: userInvoices.UserListInvoicesHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/invoices/:id",
+			Handler: userInvoices.UserGetInvoiceHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/invoices/:id/download",
+			Handler: userInvoices.UserDownloadInvoiceHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/affiliate",
+			Handler: userAffiliate.UserAffiliateSummaryHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/affiliate/commissions",
+			Handler: userAffiliate.UserListCommissionsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/affiliate/withdraw",
+			Handler: userAffiliate.UserWithdrawCommissionHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/affiliate/payouts",
+			Handler: userAffiliate.UserListPayoutsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/affiliate/payouts",
+			Handler: userAffiliate.UserCreatePayoutHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/redeem",
+			Handler: userRedeem.UserRedeemHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/redeem/history",
+			Handler: userRedeem.UserListRedemptionsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodGet,
+			Path:    "/notifications",
+			Handler: userNotifications.UserListNotificationsHandler(svcCtx),
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/notifications/:id/read",
+			Handler: userNotifications.UserReadNotificationHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/orders",
 			Handler: userOrders.UserCreateOrderHandler(svcCtx),
 		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/orders/quote",
+			Handler: userOrders.UserQuoteOrderHandler(svcCtx),
+		},
 		{
 			Method:  http.MethodPost,
 			Path:    "/orders/:id/cancel",
@@ -5,6 +5,7 @@ import (
 
 	"github.com/zeromicro/go-zero/rest/httpx"
 
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
 	"github.com/zero-net-panel/zero-net-panel/internal/logic"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 )
@@ -15,7 +16,7 @@ func PingHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 		l := logic.NewPingLogic(r.Context(), svcCtx)
 		resp, err := l.Ping()
 		if err != nil {
-			httpx.ErrorCtx(r.Context(), w, err)
+			handlercommon.RespondError(w, r, err)
 			return
 		}
 
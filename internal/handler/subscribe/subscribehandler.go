@@ -0,0 +1,47 @@
+package subscribe
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+
+	handlercommon "github.com/zero-net-panel/zero-net-panel/internal/handler/common"
+	subscribelogic "github.com/zero-net-panel/zero-net-panel/internal/logic/subscribe"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// SubscribeHandler serves rendered subscription content to clients by token.
+func SubscribeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.SubscribeRequest
+		if err := httpx.Parse(r, &req); err != nil {
+			handlercommon.RespondParseError(w, r, err)
+			return
+		}
+
+		logic := subscribelogic.NewFetchLogic(r.Context(), svcCtx)
+		rendered, sub, err := logic.Fetch(&req, handlercommon.ClientIP(r), r.UserAgent())
+		if err != nil {
+			handlercommon.RespondError(w, r, err)
+			return
+		}
+
+		etag := strconv.Quote(rendered.ETag)
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Subscription-Userinfo", fmt.Sprintf("upload=0; download=%d; total=%d; expire=%d",
+			sub.TrafficUsedBytes, sub.TrafficTotalBytes, sub.ExpiresAt.Unix()))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", rendered.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(rendered.Content)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(rendered.Content))
+	}
+}
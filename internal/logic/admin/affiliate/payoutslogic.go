@@ -0,0 +1,129 @@
+package affiliate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// PayoutsLogic 管理端佣金提现审核。
+type PayoutsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewPayoutsLogic 构造函数。
+func NewPayoutsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PayoutsLogic {
+	return &PayoutsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 按状态、用户过滤提现申请。
+func (l *PayoutsLogic) List(req *types.AdminListPayoutsRequest) (*types.CommissionPayoutListResponse, error) {
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+
+	opts := repository.ListCommissionPayoutsOptions{
+		Page:    page,
+		PerPage: perPage,
+		Status:  req.Status,
+	}
+	if req.UserID != 0 {
+		userID := req.UserID
+		opts.UserID = &userID
+	}
+
+	payouts, total, err := l.svcCtx.Repositories.Affiliate.ListPayouts(l.ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.CommissionPayoutSummary, 0, len(payouts))
+	for _, payout := range payouts {
+		list = append(list, orderutil.ToCommissionPayoutSummary(payout))
+	}
+
+	return &types.CommissionPayoutListResponse{
+		Payouts: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
+
+// Approve 通过提现申请，佣金已在申请时冻结扣减，线下打款后调用。
+func (l *PayoutsLogic) Approve(req *types.AdminReviewPayoutRequest) (*types.CommissionPayoutSummary, error) {
+	return l.review(req, repository.CommissionPayoutStatusApproved)
+}
+
+// Reject 驳回提现申请并退回冻结的佣金。
+func (l *PayoutsLogic) Reject(req *types.AdminReviewPayoutRequest) (*types.CommissionPayoutSummary, error) {
+	return l.review(req, repository.CommissionPayoutStatusRejected)
+}
+
+func (l *PayoutsLogic) review(req *types.AdminReviewPayoutRequest, status string) (*types.CommissionPayoutSummary, error) {
+	actor, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	if req.PayoutID == 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	var payout repository.CommissionPayout
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		repos, err := repository.NewRepositories(tx)
+		if err != nil {
+			return err
+		}
+
+		payout, err = repos.Affiliate.UpdatePayoutStatus(l.ctx, req.PayoutID, status, actor.Email, strings.TrimSpace(req.Note))
+		if err != nil {
+			return err
+		}
+		if status != repository.CommissionPayoutStatusRejected {
+			return nil
+		}
+
+		payoutID := payout.ID
+		_, _, err = repos.Affiliate.ApplyEntry(l.ctx, repository.CommissionEntry{
+			UserID:      payout.UserID,
+			Type:        repository.CommissionEntryPayoutReturn,
+			AmountCents: payout.AmountCents,
+			Currency:    payout.Currency,
+			SourceKey:   fmt.Sprintf("payout_return:%d", payoutID),
+			PayoutID:    &payoutID,
+			Description: fmt.Sprintf("佣金提现申请 #%d 驳回退回", payoutID),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := orderutil.ToCommissionPayoutSummary(payout)
+	return &resp, nil
+}
@@ -0,0 +1,151 @@
+package clienttyperules
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ClientTypeRulesLogic 管理 User-Agent 路由规则。
+type ClientTypeRulesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewClientTypeRulesLogic 构造函数。
+func NewClientTypeRulesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ClientTypeRulesLogic {
+	return &ClientTypeRulesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 按匹配顺序返回全部规则。
+func (l *ClientTypeRulesLogic) List() (*types.AdminClientTypeRuleListResponse, error) {
+	rules, err := l.svcCtx.Repositories.ClientTypeRule.List(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.AdminClientTypeRuleListResponse{
+		Rules:             make([]types.ClientTypeRuleSummary, 0, len(rules)),
+		DefaultClientType: l.svcCtx.Config.Template.DefaultClientType,
+	}
+	for _, rule := range rules {
+		resp.Rules = append(resp.Rules, toRuleSummary(rule))
+	}
+	return resp, nil
+}
+
+// Create 创建规则，pattern 需为合法正则。
+func (l *ClientTypeRulesLogic) Create(req *types.AdminCreateClientTypeRuleRequest) (*types.ClientTypeRuleSummary, error) {
+	if _, err := subscriptionutil.CompileClientTypePattern(req.Pattern); err != nil {
+		return nil, err
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	rule, err := l.svcCtx.Repositories.ClientTypeRule.Create(l.ctx, repository.ClientTypeRule{
+		Pattern:     req.Pattern,
+		ClientType:  req.ClientType,
+		Description: req.Description,
+		Priority:    req.Priority,
+		Enabled:     enabled,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("audit: client type rule created id=%d pattern=%s client_type=%s", rule.ID, rule.Pattern, rule.ClientType)
+	summary := toRuleSummary(rule)
+	return &summary, nil
+}
+
+// Update 更新规则。
+func (l *ClientTypeRulesLogic) Update(req *types.AdminUpdateClientTypeRuleRequest) (*types.ClientTypeRuleSummary, error) {
+	rule, err := l.svcCtx.Repositories.ClientTypeRule.Get(l.ctx, req.RuleID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Pattern != nil {
+		if _, err := subscriptionutil.CompileClientTypePattern(*req.Pattern); err != nil {
+			return nil, err
+		}
+		rule.Pattern = *req.Pattern
+	}
+	if req.ClientType != nil {
+		rule.ClientType = *req.ClientType
+	}
+	if req.Description != nil {
+		rule.Description = *req.Description
+	}
+	if req.Priority != nil {
+		rule.Priority = *req.Priority
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+
+	updated, err := l.svcCtx.Repositories.ClientTypeRule.Update(l.ctx, req.RuleID, rule)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("audit: client type rule updated id=%d pattern=%s client_type=%s enabled=%t", updated.ID, updated.Pattern, updated.ClientType, updated.Enabled)
+	summary := toRuleSummary(updated)
+	return &summary, nil
+}
+
+// Delete 删除规则并返回剩余规则。
+func (l *ClientTypeRulesLogic) Delete(req *types.AdminDeleteClientTypeRuleRequest) (*types.AdminClientTypeRuleListResponse, error) {
+	if err := l.svcCtx.Repositories.ClientTypeRule.Delete(l.ctx, req.RuleID); err != nil {
+		return nil, err
+	}
+
+	l.Infof("audit: client type rule deleted id=%d", req.RuleID)
+	return l.List()
+}
+
+// Test 按当前规则识别 User-Agent，并给出未指定模板时会使用的默认模板（0 表示使用订阅自身模板）。
+func (l *ClientTypeRulesLogic) Test(req *types.AdminTestClientTypeRuleRequest) (*types.AdminTestClientTypeRuleResponse, error) {
+	matcher, err := subscriptionutil.LoadClientTypeMatcher(l.ctx, l.svcCtx.Repositories)
+	if err != nil {
+		return nil, err
+	}
+
+	clientType, ruleID := matcher.Match(strings.TrimSpace(req.UserAgent))
+	templateID, err := subscriptionutil.ResolveTemplateID(l.ctx, l.svcCtx.Repositories, repository.Subscription{}, clientType, l.svcCtx.Config.Template.DefaultClientType)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AdminTestClientTypeRuleResponse{
+		ClientType:        clientType,
+		RuleID:            ruleID,
+		DefaultTemplateID: templateID,
+	}, nil
+}
+
+func toRuleSummary(rule repository.ClientTypeRule) types.ClientTypeRuleSummary {
+	return types.ClientTypeRuleSummary{
+		ID:          rule.ID,
+		Pattern:     rule.Pattern,
+		ClientType:  rule.ClientType,
+		Description: rule.Description,
+		Priority:    rule.Priority,
+		Enabled:     rule.Enabled,
+		CreatedAt:   rule.CreatedAt.Unix(),
+		UpdatedAt:   rule.UpdatedAt.Unix(),
+	}
+}
@@ -0,0 +1,126 @@
+package exchangerates
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ImportLogic 从 CSV/JSON 文本导入汇率。
+type ImportLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewImportLogic 构造函数。
+func NewImportLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportLogic {
+	return &ImportLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Import 解析并写入汇率，整批在同一事务中生效。
+func (l *ImportLogic) Import(req *types.AdminImportExchangeRatesRequest) (*types.AdminExchangeRateListResponse, error) {
+	var (
+		inputs []types.ExchangeRateInput
+		err    error
+	)
+	switch strings.ToLower(strings.TrimSpace(req.Format)) {
+	case "", "csv":
+		inputs, err = ParseRatesCSV(req.Content)
+	case "json":
+		inputs, err = ParseRatesJSON(req.Content)
+	default:
+		return nil, repository.ErrInvalidArgument
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		source = "import"
+	}
+
+	rates, err := l.svcCtx.Repositories.ExchangeRate.Upsert(l.ctx, fromExchangeRateInputs(inputs, source))
+	if err != nil {
+		return nil, err
+	}
+
+	return toExchangeRateListResponse(rates), nil
+}
+
+// ParseRatesCSV 解析 base,quote,rate 三列 CSV，首行为表头时自动跳过。
+func ParseRatesCSV(content string) ([]types.ExchangeRateInput, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var inputs []types.ExchangeRateInput
+	for line := 0; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil || len(record) < 3 {
+			return nil, repository.ErrInvalidArgument
+		}
+
+		rate, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			if line == 0 {
+				continue
+			}
+			return nil, repository.ErrInvalidArgument
+		}
+		inputs = append(inputs, types.ExchangeRateInput{
+			BaseCurrency:  record[0],
+			QuoteCurrency: record[1],
+			Rate:          rate,
+		})
+	}
+	if len(inputs) == 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	return inputs, nil
+}
+
+// ParseRatesJSON 接受汇率数组或 {"rates": [...]}。
+func ParseRatesJSON(content string) ([]types.ExchangeRateInput, error) {
+	content = strings.TrimSpace(content)
+
+	var inputs []types.ExchangeRateInput
+	if strings.HasPrefix(content, "[") {
+		if err := json.Unmarshal([]byte(content), &inputs); err != nil {
+			return nil, repository.ErrInvalidArgument
+		}
+	} else {
+		var wrapper struct {
+			Rates []types.ExchangeRateInput `json:"rates"`
+		}
+		if err := json.Unmarshal([]byte(content), &wrapper); err != nil {
+			return nil, repository.ErrInvalidArgument
+		}
+		inputs = wrapper.Rates
+	}
+	if len(inputs) == 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	return inputs, nil
+}
@@ -0,0 +1,40 @@
+package exchangerates
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ListLogic 管理端汇率列表。
+type ListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListLogic 构造函数。
+func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
+	return &ListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回汇率列表。
+func (l *ListLogic) List(req *types.AdminListExchangeRatesRequest) (*types.AdminExchangeRateListResponse, error) {
+	rates, err := l.svcCtx.Repositories.ExchangeRate.List(l.ctx, repository.ListExchangeRatesOptions{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toExchangeRateListResponse(rates), nil
+}
@@ -0,0 +1,38 @@
+package exchangerates
+
+import (
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func toExchangeRateSummary(rate repository.ExchangeRate) types.ExchangeRateSummary {
+	return types.ExchangeRateSummary{
+		ID:            rate.ID,
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		Source:        rate.Source,
+		UpdatedAt:     rate.UpdatedAt.Unix(),
+	}
+}
+
+func toExchangeRateListResponse(rates []repository.ExchangeRate) *types.AdminExchangeRateListResponse {
+	list := make([]types.ExchangeRateSummary, 0, len(rates))
+	for _, rate := range rates {
+		list = append(list, toExchangeRateSummary(rate))
+	}
+	return &types.AdminExchangeRateListResponse{Rates: list}
+}
+
+func fromExchangeRateInputs(inputs []types.ExchangeRateInput, source string) []repository.ExchangeRate {
+	rates := make([]repository.ExchangeRate, 0, len(inputs))
+	for _, input := range inputs {
+		rates = append(rates, repository.ExchangeRate{
+			BaseCurrency:  input.BaseCurrency,
+			QuoteCurrency: input.QuoteCurrency,
+			Rate:          input.Rate,
+			Source:        source,
+		})
+	}
+	return rates
+}
@@ -0,0 +1,42 @@
+package exchangerates
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// UpsertLogic 批量新增或更新汇率。
+type UpsertLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUpsertLogic 构造函数。
+func NewUpsertLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpsertLogic {
+	return &UpsertLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Upsert 按币种对写入汇率，返回写入后的记录。
+func (l *UpsertLogic) Upsert(req *types.AdminUpsertExchangeRatesRequest) (*types.AdminExchangeRateListResponse, error) {
+	source := strings.TrimSpace(req.Source)
+	if source == "" {
+		source = "manual"
+	}
+
+	rates, err := l.svcCtx.Repositories.ExchangeRate.Upsert(l.ctx, fromExchangeRateInputs(req.Rates, source))
+	if err != nil {
+		return nil, err
+	}
+
+	return toExchangeRateListResponse(rates), nil
+}
@@ -0,0 +1,37 @@
+package invoices
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// DownloadLogic 管理端下载任意发票。
+type DownloadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDownloadLogic 构造函数。
+func NewDownloadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DownloadLogic {
+	return &DownloadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Download 渲染发票文件。
+func (l *DownloadLogic) Download(req *types.DownloadInvoiceRequest) (orderutil.InvoiceFile, error) {
+	invoice, err := l.svcCtx.Repositories.Invoice.Get(l.ctx, req.InvoiceID)
+	if err != nil {
+		return orderutil.InvoiceFile{}, err
+	}
+
+	return orderutil.RenderInvoiceFile(l.ctx, l.svcCtx.Config.Invoice, l.svcCtx.Repositories, invoice, req.Format)
+}
@@ -0,0 +1,76 @@
+package invoices
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ListLogic 管理端发票列表。
+type ListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListLogic 构造函数。
+func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
+	return &ListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 按用户、订单、类型或编号过滤发票。
+func (l *ListLogic) List(req *types.AdminListInvoicesRequest) (*types.InvoiceListResponse, error) {
+	opts := repository.ListInvoicesOptions{
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		Kind:    req.Kind,
+		Number:  req.Number,
+	}
+	if req.UserID > 0 {
+		opts.UserID = &req.UserID
+	}
+	if req.OrderID > 0 {
+		opts.OrderID = &req.OrderID
+	}
+
+	invoices, total, err := l.svcCtx.Repositories.Invoice.List(l.ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.InvoiceDetail, 0, len(invoices))
+	for _, invoice := range invoices {
+		list = append(list, orderutil.ToInvoiceDetail(invoice))
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	return &types.InvoiceListResponse{
+		Invoices: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
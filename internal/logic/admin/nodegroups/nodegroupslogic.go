@@ -0,0 +1,147 @@
+package nodegroups
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// NodeGroupsLogic 管理节点分组。
+type NodeGroupsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewNodeGroupsLogic 构造函数。
+func NewNodeGroupsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *NodeGroupsLogic {
+	return &NodeGroupsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回全部分组及其成员与引用套餐。
+func (l *NodeGroupsLogic) List() (*types.AdminNodeGroupListResponse, error) {
+	groups, err := l.svcCtx.Repositories.NodeGroup.List(l.ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	summaries, err := l.summaries(groups...)
+	if err != nil {
+		return nil, err
+	}
+	return &types.AdminNodeGroupListResponse{Groups: summaries}, nil
+}
+
+// Create 创建分组，match_tags 用于按节点标签动态匹配成员。
+func (l *NodeGroupsLogic) Create(req *types.AdminCreateNodeGroupRequest) (*types.NodeGroupSummary, error) {
+	group, err := l.svcCtx.Repositories.NodeGroup.Create(l.ctx, repository.NodeGroup{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+		MatchTags:   req.MatchTags,
+	}, req.NodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("node-group: created id=%d name=%s", group.ID, group.Name)
+	l.invalidateRenders()
+	return l.summary(group)
+}
+
+// Update 更新分组；node_ids、match_tags 传入时整体替换，传空数组清空，省略则保持不变。
+func (l *NodeGroupsLogic) Update(req *types.AdminUpdateNodeGroupRequest) (*types.NodeGroupSummary, error) {
+	group, err := l.svcCtx.Repositories.NodeGroup.Get(l.ctx, req.GroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		group.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Description != nil {
+		group.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.MatchTags != nil {
+		group.MatchTags = req.MatchTags
+	}
+
+	updated, err := l.svcCtx.Repositories.NodeGroup.Update(l.ctx, req.GroupID, group, req.NodeIDs)
+	if err != nil {
+		return nil, err
+	}
+	l.invalidateRenders()
+
+	return l.summary(updated)
+}
+
+// Delete 删除分组并返回剩余分组；仍被套餐引用的分组返回 ErrConflict。
+func (l *NodeGroupsLogic) Delete(req *types.AdminDeleteNodeGroupRequest) (*types.AdminNodeGroupListResponse, error) {
+	if err := l.svcCtx.Repositories.NodeGroup.Delete(l.ctx, req.GroupID); err != nil {
+		return nil, err
+	}
+
+	l.Infof("node-group: deleted id=%d", req.GroupID)
+	l.invalidateRenders()
+	return l.List()
+}
+
+// invalidateRenders 分组成员与名称会出现在订阅渲染上下文中，变更后使渲染缓存失效。
+func (l *NodeGroupsLogic) invalidateRenders() {
+	if err := subscriptionutil.InvalidateNodeRenders(l.ctx, l.svcCtx.Cache); err != nil {
+		l.Errorf("node-group: invalidate subscription renders: %v", err)
+	}
+}
+
+func (l *NodeGroupsLogic) summary(group repository.NodeGroup) (*types.NodeGroupSummary, error) {
+	summaries, err := l.summaries(group)
+	if err != nil {
+		return nil, err
+	}
+	return &summaries[0], nil
+}
+
+func (l *NodeGroupsLogic) summaries(groups ...repository.NodeGroup) ([]types.NodeGroupSummary, error) {
+	ids := make([]uint64, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+	}
+
+	static, err := l.svcCtx.Repositories.NodeGroup.ListNodeIDs(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := l.svcCtx.Repositories.NodeGroup.ResolveNodeIDs(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	plans, err := l.svcCtx.Repositories.NodeGroup.ListPlanIDs(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]types.NodeGroupSummary, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, types.NodeGroupSummary{
+			ID:              group.ID,
+			Name:            group.Name,
+			Description:     group.Description,
+			NodeIDs:         append([]uint64{}, static[group.ID]...),
+			MatchTags:       append([]string{}, group.MatchTags...),
+			ResolvedNodeIDs: append([]uint64{}, resolved[group.ID]...),
+			PlanIDs:         append([]uint64{}, plans[group.ID]...),
+			CreatedAt:       group.CreatedAt.Unix(),
+			UpdatedAt:       group.UpdatedAt.Unix(),
+		})
+	}
+	return result, nil
+}
@@ -74,10 +74,14 @@ func mapNodeSummary(node repository.Node) types.NodeSummary {
 		Tags:         append([]string(nil), node.Tags...),
 		Protocols:    append([]string(nil), node.Protocols...),
 		CapacityMbps: node.CapacityMbps,
+		SortOrder:    node.SortOrder,
 		Description:  node.Description,
 		LastSyncedAt: node.LastSyncedAt.Unix(),
 		UpdatedAt:    node.UpdatedAt.Unix(),
 	}
+	if node.AgentTokenRotatedAt != nil {
+		summary.AgentTokenRotatedAt = node.AgentTokenRotatedAt.Unix()
+	}
 	return summary
 }
 
@@ -8,6 +8,7 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -77,6 +78,9 @@ func (l *SyncLogic) Sync(req *types.AdminSyncNodeKernelRequest) (resp *types.Adm
 	if err != nil {
 		return nil, err
 	}
+	if err := subscriptionutil.InvalidateNodeRenders(l.ctx, l.svcCtx.Cache); err != nil {
+		l.Errorf("node sync: invalidate subscription renders: %v", err)
+	}
 
 	message := "同步完成"
 	if config.RetrievedAt.Sub(stored.LastSyncedAt) > time.Minute {
@@ -0,0 +1,41 @@
+package nodes
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// TokenLogic 管理节点回调凭据。
+type TokenLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTokenLogic 构造函数。
+func NewTokenLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TokenLogic {
+	return &TokenLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Rotate 为节点签发新凭据，旧凭据立即失效；明文只在响应中出现一次。
+func (l *TokenLogic) Rotate(req *types.AdminRotateNodeTokenRequest) (*types.AdminRotateNodeTokenResponse, error) {
+	node, token, err := l.svcCtx.Repositories.Node.RotateAgentToken(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("node token rotated: node=%d", node.ID)
+	return &types.AdminRotateNodeTokenResponse{
+		NodeID:    node.ID,
+		Token:     token,
+		RotatedAt: node.AgentTokenRotatedAt.Unix(),
+	}, nil
+}
@@ -0,0 +1,58 @@
+package nodes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// UpdateLogic 调整节点排序与状态。
+type UpdateLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUpdateLogic 构造函数。
+func NewUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateLogic {
+	return &UpdateLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Update 更新节点排序与状态；置为 maintenance 或 disabled 的节点不再出现在订阅中，且不会因内核同步自动上线。
+func (l *UpdateLogic) Update(req *types.AdminUpdateNodeRequest) (*types.NodeSummary, error) {
+	node, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SortOrder != nil {
+		node.SortOrder = *req.SortOrder
+	}
+	if req.Status != nil {
+		node.Status = strings.ToLower(strings.TrimSpace(*req.Status))
+	}
+	if !repository.ValidNodeStatus(node.Status) {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	updated, err := l.svcCtx.Repositories.Node.Update(l.ctx, req.NodeID, node)
+	if err != nil {
+		return nil, err
+	}
+	if err := subscriptionutil.InvalidateNodeRenders(l.ctx, l.svcCtx.Cache); err != nil {
+		l.Errorf("node update: invalidate subscription renders: %v", err)
+	}
+
+	summary := mapNodeSummary(updated)
+	return &summary, nil
+}
@@ -113,6 +113,14 @@ func (l *MarkPaidLogic) MarkPaid(req *types.AdminMarkOrderPaidRequest) (*types.A
 			metadata["balance_charged"] = true
 		}
 
+		fulfillment, err := orderutil.FulfillPaidOrder(l.ctx, tx, order, paidAt)
+		if err != nil {
+			return err
+		}
+		for k, v := range fulfillment {
+			metadata[k] = v
+		}
+
 		stateParams := repository.UpdateOrderPaymentStateParams{
 			PaymentStatus: repository.OrderPaymentStatusSucceeded,
 			OrderStatus:   pointerOf(repository.OrderStatusPaid),
@@ -139,6 +147,9 @@ func (l *MarkPaidLogic) MarkPaid(req *types.AdminMarkOrderPaidRequest) (*types.A
 			}
 			updatedOrder = refreshed
 		}
+		if err := orderutil.OnOrderPaid(l.ctx, tx, l.svcCtx.Config, updatedOrder.ID); err != nil {
+			return err
+		}
 		updated = updatedOrder
 		return nil
 	})
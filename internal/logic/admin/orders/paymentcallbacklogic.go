@@ -137,6 +137,11 @@ func (l *PaymentCallbackLogic) Process(req *types.AdminPaymentCallbackRequest) (
 			if ref := strings.TrimSpace(req.Reference); ref != "" {
 				stateParams.PaymentReference = &ref
 			}
+			fulfillment, err := orderutil.FulfillPaidOrder(l.ctx, tx, order, paidAt)
+			if err != nil {
+				return err
+			}
+			stateParams.MetadataPatch = fulfillment
 		} else {
 			orderStatus := repository.OrderStatusPaymentFailed
 			stateParams.OrderStatus = &orderStatus
@@ -152,6 +157,11 @@ func (l *PaymentCallbackLogic) Process(req *types.AdminPaymentCallbackRequest) (
 		if err != nil {
 			return err
 		}
+		if status == repository.OrderPaymentStatusSucceeded {
+			if err := orderutil.OnOrderPaid(l.ctx, tx, l.svcCtx.Config, updated.ID); err != nil {
+				return err
+			}
+		}
 		updatedOrder = updated
 		return nil
 	})
@@ -129,7 +129,11 @@ func (l *RefundLogic) Refund(req *types.AdminRefundOrderRequest) (*types.AdminOr
 			Reference:   txRecord.Reference,
 			Metadata:    refundEntryMetadata,
 		}
-		if _, err := orderRepo.CreateRefund(l.ctx, refundRecord); err != nil {
+		createdRefund, err := orderRepo.CreateRefund(l.ctx, refundRecord)
+		if err != nil {
+			return err
+		}
+		if err := orderutil.OnOrderRefunded(l.ctx, tx, l.svcCtx.Config, order, createdRefund); err != nil {
 			return err
 		}
 
@@ -2,6 +2,9 @@ package orders
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +13,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -138,3 +142,110 @@ func TestAdminRefundOrder_FullRefundCancelsOrder(t *testing.T) {
 	require.Equal(t, int64(1500), transactions[0].AmountCents)
 	require.Contains(t, transactions[0].Metadata, "ticket")
 }
+
+func TestAdminOrderInvoices_SequentialNumbersAndCreditNote(t *testing.T) {
+	svcCtx, cleanup := setupAdminOrderTestContext(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	admin := repository.User{Email: "admin-invoice@test.local", DisplayName: "Admin", Roles: []string{"admin"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&admin).Error)
+	customer := repository.User{Email: "buyer-invoice@test.local", DisplayName: "Buyer", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&customer).Error)
+
+	_, err := svcCtx.Repositories.Invoice.SaveBillingProfile(ctx, repository.BillingProfile{
+		UserID:      customer.ID,
+		Name:        "Buyer",
+		CompanyName: "Buyer Ltd",
+		TaxID:       "TAX-001",
+	})
+	require.NoError(t, err)
+
+	createOrder := func(total int64) repository.Order {
+		order := repository.Order{
+			Number:        repository.GenerateOrderNumber(),
+			UserID:        customer.ID,
+			Status:        repository.OrderStatusPendingPayment,
+			PaymentMethod: repository.PaymentMethodExternal,
+			PaymentStatus: repository.OrderPaymentStatusPending,
+			TotalCents:    total,
+			Currency:      "CNY",
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		require.NoError(t, svcCtx.DB.Create(&order).Error)
+		require.NoError(t, svcCtx.DB.Create(&repository.OrderItem{
+			OrderID:        order.ID,
+			ItemType:       "plan",
+			ItemID:         1,
+			Name:           "Premium",
+			Quantity:       1,
+			UnitPriceCents: total,
+			Currency:       "CNY",
+			SubtotalCents:  total,
+			CreatedAt:      now,
+		}).Error)
+		return order
+	}
+
+	_, _, err = svcCtx.Repositories.Balance.ApplyTransaction(ctx, customer.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 5000,
+		Currency:    "CNY",
+	})
+	require.NoError(t, err)
+
+	ctx = security.WithUser(ctx, security.UserClaims{ID: admin.ID, Email: admin.Email, Roles: []string{"admin"}})
+	year := now.Year()
+
+	first := createOrder(1500)
+	_, err = NewMarkPaidLogic(ctx, svcCtx).MarkPaid(&types.AdminMarkOrderPaidRequest{OrderID: first.ID, ChargeBalance: true})
+	require.NoError(t, err)
+	second := createOrder(800)
+	_, err = NewMarkPaidLogic(ctx, svcCtx).MarkPaid(&types.AdminMarkOrderPaidRequest{OrderID: second.ID, ChargeBalance: true})
+	require.NoError(t, err)
+
+	firstInvoice, err := svcCtx.Repositories.Invoice.GetBySourceKey(ctx, "order:"+strconv.FormatUint(first.ID, 10))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("INV-%d-000001", year), firstInvoice.Number)
+	require.Equal(t, int64(1500), firstInvoice.TotalCents)
+	require.Equal(t, "Buyer Ltd", firstInvoice.Buyer["company_name"])
+
+	secondInvoice, err := svcCtx.Repositories.Invoice.GetBySourceKey(ctx, "order:"+strconv.FormatUint(second.ID, 10))
+	require.NoError(t, err)
+	require.Equal(t, fmt.Sprintf("INV-%d-000002", year), secondInvoice.Number)
+
+	// 重复标记已支付不会重复开票。
+	_, err = NewMarkPaidLogic(ctx, svcCtx).MarkPaid(&types.AdminMarkOrderPaidRequest{OrderID: first.ID})
+	require.NoError(t, err)
+	_, total, err := svcCtx.Repositories.Invoice.List(ctx, repository.ListInvoicesOptions{Kind: repository.InvoiceKindInvoice})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), total)
+
+	_, err = NewRefundLogic(ctx, svcCtx).Refund(&types.AdminRefundOrderRequest{OrderID: first.ID, AmountCents: 500, Reason: "partial"})
+	require.NoError(t, err)
+
+	notes, _, err := svcCtx.Repositories.Invoice.List(ctx, repository.ListInvoicesOptions{Kind: repository.InvoiceKindCreditNote})
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	require.Equal(t, fmt.Sprintf("CN-%d-000001", year), notes[0].Number)
+	require.Equal(t, int64(-500), notes[0].TotalCents)
+	require.NotNil(t, notes[0].RelatedInvoiceID)
+	require.Equal(t, firstInvoice.ID, *notes[0].RelatedInvoiceID)
+
+	html, err := orderutil.RenderInvoiceFile(ctx, svcCtx.Config.Invoice, svcCtx.Repositories, notes[0], "html")
+	require.NoError(t, err)
+	require.Contains(t, string(html.Content), firstInvoice.Number)
+	hostile := firstInvoice
+	hostile.Note = `<script>alert(1)</script>`
+	escaped, err := orderutil.RenderInvoiceHTML(svcCtx.Config.Invoice, hostile, nil)
+	require.NoError(t, err)
+	require.NotContains(t, escaped, "<script>")
+	require.Contains(t, escaped, "&lt;script&gt;")
+	pdfFile, err := orderutil.RenderInvoiceFile(ctx, svcCtx.Config.Invoice, svcCtx.Repositories, firstInvoice, "pdf")
+	require.NoError(t, err)
+	require.Equal(t, "application/pdf", pdfFile.ContentType)
+	require.True(t, strings.HasPrefix(string(pdfFile.Content), "%PDF-"))
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -39,26 +40,60 @@ func (l *CreateLogic) Create(req *types.AdminCreatePlanRequest) (*types.PlanSumm
 	}
 
 	plan := repository.Plan{
-		Name:              strings.TrimSpace(req.Name),
-		Slug:              strings.TrimSpace(req.Slug),
-		Description:       strings.TrimSpace(req.Description),
-		Tags:              append([]string(nil), req.Tags...),
-		Features:          append([]string(nil), req.Features...),
-		PriceCents:        req.PriceCents,
-		Currency:          strings.ToUpper(currency),
-		DurationDays:      req.DurationDays,
-		TrafficLimitBytes: req.TrafficLimitBytes,
-		DevicesLimit:      req.DevicesLimit,
-		SortOrder:         req.SortOrder,
-		Status:            status,
-		Visible:           req.Visible,
+		Name:                   strings.TrimSpace(req.Name),
+		Slug:                   strings.TrimSpace(req.Slug),
+		Description:            strings.TrimSpace(req.Description),
+		Tags:                   append([]string(nil), req.Tags...),
+		Features:               append([]string(nil), req.Features...),
+		PriceCents:             req.PriceCents,
+		Currency:               strings.ToUpper(currency),
+		DurationDays:           req.DurationDays,
+		TrafficLimitBytes:      req.TrafficLimitBytes,
+		DevicesLimit:           req.DevicesLimit,
+		TrafficResetPolicy:     repository.NormalizeTrafficResetPolicy(req.TrafficResetPolicy),
+		TrafficAddonBytes:      req.TrafficAddonBytes,
+		TrafficAddonPriceCents: req.TrafficAddonPriceCents,
+		SortOrder:              req.SortOrder,
+		Status:                 status,
+		Visible:                req.Visible,
+	}
+	if err := validateTrafficOptions(plan); err != nil {
+		return nil, err
 	}
 
-	created, err := l.svcCtx.Repositories.Plan.Create(l.ctx, plan)
+	var created repository.Plan
+	var prices []repository.PlanPrice
+	var nodeIDs, groupIDs []uint64
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		planRepo, err := repository.NewPlanRepository(tx)
+		if err != nil {
+			return err
+		}
+		created, err = planRepo.Create(l.ctx, plan)
+		if err != nil {
+			return err
+		}
+		if len(req.Prices) > 0 {
+			prices, err = planRepo.ReplacePrices(l.ctx, created.ID, fromPlanPrices(req.Prices))
+			if err != nil {
+				return err
+			}
+		}
+		if len(req.NodeIDs) > 0 {
+			nodeIDs, err = planRepo.ReplaceNodes(l.ctx, created.ID, req.NodeIDs)
+			if err != nil {
+				return err
+			}
+		}
+		if len(req.NodeGroupIDs) > 0 {
+			groupIDs, err = planRepo.ReplaceNodeGroups(l.ctx, created.ID, req.NodeGroupIDs)
+		}
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	summary := toPlanSummary(created)
+	summary := toPlanSummary(created, prices, nodeIDs, groupIDs)
 	return &summary, nil
 }
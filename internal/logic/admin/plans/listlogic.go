@@ -45,9 +45,26 @@ func (l *ListLogic) List(req *types.AdminListPlansRequest) (*types.AdminPlanList
 		return nil, err
 	}
 
+	ids := make([]uint64, 0, len(plans))
+	for _, plan := range plans {
+		ids = append(ids, plan.ID)
+	}
+	prices, err := l.svcCtx.Repositories.Plan.ListPrices(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs, err := l.svcCtx.Repositories.Plan.ListNodeIDs(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs, err := l.svcCtx.Repositories.Plan.ListNodeGroupIDs(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
 	list := make([]types.PlanSummary, 0, len(plans))
 	for _, plan := range plans {
-		list = append(list, toPlanSummary(plan))
+		list = append(list, toPlanSummary(plan, prices[plan.ID], nodeIDs[plan.ID], groupIDs[plan.ID]))
 	}
 
 	page, perPage := normalizePage(req.Page, req.PerPage)
@@ -5,23 +5,65 @@ import (
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
 
-func toPlanSummary(plan repository.Plan) types.PlanSummary {
+func toPlanSummary(plan repository.Plan, prices []repository.PlanPrice, nodeIDs, groupIDs []uint64) types.PlanSummary {
 	return types.PlanSummary{
-		ID:                plan.ID,
-		Name:              plan.Name,
-		Slug:              plan.Slug,
-		Description:       plan.Description,
-		Tags:              append([]string(nil), plan.Tags...),
-		Features:          append([]string(nil), plan.Features...),
-		PriceCents:        plan.PriceCents,
-		Currency:          plan.Currency,
-		DurationDays:      plan.DurationDays,
-		TrafficLimitBytes: plan.TrafficLimitBytes,
-		DevicesLimit:      plan.DevicesLimit,
-		SortOrder:         plan.SortOrder,
-		Status:            plan.Status,
-		Visible:           plan.Visible,
-		CreatedAt:         plan.CreatedAt.Unix(),
-		UpdatedAt:         plan.UpdatedAt.Unix(),
+		ID:                     plan.ID,
+		Name:                   plan.Name,
+		Slug:                   plan.Slug,
+		Description:            plan.Description,
+		Tags:                   append([]string(nil), plan.Tags...),
+		Features:               append([]string(nil), plan.Features...),
+		PriceCents:             plan.PriceCents,
+		Currency:               plan.Currency,
+		DurationDays:           plan.DurationDays,
+		TrafficLimitBytes:      plan.TrafficLimitBytes,
+		DevicesLimit:           plan.DevicesLimit,
+		TrafficResetPolicy:     repository.NormalizeTrafficResetPolicy(plan.TrafficResetPolicy),
+		TrafficAddonBytes:      plan.TrafficAddonBytes,
+		TrafficAddonPriceCents: plan.TrafficAddonPriceCents,
+		SortOrder:              plan.SortOrder,
+		Status:                 plan.Status,
+		Visible:                plan.Visible,
+		Prices:                 toPlanPrices(prices),
+		NodeIDs:                append([]uint64{}, nodeIDs...),
+		NodeGroupIDs:           append([]uint64{}, groupIDs...),
+		CreatedAt:              plan.CreatedAt.Unix(),
+		UpdatedAt:              plan.UpdatedAt.Unix(),
 	}
 }
+
+// validateTrafficOptions 校验重置策略与流量包配置：加购流量与价格需同时为正或同时为零。
+func validateTrafficOptions(plan repository.Plan) error {
+	if !repository.ValidTrafficResetPolicy(plan.TrafficResetPolicy) {
+		return repository.ErrInvalidArgument
+	}
+	if plan.TrafficAddonBytes < 0 || plan.TrafficAddonPriceCents < 0 {
+		return repository.ErrInvalidArgument
+	}
+	if (plan.TrafficAddonBytes > 0) != (plan.TrafficAddonPriceCents > 0) {
+		return repository.ErrInvalidArgument
+	}
+	return nil
+}
+
+func toPlanPrices(prices []repository.PlanPrice) []types.PlanPrice {
+	result := make([]types.PlanPrice, 0, len(prices))
+	for _, price := range prices {
+		result = append(result, types.PlanPrice{
+			Currency:   price.Currency,
+			PriceCents: price.PriceCents,
+		})
+	}
+	return result
+}
+
+func fromPlanPrices(prices []types.PlanPrice) []repository.PlanPrice {
+	result := make([]repository.PlanPrice, 0, len(prices))
+	for _, price := range prices {
+		result = append(result, repository.PlanPrice{
+			Currency:   price.Currency,
+			PriceCents: price.PriceCents,
+		})
+	}
+	return result
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
@@ -63,6 +65,15 @@ func (l *UpdateLogic) Update(req *types.AdminUpdatePlanRequest) (*types.PlanSumm
 	if req.DevicesLimit != nil {
 		plan.DevicesLimit = *req.DevicesLimit
 	}
+	if req.TrafficResetPolicy != nil {
+		plan.TrafficResetPolicy = repository.NormalizeTrafficResetPolicy(*req.TrafficResetPolicy)
+	}
+	if req.TrafficAddonBytes != nil {
+		plan.TrafficAddonBytes = *req.TrafficAddonBytes
+	}
+	if req.TrafficAddonPriceCents != nil {
+		plan.TrafficAddonPriceCents = *req.TrafficAddonPriceCents
+	}
 	if req.SortOrder != nil {
 		plan.SortOrder = *req.SortOrder
 	}
@@ -73,11 +84,51 @@ func (l *UpdateLogic) Update(req *types.AdminUpdatePlanRequest) (*types.PlanSumm
 		plan.Visible = *req.Visible
 	}
 
+	if err := validateTrafficOptions(plan); err != nil {
+		return nil, err
+	}
+
 	updated, err := l.svcCtx.Repositories.Plan.Update(l.ctx, req.PlanID, plan)
 	if err != nil {
 		return nil, err
 	}
 
-	summary := toPlanSummary(updated)
+	// prices 为 nil 时保持原价目表，传空数组则清空。
+	if req.Prices != nil {
+		if _, err := l.svcCtx.Repositories.Plan.ReplacePrices(l.ctx, req.PlanID, fromPlanPrices(req.Prices)); err != nil {
+			return nil, err
+		}
+	}
+	// node_ids、node_group_ids 同理；两者均为空时不限节点。
+	if req.NodeIDs != nil {
+		if _, err := l.svcCtx.Repositories.Plan.ReplaceNodes(l.ctx, req.PlanID, req.NodeIDs); err != nil {
+			return nil, err
+		}
+	}
+	if req.NodeGroupIDs != nil {
+		if _, err := l.svcCtx.Repositories.Plan.ReplaceNodeGroups(l.ctx, req.PlanID, req.NodeGroupIDs); err != nil {
+			return nil, err
+		}
+	}
+	if req.NodeIDs != nil || req.NodeGroupIDs != nil {
+		if err := subscriptionutil.InvalidateNodeRenders(l.ctx, l.svcCtx.Cache); err != nil {
+			l.Errorf("plan update: invalidate subscription renders: %v", err)
+		}
+	}
+
+	prices, err := l.svcCtx.Repositories.Plan.ListPrices(l.ctx, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs, err := l.svcCtx.Repositories.Plan.ListNodeIDs(l.ctx, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	groupIDs, err := l.svcCtx.Repositories.Plan.ListNodeGroupIDs(l.ctx, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := toPlanSummary(updated, prices[req.PlanID], nodeIDs[req.PlanID], groupIDs[req.PlanID])
 	return &summary, nil
 }
@@ -0,0 +1,39 @@
+package reconciliations
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// GetLogic 对账详情。
+type GetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGetLogic 构造函数。
+func NewGetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetLogic {
+	return &GetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Get 返回对账摘要与全部差异明细。
+func (l *GetLogic) Get(req *types.AdminGetReconciliationRequest) (*types.AdminReconciliationResponse, error) {
+	run, issues, err := l.svcCtx.Repositories.Reconciliation.GetRun(l.ctx, req.RunID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AdminReconciliationResponse{
+		Run:    toRunSummary(run),
+		Issues: toIssueSummaries(issues),
+	}, nil
+}
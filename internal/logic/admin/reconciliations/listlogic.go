@@ -0,0 +1,56 @@
+package reconciliations
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ListLogic 对账记录列表。
+type ListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListLogic 构造函数。
+func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
+	return &ListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 按时间倒序返回对账执行记录。
+func (l *ListLogic) List(req *types.AdminListReconciliationsRequest) (*types.AdminReconciliationListResponse, error) {
+	runs, total, err := l.svcCtx.Repositories.Reconciliation.ListRuns(l.ctx, repository.ListReconciliationRunsOptions{
+		Page:     req.Page,
+		PerPage:  req.PerPage,
+		Provider: req.Provider,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.ReconciliationRunSummary, 0, len(runs))
+	for _, run := range runs {
+		list = append(list, toRunSummary(run))
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	return &types.AdminReconciliationListResponse{
+		Runs: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
@@ -0,0 +1,69 @@
+package reconciliations
+
+import (
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func toRunSummary(run repository.ReconciliationRun) types.ReconciliationRunSummary {
+	summary := types.ReconciliationRunSummary{
+		ID:             run.ID,
+		Provider:       run.Provider,
+		Source:         run.Source,
+		SettlementRows: run.SettlementRows,
+		MatchedCount:   run.MatchedCount,
+		IssueCount:     run.IssueCount,
+		Summary:        run.Summary,
+		CreatedBy:      run.CreatedBy,
+		CreatedAt:      run.CreatedAt.Unix(),
+	}
+	if summary.Summary == nil {
+		summary.Summary = map[string]int{}
+	}
+	if run.PeriodStart != nil {
+		ts := run.PeriodStart.Unix()
+		summary.PeriodStart = &ts
+	}
+	if run.PeriodEnd != nil {
+		ts := run.PeriodEnd.Unix()
+		summary.PeriodEnd = &ts
+	}
+	return summary
+}
+
+func toIssueSummaries(issues []repository.ReconciliationIssue) []types.ReconciliationIssueSummary {
+	result := make([]types.ReconciliationIssueSummary, 0, len(issues))
+	for _, issue := range issues {
+		item := types.ReconciliationIssueSummary{
+			ID:               issue.ID,
+			Kind:             issue.Kind,
+			OrderID:          issue.OrderID,
+			PaymentID:        issue.PaymentID,
+			IntentID:         issue.IntentID,
+			Reference:        issue.Reference,
+			LocalStatus:      issue.LocalStatus,
+			SettlementStatus: issue.SettlementStatus,
+			LocalCents:       issue.LocalCents,
+			SettledCents:     issue.SettledCents,
+			LocalCurrency:    issue.LocalCurrency,
+			SettledCurrency:  issue.SettledCurrency,
+			Detail:           issue.Detail,
+		}
+		if issue.SettledAt != nil {
+			ts := issue.SettledAt.Unix()
+			item.SettledAt = &ts
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -0,0 +1,74 @@
+package reconciliations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/billing"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/payment"
+)
+
+// RunLogic 导入结算文件并执行对账。
+type RunLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRunLogic 构造函数。
+func NewRunLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RunLogic {
+	return &RunLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Run 解析结算文件并与本地支付流水比对，dry_run 时不保存结果。
+func (l *RunLogic) Run(req *types.AdminReconcileRequest) (*types.AdminReconciliationResponse, error) {
+	actor, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	settlements, err := payment.ParseSettlements(req.Format, []byte(req.Content))
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSettlement) {
+			return nil, fmt.Errorf("%w: %v", repository.ErrInvalidArgument, err)
+		}
+		return nil, err
+	}
+
+	opts := billing.ReconcileOptions{
+		Provider: strings.TrimSpace(req.Provider),
+		Source:   billing.ReconcileSourceImport,
+		Operator: actor.Email,
+		DryRun:   req.DryRun,
+	}
+	if req.PeriodStart != nil && req.PeriodEnd != nil {
+		opts.From = time.Unix(*req.PeriodStart, 0).UTC()
+		opts.To = time.Unix(*req.PeriodEnd, 0).UTC()
+	} else if req.PeriodStart != nil || req.PeriodEnd != nil {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	provider := payment.NewStaticProvider(opts.Provider, settlements)
+	run, issues, err := billing.NewReconcileLogic(l.ctx, l.svcCtx).Run(provider, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AdminReconciliationResponse{
+		Run:    toRunSummary(run),
+		Issues: toIssueSummaries(issues),
+	}, nil
+}
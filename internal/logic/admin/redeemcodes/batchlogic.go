@@ -0,0 +1,189 @@
+package redeemcodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// maxBatchQuantity 单批次最多生成的兑换码数量。
+const maxBatchQuantity = 5000
+
+// BatchLogic 兑换码批次的生成、查询与导出。
+type BatchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewBatchLogic 构造函数。
+func NewBatchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BatchLogic {
+	return &BatchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Create 校验权益参数后批量生成兑换码。
+func (l *BatchLogic) Create(req *types.AdminCreateRedeemBatchRequest) (*types.AdminCreateRedeemBatchResponse, error) {
+	actor, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	if req.Quantity <= 0 || req.Quantity > maxBatchQuantity || req.MaxUses < 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	batch := repository.RedeemBatch{
+		Name:      strings.TrimSpace(req.Name),
+		Kind:      strings.ToLower(strings.TrimSpace(req.Kind)),
+		MaxUses:   req.MaxUses,
+		Quantity:  req.Quantity,
+		CreatedBy: actor.Email,
+	}
+	if batch.MaxUses == 0 {
+		batch.MaxUses = 1
+	}
+	if req.ExpiresAt != nil {
+		expiresAt := time.Unix(*req.ExpiresAt, 0).UTC()
+		if !expiresAt.After(time.Now().UTC()) {
+			return nil, repository.ErrInvalidArgument
+		}
+		batch.ExpiresAt = &expiresAt
+	}
+
+	switch batch.Kind {
+	case repository.RedeemKindBalance:
+		if req.AmountCents <= 0 {
+			return nil, repository.ErrInvalidArgument
+		}
+		batch.AmountCents = req.AmountCents
+		batch.Currency = repository.NormalizeCurrency(req.Currency)
+		if batch.Currency == "" {
+			batch.Currency = "CNY"
+		}
+	case repository.RedeemKindPlan:
+		if req.PlanID == 0 || req.PlanDays <= 0 {
+			return nil, repository.ErrInvalidArgument
+		}
+		plan, err := l.svcCtx.Repositories.Plan.Get(l.ctx, req.PlanID)
+		if err != nil {
+			return nil, err
+		}
+		batch.PlanID = plan.ID
+		batch.PlanDays = req.PlanDays
+	case repository.RedeemKindTraffic:
+		if req.TrafficBytes <= 0 {
+			return nil, repository.ErrInvalidArgument
+		}
+		batch.TrafficBytes = req.TrafficBytes
+	default:
+		return nil, repository.ErrInvalidArgument
+	}
+	if batch.Name == "" {
+		batch.Name = fmt.Sprintf("%s-%s", batch.Kind, time.Now().UTC().Format("20060102150405"))
+	}
+
+	created, codes, err := l.svcCtx.Repositories.Redeem.CreateBatch(l.ctx, batch, req.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("redeem: batch=%d kind=%s quantity=%d created by %s", created.ID, created.Kind, created.Quantity, actor.Email)
+
+	resp := &types.AdminCreateRedeemBatchResponse{
+		Batch: toBatchSummary(created),
+		Codes: make([]types.RedeemCodeSummary, 0, len(codes)),
+	}
+	for _, code := range codes {
+		resp.Codes = append(resp.Codes, toCodeSummary(code))
+	}
+	return resp, nil
+}
+
+// List 兑换码批次列表。
+func (l *BatchLogic) List(req *types.AdminListRedeemBatchesRequest) (*types.AdminRedeemBatchListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	batches, total, err := l.svcCtx.Repositories.Redeem.ListBatches(l.ctx, repository.ListRedeemBatchesOptions{
+		Page:    page,
+		PerPage: perPage,
+		Kind:    req.Kind,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.RedeemBatchSummary, 0, len(batches))
+	for _, batch := range batches {
+		list = append(list, toBatchSummary(batch))
+	}
+	return &types.AdminRedeemBatchListResponse{
+		Batches:    list,
+		Pagination: pagination(page, perPage, total),
+	}, nil
+}
+
+// Export 以 CSV 导出批次内全部兑换码，返回文件名与内容。
+func (l *BatchLogic) Export(req *types.AdminExportRedeemBatchRequest) (string, []byte, error) {
+	batch, err := l.svcCtx.Repositories.Redeem.GetBatch(l.ctx, req.BatchID)
+	if err != nil {
+		return "", nil, err
+	}
+	codes, err := l.svcCtx.Repositories.Redeem.ListBatchCodes(l.ctx, batch.ID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	expiresAt := ""
+	if batch.ExpiresAt != nil {
+		expiresAt = batch.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	value := batchValue(batch)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"code", "kind", "value", "max_uses", "used_count", "status", "expires_at"})
+	for _, code := range codes {
+		_ = w.Write([]string{
+			code.Code,
+			batch.Kind,
+			value,
+			strconv.Itoa(code.MaxUses),
+			strconv.Itoa(code.UsedCount),
+			code.Status,
+			expiresAt,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", nil, err
+	}
+
+	return fmt.Sprintf("redeem-batch-%d.csv", batch.ID), buf.Bytes(), nil
+}
+
+// batchValue 以可读形式描述批次权益，用于 CSV 导出。
+func batchValue(batch repository.RedeemBatch) string {
+	switch batch.Kind {
+	case repository.RedeemKindBalance:
+		return fmt.Sprintf("%d %s", batch.AmountCents, batch.Currency)
+	case repository.RedeemKindPlan:
+		return fmt.Sprintf("plan:%d/%dd", batch.PlanID, batch.PlanDays)
+	case repository.RedeemKindTraffic:
+		return fmt.Sprintf("%d bytes", batch.TrafficBytes)
+	default:
+		return ""
+	}
+}
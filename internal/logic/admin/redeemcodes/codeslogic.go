@@ -0,0 +1,92 @@
+package redeemcodes
+
+import (
+	"context"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// CodesLogic 兑换码与兑换流水管理。
+type CodesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCodesLogic 构造函数。
+func NewCodesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CodesLogic {
+	return &CodesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 按批次、状态或兑换码查询。
+func (l *CodesLogic) List(req *types.AdminListRedeemCodesRequest) (*types.AdminRedeemCodeListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	codes, total, err := l.svcCtx.Repositories.Redeem.ListCodes(l.ctx, repository.ListRedeemCodesOptions{
+		Page:    page,
+		PerPage: perPage,
+		BatchID: req.BatchID,
+		Status:  req.Status,
+		Code:    req.Code,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.RedeemCodeSummary, 0, len(codes))
+	for _, code := range codes {
+		list = append(list, toCodeSummary(code))
+	}
+	return &types.AdminRedeemCodeListResponse{
+		Codes:      list,
+		Pagination: pagination(page, perPage, total),
+	}, nil
+}
+
+// Update 启用或禁用兑换码，不影响已完成的兑换。
+func (l *CodesLogic) Update(req *types.AdminUpdateRedeemCodeRequest) (*types.RedeemCodeSummary, error) {
+	code, err := l.svcCtx.Repositories.Redeem.UpdateCodeStatus(l.ctx, req.CodeID, strings.ToLower(strings.TrimSpace(req.Status)))
+	if err != nil {
+		return nil, err
+	}
+	resp := toCodeSummary(code)
+	return &resp, nil
+}
+
+// Redemptions 兑换流水。
+func (l *CodesLogic) Redemptions(req *types.AdminListRedemptionsRequest) (*types.RedemptionListResponse, error) {
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	opts := repository.ListRedemptionsOptions{
+		Page:    page,
+		PerPage: perPage,
+		BatchID: req.BatchID,
+		CodeID:  req.CodeID,
+	}
+	if req.UserID != 0 {
+		userID := req.UserID
+		opts.UserID = &userID
+	}
+
+	redemptions, total, err := l.svcCtx.Repositories.Redeem.ListRedemptions(l.ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.RedeemRedemptionSummary, 0, len(redemptions))
+	for _, r := range redemptions {
+		list = append(list, orderutil.ToRedeemRedemptionSummary(r))
+	}
+	return &types.RedemptionListResponse{
+		Redemptions: list,
+		Pagination:  pagination(page, perPage, total),
+	}, nil
+}
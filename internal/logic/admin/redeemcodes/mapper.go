@@ -0,0 +1,65 @@
+package redeemcodes
+
+import (
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func toBatchSummary(batch repository.RedeemBatch) types.RedeemBatchSummary {
+	summary := types.RedeemBatchSummary{
+		ID:           batch.ID,
+		Name:         batch.Name,
+		Kind:         batch.Kind,
+		AmountCents:  batch.AmountCents,
+		Currency:     batch.Currency,
+		PlanID:       batch.PlanID,
+		PlanDays:     batch.PlanDays,
+		TrafficBytes: batch.TrafficBytes,
+		MaxUses:      batch.MaxUses,
+		Quantity:     batch.Quantity,
+		CreatedBy:    batch.CreatedBy,
+		CreatedAt:    batch.CreatedAt.UTC().Unix(),
+	}
+	if batch.ExpiresAt != nil {
+		expiresAt := batch.ExpiresAt.UTC().Unix()
+		summary.ExpiresAt = &expiresAt
+	}
+	return summary
+}
+
+func toCodeSummary(code repository.RedeemCode) types.RedeemCodeSummary {
+	summary := types.RedeemCodeSummary{
+		ID:        code.ID,
+		BatchID:   code.BatchID,
+		Code:      code.Code,
+		MaxUses:   code.MaxUses,
+		UsedCount: code.UsedCount,
+		Status:    code.Status,
+		CreatedAt: code.CreatedAt.UTC().Unix(),
+	}
+	if code.LastUsedAt != nil {
+		lastUsedAt := code.LastUsedAt.UTC().Unix()
+		summary.LastUsedAt = &lastUsedAt
+	}
+	return summary
+}
+
+func pagination(page, perPage int, total int64) types.PaginationMeta {
+	return types.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		TotalCount: total,
+		HasNext:    int64(page*perPage) < total,
+		HasPrev:    page > 1,
+	}
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -0,0 +1,154 @@
+package subscriptions
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+const (
+	defaultAnomalyMinIPs = 10
+	maxAnomalyLimit      = 200
+)
+
+// AccessLogic 查询订阅拉取日志与来源 IP 异常报表。
+type AccessLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAccessLogic 构造函数。
+func NewAccessLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessLogic {
+	return &AccessLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Logs 按时间倒序列出订阅的拉取记录。
+func (l *AccessLogic) Logs(req *types.AdminSubscriptionAccessLogsRequest) (*types.AdminSubscriptionAccessLogsResponse, error) {
+	if _, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	logs, total, err := l.svcCtx.Repositories.Subscription.ListAccessLogs(l.ctx, repository.ListAccessLogsOptions{
+		Page:           page,
+		PerPage:        perPage,
+		SubscriptionID: req.SubscriptionID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.SubscriptionAccessLogSummary, 0, len(logs))
+	for _, log := range logs {
+		list = append(list, toAccessLogSummary(log))
+	}
+	return &types.AdminSubscriptionAccessLogsResponse{
+		Logs:       list,
+		Pagination: pagination(page, perPage, total),
+	}, nil
+}
+
+// Anomalies 统计窗口内来源 IP 数不少于 min_ips 的 token；未指定时窗口与阈值取巡检任务配置，
+// 任务未开启自动暂停时阈值默认为 10。
+func (l *AccessLogic) Anomalies(req *types.AdminSubscriptionAccessAnomaliesRequest) (*types.AdminSubscriptionAccessAnomaliesResponse, error) {
+	cfg := l.svcCtx.Config.Jobs.AccessAudit
+	cfg.Normalize()
+
+	if req.WindowHours < 0 || req.MinIPs < 0 || req.Limit < 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+	window := cfg.Window
+	if req.WindowHours > 0 {
+		window = time.Duration(req.WindowHours) * time.Hour
+	}
+	minIPs := req.MinIPs
+	if minIPs == 0 {
+		minIPs = cfg.SuspendDistinctIPs
+	}
+	if minIPs == 0 {
+		minIPs = defaultAnomalyMinIPs
+	}
+	limit := req.Limit
+	if limit == 0 || limit > maxAnomalyLimit {
+		limit = cfg.BatchSize
+	}
+
+	since := time.Now().UTC().Add(-window)
+	stats, err := l.svcCtx.Repositories.Subscription.ListAccessAnomalies(l.ctx, since, minIPs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	anomalies := make([]types.SubscriptionAccessAnomaly, 0, len(stats))
+	for _, stat := range stats {
+		anomaly := types.SubscriptionAccessAnomaly{
+			Token:             stat.Token,
+			SubscriptionID:    stat.SubscriptionID,
+			UserID:            stat.UserID,
+			DistinctIPs:       stat.DistinctIPs,
+			DistinctCountries: stat.DistinctCountries,
+			Fetches:           stat.Fetches,
+		}
+		sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, stat.SubscriptionID)
+		switch {
+		case err == nil:
+			anomaly.SubscriptionStatus = sub.Status
+		case !errors.Is(err, repository.ErrNotFound):
+			return nil, err
+		}
+		anomalies = append(anomalies, anomaly)
+	}
+
+	return &types.AdminSubscriptionAccessAnomaliesResponse{
+		Since:            since.Unix(),
+		MinIPs:           minIPs,
+		SuspendThreshold: cfg.SuspendDistinctIPs,
+		Anomalies:        anomalies,
+	}, nil
+}
+
+func toAccessLogSummary(log repository.SubscriptionAccessLog) types.SubscriptionAccessLogSummary {
+	return types.SubscriptionAccessLogSummary{
+		ID:             log.ID,
+		SubscriptionID: log.SubscriptionID,
+		UserID:         log.UserID,
+		TokenID:        log.TokenID,
+		Token:          log.Token,
+		IP:             log.IP,
+		UserAgent:      log.UserAgent,
+		ClientType:     log.ClientType,
+		Country:        log.Country,
+		CreatedAt:      log.CreatedAt.UTC().Unix(),
+	}
+}
+
+func pagination(page, perPage int, total int64) types.PaginationMeta {
+	return types.PaginationMeta{
+		Page:       page,
+		PerPage:    perPage,
+		TotalCount: total,
+		HasNext:    int64(page*perPage) < total,
+		HasPrev:    page > 1,
+	}
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -0,0 +1,54 @@
+package subscriptions
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// StatusLogic 管理员手动流转订阅状态。
+type StatusLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewStatusLogic 构造函数。
+func NewStatusLogic(ctx context.Context, svcCtx *svc.ServiceContext) *StatusLogic {
+	return &StatusLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Update 支持 suspended、cancelled 与 active；恢复为 active 时按到期时间与流量用量落到实际状态。
+func (l *StatusLogic) Update(req *types.AdminUpdateSubscriptionStatusRequest) (*types.UserSubscriptionSummary, error) {
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	switch status {
+	case repository.SubscriptionStatusActive:
+		sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		status = repository.SettledSubscriptionStatus(sub, time.Now().UTC())
+	case repository.SubscriptionStatusSuspended, repository.SubscriptionStatusCancelled:
+	default:
+		return nil, repository.ErrInvalidArgument
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.TransitionStatus(l.ctx, req.SubscriptionID, status, repository.SubscriptionReasonAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := orderutil.ToUserSubscriptionSummary(sub)
+	return &summary, nil
+}
@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// BundleLogic 导入导出模板包。
+type BundleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewBundleLogic 构造函数。
+func NewBundleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BundleLogic {
+	return &BundleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Export 导出模板包，返回文件名、Content-Type 与内容。
+func (l *BundleLogic) Export(req *types.AdminExportSubscriptionTemplatesRequest) (string, string, []byte, error) {
+	format, err := subscriptionutil.NormalizeBundleFormat(req.Format)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	now := time.Now().UTC()
+	bundle, err := subscriptionutil.ExportBundle(l.ctx, l.svcCtx.Repositories, subscriptionutil.ExportOptions{
+		ClientType:     strings.TrimSpace(req.ClientType),
+		IncludeHistory: req.IncludeHistory,
+	}, now)
+	if err != nil {
+		return "", "", nil, err
+	}
+	content, err := subscriptionutil.EncodeBundle(bundle, format)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if format == subscriptionutil.BundleFormatYAML {
+		contentType = "application/yaml; charset=utf-8"
+	}
+	filename := fmt.Sprintf("subscription-templates-%s.%s", now.Format("20060102150405"), format)
+	return filename, contentType, content, nil
+}
+
+// Import 按 (client_type, name) 导入模板包，dry_run 时仅返回将要执行的变更。
+func (l *BundleLogic) Import(req *types.AdminImportSubscriptionTemplatesRequest) (*types.AdminImportSubscriptionTemplatesResponse, error) {
+	bundle, err := subscriptionutil.DecodeBundle([]byte(req.Content), req.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := resolveOperator(l.ctx, req.Operator)
+	results, err := subscriptionutil.ImportBundle(l.ctx, l.svcCtx.Repositories, bundle, subscriptionutil.ImportOptions{
+		DryRun:   req.DryRun,
+		Publish:  req.Publish,
+		Operator: operator,
+		Limits:   subscriptionutil.RenderLimits(l.svcCtx.Config.Template),
+	}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.AdminImportSubscriptionTemplatesResponse{
+		DryRun:  req.DryRun,
+		Results: make([]types.SubscriptionTemplateImportResult, 0, len(results)),
+	}
+	for _, result := range results {
+		switch result.Action {
+		case subscriptionutil.BundleActionCreate:
+			resp.Created++
+		case subscriptionutil.BundleActionUpdate:
+			resp.Updated++
+		default:
+			resp.Unchanged++
+		}
+		resp.Results = append(resp.Results, types.SubscriptionTemplateImportResult{
+			ClientType:      result.ClientType,
+			Name:            result.Name,
+			TemplateID:      result.TemplateID,
+			Action:          result.Action,
+			Published:       result.Published,
+			HistoryImported: result.HistoryImported,
+			ContentDiff:     result.ContentDiff,
+			Variables:       toVariableChanges(result.Variables),
+		})
+	}
+
+	if !req.DryRun {
+		l.Infof("audit: template import operator=%s created=%d updated=%d unchanged=%d publish=%t",
+			operator, resp.Created, resp.Updated, resp.Unchanged, req.Publish)
+	}
+
+	return resp, nil
+}
@@ -5,9 +5,11 @@ import (
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
 )
 
 // CreateLogic 创建订阅模板。
@@ -28,13 +30,23 @@ func NewCreateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateLogi
 
 // Create 执行创建。
 func (l *CreateLogic) Create(req *types.AdminCreateSubscriptionTemplateRequest) (*types.SubscriptionTemplateSummary, error) {
+	if !subtemplate.SupportedFormat(req.Format) {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	format := subtemplate.NormalizeFormat(req.Format)
+	variables := toRepositoryVariables(req.Variables)
+	if err := subscriptionutil.ValidateTemplate(format, req.Content, variables); err != nil {
+		return nil, err
+	}
+
 	input := repository.CreateSubscriptionTemplateInput{
 		Name:        req.Name,
 		Description: req.Description,
 		ClientType:  req.ClientType,
-		Format:      req.Format,
+		Format:      format,
 		Content:     req.Content,
-		Variables:   toRepositoryVariables(req.Variables),
+		Variables:   variables,
 		IsDefault:   req.IsDefault,
 	}
 
@@ -0,0 +1,109 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// DiffLogic 对比模板版本。
+type DiffLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDiffLogic 构造函数。
+func NewDiffLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DiffLogic {
+	return &DiffLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// templateSnapshot 参与对比的模板内容。
+type templateSnapshot struct {
+	label     string
+	format    string
+	content   string
+	variables map[string]repository.TemplateVariable
+}
+
+// Diff 输出 from 到 to 的内容统一 diff 与变量差异。
+func (l *DiffLogic) Diff(req *types.AdminSubscriptionTemplateDiffRequest) (*types.AdminSubscriptionTemplateDiffResponse, error) {
+	from, err := l.snapshot(req.TemplateID, req.From)
+	if err != nil {
+		return nil, err
+	}
+	to, err := l.snapshot(req.TemplateID, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	contentDiff, err := subscriptionutil.ContentDiff(from.label, to.label, from.content, to.content)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AdminSubscriptionTemplateDiffResponse{
+		TemplateID:  req.TemplateID,
+		From:        req.From,
+		To:          req.To,
+		FromFormat:  from.format,
+		ToFormat:    to.format,
+		ContentDiff: contentDiff,
+		Variables:   toVariableChanges(subscriptionutil.DiffVariables(from.variables, to.variables)),
+	}, nil
+}
+
+func (l *DiffLogic) snapshot(templateID uint64, version uint32) (templateSnapshot, error) {
+	if version == 0 {
+		tpl, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, templateID)
+		if err != nil {
+			return templateSnapshot{}, err
+		}
+		return templateSnapshot{label: "draft", format: tpl.Format, content: tpl.Content, variables: tpl.Variables}, nil
+	}
+
+	history, err := l.svcCtx.Repositories.SubscriptionTemplate.GetHistory(l.ctx, templateID, version)
+	if err != nil {
+		return templateSnapshot{}, err
+	}
+	return templateSnapshot{
+		label:     fmt.Sprintf("v%d", history.Version),
+		format:    history.Format,
+		content:   history.Content,
+		variables: history.Variables,
+	}, nil
+}
+
+func toVariableChanges(changes []subscriptionutil.VariableChange) []types.TemplateVariableChange {
+	result := make([]types.TemplateVariableChange, 0, len(changes))
+	for _, change := range changes {
+		item := types.TemplateVariableChange{Name: change.Name, Change: change.Change}
+		if change.Before != nil {
+			item.Before = toVariablePtr(*change.Before)
+		}
+		if change.After != nil {
+			item.After = toVariablePtr(*change.After)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func toVariablePtr(v repository.TemplateVariable) *types.TemplateVariable {
+	return &types.TemplateVariable{
+		ValueType:    v.ValueType,
+		Required:     v.Required,
+		Description:  v.Description,
+		DefaultValue: v.DefaultValue,
+	}
+}
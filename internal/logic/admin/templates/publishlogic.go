@@ -3,9 +3,11 @@ package templates
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -30,22 +32,12 @@ func NewPublishLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PublishLo
 
 // Publish 执行发布。
 func (l *PublishLogic) Publish(req *types.AdminPublishSubscriptionTemplateRequest) (*types.AdminPublishSubscriptionTemplateResponse, error) {
-	operator := strings.TrimSpace(req.Operator)
-	if operator == "" {
-		if user, ok := security.UserFromContext(l.ctx); ok {
-			operator = strings.TrimSpace(user.DisplayName)
-			if operator == "" {
-				operator = strings.TrimSpace(user.Email)
-			}
-		}
-	}
-	if operator == "" {
-		operator = "system"
-	}
+	operator := resolveOperator(l.ctx, req.Operator)
 
 	input := repository.PublishSubscriptionTemplateInput{
 		Changelog: strings.TrimSpace(req.Changelog),
 		Operator:  operator,
+		Check:     subscriptionutil.PublishCheck(subscriptionutil.RenderLimits(l.svcCtx.Config.Template), time.Now()),
 	}
 
 	tpl, history, err := l.svcCtx.Repositories.SubscriptionTemplate.Publish(l.ctx, req.TemplateID, input)
@@ -63,3 +55,20 @@ func (l *PublishLogic) Publish(req *types.AdminPublishSubscriptionTemplateReques
 		History:  historyEntry,
 	}, nil
 }
+
+// resolveOperator 优先使用请求中的操作人，否则取当前管理员名称或邮箱，均为空时记为 system。
+func resolveOperator(ctx context.Context, operator string) string {
+	operator = strings.TrimSpace(operator)
+	if operator == "" {
+		if user, ok := security.UserFromContext(ctx); ok {
+			operator = strings.TrimSpace(user.DisplayName)
+			if operator == "" {
+				operator = strings.TrimSpace(user.Email)
+			}
+		}
+	}
+	if operator == "" {
+		operator = "system"
+	}
+	return operator
+}
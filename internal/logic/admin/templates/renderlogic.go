@@ -0,0 +1,110 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// 试渲染数据来源。
+const (
+	renderSourceFixture      = "fixture"
+	renderSourceSubscription = "subscription"
+)
+
+// RenderLogic 试渲染订阅模板。
+type RenderLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRenderLogic 构造函数。
+func NewRenderLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RenderLogic {
+	return &RenderLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Render 在沙箱限制内渲染草稿或指定历史版本；渲染失败不返回错误，而是在响应中给出失败详情。
+func (l *RenderLogic) Render(req *types.AdminRenderSubscriptionTemplateRequest) (*types.AdminRenderSubscriptionTemplateResponse, error) {
+	tpl, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Version > 0 {
+		history, err := l.svcCtx.Repositories.SubscriptionTemplate.GetHistory(l.ctx, tpl.ID, req.Version)
+		if err != nil {
+			return nil, err
+		}
+		tpl.Version = history.Version
+		tpl.Format = history.Format
+		tpl.Content = history.Content
+		tpl.Variables = history.Variables
+	}
+
+	now := time.Now().UTC()
+	resp := &types.AdminRenderSubscriptionTemplateResponse{
+		TemplateID:  tpl.ID,
+		Version:     req.Version,
+		Source:      renderSourceFixture,
+		ContentType: subtemplate.ContentType(tpl.Format),
+	}
+
+	var data map[string]any
+	if req.SubscriptionID > 0 {
+		sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		data, err = subscriptionutil.BuildContext(l.ctx, l.svcCtx.Repositories, sub, tpl, now)
+		if err != nil {
+			return nil, err
+		}
+		resp.Source = renderSourceSubscription
+		resp.SubscriptionID = sub.ID
+	} else {
+		data = subscriptionutil.FixtureContext(tpl, now)
+	}
+
+	limits := subscriptionutil.RenderLimits(l.svcCtx.Config.Template)
+	started := time.Now()
+	content, err := subscriptionutil.RenderSandboxed(tpl, data, limits)
+	resp.DurationMs = time.Since(started).Milliseconds()
+	if err != nil {
+		resp.Error = toRenderError(err)
+		l.Infof("template-render: template_id=%d version=%d source=%s failed: %v", tpl.ID, req.Version, resp.Source, err)
+		return resp, nil
+	}
+
+	resp.Success = true
+	resp.Content = content
+	resp.SizeBytes = len(content)
+	return resp, nil
+}
+
+func toRenderError(err error) *types.TemplateRenderError {
+	line, column := subtemplate.ErrorPosition(err)
+	result := &types.TemplateRenderError{
+		Message: err.Error(),
+		Line:    line,
+		Column:  column,
+	}
+	var varErr *subtemplate.VariableError
+	if errors.As(err, &varErr) {
+		result.Undeclared = varErr.Undeclared
+		result.Missing = varErr.Missing
+		result.Invalid = varErr.Invalid
+	}
+	return result
+}
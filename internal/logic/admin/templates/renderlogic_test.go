@@ -0,0 +1,156 @@
+package templates
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func setupTemplateTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:admintemplates?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+	}
+	svcCtx.Config.Template.Normalize()
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestRenderDraftAndBlockPublish(t *testing.T) {
+	svcCtx, cleanup := setupTemplateTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	created, err := NewCreateLogic(ctx, svcCtx).Create(&types.AdminCreateSubscriptionTemplateRequest{
+		Name:       "Lines",
+		ClientType: "clash",
+		Content:    `{{ .subscription.plan }}{{ range .nodes }} {{ .name }}{{ end }}`,
+	})
+	require.NoError(t, err)
+
+	renderLogic := NewRenderLogic(ctx, svcCtx)
+	resp, err := renderLogic.Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	require.Equal(t, "fixture", resp.Source)
+	require.Equal(t, "示例套餐 示例-香港 示例-东京", resp.Content)
+	require.Equal(t, len(resp.Content), resp.SizeBytes)
+
+	_, err = NewPublishLogic(ctx, svcCtx).Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: created.ID, Operator: "ops"})
+	require.NoError(t, err)
+
+	// 草稿执行出错：返回行列号并阻止发布，已发布版本仍可试渲染。
+	broken := "{{ .subscription.plan }}\n  {{ index .nodes 5 }}"
+	_, err = NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdateSubscriptionTemplateRequest{TemplateID: created.ID, Content: &broken})
+	require.NoError(t, err)
+
+	resp, err = renderLogic.Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID})
+	require.NoError(t, err)
+	require.False(t, resp.Success)
+	require.NotNil(t, resp.Error)
+	require.Equal(t, 2, resp.Error.Line)
+	require.Equal(t, 5, resp.Error.Column)
+
+	_, err = NewPublishLogic(ctx, svcCtx).Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: created.ID, Operator: "ops"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	stored, err := svcCtx.Repositories.SubscriptionTemplate.Get(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), stored.Version)
+
+	resp, err = renderLogic.Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID, Version: 1})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	require.Equal(t, uint32(1), resp.Version)
+	_, err = renderLogic.Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID, Version: 9})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	// 使用真实订阅渲染；输出超过上限时失败。
+	owner := repository.User{Email: "render@test.dev", DisplayName: "Render", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+	sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+		UserID:     owner.ID,
+		Name:       "Mine",
+		PlanName:   "Real",
+		TemplateID: created.ID,
+		Token:      "feedfacefeedfacefeedfacefeedface",
+		ExpiresAt:  now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	resp, err = renderLogic.Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID, Version: 1, SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	require.Equal(t, "subscription", resp.Source)
+	require.Equal(t, "Real", resp.Content)
+
+	svcCtx.Config.Template.MaxOutputBytes = 3
+	resp, err = renderLogic.Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID, Version: 1, SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.False(t, resp.Success)
+	require.Contains(t, resp.Error.Message, "size limit")
+}
+
+func TestJinjaTemplateValidationAndDryRun(t *testing.T) {
+	svcCtx, cleanup := setupTemplateTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	create := NewCreateLogic(ctx, svcCtx)
+
+	_, err := create.Create(&types.AdminCreateSubscriptionTemplateRequest{Name: "Broken", ClientType: "clash", Format: "jinja", Content: "{% for node in %}"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	_, err = create.Create(&types.AdminCreateSubscriptionTemplateRequest{Name: "Empty", ClientType: "clash", Format: "pongo2"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	_, err = create.Create(&types.AdminCreateSubscriptionTemplateRequest{Name: "Files", ClientType: "clash", Format: "jinja", Content: `{% ssi "/etc/hosts" %}`})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	created, err := create.Create(&types.AdminCreateSubscriptionTemplateRequest{
+		Name:       "Jinja",
+		ClientType: "clash",
+		Format:     "Jinja2",
+		Content:    `{{ subscription.plan }}{% for node in nodes %} {{ node.name }}{% endfor %}`,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "jinja", created.Format)
+
+	resp, err := NewRenderLogic(ctx, svcCtx).Render(&types.AdminRenderSubscriptionTemplateRequest{TemplateID: created.ID})
+	require.NoError(t, err)
+	require.True(t, resp.Success)
+	require.Equal(t, "示例套餐 示例-香港 示例-东京", resp.Content)
+
+	broken := "ok\n{{ nodes.0.name|nosuchfilter }}"
+	_, err = NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdateSubscriptionTemplateRequest{TemplateID: created.ID, Content: &broken})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
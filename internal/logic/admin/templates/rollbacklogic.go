@@ -0,0 +1,65 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// RollbackLogic 回滚模板到历史版本。
+type RollbackLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRollbackLogic 构造函数。
+func NewRollbackLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RollbackLogic {
+	return &RollbackLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Rollback 将历史版本复制到草稿并发布为新版本，changelog 记录来源版本；当前未发布的草稿改动会被覆盖。
+func (l *RollbackLogic) Rollback(req *types.AdminRollbackSubscriptionTemplateRequest) (*types.AdminPublishSubscriptionTemplateResponse, error) {
+	if req.Version == 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	source, err := l.svcCtx.Repositories.SubscriptionTemplate.GetHistory(l.ctx, req.TemplateID, req.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	operator := resolveOperator(l.ctx, req.Operator)
+	changelog := fmt.Sprintf("回滚至版本 v%d", source.Version)
+	if note := strings.TrimSpace(req.Changelog); note != "" {
+		changelog += "：" + note
+	}
+
+	tpl, history, err := l.svcCtx.Repositories.SubscriptionTemplate.Rollback(l.ctx, req.TemplateID, source.Version, repository.PublishSubscriptionTemplateInput{
+		Changelog: changelog,
+		Operator:  operator,
+		Check:     subscriptionutil.PublishCheck(subscriptionutil.RenderLimits(l.svcCtx.Config.Template), time.Now()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	l.Infof("audit: template rollback template_id=%d from_version=%d new_version=%d operator=%s", tpl.ID, source.Version, tpl.Version, operator)
+
+	return &types.AdminPublishSubscriptionTemplateResponse{
+		Template: toTemplateSummary(tpl),
+		History:  toHistoryEntry(history),
+	}, nil
+}
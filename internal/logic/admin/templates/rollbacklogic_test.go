@@ -0,0 +1,87 @@
+package templates
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func TestDiffAndRollback(t *testing.T) {
+	svcCtx, cleanup := setupTemplateTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	created, err := NewCreateLogic(ctx, svcCtx).Create(&types.AdminCreateSubscriptionTemplateRequest{
+		Name:       "Versions",
+		ClientType: "clash",
+		Content:    "# {{ .brand }}\n{{ .subscription.plan }}\n",
+		Variables: map[string]types.TemplateVariable{
+			"brand": {ValueType: "string", DefaultValue: "ZNP"},
+		},
+	})
+	require.NoError(t, err)
+
+	publish := NewPublishLogic(ctx, svcCtx)
+	_, err = publish.Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: created.ID, Changelog: "初版", Operator: "ops"})
+	require.NoError(t, err)
+
+	content := "# {{ .brand }}\n{{ .subscription.name }}\n{{ .footer }}\n"
+	_, err = NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdateSubscriptionTemplateRequest{
+		TemplateID: created.ID,
+		Content:    &content,
+		Variables: map[string]types.TemplateVariable{
+			"brand":  {ValueType: "string", DefaultValue: "ZNP Pro"},
+			"footer": {ValueType: "string"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = publish.Publish(&types.AdminPublishSubscriptionTemplateRequest{TemplateID: created.ID, Operator: "ops"})
+	require.NoError(t, err)
+
+	diff, err := NewDiffLogic(ctx, svcCtx).Diff(&types.AdminSubscriptionTemplateDiffRequest{TemplateID: created.ID, From: 1, To: 2})
+	require.NoError(t, err)
+	require.Contains(t, diff.ContentDiff, "--- v1")
+	require.Contains(t, diff.ContentDiff, "+++ v2")
+	require.Contains(t, diff.ContentDiff, "-{{ .subscription.plan }}")
+	require.Contains(t, diff.ContentDiff, "+{{ .subscription.name }}")
+	require.Len(t, diff.Variables, 2)
+	require.Equal(t, "brand", diff.Variables[0].Name)
+	require.Equal(t, "changed", diff.Variables[0].Change)
+	require.Equal(t, "ZNP", diff.Variables[0].Before.DefaultValue)
+	require.Equal(t, "ZNP Pro", diff.Variables[0].After.DefaultValue)
+	require.Equal(t, "footer", diff.Variables[1].Name)
+	require.Equal(t, "added", diff.Variables[1].Change)
+	require.Nil(t, diff.Variables[1].Before)
+
+	// 与草稿对比：草稿未改动时内容无差异。
+	diff, err = NewDiffLogic(ctx, svcCtx).Diff(&types.AdminSubscriptionTemplateDiffRequest{TemplateID: created.ID, From: 2})
+	require.NoError(t, err)
+	require.Empty(t, diff.ContentDiff)
+	require.Empty(t, diff.Variables)
+
+	_, err = NewDiffLogic(ctx, svcCtx).Diff(&types.AdminSubscriptionTemplateDiffRequest{TemplateID: created.ID, From: 1, To: 7})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	rollback := NewRollbackLogic(ctx, svcCtx)
+	resp, err := rollback.Rollback(&types.AdminRollbackSubscriptionTemplateRequest{TemplateID: created.ID, Version: 1, Changelog: "v2 渲染异常", Operator: "ops"})
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), resp.Template.Version)
+	require.Equal(t, uint32(3), resp.History.Version)
+	require.Equal(t, "回滚至版本 v1：v2 渲染异常", resp.History.Changelog)
+
+	current, err := svcCtx.Repositories.SubscriptionTemplate.Get(ctx, created.ID)
+	require.NoError(t, err)
+	require.Equal(t, "# {{ .brand }}\n{{ .subscription.plan }}\n", current.Content)
+	require.Equal(t, "ZNP", current.Variables["brand"].DefaultValue)
+	require.NotContains(t, current.Variables, "footer")
+
+	_, err = rollback.Rollback(&types.AdminRollbackSubscriptionTemplateRequest{TemplateID: created.ID, Version: 9})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = rollback.Rollback(&types.AdminRollbackSubscriptionTemplateRequest{TemplateID: created.ID})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
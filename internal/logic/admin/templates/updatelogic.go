@@ -2,12 +2,15 @@ package templates
 
 import (
 	"context"
+	"strings"
 
 	"github.com/zeromicro/go-zero/core/logx"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
 )
 
 // UpdateLogic 更新模板。
@@ -28,6 +31,33 @@ func NewUpdateLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateLogi
 
 // Update 执行更新操作。
 func (l *UpdateLogic) Update(req *types.AdminUpdateSubscriptionTemplateRequest) (*types.SubscriptionTemplateSummary, error) {
+	if req.Format != nil {
+		if strings.TrimSpace(*req.Format) == "" || !subtemplate.SupportedFormat(*req.Format) {
+			return nil, repository.ErrInvalidArgument
+		}
+		format := subtemplate.NormalizeFormat(*req.Format)
+		req.Format = &format
+	}
+
+	// 按合并后的格式、内容与变量校验，避免只改变量或内容时绕过引用检查。
+	current, err := l.svcCtx.Repositories.SubscriptionTemplate.Get(l.ctx, req.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	format, content, variables := current.Format, current.Content, current.Variables
+	if req.Format != nil {
+		format = *req.Format
+	}
+	if req.Content != nil {
+		content = *req.Content
+	}
+	if req.Variables != nil {
+		variables = toRepositoryVariables(req.Variables)
+	}
+	if err := subscriptionutil.ValidateTemplate(format, content, variables); err != nil {
+		return nil, err
+	}
+
 	input := repository.UpdateSubscriptionTemplateInput{
 		Name:        req.Name,
 		Description: req.Description,
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+const minPasswordLength = 8
+
+// RegisterLogic 处理自助注册。
+type RegisterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRegisterLogic 构造函数。
+func NewRegisterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegisterLogic {
+	return &RegisterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Register 创建用户并签发令牌；携带邀请码时记录邀请关系。
+func (l *RegisterLogic) Register(req *types.AuthRegisterRequest) (*types.AuthLoginResponse, error) {
+	cfg := l.svcCtx.Config.Registration
+	if !cfg.Enable {
+		return nil, repository.ErrForbidden
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if _, err := mail.ParseAddress(email); err != nil || len(req.Password) < minPasswordLength {
+		return nil, repository.ErrInvalidArgument
+	}
+	inviteCode := repository.NormalizeInviteCode(req.InviteCode)
+	if cfg.RequireInviteCode && inviteCode == "" {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	displayName := strings.TrimSpace(req.DisplayName)
+	if displayName == "" {
+		displayName = strings.SplitN(email, "@", 2)[0]
+	}
+
+	var user repository.User
+	err = l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		repos, err := repository.NewRepositories(tx)
+		if err != nil {
+			return err
+		}
+
+		var invite repository.InviteCode
+		if inviteCode != "" {
+			invite, err = repos.Affiliate.FindInviteCode(l.ctx, inviteCode)
+			if err != nil {
+				if errors.Is(err, repository.ErrNotFound) {
+					return repository.ErrInvalidArgument
+				}
+				return err
+			}
+		}
+
+		user, err = repos.User.Create(l.ctx, repository.User{
+			Email:        email,
+			DisplayName:  displayName,
+			PasswordHash: string(hash),
+		})
+		if err != nil {
+			return err
+		}
+
+		if invite.UserID != 0 {
+			if _, err := repos.Affiliate.CreateReferral(l.ctx, repository.Referral{
+				UserID:     user.ID,
+				ReferrerID: invite.UserID,
+				InviteCode: invite.Code,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	audience := l.svcCtx.Config.Project.Name
+	if audience == "" {
+		audience = "znp"
+	}
+
+	pair, err := l.svcCtx.Auth.GenerateTokenPair(strconv.FormatUint(user.ID, 10), user.Roles, audience)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.AuthLoginResponse{
+		AccessToken:      pair.AccessToken,
+		RefreshToken:     pair.RefreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        computeTTL(pair.AccessExpire),
+		RefreshExpiresIn: computeTTL(pair.RefreshExpire),
+		User:             toAuthenticatedUser(user),
+	}, nil
+}
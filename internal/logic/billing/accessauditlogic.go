@@ -0,0 +1,101 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+// AccessAuditResult 汇总一次拉取日志巡检的处理结果。
+type AccessAuditResult struct {
+	Flagged   int
+	Suspended int
+	Pruned    int64
+}
+
+// AccessAuditLogic 巡检订阅拉取日志：窗口内来源 IP 过多的 token 自动暂停其订阅，并清理过期日志。
+type AccessAuditLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAccessAuditLogic constructs AccessAuditLogic.
+func NewAccessAuditLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AccessAuditLogic {
+	return &AccessAuditLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Run 清理保留期之前的日志；SuspendDistinctIPs 大于 0 时暂停超过阈值的订阅，已暂停或已取消的订阅只计入 Flagged。
+// 订阅状态在窗口内变化过时，只按变化之后的拉取判断，管理员恢复的订阅不会因恢复前的日志再次被暂停。
+func (l *AccessAuditLogic) Run(now time.Time) (AccessAuditResult, error) {
+	var result AccessAuditResult
+
+	cfg := l.svcCtx.Config.Jobs.AccessAudit
+	cfg.Normalize()
+
+	pruned, err := l.svcCtx.Repositories.Subscription.PruneAccessLogs(l.ctx, now.Add(-cfg.Retention))
+	if err != nil {
+		return result, err
+	}
+	result.Pruned = pruned
+
+	if cfg.SuspendDistinctIPs <= 0 {
+		return result, nil
+	}
+
+	since := now.Add(-cfg.Window)
+	stats, err := l.svcCtx.Repositories.Subscription.ListAccessAnomalies(l.ctx, since, cfg.SuspendDistinctIPs, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, stat := range stats {
+		if err := l.ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Flagged++
+
+		sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, stat.SubscriptionID)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		if sub.Status == repository.SubscriptionStatusSuspended || sub.Status == repository.SubscriptionStatusCancelled {
+			continue
+		}
+		// 窗口内状态发生过变化（如管理员解除暂停）时只统计变化之后的拉取，避免依据旧日志再次暂停。
+		if sub.StatusChangedAt != nil && sub.StatusChangedAt.After(since) {
+			stat, err = l.svcCtx.Repositories.Subscription.GetAccessStat(l.ctx, sub.ID, stat.Token, *sub.StatusChangedAt)
+			if err != nil {
+				return result, err
+			}
+			if stat.DistinctIPs < int64(cfg.SuspendDistinctIPs) {
+				continue
+			}
+		}
+
+		if _, err := l.svcCtx.Repositories.Subscription.TransitionStatus(l.ctx, sub.ID, repository.SubscriptionStatusSuspended, repository.SubscriptionReasonAccessAbuse); err != nil {
+			if errors.Is(err, repository.ErrInvalidState) {
+				continue
+			}
+			return result, err
+		}
+
+		result.Suspended++
+		l.Infof("access-audit: suspended subscription=%d user=%d distinct_ips=%d countries=%d fetches=%d",
+			sub.ID, sub.UserID, stat.DistinctIPs, stat.DistinctCountries, stat.Fetches)
+	}
+
+	return result, nil
+}
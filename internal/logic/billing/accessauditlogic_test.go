@@ -0,0 +1,175 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	adminsubscriptions "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/subscriptions"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscribe"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+	"github.com/zero-net-panel/zero-net-panel/pkg/geoip"
+)
+
+func setupAccessAuditTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:accessaudit?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	cacheProvider, err := cache.New(cache.Config{})
+	require.NoError(t, err)
+
+	geo, err := geoip.Load(strings.NewReader("start,end,country\n198.51.100.0,198.51.100.255,US\n203.0.113.0,203.0.113.255,JP\n"))
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Cache:        cacheProvider,
+		Repositories: repos,
+		GeoIP:        geo,
+	}
+	svcCtx.Config.Jobs.AccessAudit.SuspendDistinctIPs = 4
+
+	cleanup := func() {
+		_ = cacheProvider.Close()
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestAccessAuditSuspendsSharedTokens(t *testing.T) {
+	svcCtx, cleanup := setupAccessAuditTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	owner := repository.User{Email: "audit@test.dev", DisplayName: "Audit", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+
+	tpl, err := svcCtx.Repositories.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Plain",
+		ClientType: "clash",
+		Format:     "go_template",
+		Content:    "plain",
+	})
+	require.NoError(t, err)
+	_, _, err = svcCtx.Repositories.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+
+	newSubscription := func(name string) repository.Subscription {
+		token, err := repository.GenerateSubscriptionToken()
+		require.NoError(t, err)
+		sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+			UserID:     owner.ID,
+			Name:       name,
+			TemplateID: tpl.ID,
+			Token:      token,
+			Status:     repository.SubscriptionStatusActive,
+			ExpiresAt:  now.Add(24 * time.Hour),
+		})
+		require.NoError(t, err)
+		return sub
+	}
+	shared := newSubscription("Shared")
+	private := newSubscription("Private")
+
+	fetch := subscribe.NewFetchLogic(ctx, svcCtx)
+	for i := 1; i <= 3; i++ {
+		_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: shared.Token}, fmt.Sprintf("198.51.100.%d", i), "mihomo/1.18")
+		require.NoError(t, err)
+	}
+	_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: shared.Token}, "203.0.113.7", "Shadowrocket/2070")
+	require.NoError(t, err)
+	_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: private.Token}, "198.51.100.1", "")
+	require.NoError(t, err)
+
+	logs, err := adminsubscriptions.NewAccessLogic(ctx, svcCtx).Logs(&types.AdminSubscriptionAccessLogsRequest{SubscriptionID: shared.ID})
+	require.NoError(t, err)
+	require.Len(t, logs.Logs, 4)
+	require.Equal(t, "203.0.113.7", logs.Logs[0].IP)
+	require.Equal(t, "shadowrocket", logs.Logs[0].ClientType)
+	require.Equal(t, "JP", logs.Logs[0].Country)
+	require.Equal(t, "clash-meta", logs.Logs[1].ClientType)
+	require.Equal(t, "US", logs.Logs[1].Country)
+
+	report, err := adminsubscriptions.NewAccessLogic(ctx, svcCtx).Anomalies(&types.AdminSubscriptionAccessAnomaliesRequest{MinIPs: 2})
+	require.NoError(t, err)
+	require.Len(t, report.Anomalies, 1)
+	require.Equal(t, shared.ID, report.Anomalies[0].SubscriptionID)
+	require.Equal(t, int64(4), report.Anomalies[0].DistinctIPs)
+	require.Equal(t, int64(2), report.Anomalies[0].DistinctCountries)
+	require.Equal(t, 4, report.SuspendThreshold)
+
+	result, err := NewAccessAuditLogic(ctx, svcCtx).Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Flagged)
+	require.Equal(t, 1, result.Suspended)
+
+	updated, err := svcCtx.Repositories.Subscription.Get(ctx, shared.ID)
+	require.NoError(t, err)
+	require.Equal(t, repository.SubscriptionStatusSuspended, updated.Status)
+	require.Equal(t, repository.SubscriptionReasonAccessAbuse, updated.StatusReason)
+
+	// 暂停后的拉取被拒绝但仍会记录；再次巡检不会重复暂停。
+	_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: shared.Token}, "198.51.100.9", "mihomo/1.18")
+	require.ErrorIs(t, err, repository.ErrForbidden)
+	result, err = NewAccessAuditLogic(ctx, svcCtx).Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Suspended)
+
+	logs, err = adminsubscriptions.NewAccessLogic(ctx, svcCtx).Logs(&types.AdminSubscriptionAccessLogsRequest{SubscriptionID: shared.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), logs.Pagination.TotalCount)
+
+	// 管理员恢复后，恢复前的旧日志不再触发暂停；恢复后继续被多 IP 共享时仍会再次暂停。
+	_, err = adminsubscriptions.NewStatusLogic(ctx, svcCtx).Update(&types.AdminUpdateSubscriptionStatusRequest{SubscriptionID: shared.ID, Status: repository.SubscriptionStatusActive})
+	require.NoError(t, err)
+	result, err = NewAccessAuditLogic(ctx, svcCtx).Run(time.Now().UTC())
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Suspended)
+	updated, err = svcCtx.Repositories.Subscription.Get(ctx, shared.ID)
+	require.NoError(t, err)
+	require.Equal(t, repository.SubscriptionStatusActive, updated.Status)
+
+	for i := 11; i <= 14; i++ {
+		_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: shared.Token}, fmt.Sprintf("198.51.100.%d", i), "mihomo/1.18")
+		require.NoError(t, err)
+	}
+	result, err = NewAccessAuditLogic(ctx, svcCtx).Run(time.Now().UTC())
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Suspended)
+
+	// 超过保留期的日志被清理。
+	result, err = NewAccessAuditLogic(ctx, svcCtx).Run(now.Add(31 * 24 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, int64(10), result.Pruned)
+
+	private, err = svcCtx.Repositories.Subscription.Get(ctx, private.ID)
+	require.NoError(t, err)
+	require.Equal(t, repository.SubscriptionStatusActive, private.Status)
+}
@@ -0,0 +1,160 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	userorder "github.com/zero-net-panel/zero-net-panel/internal/logic/user/order"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// NotificationCategoryAutoRenew 自动续费通知分类。
+const NotificationCategoryAutoRenew = "auto_renew"
+
+// AutoRenewResult 汇总一次扫描的处理结果。
+type AutoRenewResult struct {
+	Scanned  int
+	Renewed  int
+	Failed   int
+	Disabled int
+}
+
+// AutoRenewLogic 扫描即将到期且开启自动续费的订阅，并通过余额下单续费。
+type AutoRenewLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewAutoRenewLogic constructs AutoRenewLogic.
+func NewAutoRenewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *AutoRenewLogic {
+	return &AutoRenewLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Run 处理在 now + LeadDays 之前到期、且过期未超过 GraceDays 的订阅。余额不足时按 RetryInterval 重试，
+// 连续失败 MaxAttempts 次或遇到不可重试错误时关闭自动续费并通知用户。
+func (l *AutoRenewLogic) Run(now time.Time) (AutoRenewResult, error) {
+	var result AutoRenewResult
+
+	cfg := l.svcCtx.Config.Jobs.AutoRenew
+	cfg.Normalize()
+
+	horizon := now.Add(time.Duration(cfg.LeadDays) * 24 * time.Hour)
+	graceStart := now.Add(-time.Duration(cfg.GraceDays) * 24 * time.Hour)
+	subs, err := l.svcCtx.Repositories.Subscription.ListAutoRenewDue(l.ctx, graceStart, horizon, now, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, sub := range subs {
+		if err := l.ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Scanned++
+
+		order, renewErr := l.renew(sub)
+		if renewErr == nil {
+			if _, err := l.svcCtx.Repositories.Subscription.RecordAutoRenewResult(l.ctx, sub.ID, repository.AutoRenewResultParams{Succeeded: true}); err != nil {
+				return result, err
+			}
+			l.notify(sub, "订阅已自动续费", fmt.Sprintf("订阅「%s」已通过余额自动续费，订单号 %s。", sub.Name, order.Number), map[string]any{
+				"subscription_id": sub.ID,
+				"order_id":        order.ID,
+				"order_number":    order.Number,
+				"result":          "renewed",
+			})
+			result.Renewed++
+			continue
+		}
+		if errors.Is(renewErr, context.Canceled) || errors.Is(renewErr, context.DeadlineExceeded) {
+			return result, renewErr
+		}
+
+		result.Failed++
+		failures := sub.AutoRenewFailures + 1
+		retryable := errors.Is(renewErr, repository.ErrInsufficientBalance)
+		disable := !retryable || failures >= cfg.MaxAttempts
+		nextAt := now.Add(cfg.RetryInterval)
+
+		if _, err := l.svcCtx.Repositories.Subscription.RecordAutoRenewResult(l.ctx, sub.ID, repository.AutoRenewResultParams{
+			Error:   renewErr.Error(),
+			NextAt:  &nextAt,
+			Disable: disable,
+		}); err != nil {
+			return result, err
+		}
+
+		metadata := map[string]any{
+			"subscription_id": sub.ID,
+			"attempt":         failures,
+			"max_attempts":    cfg.MaxAttempts,
+			"error":           renewErr.Error(),
+		}
+		if disable {
+			result.Disabled++
+			metadata["result"] = "disabled"
+			l.notify(sub, "自动续费已停止", fmt.Sprintf("订阅「%s」自动续费失败 %d 次，已关闭自动续费，请充值后手动续费。", sub.Name, failures), metadata)
+		} else {
+			metadata["result"] = "retry"
+			metadata["next_attempt_at"] = nextAt.Unix()
+			l.notify(sub, "自动续费失败", fmt.Sprintf("订阅「%s」自动续费失败：余额不足，将于 %s 重试。", sub.Name, nextAt.Format(time.RFC3339)), metadata)
+		}
+		l.Infof("auto-renew: subscription=%d attempt=%d disabled=%t err=%v", sub.ID, failures, disable, renewErr)
+	}
+
+	return result, nil
+}
+
+// renew 以订阅所属用户身份调用 CreateLogic，复用其余额扣款与履约事务。
+func (l *AutoRenewLogic) renew(sub repository.Subscription) (types.OrderDetail, error) {
+	user, err := l.svcCtx.Repositories.User.Get(l.ctx, sub.UserID)
+	if err != nil {
+		return types.OrderDetail{}, err
+	}
+
+	userCtx := security.WithUser(l.ctx, security.UserClaims{
+		ID:          user.ID,
+		Email:       user.Email,
+		DisplayName: user.DisplayName,
+		Roles:       user.Roles,
+	})
+
+	resp, err := userorder.NewCreateLogic(userCtx, l.svcCtx).Create(&types.UserCreateOrderRequest{
+		PlanID:         sub.PlanID,
+		Quantity:       1,
+		SubscriptionID: sub.ID,
+		PaymentMethod:  repository.PaymentMethodBalance,
+		IdempotencyKey: fmt.Sprintf("auto-renew:%d:%d", sub.ID, sub.ExpiresAt.Unix()),
+	})
+	if err != nil {
+		return types.OrderDetail{}, err
+	}
+	if resp.Order.Status != repository.OrderStatusPaid {
+		return types.OrderDetail{}, repository.ErrInvalidState
+	}
+
+	return resp.Order, nil
+}
+
+func (l *AutoRenewLogic) notify(sub repository.Subscription, title, content string, metadata map[string]any) {
+	if _, err := l.svcCtx.Repositories.Notification.Create(l.ctx, repository.Notification{
+		UserID:   sub.UserID,
+		Category: NotificationCategoryAutoRenew,
+		Title:    title,
+		Content:  content,
+		Metadata: metadata,
+	}); err != nil {
+		l.Errorf("auto-renew: notify user %d failed: %v", sub.UserID, err)
+	}
+}
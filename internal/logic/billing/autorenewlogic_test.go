@@ -0,0 +1,166 @@
+package billing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+)
+
+func setupAutoRenewTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:autorenew?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestAutoRenewChargesBalanceAndRetries(t *testing.T) {
+	svcCtx, cleanup := setupAutoRenewTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	svcCtx.Config.Jobs.AutoRenew.MaxAttempts = 2
+	svcCtx.Config.Jobs.AutoRenew.RetryInterval = time.Hour
+
+	user := repository.User{
+		Email:       "renew@test.dev",
+		DisplayName: "Renew",
+		Roles:       []string{"user"},
+		Status:      "active",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&user).Error)
+
+	plan := repository.Plan{
+		Name:              "Monthly",
+		Slug:              "monthly",
+		PriceCents:        1000,
+		Currency:          "CNY",
+		DurationDays:      30,
+		TrafficLimitBytes: 1 << 30,
+		DevicesLimit:      2,
+		Status:            "active",
+		Visible:           true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&plan).Error)
+
+	_, _, err := svcCtx.Repositories.Balance.ApplyTransaction(ctx, user.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 1500,
+		Currency:    "CNY",
+		Reference:   "seed",
+	})
+	require.NoError(t, err)
+
+	sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+		UserID:            user.ID,
+		PlanID:            plan.ID,
+		Name:              "Monthly",
+		PlanName:          "Monthly",
+		Token:             "renew-token",
+		ExpiresAt:         now.Add(24 * time.Hour),
+		TrafficTotalBytes: plan.TrafficLimitBytes,
+		DevicesLimit:      plan.DevicesLimit,
+	})
+	require.NoError(t, err)
+	_, err = svcCtx.Repositories.Subscription.SetAutoRenew(ctx, sub.ID, user.ID, true)
+	require.NoError(t, err)
+
+	// 过期超过宽限期的订阅不再自动扣款。
+	lapsed, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+		UserID:            user.ID,
+		PlanID:            plan.ID,
+		Name:              "Lapsed",
+		PlanName:          "Monthly",
+		Token:             "lapsed-token",
+		ExpiresAt:         now.Add(-30 * 24 * time.Hour),
+		TrafficTotalBytes: plan.TrafficLimitBytes,
+		DevicesLimit:      plan.DevicesLimit,
+	})
+	require.NoError(t, err)
+	_, err = svcCtx.Repositories.Subscription.SetAutoRenew(ctx, lapsed.ID, user.ID, true)
+	require.NoError(t, err)
+
+	logic := NewAutoRenewLogic(ctx, svcCtx)
+
+	result, err := logic.Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Scanned)
+	require.Equal(t, 1, result.Renewed)
+
+	renewed, err := svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, sub.ExpiresAt.Add(30*24*time.Hour), renewed.ExpiresAt, time.Second)
+	require.True(t, renewed.AutoRenew)
+
+	balance, err := svcCtx.Repositories.Balance.GetBalance(ctx, user.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), balance.BalanceCents)
+
+	// 已续费的订阅不再落入扫描窗口。
+	result, err = logic.Run(now)
+	require.NoError(t, err)
+	require.Zero(t, result.Scanned)
+
+	// 余额不足：记录失败并按间隔重试，达到上限后关闭。
+	require.NoError(t, svcCtx.DB.Model(&repository.Subscription{}).Where("id = ?", sub.ID).
+		Update("expires_at", now.Add(48*time.Hour)).Error)
+
+	result, err = logic.Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Failed)
+	require.Zero(t, result.Disabled)
+
+	result, err = logic.Run(now.Add(30 * time.Minute))
+	require.NoError(t, err)
+	require.Zero(t, result.Scanned)
+
+	result, err = logic.Run(now.Add(2 * time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Disabled)
+
+	stopped, err := svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.False(t, stopped.AutoRenew)
+	require.Equal(t, 2, stopped.AutoRenewFailures)
+	require.NotEmpty(t, stopped.AutoRenewLastError)
+
+	notifications, total, err := svcCtx.Repositories.Notification.ListByUser(ctx, user.ID, repository.ListNotificationsOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, total)
+	require.Equal(t, "自动续费已停止", notifications[0].Title)
+}
@@ -0,0 +1,182 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+// NotificationCategorySubscription 订阅状态变更通知分类。
+const NotificationCategorySubscription = "subscription"
+
+// SubscriptionLifecycleResult 汇总一次生命周期扫描的处理结果。
+type SubscriptionLifecycleResult struct {
+	Expired    int
+	Exhausted  int
+	Suspended  int
+	Restored   int
+	Dispatched int
+	Failed     int
+}
+
+// SubscriptionLifecycleLogic 根据到期时间、流量用量与账号状态流转订阅状态，并投递状态事件。
+type SubscriptionLifecycleLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSubscriptionLifecycleLogic constructs SubscriptionLifecycleLogic.
+func NewSubscriptionLifecycleLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SubscriptionLifecycleLogic {
+	return &SubscriptionLifecycleLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Run 依次处理到期、流量耗尽、账号停用/恢复，最后投递本轮及此前未送达的事件。
+// 单个订阅的状态已被并发修改时跳过，留待下一轮重新判断。
+func (l *SubscriptionLifecycleLogic) Run(now time.Time) (SubscriptionLifecycleResult, error) {
+	var result SubscriptionLifecycleResult
+
+	cfg := l.svcCtx.Config.Jobs.SubscriptionLifecycle
+	cfg.Normalize()
+	repo := l.svcCtx.Repositories.Subscription
+
+	expired, err := repo.ListExpiryDue(l.ctx, now, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	if result.Expired, err = l.transition(expired, func(repository.Subscription) string {
+		return repository.SubscriptionStatusExpired
+	}, repository.SubscriptionReasonExpired); err != nil {
+		return result, err
+	}
+
+	exhausted, err := repo.ListTrafficExhausted(l.ctx, now, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	if result.Exhausted, err = l.transition(exhausted, func(repository.Subscription) string {
+		return repository.SubscriptionStatusExhausted
+	}, repository.SubscriptionReasonTrafficExhausted); err != nil {
+		return result, err
+	}
+
+	disabled, err := repo.ListOwnerDisabled(l.ctx, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	if result.Suspended, err = l.transition(disabled, func(repository.Subscription) string {
+		return repository.SubscriptionStatusSuspended
+	}, repository.SubscriptionReasonOwnerDisabled); err != nil {
+		return result, err
+	}
+
+	restored, err := repo.ListOwnerRestored(l.ctx, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	if result.Restored, err = l.transition(restored, func(sub repository.Subscription) string {
+		return repository.SettledSubscriptionStatus(sub, now)
+	}, repository.SubscriptionReasonOwnerRestored); err != nil {
+		return result, err
+	}
+
+	result.Dispatched, result.Failed, err = l.dispatch(cfg.BatchSize, cfg.MaxEventAttempts)
+	return result, err
+}
+
+func (l *SubscriptionLifecycleLogic) transition(subs []repository.Subscription, target func(repository.Subscription) string, reason string) (int, error) {
+	count := 0
+	for _, sub := range subs {
+		if err := l.ctx.Err(); err != nil {
+			return count, err
+		}
+		to := target(sub)
+		if _, err := l.svcCtx.Repositories.Subscription.TransitionStatus(l.ctx, sub.ID, to, reason); err != nil {
+			if errors.Is(err, repository.ErrInvalidState) {
+				continue
+			}
+			return count, err
+		}
+		count++
+		l.Infof("subscription-lifecycle: subscription=%d %s -> %s reason=%s", sub.ID, sub.Status, to, reason)
+	}
+	return count, nil
+}
+
+// dispatch 投递未送达的事件；订阅者失败时保留事件，达到 maxAttempts 后放弃。
+func (l *SubscriptionLifecycleLogic) dispatch(limit, maxAttempts int) (int, int, error) {
+	pending, err := l.svcCtx.Repositories.Subscription.ListPendingEvents(l.ctx, limit)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	dispatched, failed := 0, 0
+	for _, event := range pending {
+		if err := l.ctx.Err(); err != nil {
+			return dispatched, failed, err
+		}
+
+		message := ""
+		if err := l.svcCtx.Events.DispatchSubscription(l.ctx, event); err != nil {
+			message = err.Error()
+			failed++
+			l.Errorf("subscription-lifecycle: event=%d attempt=%d err=%v", event.ID, event.Attempts+1, err)
+		} else {
+			dispatched++
+		}
+		giveUp := event.Attempts+1 >= maxAttempts
+		if _, err := l.svcCtx.Repositories.Subscription.RecordEventDelivery(l.ctx, event.ID, message, giveUp); err != nil {
+			return dispatched, failed, err
+		}
+	}
+
+	return dispatched, failed, nil
+}
+
+// RegisterSubscriptionNotifications 订阅状态事件，在订阅到期、流量耗尽、被暂停或取消时发送站内通知。
+func RegisterSubscriptionNotifications(svcCtx *svc.ServiceContext) {
+	svcCtx.Events.OnSubscription("notifications", func(ctx context.Context, event repository.SubscriptionEvent) error {
+		var title, format string
+		switch event.ToStatus {
+		case repository.SubscriptionStatusExpired:
+			title, format = "订阅已到期", "订阅「%s」已到期，续费后即可恢复使用。"
+		case repository.SubscriptionStatusExhausted:
+			title, format = "订阅流量已用尽", "订阅「%s」本期流量已用尽，可购买流量包或等待下次重置。"
+		case repository.SubscriptionStatusSuspended:
+			title, format = "订阅已暂停", "订阅「%s」已被暂停，如有疑问请联系管理员。"
+		case repository.SubscriptionStatusCancelled:
+			title, format = "订阅已取消", "订阅「%s」已取消。"
+		default:
+			return nil
+		}
+
+		sub, err := svcCtx.Repositories.Subscription.Get(ctx, event.SubscriptionID)
+		if err != nil {
+			return err
+		}
+		_, err = svcCtx.Repositories.Notification.Create(ctx, repository.Notification{
+			UserID:   event.UserID,
+			Category: NotificationCategorySubscription,
+			Title:    title,
+			Content:  fmt.Sprintf(format, sub.Name),
+			Metadata: map[string]any{
+				"subscription_id": event.SubscriptionID,
+				"event_id":        event.ID,
+				"from_status":     event.FromStatus,
+				"to_status":       event.ToStatus,
+				"reason":          event.Reason,
+			},
+		})
+		return err
+	})
+}
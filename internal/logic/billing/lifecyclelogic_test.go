@@ -0,0 +1,177 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/events"
+	adminsubscriptions "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/subscriptions"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func setupLifecycleTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:lifecycle?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+		Events:       events.NewBus(),
+	}
+	svcCtx.Config.Jobs.SubscriptionLifecycle.MaxEventAttempts = 2
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestSubscriptionLifecycleTransitionsAndEvents(t *testing.T) {
+	svcCtx, cleanup := setupLifecycleTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	owner := repository.User{Email: "owner@test.dev", DisplayName: "Owner", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+	banned := repository.User{Email: "banned@test.dev", DisplayName: "Banned", Roles: []string{"user"}, Status: "disabled", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&banned).Error)
+
+	create := func(userID uint64, name string, expiresAt time.Time, total, used int64) repository.Subscription {
+		sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+			UserID:            userID,
+			PlanID:            1,
+			Name:              name,
+			PlanName:          "Basic",
+			ExpiresAt:         expiresAt,
+			TrafficTotalBytes: total,
+			TrafficUsedBytes:  used,
+		})
+		require.NoError(t, err)
+		require.Equal(t, repository.SubscriptionStatusActive, sub.Status)
+		return sub
+	}
+	expiring := create(owner.ID, "expiring", now.Add(-time.Hour), 100, 0)
+	drained := create(owner.ID, "drained", now.Add(24*time.Hour), 100, 100)
+	healthy := create(owner.ID, "healthy", now.Add(24*time.Hour), 100, 10)
+	suspended := create(banned.ID, "banned", now.Add(24*time.Hour), 100, 10)
+
+	_, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{UserID: owner.ID, Status: "paused"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	var delivered []repository.SubscriptionEvent
+	failing := true
+	svcCtx.Events.OnSubscription("recorder", func(_ context.Context, event repository.SubscriptionEvent) error {
+		if failing {
+			return errors.New("downstream unavailable")
+		}
+		delivered = append(delivered, event)
+		return nil
+	})
+
+	logic := NewSubscriptionLifecycleLogic(ctx, svcCtx)
+	result, err := logic.Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Expired)
+	require.Equal(t, 1, result.Exhausted)
+	require.Equal(t, 1, result.Suspended)
+	require.Zero(t, result.Dispatched)
+	require.Equal(t, 3, result.Failed)
+
+	status := func(id uint64) repository.Subscription {
+		sub, err := svcCtx.Repositories.Subscription.Get(ctx, id)
+		require.NoError(t, err)
+		return sub
+	}
+	require.Equal(t, repository.SubscriptionStatusExpired, status(expiring.ID).Status)
+	require.Equal(t, repository.SubscriptionStatusExhausted, status(drained.ID).Status)
+	require.Equal(t, repository.SubscriptionStatusActive, status(healthy.ID).Status)
+	sub := status(suspended.ID)
+	require.Equal(t, repository.SubscriptionStatusSuspended, sub.Status)
+	require.Equal(t, repository.SubscriptionReasonOwnerDisabled, sub.StatusReason)
+	require.NotNil(t, sub.StatusChangedAt)
+
+	// 失败的事件在下一轮重试，状态不再重复流转。
+	failing = false
+	result, err = logic.Run(now)
+	require.NoError(t, err)
+	require.Zero(t, result.Expired+result.Exhausted+result.Suspended)
+	require.Equal(t, 3, result.Dispatched)
+	require.Len(t, delivered, 3)
+	require.Equal(t, repository.SubscriptionStatusActive, delivered[0].FromStatus)
+	require.Equal(t, repository.SubscriptionStatusExpired, delivered[0].ToStatus)
+
+	pending, err := svcCtx.Repositories.Subscription.ListPendingEvents(ctx, 0)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// 账号恢复后解除因停用导致的暂停。
+	require.NoError(t, svcCtx.DB.Model(&repository.User{}).Where("id = ?", banned.ID).Update("status", "active").Error)
+	result, err = logic.Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Restored)
+	require.Equal(t, repository.SubscriptionStatusActive, status(suspended.ID).Status)
+
+	// 续费使已到期订阅重新生效。
+	renewed, err := svcCtx.Repositories.Subscription.ApplyPlan(ctx, expiring.ID, repository.ApplySubscriptionPlanParams{
+		PlanID:            1,
+		PlanName:          "Basic",
+		ExpiresAt:         now.Add(30 * 24 * time.Hour),
+		TrafficTotalBytes: 100,
+		ResetTraffic:      true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, repository.SubscriptionStatusActive, renewed.Status)
+	require.Equal(t, repository.SubscriptionReasonPlanApplied, renewed.StatusReason)
+
+	// 管理员暂停的订阅不会被自动恢复，取消为终态。
+	statusLogic := adminsubscriptions.NewStatusLogic(ctx, svcCtx)
+	summary, err := statusLogic.Update(&types.AdminUpdateSubscriptionStatusRequest{SubscriptionID: healthy.ID, Status: "suspended"})
+	require.NoError(t, err)
+	require.Equal(t, repository.SubscriptionStatusSuspended, summary.Status)
+	result, err = logic.Run(now)
+	require.NoError(t, err)
+	require.Zero(t, result.Restored)
+
+	summary, err = statusLogic.Update(&types.AdminUpdateSubscriptionStatusRequest{SubscriptionID: healthy.ID, Status: "active"})
+	require.NoError(t, err)
+	require.Equal(t, repository.SubscriptionStatusActive, summary.Status)
+
+	_, err = statusLogic.Update(&types.AdminUpdateSubscriptionStatusRequest{SubscriptionID: healthy.ID, Status: "expired"})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	_, err = statusLogic.Update(&types.AdminUpdateSubscriptionStatusRequest{SubscriptionID: healthy.ID, Status: "cancelled"})
+	require.NoError(t, err)
+	_, err = svcCtx.Repositories.Subscription.TransitionStatus(ctx, healthy.ID, repository.SubscriptionStatusActive, repository.SubscriptionReasonAdmin)
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+	_, err = svcCtx.Repositories.Subscription.ApplyPlan(ctx, healthy.ID, repository.ApplySubscriptionPlanParams{
+		PlanID:    1,
+		ExpiresAt: now.Add(30 * 24 * time.Hour),
+	})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+}
@@ -0,0 +1,336 @@
+package billing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/pkg/payment"
+)
+
+// 对账来源，写入 ReconciliationRun.Source。
+const (
+	ReconcileSourceImport = "import"
+	ReconcileSourceCLI    = "cli"
+)
+
+// ReconcileOptions 控制一次对账。
+type ReconcileOptions struct {
+	// Provider 为网关名称，同时用于筛选本地 OrderPayment.Provider（为空不筛选）。
+	Provider string
+	Source   string
+	// From/To 同时设置时，会检查窗口内本地已成功但结算中缺失的支付。
+	From     time.Time
+	To       time.Time
+	Operator string
+	DryRun   bool
+}
+
+// ReconcileLogic 将网关结算记录与本地支付流水比对。
+type ReconcileLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewReconcileLogic constructs ReconcileLogic.
+func NewReconcileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReconcileLogic {
+	return &ReconcileLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Run 从 provider 读取结算记录后执行对账；目前只有导入文件的 payment.StaticProvider。
+func (l *ReconcileLogic) Run(provider payment.SettlementProvider, opts ReconcileOptions) (repository.ReconciliationRun, []repository.ReconciliationIssue, error) {
+	if provider == nil {
+		return repository.ReconciliationRun{}, nil, repository.ErrInvalidArgument
+	}
+	if strings.TrimSpace(opts.Provider) == "" {
+		opts.Provider = provider.Name()
+	}
+
+	settlements, err := provider.ListSettlements(l.ctx, opts.From, opts.To)
+	if err != nil {
+		return repository.ReconciliationRun{}, nil, err
+	}
+
+	return l.Reconcile(settlements, opts)
+}
+
+// Reconcile 按 IntentID 优先、Reference 次之匹配本地支付，记录金额/币种不一致、
+// 网关成功但本地未收到回调、状态冲突、无法匹配的结算以及重复结算；DryRun 时不落库。
+// 退款类结算匹配到原支付后按订单汇总，与本地退款记录（OrderRefund）合计比对，不占用支付的匹配。
+func (l *ReconcileLogic) Reconcile(settlements []payment.Settlement, opts ReconcileOptions) (repository.ReconciliationRun, []repository.ReconciliationIssue, error) {
+	opts.Provider = strings.TrimSpace(opts.Provider)
+	if opts.Source == "" {
+		opts.Source = ReconcileSourceImport
+	}
+	checkUnsettled := !opts.From.IsZero() && !opts.To.IsZero()
+	if checkUnsettled && !opts.To.After(opts.From) {
+		return repository.ReconciliationRun{}, nil, repository.ErrInvalidArgument
+	}
+
+	intentIDs := make([]string, 0, len(settlements))
+	references := make([]string, 0, len(settlements))
+	for _, s := range settlements {
+		if s.IntentID != "" {
+			intentIDs = append(intentIDs, s.IntentID)
+		}
+		if s.Reference != "" {
+			references = append(references, s.Reference)
+		}
+	}
+
+	payments, err := l.svcCtx.Repositories.Order.ListPaymentsByKeys(l.ctx, intentIDs, references)
+	if err != nil {
+		return repository.ReconciliationRun{}, nil, err
+	}
+
+	byIntent := make(map[string]repository.OrderPayment, len(payments))
+	byReference := make(map[string]repository.OrderPayment, len(payments))
+	for _, p := range payments {
+		if p.IntentID != "" {
+			if _, ok := byIntent[p.IntentID]; !ok {
+				byIntent[p.IntentID] = p
+			}
+		}
+		if p.Reference != "" {
+			if _, ok := byReference[p.Reference]; !ok {
+				byReference[p.Reference] = p
+			}
+		}
+	}
+
+	var issues []repository.ReconciliationIssue
+	seen := make(map[uint64]bool, len(settlements))
+	refunds := make(map[uint64]*refundSettlements)
+	var refundOrderIDs []uint64
+	matched := 0
+
+	for _, s := range settlements {
+		local, found := lookupPayment(byIntent, byReference, s)
+		if !found {
+			issues = append(issues, settlementIssue(repository.ReconciliationIssueOrphanSettlement, s, nil, "no local payment matches intent_id or reference"))
+			continue
+		}
+		if s.Status == payment.SettlementStatusRefunded {
+			group, ok := refunds[local.OrderID]
+			if !ok {
+				group = &refundSettlements{payment: local}
+				refunds[local.OrderID] = group
+				refundOrderIDs = append(refundOrderIDs, local.OrderID)
+			}
+			group.rows = append(group.rows, s)
+			continue
+		}
+		if seen[local.ID] {
+			issues = append(issues, settlementIssue(repository.ReconciliationIssueDuplicateSettlement, s, &local, "payment already matched by another settlement row"))
+			continue
+		}
+		seen[local.ID] = true
+
+		rowIssues := compareSettlement(s, local)
+		if len(rowIssues) == 0 {
+			matched++
+			continue
+		}
+		issues = append(issues, rowIssues...)
+	}
+
+	if len(refundOrderIDs) > 0 {
+		localRefunds, err := l.svcCtx.Repositories.Order.ListRefunds(l.ctx, refundOrderIDs)
+		if err != nil {
+			return repository.ReconciliationRun{}, nil, err
+		}
+		for _, orderID := range refundOrderIDs {
+			group := refunds[orderID]
+			if issue, ok := compareRefunds(group, localRefunds[orderID]); ok {
+				issues = append(issues, issue)
+				continue
+			}
+			matched += len(group.rows)
+		}
+	}
+
+	if checkUnsettled {
+		succeeded, err := l.svcCtx.Repositories.Order.ListPaymentsInRange(l.ctx, repository.ListPaymentsInRangeOptions{
+			Provider: opts.Provider,
+			Method:   repository.PaymentMethodExternal,
+			Status:   repository.OrderPaymentStatusSucceeded,
+			From:     opts.From,
+			To:       opts.To,
+		})
+		if err != nil {
+			return repository.ReconciliationRun{}, nil, err
+		}
+		for _, p := range succeeded {
+			if seen[p.ID] {
+				continue
+			}
+			local := p
+			issues = append(issues, repository.ReconciliationIssue{
+				Kind:          repository.ReconciliationIssueUnsettledPayment,
+				OrderID:       local.OrderID,
+				PaymentID:     local.ID,
+				IntentID:      local.IntentID,
+				Reference:     local.Reference,
+				LocalStatus:   local.Status,
+				LocalCents:    local.AmountCents,
+				LocalCurrency: local.Currency,
+				Detail:        "payment succeeded locally but is absent from the settlement data",
+			})
+		}
+	}
+
+	summary := make(map[string]int)
+	for _, issue := range issues {
+		summary[issue.Kind]++
+	}
+
+	run := repository.ReconciliationRun{
+		Provider:       opts.Provider,
+		Source:         opts.Source,
+		SettlementRows: len(settlements),
+		MatchedCount:   matched,
+		IssueCount:     len(issues),
+		Summary:        summary,
+		CreatedBy:      opts.Operator,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if checkUnsettled {
+		from := opts.From.UTC()
+		to := opts.To.UTC()
+		run.PeriodStart = &from
+		run.PeriodEnd = &to
+	}
+
+	if opts.DryRun {
+		if issues == nil {
+			issues = []repository.ReconciliationIssue{}
+		}
+		return run, issues, nil
+	}
+
+	run, issues, err = l.svcCtx.Repositories.Reconciliation.CreateRun(l.ctx, run, issues)
+	if err != nil {
+		return repository.ReconciliationRun{}, nil, err
+	}
+	if run.IssueCount > 0 {
+		l.Infof("reconcile: run=%d provider=%s rows=%d matched=%d issues=%d", run.ID, run.Provider, run.SettlementRows, run.MatchedCount, run.IssueCount)
+	}
+
+	return run, issues, nil
+}
+
+func lookupPayment(byIntent, byReference map[string]repository.OrderPayment, s payment.Settlement) (repository.OrderPayment, bool) {
+	if s.IntentID != "" {
+		if p, ok := byIntent[s.IntentID]; ok {
+			return p, true
+		}
+	}
+	if s.Reference != "" {
+		if p, ok := byReference[s.Reference]; ok {
+			return p, true
+		}
+	}
+	return repository.OrderPayment{}, false
+}
+
+// refundSettlements 同一订单的退款类结算，payment 为匹配到的原支付。
+type refundSettlements struct {
+	payment repository.OrderPayment
+	rows    []payment.Settlement
+}
+
+// compareRefunds 比对订单的退款结算合计与本地退款合计，一致时返回 false。
+func compareRefunds(group *refundSettlements, local []repository.OrderRefund) (repository.ReconciliationIssue, bool) {
+	var settled, refunded int64
+	currencyMismatch := false
+	for _, row := range group.rows {
+		settled += row.AmountCents
+		if row.Currency != "" && !strings.EqualFold(row.Currency, group.payment.Currency) {
+			currencyMismatch = true
+		}
+	}
+	for _, refund := range local {
+		refunded += refund.AmountCents
+	}
+	if settled == refunded && !currencyMismatch {
+		return repository.ReconciliationIssue{}, false
+	}
+
+	last := group.rows[len(group.rows)-1]
+	kind := repository.ReconciliationIssueAmountMismatch
+	detail := fmt.Sprintf("refunds settled %d %s, refunded locally %d %s", settled, last.Currency, refunded, group.payment.Currency)
+	if len(local) == 0 {
+		kind = repository.ReconciliationIssueStatusMismatch
+		detail = "gateway reports a refund but the order has no local refund"
+	}
+	issue := settlementIssue(kind, last, &group.payment, detail)
+	issue.SettledCents = settled
+	issue.LocalCents = refunded
+	return issue, true
+}
+
+// compareSettlement 比对收款类结算与本地支付的状态和金额。
+func compareSettlement(s payment.Settlement, local repository.OrderPayment) []repository.ReconciliationIssue {
+	var issues []repository.ReconciliationIssue
+
+	localStatus := strings.ToLower(local.Status)
+	switch s.Status {
+	case payment.SettlementStatusSucceeded:
+		if localStatus != repository.OrderPaymentStatusSucceeded {
+			issues = append(issues, settlementIssue(repository.ReconciliationIssueMissingCallback, s, &local,
+				fmt.Sprintf("gateway settled but local payment is %s", localStatus)))
+		}
+	case payment.SettlementStatusFailed:
+		if localStatus == repository.OrderPaymentStatusSucceeded {
+			issues = append(issues, settlementIssue(repository.ReconciliationIssueStatusMismatch, s, &local,
+				"gateway reports failure but local payment succeeded"))
+		}
+	}
+
+	if s.AmountCents != local.AmountCents || (s.Currency != "" && !strings.EqualFold(s.Currency, local.Currency)) {
+		issues = append(issues, settlementIssue(repository.ReconciliationIssueAmountMismatch, s, &local,
+			fmt.Sprintf("settled %d %s, expected %d %s", s.AmountCents, s.Currency, local.AmountCents, local.Currency)))
+	}
+
+	return issues
+}
+
+func settlementIssue(kind string, s payment.Settlement, local *repository.OrderPayment, detail string) repository.ReconciliationIssue {
+	issue := repository.ReconciliationIssue{
+		Kind:             kind,
+		IntentID:         s.IntentID,
+		Reference:        s.Reference,
+		SettlementStatus: s.Status,
+		SettledCents:     s.AmountCents,
+		SettledCurrency:  s.Currency,
+		Detail:           detail,
+	}
+	if !s.SettledAt.IsZero() {
+		settledAt := s.SettledAt.UTC()
+		issue.SettledAt = &settledAt
+	}
+	if local != nil {
+		issue.OrderID = local.OrderID
+		issue.PaymentID = local.ID
+		issue.LocalStatus = local.Status
+		issue.LocalCents = local.AmountCents
+		issue.LocalCurrency = local.Currency
+		if issue.IntentID == "" {
+			issue.IntentID = local.IntentID
+		}
+		if issue.Reference == "" {
+			issue.Reference = local.Reference
+		}
+	}
+	return issue
+}
@@ -0,0 +1,212 @@
+package billing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/pkg/payment"
+)
+
+func TestReconcileFlagsDrift(t *testing.T) {
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:reconcile?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	ctx := context.Background()
+	_, err = migrations.Apply(ctx, db, 0, false)
+	require.NoError(t, err)
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+	svcCtx := &svc.ServiceContext{DB: db, Repositories: repos}
+
+	now := time.Now().UTC()
+	order := repository.Order{
+		Number:        repository.GenerateOrderNumber(),
+		UserID:        1,
+		Status:        repository.OrderStatusPaid,
+		PaymentMethod: repository.PaymentMethodExternal,
+		TotalCents:    1000,
+		Currency:      "CNY",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, db.Create(&order).Error)
+
+	newPayment := func(intent, reference, status string, amount int64) repository.OrderPayment {
+		p, err := repos.Order.CreatePayment(ctx, repository.OrderPayment{
+			OrderID:     order.ID,
+			Provider:    "stripe",
+			Method:      repository.PaymentMethodExternal,
+			IntentID:    intent,
+			Reference:   reference,
+			Status:      status,
+			AmountCents: amount,
+			Currency:    "CNY",
+			CreatedAt:   now.Add(-time.Hour),
+		})
+		require.NoError(t, err)
+		return p
+	}
+	newPayment("pi_ok", "", repository.OrderPaymentStatusSucceeded, 1000)
+	pending := newPayment("pi_pending", "", repository.OrderPaymentStatusPending, 500)
+	short := newPayment("", "ref_short", repository.OrderPaymentStatusSucceeded, 800)
+	unsettled := newPayment("pi_unsettled", "", repository.OrderPaymentStatusSucceeded, 300)
+
+	settlements, err := payment.ParseSettlementsCSV([]byte(`intent_id,reference,amount,currency,status
+pi_ok,,10.00,CNY,paid
+pi_pending,,5.00,CNY,succeeded
+,ref_short,7.00,CNY,settled
+pi_unknown,,1.00,CNY,paid
+pi_ok,,10.00,CNY,paid
+`))
+	require.NoError(t, err)
+
+	logic := NewReconcileLogic(ctx, svcCtx)
+	run, issues, err := logic.Run(payment.NewStaticProvider("stripe", settlements), ReconcileOptions{
+		From: now.Add(-24 * time.Hour),
+		To:   now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NotZero(t, run.ID)
+	require.Equal(t, "stripe", run.Provider)
+	require.Equal(t, 5, run.SettlementRows)
+	require.Equal(t, 1, run.MatchedCount)
+	require.Equal(t, map[string]int{
+		repository.ReconciliationIssueMissingCallback:     1,
+		repository.ReconciliationIssueAmountMismatch:      1,
+		repository.ReconciliationIssueOrphanSettlement:    1,
+		repository.ReconciliationIssueDuplicateSettlement: 1,
+		repository.ReconciliationIssueUnsettledPayment:    1,
+	}, run.Summary)
+
+	byKind := make(map[string]repository.ReconciliationIssue, len(issues))
+	for _, issue := range issues {
+		byKind[issue.Kind] = issue
+	}
+	require.Equal(t, pending.ID, byKind[repository.ReconciliationIssueMissingCallback].PaymentID)
+	require.Equal(t, short.ID, byKind[repository.ReconciliationIssueAmountMismatch].PaymentID)
+	require.Equal(t, int64(700), byKind[repository.ReconciliationIssueAmountMismatch].SettledCents)
+	require.Equal(t, "pi_unknown", byKind[repository.ReconciliationIssueOrphanSettlement].IntentID)
+	require.Equal(t, unsettled.ID, byKind[repository.ReconciliationIssueUnsettledPayment].PaymentID)
+
+	stored, storedIssues, err := repos.Reconciliation.GetRun(ctx, run.ID)
+	require.NoError(t, err)
+	require.Equal(t, 5, stored.IssueCount)
+	require.Len(t, storedIssues, 5)
+
+	dryRun, _, err := logic.Reconcile(settlements[:1], ReconcileOptions{Provider: "stripe", DryRun: true})
+	require.NoError(t, err)
+	require.Zero(t, dryRun.ID)
+	require.Equal(t, 1, dryRun.MatchedCount)
+	_, total, err := repos.Reconciliation.ListRuns(ctx, repository.ListReconciliationRunsOptions{})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+}
+
+func TestReconcileComparesRefundsAgainstOrderRefunds(t *testing.T) {
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:reconcile-refund?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	ctx := context.Background()
+	_, err = migrations.Apply(ctx, db, 0, false)
+	require.NoError(t, err)
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+	svcCtx := &svc.ServiceContext{DB: db, Repositories: repos}
+
+	now := time.Now().UTC()
+	newPaidOrder := func(intent string) repository.Order {
+		order := repository.Order{
+			Number:        repository.GenerateOrderNumber(),
+			UserID:        1,
+			Status:        repository.OrderStatusPaid,
+			PaymentMethod: repository.PaymentMethodExternal,
+			TotalCents:    1000,
+			Currency:      "CNY",
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		require.NoError(t, db.Create(&order).Error)
+		_, err := repos.Order.CreatePayment(ctx, repository.OrderPayment{
+			OrderID:     order.ID,
+			Provider:    "stripe",
+			Method:      repository.PaymentMethodExternal,
+			IntentID:    intent,
+			Status:      repository.OrderPaymentStatusSucceeded,
+			AmountCents: 1000,
+			Currency:    "CNY",
+			CreatedAt:   now,
+		})
+		require.NoError(t, err)
+		return order
+	}
+
+	partial := newPaidOrder("pi_partial")
+	_, err = repos.Order.CreateRefund(ctx, repository.OrderRefund{OrderID: partial.ID, AmountCents: 300, Reference: "r1"})
+	require.NoError(t, err)
+	_, err = repos.Order.CreateRefund(ctx, repository.OrderRefund{OrderID: partial.ID, AmountCents: 100, Reference: "r2"})
+	require.NoError(t, err)
+	unrecorded := newPaidOrder("pi_unrecorded")
+
+	// 原支付与两笔部分退款共用同一 intent，合计等于本地退款时全部视为匹配。
+	settlements, err := payment.ParseSettlementsCSV([]byte(`intent_id,amount,currency,status
+pi_partial,10.00,CNY,paid
+pi_partial,3.00,CNY,refunded
+pi_partial,1.00,CNY,refunded
+`))
+	require.NoError(t, err)
+
+	logic := NewReconcileLogic(ctx, svcCtx)
+	run, issues, err := logic.Reconcile(settlements, ReconcileOptions{Provider: "stripe", DryRun: true})
+	require.NoError(t, err)
+	require.Empty(t, issues)
+	require.Equal(t, 3, run.MatchedCount)
+
+	settlements, err = payment.ParseSettlementsCSV([]byte(`intent_id,amount,currency,status
+pi_partial,10.00,CNY,paid
+pi_partial,3.00,CNY,refunded
+pi_unrecorded,10.00,CNY,paid
+pi_unrecorded,10.00,CNY,refunded
+`))
+	require.NoError(t, err)
+
+	run, issues, err = logic.Reconcile(settlements, ReconcileOptions{Provider: "stripe", DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, run.MatchedCount)
+	require.Equal(t, map[string]int{
+		repository.ReconciliationIssueAmountMismatch: 1,
+		repository.ReconciliationIssueStatusMismatch: 1,
+	}, run.Summary)
+
+	byKind := make(map[string]repository.ReconciliationIssue, len(issues))
+	for _, issue := range issues {
+		byKind[issue.Kind] = issue
+	}
+	mismatch := byKind[repository.ReconciliationIssueAmountMismatch]
+	require.Equal(t, partial.ID, mismatch.OrderID)
+	require.Equal(t, int64(300), mismatch.SettledCents)
+	require.Equal(t, int64(400), mismatch.LocalCents)
+	require.Equal(t, unrecorded.ID, byKind[repository.ReconciliationIssueStatusMismatch].OrderID)
+}
@@ -0,0 +1,70 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+// TrafficResetResult 汇总一次流量重置扫描的处理结果。
+type TrafficResetResult struct {
+	Scanned int
+	Reset   int
+	Skipped int
+}
+
+// TrafficResetLogic 按订阅的重置策略在周期边界清零已用流量，并归档上一周期用量。
+type TrafficResetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTrafficResetLogic constructs TrafficResetLogic.
+func NewTrafficResetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TrafficResetLogic {
+	return &TrafficResetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Run 处理 now 之前到达重置时间且未过期的订阅。已被其他实例处理的订阅计入 Skipped。
+func (l *TrafficResetLogic) Run(now time.Time) (TrafficResetResult, error) {
+	var result TrafficResetResult
+
+	cfg := l.svcCtx.Config.Jobs.TrafficReset
+	cfg.Normalize()
+
+	subs, err := l.svcCtx.Repositories.Subscription.ListTrafficResetDue(l.ctx, now, cfg.BatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, sub := range subs {
+		if err := l.ctx.Err(); err != nil {
+			return result, err
+		}
+		result.Scanned++
+
+		updated, period, err := l.svcCtx.Repositories.Subscription.ResetTrafficPeriod(l.ctx, sub.ID, now)
+		if errors.Is(err, repository.ErrInvalidState) || errors.Is(err, repository.ErrConflict) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		result.Reset++
+		l.Infof("traffic-reset: subscription=%d policy=%s used=%d total=%d next=%v",
+			updated.ID, updated.TrafficResetPolicy, period.UsedBytes, period.TotalBytes, updated.TrafficNextResetAt)
+	}
+
+	return result, nil
+}
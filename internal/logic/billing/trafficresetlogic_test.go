@@ -0,0 +1,193 @@
+package billing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	userorder "github.com/zero-net-panel/zero-net-panel/internal/logic/user/order"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func setupTrafficResetTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:trafficreset?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestTrafficResetArchivesPeriodAndDropsAddon(t *testing.T) {
+	svcCtx, cleanup := setupTrafficResetTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	const gib = int64(1 << 30)
+
+	user := repository.User{
+		Email:       "quota@test.dev",
+		DisplayName: "Quota",
+		Roles:       []string{"user"},
+		Status:      "active",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&user).Error)
+
+	plan, err := svcCtx.Repositories.Plan.Create(ctx, repository.Plan{
+		Name:                   "Yearly",
+		Slug:                   "yearly",
+		PriceCents:             10000,
+		Currency:               "CNY",
+		DurationDays:           365,
+		TrafficLimitBytes:      10 * gib,
+		DevicesLimit:           3,
+		TrafficResetPolicy:     repository.TrafficResetMonthly,
+		TrafficAddonBytes:      5 * gib,
+		TrafficAddonPriceCents: 500,
+		Status:                 "active",
+		Visible:                true,
+	})
+	require.NoError(t, err)
+
+	_, _, err = svcCtx.Repositories.Balance.ApplyTransaction(ctx, user.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 20000,
+		Currency:    "CNY",
+		Reference:   "seed",
+	})
+	require.NoError(t, err)
+
+	userCtx := security.WithUser(ctx, security.UserClaims{ID: user.ID, Email: user.Email, Roles: user.Roles})
+	created, err := userorder.NewCreateLogic(userCtx, svcCtx).Create(&types.UserCreateOrderRequest{
+		PlanID:   plan.ID,
+		Quantity: 1,
+	})
+	require.NoError(t, err)
+	subscriptionID := created.Order.Metadata["subscription_id"].(uint64)
+
+	sub, err := svcCtx.Repositories.Subscription.Get(ctx, subscriptionID)
+	require.NoError(t, err)
+	require.Equal(t, repository.TrafficResetMonthly, sub.TrafficResetPolicy)
+	require.NotNil(t, sub.TrafficNextResetAt)
+	firstReset := *repository.NextTrafficResetAt(repository.TrafficResetMonthly, 0, now)
+	require.True(t, sub.TrafficNextResetAt.Equal(firstReset))
+	require.Equal(t, 1, sub.TrafficNextResetAt.Day())
+
+	require.NoError(t, svcCtx.DB.Model(&repository.Subscription{}).
+		Where("id = ?", sub.ID).
+		Update("traffic_used_bytes", 8*gib).Error)
+
+	// 加购两份流量包，只计入当期。
+	quote, err := userorder.NewQuoteLogic(userCtx, svcCtx).Quote(&types.UserOrderQuoteRequest{
+		OrderKind:      orderutil.OrderKindTrafficAddon,
+		Quantity:       2,
+		SubscriptionID: sub.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), quote.TotalCents)
+	require.Equal(t, 10*gib, quote.TrafficBytes)
+
+	addon, err := userorder.NewCreateLogic(userCtx, svcCtx).Create(&types.UserCreateOrderRequest{
+		OrderKind:      orderutil.OrderKindTrafficAddon,
+		Quantity:       2,
+		SubscriptionID: sub.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, repository.OrderStatusPaid, addon.Order.Status)
+	require.Equal(t, int64(9000), addon.Balance.BalanceCents)
+	require.Equal(t, orderutil.OrderItemTypeTrafficAddon, addon.Order.Items[0].ItemType)
+
+	sub, err = svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, 20*gib, sub.TrafficTotalBytes)
+	require.Equal(t, 10*gib, sub.TrafficExtraBytes)
+	expiresAt := sub.ExpiresAt
+
+	// 未到重置时间不处理；未知订单类型被拒绝。
+	logic := NewTrafficResetLogic(ctx, svcCtx)
+	result, err := logic.Run(now)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Scanned)
+
+	_, err = userorder.NewCreateLogic(userCtx, svcCtx).Create(&types.UserCreateOrderRequest{
+		OrderKind: "gift",
+		PlanID:    plan.ID,
+	})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	resetAt := firstReset.Add(time.Hour)
+	result, err = logic.Run(resetAt)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Reset)
+
+	sub, err = svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Zero(t, sub.TrafficUsedBytes)
+	require.Zero(t, sub.TrafficExtraBytes)
+	require.Equal(t, 10*gib, sub.TrafficTotalBytes)
+	require.True(t, sub.TrafficPeriodStartAt.Equal(firstReset))
+	require.True(t, sub.TrafficNextResetAt.Equal(firstReset.AddDate(0, 1, 0)))
+	require.True(t, sub.ExpiresAt.Equal(expiresAt))
+
+	periods, err := svcCtx.Repositories.Subscription.ListTrafficPeriods(ctx, sub.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, periods, 1)
+	require.Equal(t, 8*gib, periods[0].UsedBytes)
+	require.Equal(t, 20*gib, periods[0].TotalBytes)
+	require.Equal(t, 10*gib, periods[0].ExtraBytes)
+	require.True(t, periods[0].PeriodEnd.Equal(firstReset))
+
+	// 同一周期不会重复重置。
+	result, err = logic.Run(resetAt)
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Scanned)
+}
+
+func TestNextTrafficResetAtClampsPurchaseDay(t *testing.T) {
+	after := time.Date(2026, time.January, 31, 12, 0, 0, 0, time.UTC)
+
+	next := repository.NextTrafficResetAt(repository.TrafficResetPurchaseDay, 31, after)
+	require.NotNil(t, next)
+	require.Equal(t, time.Date(2026, time.February, 28, 0, 0, 0, 0, time.UTC), *next)
+
+	next = repository.NextTrafficResetAt(repository.TrafficResetPurchaseDay, 31, *next)
+	require.Equal(t, time.Date(2026, time.March, 31, 0, 0, 0, 0, time.UTC), *next)
+
+	next = repository.NextTrafficResetAt(repository.TrafficResetMonthly, 0, time.Date(2026, time.December, 1, 0, 0, 0, 0, time.UTC))
+	require.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), *next)
+
+	require.Nil(t, repository.NextTrafficResetAt(repository.TrafficResetNever, 0, after))
+}
@@ -0,0 +1,150 @@
+package deviceutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+)
+
+const lockTTL = 5 * time.Second
+
+// Device 表示订阅在滑动窗口内出现过的一个客户端 IP，同一 IP 出现在多个节点只计一次。
+type Device struct {
+	IP         string   `json:"ip"`
+	NodeIDs    []uint64 `json:"node_ids"`
+	LastSeenAt int64    `json:"last_seen_at"`
+}
+
+// Tracker 基于 cache.Cache 聚合节点上报的在线 IP。每个订阅一个键，写入时加锁合并，
+// 键的过期时间与窗口一致，订阅停止上报后自动清空。
+type Tracker struct {
+	cache  cache.Cache
+	window time.Duration
+}
+
+// NewTracker 构造函数，window 未设置时默认 3 分钟。
+func NewTracker(c cache.Cache, window time.Duration) *Tracker {
+	if window <= 0 {
+		window = 3 * time.Minute
+	}
+	return &Tracker{cache: c, window: window}
+}
+
+// Report 合并一个节点对某订阅的上报，返回合并后仍在窗口内的设备。
+func (t *Tracker) Report(ctx context.Context, nodeID, subscriptionID uint64, ips []string, now time.Time) ([]Device, error) {
+	key := onlineKey(subscriptionID)
+	lock, err := t.cache.AcquireLock(ctx, key+":lock", lockTTL)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = lock.Release(context.Background()) }()
+
+	devices, err := t.load(ctx, key, now)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int, len(devices))
+	for i, device := range devices {
+		index[device.IP] = i
+	}
+	for _, raw := range ips {
+		ip := normalizeIP(raw)
+		if ip == "" {
+			continue
+		}
+		i, ok := index[ip]
+		if !ok {
+			devices = append(devices, Device{IP: ip})
+			i = len(devices) - 1
+			index[ip] = i
+		}
+		devices[i].LastSeenAt = now.UTC().Unix()
+		devices[i].NodeIDs = appendNodeID(devices[i].NodeIDs, nodeID)
+	}
+
+	sortDevices(devices)
+	if len(devices) == 0 {
+		return devices, t.cache.Del(ctx, key)
+	}
+	if err := t.cache.Set(ctx, key, devices, t.window); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// Online 返回订阅当前在线的设备，按最近出现时间倒序。
+func (t *Tracker) Online(ctx context.Context, subscriptionID uint64, now time.Time) ([]Device, error) {
+	devices, err := t.load(ctx, onlineKey(subscriptionID), now)
+	if err != nil {
+		return nil, err
+	}
+	sortDevices(devices)
+	return devices, nil
+}
+
+// OverLimit 判断在线设备数是否超过限制，limit 不大于 0 表示不限。
+func OverLimit(devices []Device, limit int) bool {
+	return limit > 0 && len(devices) > limit
+}
+
+func (t *Tracker) load(ctx context.Context, key string, now time.Time) ([]Device, error) {
+	var stored []Device
+	if err := t.cache.Get(ctx, key, &stored); err != nil {
+		if errors.Is(err, cache.ErrNotFound) {
+			return []Device{}, nil
+		}
+		return nil, err
+	}
+
+	cutoff := now.UTC().Add(-t.window).Unix()
+	devices := make([]Device, 0, len(stored))
+	for _, device := range stored {
+		if device.LastSeenAt > cutoff {
+			devices = append(devices, device)
+		}
+	}
+	return devices, nil
+}
+
+func onlineKey(subscriptionID uint64) string {
+	return fmt.Sprintf("znp:online:subscription:%d", subscriptionID)
+}
+
+func normalizeIP(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if host, _, err := net.SplitHostPort(raw); err == nil {
+		raw = host
+	}
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+func appendNodeID(ids []uint64, nodeID uint64) []uint64 {
+	for _, id := range ids {
+		if id == nodeID {
+			return ids
+		}
+	}
+	ids = append(ids, nodeID)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func sortDevices(devices []Device) {
+	sort.SliceStable(devices, func(i, j int) bool {
+		if devices[i].LastSeenAt != devices[j].LastSeenAt {
+			return devices[i].LastSeenAt > devices[j].LastSeenAt
+		}
+		return devices[i].IP < devices[j].IP
+	})
+}
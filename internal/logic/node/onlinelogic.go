@@ -0,0 +1,83 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/deviceutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// OnlineLogic 接收节点上报的在线 IP。
+type OnlineLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewOnlineLogic 构造函数。
+func NewOnlineLogic(ctx context.Context, svcCtx *svc.ServiceContext) *OnlineLogic {
+	return &OnlineLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Report 合并各订阅的在线 IP，返回合并后超过设备限制的订阅。
+// 只接受出现在该节点用户列表中的订阅（可服务且套餐授权包含该节点），其余订阅被忽略，
+// 避免节点凭据泄露后被用来替其他节点的用户上报在线设备。
+func (l *OnlineLogic) Report(req *types.NodeOnlineReportRequest) (*types.NodeOnlineReportResponse, error) {
+	if _, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	tracker := deviceutil.NewTracker(l.svcCtx.Cache, l.svcCtx.Config.Node.OnlineWindow)
+	resp := &types.NodeOnlineReportResponse{
+		NodeID:     req.NodeID,
+		OverLimit:  []uint64{},
+		ReportedAt: now.Unix(),
+	}
+	entitlements := make(map[uint64]repository.NodeEntitlement)
+	for _, user := range req.Users {
+		sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, user.SubscriptionID)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if !repository.SubscriptionServable(sub, now) {
+			continue
+		}
+		entitlement, ok := entitlements[sub.PlanID]
+		if !ok {
+			entitlement, err = l.svcCtx.Repositories.Node.ResolveEntitlement(l.ctx, sub.PlanID)
+			if err != nil {
+				return nil, err
+			}
+			entitlements[sub.PlanID] = entitlement
+		}
+		if !entitlement.Allows(req.NodeID) {
+			continue
+		}
+
+		devices, err := tracker.Report(l.ctx, req.NodeID, sub.ID, user.IPs, now)
+		if err != nil {
+			return nil, err
+		}
+		resp.Accepted++
+		if deviceutil.OverLimit(devices, sub.DevicesLimit) {
+			resp.OverLimit = append(resp.OverLimit, sub.ID)
+			l.Infof("node-online: subscription=%d devices=%d limit=%d", sub.ID, len(devices), sub.DevicesLimit)
+		}
+	}
+
+	return resp, nil
+}
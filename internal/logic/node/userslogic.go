@@ -0,0 +1,114 @@
+package node
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/deviceutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// 单页用户数的默认值与上限。
+const (
+	defaultNodeUsersLimit = 500
+	maxNodeUsersLimit     = 2000
+)
+
+// UsersLogic 生成节点侧用户列表。
+type UsersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUsersLogic 构造函数。
+func NewUsersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UsersLogic {
+	return &UsersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 按订阅 ID 分页返回当前可服务且套餐授权包含该节点的订阅。在线设备超过限制的订阅在 deny 模式下被剔除，flag 模式下保留并标记。
+// 分页按扫描到的订阅推进，某页经授权过滤后可能为空，节点应以 has_more 判断是否继续拉取。
+func (l *UsersLogic) List(req *types.NodeUsersRequest) (*types.NodeUsersResponse, error) {
+	if _, err := l.svcCtx.Repositories.Node.Get(l.ctx, req.NodeID); err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultNodeUsersLimit
+	}
+	if limit > maxNodeUsersLimit {
+		limit = maxNodeUsersLimit
+	}
+
+	now := time.Now().UTC()
+	subs, err := l.svcCtx.Repositories.Subscription.ListServable(l.ctx, now, req.AfterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(subs) > limit
+	if hasMore {
+		subs = subs[:limit]
+	}
+
+	cfg := l.svcCtx.Config.Node
+	tracker := deviceutil.NewTracker(l.svcCtx.Cache, cfg.OnlineWindow)
+	resp := &types.NodeUsersResponse{
+		NodeID:      req.NodeID,
+		Users:       make([]types.NodeUser, 0, len(subs)),
+		Excluded:    []uint64{},
+		NextAfterID: req.AfterID,
+		HasMore:     hasMore,
+		GeneratedAt: now.Unix(),
+	}
+	if len(subs) > 0 {
+		resp.NextAfterID = subs[len(subs)-1].ID
+	}
+	entitlements := make(map[uint64]repository.NodeEntitlement)
+	for _, sub := range subs {
+		entitlement, ok := entitlements[sub.PlanID]
+		if !ok {
+			entitlement, err = l.svcCtx.Repositories.Node.ResolveEntitlement(l.ctx, sub.PlanID)
+			if err != nil {
+				return nil, err
+			}
+			entitlements[sub.PlanID] = entitlement
+		}
+		if !entitlement.Allows(req.NodeID) {
+			continue
+		}
+
+		devices, err := tracker.Online(l.ctx, sub.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		overLimit := deviceutil.OverLimit(devices, sub.DevicesLimit)
+		if overLimit && cfg.DeviceLimitAction != config.DeviceLimitActionFlag {
+			resp.Excluded = append(resp.Excluded, sub.ID)
+			continue
+		}
+		resp.Users = append(resp.Users, types.NodeUser{
+			SubscriptionID:    sub.ID,
+			UserID:            sub.UserID,
+			Token:             sub.Token,
+			ProxyUUID:         sub.ProxyUUID,
+			ExpiresAt:         sub.ExpiresAt.Unix(),
+			TrafficTotalBytes: sub.TrafficTotalBytes,
+			TrafficUsedBytes:  sub.TrafficUsedBytes,
+			DevicesLimit:      sub.DevicesLimit,
+			OnlineDevices:     len(devices),
+			OverLimit:         overLimit,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,272 @@
+package node
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	adminnodegroups "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/nodegroups"
+	adminplans "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/plans"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/deviceutil"
+	usersub "github.com/zero-net-panel/zero-net-panel/internal/logic/user/subscription"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+)
+
+func setupNodeTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:nodeusers?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	cacheProvider, err := cache.New(cache.Config{})
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Cache:        cacheProvider,
+		Repositories: repos,
+	}
+	svcCtx.Config.Node.Normalize()
+
+	cleanup := func() {
+		_ = cacheProvider.Close()
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestNodeDeviceLimitEnforcement(t *testing.T) {
+	svcCtx, cleanup := setupNodeTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	edge := repository.Node{Name: "edge-1", Status: "online", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&edge).Error)
+	backup := repository.Node{Name: "edge-2", Status: "online", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&backup).Error)
+
+	owner := repository.User{Email: "devices@test.dev", DisplayName: "Devices", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+
+	create := func(name string, devices int, expiresAt time.Time) repository.Subscription {
+		sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+			UserID:       owner.ID,
+			Name:         name,
+			Token:        "token-" + name,
+			ExpiresAt:    expiresAt,
+			DevicesLimit: devices,
+		})
+		require.NoError(t, err)
+		return sub
+	}
+	limited := create("limited", 2, now.Add(24*time.Hour))
+	unlimited := create("unlimited", 0, now.Add(24*time.Hour))
+	_ = create("expired", 1, now.Add(-time.Hour))
+
+	online := NewOnlineLogic(ctx, svcCtx)
+	resp, err := online.Report(&types.NodeOnlineReportRequest{
+		NodeID: edge.ID,
+		Users:  []types.NodeOnlineUser{{SubscriptionID: limited.ID, IPs: []string{"203.0.113.1", "203.0.113.2"}}},
+	})
+	require.NoError(t, err)
+	require.Empty(t, resp.OverLimit)
+
+	// 同一 IP 在不同节点只计一次，未知订阅与非法 IP 被忽略。
+	resp, err = online.Report(&types.NodeOnlineReportRequest{
+		NodeID: backup.ID,
+		Users: []types.NodeOnlineUser{
+			{SubscriptionID: limited.ID, IPs: []string{"203.0.113.2:51820", "203.0.113.3", "not-an-ip"}},
+			{SubscriptionID: unlimited.ID, IPs: []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"}},
+			{SubscriptionID: 9999, IPs: []string{"192.0.2.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, resp.Accepted)
+	require.Equal(t, []uint64{limited.ID}, resp.OverLimit)
+
+	_, err = online.Report(&types.NodeOnlineReportRequest{NodeID: 404})
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	users, err := NewUsersLogic(ctx, svcCtx).List(&types.NodeUsersRequest{NodeID: edge.ID})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{limited.ID}, users.Excluded)
+	require.Len(t, users.Users, 1)
+	require.Equal(t, unlimited.ID, users.Users[0].SubscriptionID)
+	require.Equal(t, "token-unlimited", users.Users[0].Token)
+	require.NotEmpty(t, users.Users[0].ProxyUUID)
+	require.Equal(t, unlimited.ProxyUUID, users.Users[0].ProxyUUID)
+	require.Equal(t, 3, users.Users[0].OnlineDevices)
+
+	svcCtx.Config.Node.DeviceLimitAction = config.DeviceLimitActionFlag
+	users, err = NewUsersLogic(ctx, svcCtx).List(&types.NodeUsersRequest{NodeID: edge.ID})
+	require.NoError(t, err)
+	require.Empty(t, users.Excluded)
+	require.Len(t, users.Users, 2)
+	require.Equal(t, limited.ID, users.Users[0].SubscriptionID)
+	require.True(t, users.Users[0].OverLimit)
+	require.False(t, users.HasMore)
+
+	// 分页拉取：按订阅 ID 推进，直到 has_more 为 false。
+	page, err := NewUsersLogic(ctx, svcCtx).List(&types.NodeUsersRequest{NodeID: edge.ID, Limit: 1})
+	require.NoError(t, err)
+	require.True(t, page.HasMore)
+	require.Len(t, page.Users, 1)
+	require.Equal(t, limited.ID, page.NextAfterID)
+	page, err = NewUsersLogic(ctx, svcCtx).List(&types.NodeUsersRequest{NodeID: edge.ID, AfterID: page.NextAfterID, Limit: 1})
+	require.NoError(t, err)
+	require.False(t, page.HasMore)
+	require.Equal(t, unlimited.ID, page.Users[0].SubscriptionID)
+
+	userCtx := security.WithUser(ctx, security.UserClaims{ID: owner.ID, Email: owner.Email, Roles: owner.Roles})
+	devices, err := usersub.NewDevicesLogic(userCtx, svcCtx).List(&types.UserSubscriptionDevicesRequest{SubscriptionID: limited.ID})
+	require.NoError(t, err)
+	require.Equal(t, 3, devices.OnlineCount)
+	require.True(t, devices.OverLimit)
+	for _, device := range devices.Devices {
+		if device.IP == "203.0.113.2" {
+			require.Equal(t, []uint64{edge.ID, backup.ID}, device.NodeIDs)
+		}
+	}
+
+	strangerCtx := security.WithUser(ctx, security.UserClaims{ID: owner.ID + 1, Roles: []string{"user"}})
+	_, err = usersub.NewDevicesLogic(strangerCtx, svcCtx).List(&types.UserSubscriptionDevicesRequest{SubscriptionID: limited.ID})
+	require.ErrorIs(t, err, repository.ErrForbidden)
+
+	// 超出窗口未再上报的 IP 视为离线。
+	tracker := deviceutil.NewTracker(svcCtx.Cache, svcCtx.Config.Node.OnlineWindow)
+	later, err := tracker.Online(ctx, limited.ID, now.Add(svcCtx.Config.Node.OnlineWindow+time.Minute))
+	require.NoError(t, err)
+	require.Empty(t, later)
+}
+
+func TestNodeUsersScopedByPlanGroups(t *testing.T) {
+	svcCtx, cleanup := setupNodeTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	createNode := func(name string, tags ...string) repository.Node {
+		node := repository.Node{Name: name, Status: repository.NodeStatusOnline, Tags: tags, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, svcCtx.DB.Create(&node).Error)
+		return node
+	}
+	hk := createNode("hk", "asia")
+	jp := createNode("jp", "asia", "streaming")
+	us := createNode("us", "streaming")
+	de := createNode("de")
+
+	groups := adminnodegroups.NewNodeGroupsLogic(ctx, svcCtx)
+	asia, err := groups.Create(&types.AdminCreateNodeGroupRequest{Name: "HK/JP", NodeIDs: []uint64{hk.ID, jp.ID}})
+	require.NoError(t, err)
+	streaming, err := groups.Create(&types.AdminCreateNodeGroupRequest{Name: "Streaming", MatchTags: []string{"Streaming"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"streaming"}, streaming.MatchTags)
+	require.Equal(t, []uint64{jp.ID, us.ID}, streaming.ResolvedNodeIDs)
+	_, err = groups.Create(&types.AdminCreateNodeGroupRequest{Name: "HK/JP"})
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	premium, err := svcCtx.Repositories.Plan.Create(ctx, repository.Plan{Name: "Premium", Slug: "premium", Status: "active"})
+	require.NoError(t, err)
+	basic, err := svcCtx.Repositories.Plan.Create(ctx, repository.Plan{Name: "Basic", Slug: "basic", Status: "active"})
+	require.NoError(t, err)
+	summary, err := adminplans.NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdatePlanRequest{
+		PlanID:       premium.ID,
+		NodeGroupIDs: []uint64{asia.ID, streaming.ID},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uint64{asia.ID, streaming.ID}, summary.NodeGroupIDs)
+	_, err = adminplans.NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdatePlanRequest{
+		PlanID:  basic.ID,
+		NodeIDs: []uint64{de.ID},
+	})
+	require.NoError(t, err)
+
+	owner := repository.User{Email: "groups@test.dev", DisplayName: "Groups", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+	newSubscription := func(planID uint64) repository.Subscription {
+		token, err := repository.GenerateSubscriptionToken()
+		require.NoError(t, err)
+		sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+			UserID:    owner.ID,
+			PlanID:    planID,
+			Token:     token,
+			ExpiresAt: now.Add(24 * time.Hour),
+		})
+		require.NoError(t, err)
+		return sub
+	}
+	premiumSub := newSubscription(premium.ID)
+	basicSub := newSubscription(basic.ID)
+	openSub := newSubscription(0)
+
+	usersOf := func(nodeID uint64) []uint64 {
+		resp, err := NewUsersLogic(ctx, svcCtx).List(&types.NodeUsersRequest{NodeID: nodeID})
+		require.NoError(t, err)
+		ids := make([]uint64, 0, len(resp.Users))
+		for _, user := range resp.Users {
+			ids = append(ids, user.SubscriptionID)
+		}
+		return ids
+	}
+	require.Equal(t, []uint64{premiumSub.ID, openSub.ID}, usersOf(hk.ID))
+	require.Equal(t, []uint64{premiumSub.ID, openSub.ID}, usersOf(us.ID))
+	require.Equal(t, []uint64{basicSub.ID, openSub.ID}, usersOf(de.ID))
+
+	// 节点只能为其用户列表中的订阅上报在线设备，未授权该节点的订阅被忽略。
+	report, err := NewOnlineLogic(ctx, svcCtx).Report(&types.NodeOnlineReportRequest{
+		NodeID: hk.ID,
+		Users: []types.NodeOnlineUser{
+			{SubscriptionID: basicSub.ID, IPs: []string{"203.0.113.1", "203.0.113.2"}},
+			{SubscriptionID: premiumSub.ID, IPs: []string{"203.0.113.3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, report.Accepted)
+	devices, err := deviceutil.NewTracker(svcCtx.Cache, svcCtx.Config.Node.OnlineWindow).Online(ctx, basicSub.ID, time.Now().UTC())
+	require.NoError(t, err)
+	require.Empty(t, devices)
+
+	// 给节点打上标签后自动加入动态分组。
+	require.NoError(t, svcCtx.DB.Model(&de).Select("Tags").
+		Updates(repository.Node{Tags: []string{"streaming"}}).Error)
+	require.Equal(t, []uint64{premiumSub.ID, basicSub.ID, openSub.ID}, usersOf(de.ID))
+
+	// 被套餐引用的分组不可删除。
+	_, err = groups.Delete(&types.AdminDeleteNodeGroupRequest{GroupID: asia.ID})
+	require.ErrorIs(t, err, repository.ErrConflict)
+	_, err = adminplans.NewUpdateLogic(ctx, svcCtx).Update(&types.AdminUpdatePlanRequest{
+		PlanID:       premium.ID,
+		NodeGroupIDs: []uint64{streaming.ID},
+	})
+	require.NoError(t, err)
+	remaining, err := groups.Delete(&types.AdminDeleteNodeGroupRequest{GroupID: asia.ID})
+	require.NoError(t, err)
+	require.Len(t, remaining.Groups, 1)
+	require.Equal(t, []uint64{openSub.ID}, usersOf(hk.ID))
+}
@@ -0,0 +1,149 @@
+package orderutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// CommissionAccrualKey 返回订单佣金的幂等键。
+func CommissionAccrualKey(orderID uint64) string {
+	return fmt.Sprintf("order:%d", orderID)
+}
+
+// CommissionReversalKey 返回退款冲正佣金的幂等键。
+func CommissionReversalKey(refundID uint64) string {
+	return fmt.Sprintf("refund:%d", refundID)
+}
+
+// AccrueCommission 为被邀请用户的已支付订单向邀请人累计佣金。
+// 佣金按订单实付金额 × CommissionPercent 计算并换算为邀请人佣金账户币种，无可用汇率时跳过。
+func AccrueCommission(ctx context.Context, repos *repository.Repositories, cfg config.AffiliateConfig, order repository.Order) (*repository.CommissionEntry, error) {
+	cfg.Normalize()
+	if !cfg.Enable || cfg.CommissionPercent <= 0 || order.TotalCents <= 0 {
+		return nil, nil
+	}
+
+	referral, err := repos.Affiliate.GetReferral(ctx, order.UserID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := repos.Affiliate.GetAccount(ctx, referral.ReferrerID)
+	if err != nil {
+		return nil, err
+	}
+	currency := account.Currency
+	if currency == "" {
+		currency = order.Currency
+	}
+
+	amount := int64(math.Round(float64(order.TotalCents) * cfg.CommissionPercent / 100))
+	converted, err := ConvertAmount(ctx, repos, amount, order.Currency, currency)
+	if errors.Is(err, ErrCurrencyMismatch) {
+		logx.WithContext(ctx).Errorf("affiliate: skip commission for order %s: no rate %s->%s", order.Number, order.Currency, currency)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if converted <= 0 {
+		return nil, nil
+	}
+
+	entry, _, err := repos.Affiliate.ApplyEntry(ctx, repository.CommissionEntry{
+		UserID:         referral.ReferrerID,
+		Type:           repository.CommissionEntryAccrual,
+		AmountCents:    converted,
+		Currency:       currency,
+		SourceKey:      CommissionAccrualKey(order.ID),
+		OrderID:        order.ID,
+		ReferredUserID: order.UserID,
+		Description:    fmt.Sprintf("订单 %s 推广佣金", order.Number),
+		Metadata: map[string]any{
+			"order_number":       order.Number,
+			"order_total_cents":  order.TotalCents,
+			"order_currency":     order.Currency,
+			"commission_percent": cfg.CommissionPercent,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ReverseCommission 按退款占订单金额的比例冲正已累计的佣金；全额退款时冲正剩余全部佣金。
+// order 为退款写入前的订单快照。
+func ReverseCommission(ctx context.Context, repos *repository.Repositories, order repository.Order, refund repository.OrderRefund) (*repository.CommissionEntry, error) {
+	if order.TotalCents <= 0 || refund.AmountCents <= 0 {
+		return nil, nil
+	}
+
+	entries, err := repos.Affiliate.ListOrderEntries(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		accrual  *repository.CommissionEntry
+		reversed int64
+	)
+	for i := range entries {
+		switch entries[i].Type {
+		case repository.CommissionEntryAccrual:
+			accrual = &entries[i]
+		case repository.CommissionEntryReversal:
+			reversed -= entries[i].AmountCents
+		}
+	}
+	if accrual == nil {
+		return nil, nil
+	}
+
+	remaining := accrual.AmountCents - reversed
+	if remaining <= 0 {
+		return nil, nil
+	}
+
+	amount := int64(math.Round(float64(accrual.AmountCents) * float64(refund.AmountCents) / float64(order.TotalCents)))
+	if order.RefundedCents+refund.AmountCents >= order.TotalCents || amount > remaining {
+		amount = remaining
+	}
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	refundID := refund.ID
+	entry, _, err := repos.Affiliate.ApplyEntry(ctx, repository.CommissionEntry{
+		UserID:         accrual.UserID,
+		Type:           repository.CommissionEntryReversal,
+		AmountCents:    -amount,
+		Currency:       accrual.Currency,
+		SourceKey:      CommissionReversalKey(refundID),
+		OrderID:        order.ID,
+		ReferredUserID: accrual.ReferredUserID,
+		Description:    fmt.Sprintf("订单 %s 退款冲正佣金", order.Number),
+		Metadata: map[string]any{
+			"order_number":     order.Number,
+			"refund_id":        refundID,
+			"refund_amount":    refund.AmountCents,
+			"accrual_entry_id": accrual.ID,
+			"accrual_amount":   accrual.AmountCents,
+			"reversed_before":  reversed,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
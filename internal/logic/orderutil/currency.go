@@ -0,0 +1,148 @@
+package orderutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// ErrCurrencyMismatch 表示目标币种既无固定售价也无可用汇率。
+var ErrCurrencyMismatch = fmt.Errorf("%w: no exchange rate for currency", repository.ErrInvalidArgument)
+
+// 价格来源，写入 Order.Metadata["pricing"]["source"]。
+const (
+	PriceSourceBase         = "base"
+	PriceSourcePriceList    = "price_list"
+	PriceSourceExchangeRate = "exchange_rate"
+)
+
+// PlanPricing 描述套餐在目标币种下的单价及换算依据。
+type PlanPricing struct {
+	Currency       string
+	UnitPriceCents int64
+	BaseCurrency   string
+	BasePriceCents int64
+	Source         string
+	Rate           float64
+	RateID         uint64
+	RateInverse    bool
+	RateUpdatedAt  time.Time
+}
+
+// ResolvePlanPrice 按 固定售价 > 基础币种 > 汇率换算 的顺序确定套餐在 currency 下的单价。
+// 汇率仅存在反向记录时取倒数；均不存在时返回 ErrCurrencyMismatch。
+func ResolvePlanPrice(ctx context.Context, repos *repository.Repositories, plan repository.Plan, currency string) (PlanPricing, error) {
+	base := planCurrency(plan)
+	target := repository.NormalizeCurrency(currency)
+	if target == "" {
+		target = base
+	}
+
+	pricing := PlanPricing{
+		Currency:       target,
+		UnitPriceCents: plan.PriceCents,
+		BaseCurrency:   base,
+		BasePriceCents: plan.PriceCents,
+		Source:         PriceSourceBase,
+		Rate:           1,
+	}
+
+	if plan.ID != 0 {
+		prices, err := repos.Plan.ListPrices(ctx, plan.ID)
+		if err != nil {
+			return PlanPricing{}, err
+		}
+		for _, price := range prices[plan.ID] {
+			if price.Currency == target {
+				pricing.UnitPriceCents = price.PriceCents
+				pricing.Source = PriceSourcePriceList
+				return pricing, nil
+			}
+		}
+	}
+
+	if target == base {
+		return pricing, nil
+	}
+
+	rate, inverse, err := findExchangeRate(ctx, repos, base, target)
+	if err != nil {
+		return PlanPricing{}, err
+	}
+
+	pricing.Source = PriceSourceExchangeRate
+	pricing.Rate = rate.Rate
+	if inverse {
+		pricing.Rate = 1 / rate.Rate
+	}
+	pricing.RateID = rate.ID
+	pricing.RateInverse = inverse
+	pricing.RateUpdatedAt = rate.UpdatedAt
+	pricing.UnitPriceCents = ConvertCents(plan.PriceCents, pricing.Rate)
+
+	return pricing, nil
+}
+
+func findExchangeRate(ctx context.Context, repos *repository.Repositories, base, quote string) (repository.ExchangeRate, bool, error) {
+	rate, err := repos.ExchangeRate.Find(ctx, base, quote)
+	if err == nil {
+		return rate, false, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return repository.ExchangeRate{}, false, err
+	}
+
+	rate, err = repos.ExchangeRate.Find(ctx, quote, base)
+	if err == nil {
+		return rate, true, nil
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		return repository.ExchangeRate{}, false, ErrCurrencyMismatch
+	}
+	return repository.ExchangeRate{}, false, err
+}
+
+// ConvertAmount 将金额从 from 币种换算为 to 币种，无可用汇率时返回 ErrCurrencyMismatch。
+func ConvertAmount(ctx context.Context, repos *repository.Repositories, cents int64, from, to string) (int64, error) {
+	from = repository.NormalizeCurrency(from)
+	to = repository.NormalizeCurrency(to)
+	if from == "" || to == "" || from == to {
+		return cents, nil
+	}
+
+	rate, inverse, err := findExchangeRate(ctx, repos, from, to)
+	if err != nil {
+		return 0, err
+	}
+	if inverse {
+		return ConvertCents(cents, 1/rate.Rate), nil
+	}
+	return ConvertCents(cents, rate.Rate), nil
+}
+
+// ConvertCents 按汇率换算金额并四舍五入到分。
+func ConvertCents(cents int64, rate float64) int64 {
+	return int64(math.Round(float64(cents) * rate))
+}
+
+// PricingMetadata 将换算依据写入订单元数据，便于后续对账与退款。
+func PricingMetadata(p PlanPricing) map[string]any {
+	meta := map[string]any{
+		"source":           p.Source,
+		"currency":         p.Currency,
+		"unit_price_cents": p.UnitPriceCents,
+		"base_currency":    p.BaseCurrency,
+		"base_price_cents": p.BasePriceCents,
+		"rate":             p.Rate,
+	}
+	if p.Source == PriceSourceExchangeRate {
+		meta["rate_id"] = p.RateID
+		meta["rate_inverse"] = p.RateInverse
+		meta["rate_updated_at"] = p.RateUpdatedAt.Unix()
+	}
+	return meta
+}
@@ -0,0 +1,201 @@
+package orderutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// FulfillPaidOrder 在订单支付成功后开通或更新订阅，需在支付事务内调用。
+// 续费/变更订单直接切换原订阅的套餐、额度与到期时间，不会新建订阅；变更订单的抵扣在订阅行锁内按支付时的剩余价值重新校验。
+// 返回值为需要合并进订单元数据的字段；订单已履约或不含套餐时返回 nil。
+func FulfillPaidOrder(ctx context.Context, tx *gorm.DB, order repository.Order, paidAt time.Time) (map[string]any, error) {
+	if order.PlanID == nil || *order.PlanID == 0 {
+		return nil, nil
+	}
+	if _, done := order.Metadata["fulfilled_at"]; done {
+		return nil, nil
+	}
+	if kind, _ := order.Metadata["order_kind"].(string); kind == OrderKindTrafficAddon {
+		return fulfillTrafficAddon(ctx, tx, order, paidAt)
+	}
+
+	repos, err := repository.NewRepositories(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	paidAt = paidAt.UTC()
+	quantity := int(metadataInt64(order.Metadata, "quantity"))
+	if quantity <= 0 {
+		quantity = 1
+	}
+	snapshot := order.PlanSnapshot
+	planName := strings.TrimSpace(fmt.Sprint(snapshot["name"]))
+	durationDays := int(metadataInt64(snapshot, "duration_days"))
+	duration := time.Duration(durationDays*quantity) * 24 * time.Hour
+	trafficLimit := metadataInt64(snapshot, "traffic_limit_bytes")
+	devicesLimit := int(metadataInt64(snapshot, "devices_limit"))
+	resetPolicy, _ := snapshot["traffic_reset_policy"].(string)
+
+	patch := map[string]any{}
+	var subscription repository.Subscription
+	if subscriptionID := uint64(metadataInt64(order.Metadata, "subscription_id")); subscriptionID > 0 {
+		current, err := repos.Subscription.GetForUpdate(ctx, subscriptionID)
+		if err != nil {
+			return nil, err
+		}
+		if current.UserID != order.UserID {
+			return nil, repository.ErrForbidden
+		}
+
+		// 在订阅行锁内按支付时的状态重新折算剩余价值：同一订阅的多笔变更订单只能抵扣仍然存在的价值。
+		credit, err := SubscriptionCredit(ctx, repos, current, order.Currency, paidAt)
+		if errors.Is(err, ErrCurrencyMismatch) {
+			credit.CreditCents = 0
+		} else if err != nil {
+			return nil, err
+		}
+
+		expiresAt := paidAt.Add(duration)
+		resetTraffic := true
+		totalBytes := trafficLimit
+		periodPaid := order.TotalCents
+		if kind, _ := order.Metadata["order_kind"].(string); kind == OrderKindRenewal && current.ExpiresAt.After(paidAt) {
+			expiresAt = current.ExpiresAt.Add(duration)
+			resetTraffic = false
+			// 续费不打断当前周期，保留已加购的流量。
+			totalBytes += current.TrafficExtraBytes
+			periodPaid += credit.CreditCents
+		} else if proration, ok := order.Metadata["proration"].(map[string]any); ok {
+			quoted := metadataInt64(proration, "applied_cents")
+			applied := quoted
+			if applied > credit.CreditCents {
+				applied = credit.CreditCents
+			}
+			if shortfall := quoted - applied; shortfall > 0 && order.TotalCents+quoted > 0 {
+				// 报价时的抵扣已被其他订单消耗，按实际获得的价值缩短新周期。
+				ratio := float64(order.TotalCents+applied) / float64(order.TotalCents+quoted)
+				expiresAt = paidAt.Add(time.Duration(float64(duration) * ratio))
+				patch["proration_shortfall_cents"] = shortfall
+			}
+			patch["proration_applied_cents"] = applied
+			periodPaid += applied
+		}
+
+		subscription, err = repos.Subscription.ApplyPlan(ctx, current.ID, repository.ApplySubscriptionPlanParams{
+			PlanID:             *order.PlanID,
+			PlanName:           planName,
+			ExpiresAt:          expiresAt,
+			TrafficTotalBytes:  totalBytes,
+			DevicesLimit:       devicesLimit,
+			ResetTraffic:       resetTraffic,
+			TrafficResetPolicy: resetPolicy,
+			PeriodPaidCents:    periodPaid,
+			PeriodCurrency:     order.Currency,
+			PeriodStartedAt:    paidAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		templateID, err := defaultTemplateID(ctx, repos.SubscriptionTemplate)
+		if err != nil {
+			return nil, err
+		}
+		token, err := repository.GenerateSubscriptionToken()
+		if err != nil {
+			return nil, err
+		}
+
+		subscription, err = repos.Subscription.Create(ctx, repository.Subscription{
+			UserID:             order.UserID,
+			PlanID:             *order.PlanID,
+			Name:               planName,
+			PlanName:           planName,
+			Status:             repository.SubscriptionStatusActive,
+			TemplateID:         templateID,
+			Token:              token,
+			ExpiresAt:          paidAt.Add(duration),
+			TrafficTotalBytes:  trafficLimit,
+			DevicesLimit:       devicesLimit,
+			TrafficResetPolicy: resetPolicy,
+			PeriodPaidCents:    order.TotalCents,
+			PeriodCurrency:     repository.NormalizeCurrency(order.Currency),
+			PeriodStartedAt:    &paidAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	patch["subscription_id"] = subscription.ID
+	patch["fulfilled_at"] = paidAt.Unix()
+	return patch, nil
+}
+
+// defaultTemplateID 选择新订阅的模板：优先已发布的默认模板，其次为最早创建的已发布模板，
+// 草稿模板不会被选中；均不存在时返回 0。
+func defaultTemplateID(ctx context.Context, repo repository.SubscriptionTemplateRepository) (uint64, error) {
+	tpl, err := repo.GetDefault(ctx, "")
+	if err == nil {
+		return tpl.ID, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return 0, err
+	}
+
+	templates, _, err := repo.List(ctx, repository.ListTemplatesOptions{
+		PerPage:   1,
+		Sort:      "created_at",
+		Direction: "asc",
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(templates) > 0 && templates[0].Version > 0 {
+		return templates[0].ID, nil
+	}
+	return 0, nil
+}
+
+// metadataInt64 兼容 JSON 反序列化后的数值类型。
+func metadataInt64(values map[string]any, key string) int64 {
+	if values == nil {
+		return 0
+	}
+	switch v := values[key].(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case uint:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float32:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case json.Number:
+		n, _ := v.Int64()
+		return n
+	case string:
+		n, _ := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,141 @@
+package orderutil
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// GrantPlanDays 为用户发放套餐时长：已有同套餐的有效订阅时顺延到期时间，否则按套餐额度新建订阅。
+// 需在调用方事务内执行。
+func GrantPlanDays(ctx context.Context, tx *gorm.DB, userID uint64, plan repository.Plan, days int, now time.Time) (repository.Subscription, error) {
+	if userID == 0 || plan.ID == 0 || days <= 0 {
+		return repository.Subscription{}, repository.ErrInvalidArgument
+	}
+
+	templateRepo, err := repository.NewSubscriptionTemplateRepository(tx)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+	subscriptionRepo, err := repository.NewSubscriptionRepository(tx, templateRepo)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+
+	now = now.UTC()
+	duration := time.Duration(days) * 24 * time.Hour
+
+	subscriptions, _, err := subscriptionRepo.ListByUser(ctx, userID, repository.ListSubscriptionsOptions{
+		PerPage:   100,
+		Sort:      "expires_at",
+		Direction: "desc",
+	})
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+	for _, current := range subscriptions {
+		// 已过期或流量耗尽的同套餐订阅同样顺延；暂停与已取消的订阅不参与。
+		if current.PlanID != plan.ID || !grantableStatus(current.Status) {
+			continue
+		}
+		base := now
+		if current.ExpiresAt.After(now) {
+			base = current.ExpiresAt
+		}
+		return subscriptionRepo.ApplyPlan(ctx, current.ID, repository.ApplySubscriptionPlanParams{
+			PlanID:             plan.ID,
+			PlanName:           plan.Name,
+			ExpiresAt:          base.Add(duration),
+			TrafficTotalBytes:  current.TrafficTotalBytes,
+			DevicesLimit:       current.DevicesLimit,
+			TrafficResetPolicy: plan.TrafficResetPolicy,
+		})
+	}
+
+	templateID, err := defaultTemplateID(ctx, templateRepo)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+	token, err := repository.GenerateSubscriptionToken()
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+
+	return subscriptionRepo.Create(ctx, repository.Subscription{
+		UserID:             userID,
+		PlanID:             plan.ID,
+		Name:               plan.Name,
+		PlanName:           plan.Name,
+		Status:             repository.SubscriptionStatusActive,
+		TemplateID:         templateID,
+		Token:              token,
+		ExpiresAt:          now.Add(duration),
+		TrafficTotalBytes:  plan.TrafficLimitBytes,
+		DevicesLimit:       plan.DevicesLimit,
+		TrafficResetPolicy: plan.TrafficResetPolicy,
+	})
+}
+
+// GrantTraffic 为用户未过期的订阅增加流量额度；subscriptionID 为 0 时选择到期最晚的有效订阅。
+// 额度计入当期额外流量，按周期重置流量时随周期收回；需在调用方事务内执行。
+func GrantTraffic(ctx context.Context, tx *gorm.DB, userID, subscriptionID uint64, bytes int64, now time.Time) (repository.Subscription, error) {
+	if userID == 0 || bytes <= 0 {
+		return repository.Subscription{}, repository.ErrInvalidArgument
+	}
+
+	templateRepo, err := repository.NewSubscriptionTemplateRepository(tx)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+	subscriptionRepo, err := repository.NewSubscriptionRepository(tx, templateRepo)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+
+	var target repository.Subscription
+	if subscriptionID != 0 {
+		target, err = subscriptionRepo.Get(ctx, subscriptionID)
+		if err != nil {
+			return repository.Subscription{}, err
+		}
+		if target.UserID != userID {
+			return repository.Subscription{}, repository.ErrNotFound
+		}
+	} else {
+		subscriptions, _, err := subscriptionRepo.ListByUser(ctx, userID, repository.ListSubscriptionsOptions{
+			PerPage:   100,
+			Sort:      "expires_at",
+			Direction: "desc",
+		})
+		if err != nil {
+			return repository.Subscription{}, err
+		}
+		for _, candidate := range subscriptions {
+			if candidate.Status == repository.SubscriptionStatusActive || candidate.Status == repository.SubscriptionStatusExhausted {
+				target = candidate
+				break
+			}
+		}
+		if target.ID == 0 {
+			return repository.Subscription{}, repository.ErrInvalidArgument
+		}
+	}
+
+	if target.PlanID == 0 || !target.ExpiresAt.After(now) || !grantableStatus(target.Status) {
+		return repository.Subscription{}, repository.ErrInvalidState
+	}
+
+	return subscriptionRepo.AddTrafficExtra(ctx, target.ID, bytes)
+}
+
+func grantableStatus(status string) bool {
+	switch status {
+	case repository.SubscriptionStatusSuspended, repository.SubscriptionStatusCancelled:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,44 @@
+package orderutil
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// OnOrderPaid 订单进入 paid 后在同一事务内执行：开具发票、累计推广佣金。
+// 余额支付下单、人工标记支付与支付回调均需调用。
+func OnOrderPaid(ctx context.Context, tx *gorm.DB, cfg config.Config, orderID uint64) error {
+	if _, err := IssueOrderInvoice(ctx, tx, cfg.Invoice, orderID); err != nil {
+		return err
+	}
+
+	repos, err := repository.NewRepositories(tx)
+	if err != nil {
+		return err
+	}
+	order, _, err := repos.Order.Get(ctx, orderID)
+	if err != nil {
+		return err
+	}
+	_, err = AccrueCommission(ctx, repos, cfg.Affiliate, order)
+	return err
+}
+
+// OnOrderRefunded 退款记录写入后在同一事务内执行：开具红字发票、冲正推广佣金。
+// order 为退款写入前的订单快照。
+func OnOrderRefunded(ctx context.Context, tx *gorm.DB, cfg config.Config, order repository.Order, refund repository.OrderRefund) error {
+	if _, err := IssueCreditNote(ctx, tx, cfg.Invoice, order, refund); err != nil {
+		return err
+	}
+
+	repos, err := repository.NewRepositories(tx)
+	if err != nil {
+		return err
+	}
+	_, err = ReverseCommission(ctx, repos, order, refund)
+	return err
+}
@@ -0,0 +1,199 @@
+package orderutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// InvoiceSourceKey 返回订单发票的幂等键。
+func InvoiceSourceKey(orderID uint64) string {
+	return fmt.Sprintf("order:%d", orderID)
+}
+
+// CreditNoteSourceKey 返回退款红字发票的幂等键。
+func CreditNoteSourceKey(refundID uint64) string {
+	return fmt.Sprintf("refund:%d", refundID)
+}
+
+// IssueOrderInvoice 为已支付订单开具发票，需在订单落库后的同一事务内调用；
+// 金额为零的订单不开票，重复调用返回已有发票。
+func IssueOrderInvoice(ctx context.Context, tx *gorm.DB, cfg config.InvoiceConfig, orderID uint64) (*repository.Invoice, error) {
+	orderRepo, err := repository.NewOrderRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+	invoiceRepo, err := repository.NewInvoiceRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, items, err := orderRepo.Get(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.TotalCents <= 0 {
+		return nil, nil
+	}
+
+	buyer, err := invoiceBuyer(ctx, tx, order.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]repository.InvoiceLine, 0, len(items))
+	var subtotal int64
+	for _, item := range items {
+		lines = append(lines, repository.InvoiceLine{
+			Name:           item.Name,
+			Quantity:       item.Quantity,
+			UnitPriceCents: item.UnitPriceCents,
+			AmountCents:    item.SubtotalCents,
+		})
+		subtotal += item.SubtotalCents
+	}
+
+	issuedAt := time.Now().UTC()
+	if order.PaidAt != nil {
+		issuedAt = order.PaidAt.UTC()
+	}
+
+	cfg.Normalize()
+	invoice, err := invoiceRepo.Issue(ctx, repository.Invoice{
+		Kind:          repository.InvoiceKindInvoice,
+		SourceKey:     InvoiceSourceKey(order.ID),
+		UserID:        order.UserID,
+		OrderID:       order.ID,
+		OrderNumber:   order.Number,
+		Currency:      order.Currency,
+		SubtotalCents: subtotal,
+		TotalCents:    order.TotalCents,
+		Seller:        invoiceSeller(cfg),
+		Buyer:         buyer,
+		Lines:         lines,
+		IssuedAt:      issuedAt,
+	}, cfg.NumberPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// IssueCreditNote 为一次退款开具红字发票，并关联原订单发票（如存在）。
+func IssueCreditNote(ctx context.Context, tx *gorm.DB, cfg config.InvoiceConfig, order repository.Order, refund repository.OrderRefund) (*repository.Invoice, error) {
+	invoiceRepo, err := repository.NewInvoiceRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	var related *uint64
+	original, err := invoiceRepo.GetBySourceKey(ctx, InvoiceSourceKey(order.ID))
+	switch {
+	case err == nil:
+		related = &original.ID
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	buyer := original.Buyer
+	if related == nil {
+		buyer, err = invoiceBuyer(ctx, tx, order.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name := fmt.Sprintf("订单 %s 退款", order.Number)
+	if reason := strings.TrimSpace(refund.Reason); reason != "" {
+		name = fmt.Sprintf("%s（%s）", name, reason)
+	}
+
+	issuedAt := refund.CreatedAt.UTC()
+	if issuedAt.IsZero() {
+		issuedAt = time.Now().UTC()
+	}
+
+	cfg.Normalize()
+	refundID := refund.ID
+	invoice, err := invoiceRepo.Issue(ctx, repository.Invoice{
+		Kind:             repository.InvoiceKindCreditNote,
+		SourceKey:        CreditNoteSourceKey(refund.ID),
+		UserID:           order.UserID,
+		OrderID:          order.ID,
+		OrderNumber:      order.Number,
+		RefundID:         &refundID,
+		RelatedInvoiceID: related,
+		Currency:         order.Currency,
+		SubtotalCents:    -refund.AmountCents,
+		TotalCents:       -refund.AmountCents,
+		Seller:           invoiceSeller(cfg),
+		Buyer:            buyer,
+		Lines: []repository.InvoiceLine{{
+			Name:           name,
+			Quantity:       1,
+			UnitPriceCents: -refund.AmountCents,
+			AmountCents:    -refund.AmountCents,
+		}},
+		Note:     refund.Reason,
+		IssuedAt: issuedAt,
+	}, cfg.CreditNotePrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+func invoiceSeller(cfg config.InvoiceConfig) map[string]any {
+	return map[string]any{
+		"name":    cfg.SellerName,
+		"address": cfg.SellerAddress,
+		"tax_id":  cfg.SellerTaxID,
+		"email":   cfg.SellerEmail,
+	}
+}
+
+// invoiceBuyer 优先使用开票信息，未填写时回退到账户邮箱与昵称。
+func invoiceBuyer(ctx context.Context, tx *gorm.DB, userID uint64) (map[string]any, error) {
+	invoiceRepo, err := repository.NewInvoiceRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := invoiceRepo.GetBillingProfile(ctx, userID)
+	if err == nil {
+		return map[string]any{
+			"name":         profile.Name,
+			"company_name": profile.CompanyName,
+			"tax_id":       profile.TaxID,
+			"email":        profile.Email,
+			"phone":        profile.Phone,
+			"address":      profile.Address,
+			"country":      profile.Country,
+		}, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	userRepo, err := repository.NewUserRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := userRepo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"name":  user.DisplayName,
+		"email": user.Email,
+	}, nil
+}
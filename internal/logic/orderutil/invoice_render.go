@@ -0,0 +1,234 @@
+package orderutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/pkg/pdf"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// 内置发票模板，变量见 invoiceTemplateData；由 html/template 按上下文自动转义。
+const defaultInvoiceHTMLTemplate = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{ .Title }} {{ .Number }}</title>
+<style>
+body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; margin: 40px; color: #222; }
+table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+th, td { border-bottom: 1px solid #ddd; padding: 8px; text-align: left; }
+td.num, th.num { text-align: right; }
+.meta { display: flex; justify-content: space-between; margin-top: 24px; }
+.total { font-size: 1.2em; font-weight: bold; text-align: right; margin-top: 16px; }
+</style>
+</head>
+<body>
+<h1>{{ .Title }}</h1>
+<p>编号 Number：{{ .Number }}<br>开具日期 Issued：{{ .IssuedAt }}<br>订单 Order：{{ .OrderNumber }}{{ if .RelatedNumber }}<br>原发票 Original invoice：{{ .RelatedNumber }}{{ end }}</p>
+<div class="meta">
+<div><strong>卖方 Seller</strong><br>{{ .Seller.name }}{{ if .Seller.tax_id }}<br>税号 {{ .Seller.tax_id }}{{ end }}{{ if .Seller.address }}<br>{{ .Seller.address }}{{ end }}{{ if .Seller.email }}<br>{{ .Seller.email }}{{ end }}</div>
+<div><strong>买方 Buyer</strong><br>{{ if .Buyer.company_name }}{{ .Buyer.company_name }}<br>{{ end }}{{ .Buyer.name }}{{ if .Buyer.tax_id }}<br>税号 {{ .Buyer.tax_id }}{{ end }}{{ if .Buyer.address }}<br>{{ .Buyer.address }}{{ end }}{{ if .Buyer.email }}<br>{{ .Buyer.email }}{{ end }}</div>
+</div>
+<table>
+<thead><tr><th>项目 Item</th><th class="num">数量 Qty</th><th class="num">单价 Unit</th><th class="num">金额 Amount</th></tr></thead>
+<tbody>
+{{- range .Lines }}
+<tr><td>{{ .Name }}</td><td class="num">{{ .Quantity }}</td><td class="num">{{ .UnitPrice }}</td><td class="num">{{ .Amount }}</td></tr>
+{{- end }}
+</tbody>
+</table>
+<p class="total">合计 Total：{{ .Currency }} {{ .Total }}</p>
+{{ if .Note }}<p>备注 Note：{{ .Note }}</p>{{ end }}
+</body>
+</html>
+`
+
+// PDF 使用纯文本排版，经 text/template 渲染，不做 HTML 转义。
+const defaultInvoiceTextTemplate = `{{ .Title }}
+编号 Number: {{ .Number }}
+开具日期 Issued: {{ .IssuedAt }}
+订单 Order: {{ .OrderNumber }}
+{{- if .RelatedNumber }}
+原发票 Original invoice: {{ .RelatedNumber }}
+{{- end }}
+
+卖方 Seller: {{ .Seller.name }}
+{{- if .Seller.tax_id }}
+  税号 Tax ID: {{ .Seller.tax_id }}
+{{- end }}
+{{- if .Seller.address }}
+  {{ .Seller.address }}
+{{- end }}
+{{- if .Seller.email }}
+  {{ .Seller.email }}
+{{- end }}
+
+买方 Buyer: {{ if .Buyer.company_name }}{{ .Buyer.company_name }} / {{ end }}{{ .Buyer.name }}
+{{- if .Buyer.tax_id }}
+  税号 Tax ID: {{ .Buyer.tax_id }}
+{{- end }}
+{{- if .Buyer.address }}
+  {{ .Buyer.address }}
+{{- end }}
+{{- if .Buyer.email }}
+  {{ .Buyer.email }}
+{{- end }}
+
+----------------------------------------------------------------
+{{- range .Lines }}
+{{ .Name }}
+    {{ .Quantity }} x {{ .UnitPrice }} = {{ .Amount }}
+{{- end }}
+----------------------------------------------------------------
+合计 Total: {{ .Currency }} {{ .Total }}
+{{- if .Note }}
+
+备注 Note: {{ .Note }}
+{{- end }}
+`
+
+// RenderInvoiceHTML 使用 html/template 渲染发票 HTML；配置了 TemplateFile 时优先使用自定义模板。
+func RenderInvoiceHTML(cfg config.InvoiceConfig, invoice repository.Invoice, related *repository.Invoice) (string, error) {
+	content := defaultInvoiceHTMLTemplate
+	if path := strings.TrimSpace(cfg.TemplateFile); path != "" {
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("invoice: read template: %w", err)
+		}
+		content = string(raw)
+	}
+
+	tpl, err := template.New("invoice").Option("missingkey=zero").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("invoice: parse template: %w", err)
+	}
+	var buf strings.Builder
+	if err := tpl.Execute(&buf, invoiceTemplateData(invoice, related)); err != nil {
+		return "", fmt.Errorf("invoice: render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// RenderInvoicePDF 将发票渲染为 PDF。
+func RenderInvoicePDF(invoice repository.Invoice, related *repository.Invoice) ([]byte, error) {
+	text, err := subtemplate.Render("go_template", defaultInvoiceTextTemplate, invoiceTemplateData(invoice, related))
+	if err != nil {
+		return nil, err
+	}
+
+	doc := pdf.NewDocument()
+	doc.AddLines(text)
+	return doc.Bytes(), nil
+}
+
+func invoiceTemplateData(invoice repository.Invoice, related *repository.Invoice) map[string]any {
+	title := "发票 Invoice"
+	if invoice.Kind == repository.InvoiceKindCreditNote {
+		title = "红字发票 Credit Note"
+	}
+
+	lines := make([]map[string]any, 0, len(invoice.Lines))
+	for _, line := range invoice.Lines {
+		lines = append(lines, map[string]any{
+			"Name":      line.Name,
+			"Quantity":  line.Quantity,
+			"UnitPrice": FormatCents(line.UnitPriceCents),
+			"Amount":    FormatCents(line.AmountCents),
+		})
+	}
+
+	relatedNumber := ""
+	if related != nil {
+		relatedNumber = related.Number
+	}
+
+	return map[string]any{
+		"Title":         title,
+		"Kind":          invoice.Kind,
+		"Number":        invoice.Number,
+		"IssuedAt":      invoice.IssuedAt.UTC().Format("2006-01-02"),
+		"OrderNumber":   invoice.OrderNumber,
+		"RelatedNumber": relatedNumber,
+		"Currency":      invoice.Currency,
+		"Subtotal":      FormatCents(invoice.SubtotalCents),
+		"Total":         FormatCents(invoice.TotalCents),
+		"Seller":        withStringDefaults(invoice.Seller),
+		"Buyer":         withStringDefaults(invoice.Buyer),
+		"Lines":         lines,
+		"Note":          invoice.Note,
+	}
+}
+
+// withStringDefaults 补齐模板引用的字段，避免缺失键渲染为 "<no value>"。
+func withStringDefaults(values map[string]any) map[string]any {
+	result := map[string]any{
+		"name": "", "company_name": "", "tax_id": "", "email": "", "phone": "", "address": "", "country": "",
+	}
+	for k, v := range values {
+		result[k] = fmt.Sprint(v)
+	}
+	return result
+}
+
+// FormatCents 将分格式化为两位小数金额。
+func FormatCents(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// InvoiceFile 下载用的发票文件。
+type InvoiceFile struct {
+	Filename    string
+	ContentType string
+	Content     []byte
+}
+
+// RenderInvoiceFile 按 format（html/pdf）渲染发票，红字发票会带出原发票编号。
+func RenderInvoiceFile(ctx context.Context, cfg config.InvoiceConfig, repos *repository.Repositories, invoice repository.Invoice, format string) (InvoiceFile, error) {
+	var related *repository.Invoice
+	if invoice.RelatedInvoiceID != nil {
+		original, err := repos.Invoice.Get(ctx, *invoice.RelatedInvoiceID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return InvoiceFile{}, err
+		}
+		if err == nil {
+			related = &original
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "html":
+		content, err := RenderInvoiceHTML(cfg, invoice, related)
+		if err != nil {
+			return InvoiceFile{}, err
+		}
+		return InvoiceFile{
+			Filename:    invoice.Number + ".html",
+			ContentType: "text/html; charset=utf-8",
+			Content:     []byte(content),
+		}, nil
+	case "pdf":
+		content, err := RenderInvoicePDF(invoice, related)
+		if err != nil {
+			return InvoiceFile{}, err
+		}
+		return InvoiceFile{
+			Filename:    invoice.Number + ".pdf",
+			ContentType: "application/pdf",
+			Content:     content,
+		}, nil
+	default:
+		return InvoiceFile{}, repository.ErrInvalidArgument
+	}
+}
@@ -151,3 +151,125 @@ func ToOrderPayment(payment repository.OrderPayment) types.OrderPayment {
 		UpdatedAt:      payment.UpdatedAt.UTC().Unix(),
 	}
 }
+
+// ToInvoiceDetail converts an invoice record into API representation.
+func ToInvoiceDetail(invoice repository.Invoice) types.InvoiceDetail {
+	lines := make([]types.InvoiceLine, 0, len(invoice.Lines))
+	for _, line := range invoice.Lines {
+		lines = append(lines, types.InvoiceLine{
+			Name:           line.Name,
+			Quantity:       line.Quantity,
+			UnitPriceCents: line.UnitPriceCents,
+			AmountCents:    line.AmountCents,
+		})
+	}
+	return types.InvoiceDetail{
+		ID:               invoice.ID,
+		Number:           invoice.Number,
+		Kind:             invoice.Kind,
+		UserID:           invoice.UserID,
+		OrderID:          invoice.OrderID,
+		OrderNumber:      invoice.OrderNumber,
+		RefundID:         invoice.RefundID,
+		RelatedInvoiceID: invoice.RelatedInvoiceID,
+		Currency:         invoice.Currency,
+		SubtotalCents:    invoice.SubtotalCents,
+		TotalCents:       invoice.TotalCents,
+		Seller:           invoice.Seller,
+		Buyer:            invoice.Buyer,
+		Lines:            lines,
+		Note:             invoice.Note,
+		IssuedAt:         invoice.IssuedAt.UTC().Unix(),
+	}
+}
+
+// ToCommissionEntrySummary converts a commission ledger entry for API responses.
+func ToCommissionEntrySummary(entry repository.CommissionEntry) types.CommissionEntrySummary {
+	return types.CommissionEntrySummary{
+		ID:                entry.ID,
+		Type:              entry.Type,
+		AmountCents:       entry.AmountCents,
+		Currency:          entry.Currency,
+		BalanceAfterCents: entry.BalanceAfterCents,
+		OrderID:           entry.OrderID,
+		ReferredUserID:    entry.ReferredUserID,
+		PayoutID:          entry.PayoutID,
+		Description:       entry.Description,
+		Metadata:          entry.Metadata,
+		CreatedAt:         entry.CreatedAt.UTC().Unix(),
+	}
+}
+
+// ToCommissionPayoutSummary converts a payout request for API responses.
+func ToCommissionPayoutSummary(payout repository.CommissionPayout) types.CommissionPayoutSummary {
+	summary := types.CommissionPayoutSummary{
+		ID:          payout.ID,
+		UserID:      payout.UserID,
+		AmountCents: payout.AmountCents,
+		Currency:    payout.Currency,
+		Status:      payout.Status,
+		Account:     payout.Account,
+		Note:        payout.Note,
+		ReviewNote:  payout.ReviewNote,
+		ReviewedBy:  payout.ReviewedBy,
+		CreatedAt:   payout.CreatedAt.UTC().Unix(),
+		UpdatedAt:   payout.UpdatedAt.UTC().Unix(),
+	}
+	if payout.ReviewedAt != nil {
+		reviewedAt := payout.ReviewedAt.UTC().Unix()
+		summary.ReviewedAt = &reviewedAt
+	}
+	return summary
+}
+
+// ToUserSubscriptionSummary converts a subscription for user-facing responses.
+func ToUserSubscriptionSummary(sub repository.Subscription) types.UserSubscriptionSummary {
+	summary := types.UserSubscriptionSummary{
+		ID:                   sub.ID,
+		Name:                 sub.Name,
+		PlanName:             sub.PlanName,
+		Status:               sub.Status,
+		TemplateID:           sub.TemplateID,
+		AvailableTemplateIDs: append([]uint64(nil), sub.AvailableTemplateIDs...),
+		ExpiresAt:            sub.ExpiresAt.Unix(),
+		TrafficTotalBytes:    sub.TrafficTotalBytes,
+		TrafficUsedBytes:     sub.TrafficUsedBytes,
+		TrafficExtraBytes:    sub.TrafficExtraBytes,
+		TrafficResetPolicy:   repository.NormalizeTrafficResetPolicy(sub.TrafficResetPolicy),
+		DevicesLimit:         sub.DevicesLimit,
+		AutoRenew:            sub.AutoRenew,
+		AutoRenewFailures:    sub.AutoRenewFailures,
+		AutoRenewLastError:   sub.AutoRenewLastError,
+		LastRefreshedAt:      sub.LastRefreshedAt.Unix(),
+	}
+	if sub.TrafficNextResetAt != nil {
+		next := sub.TrafficNextResetAt.Unix()
+		summary.TrafficNextResetAt = &next
+	}
+	if sub.StatusChangedAt != nil {
+		changedAt := sub.StatusChangedAt.Unix()
+		summary.StatusChangedAt = &changedAt
+	}
+	summary.StatusReason = sub.StatusReason
+	return summary
+}
+
+// ToRedeemRedemptionSummary converts a redemption record for API responses.
+func ToRedeemRedemptionSummary(r repository.RedeemRedemption) types.RedeemRedemptionSummary {
+	return types.RedeemRedemptionSummary{
+		ID:                   r.ID,
+		CodeID:               r.CodeID,
+		BatchID:              r.BatchID,
+		UserID:               r.UserID,
+		Code:                 r.Code,
+		Kind:                 r.Kind,
+		AmountCents:          r.AmountCents,
+		Currency:             r.Currency,
+		PlanID:               r.PlanID,
+		PlanDays:             r.PlanDays,
+		TrafficBytes:         r.TrafficBytes,
+		SubscriptionID:       r.SubscriptionID,
+		BalanceTransactionID: r.BalanceTransactionID,
+		CreatedAt:            r.CreatedAt.UTC().Unix(),
+	}
+}
@@ -0,0 +1,285 @@
+package orderutil
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// 订单类型，写入 Order.Metadata["order_kind"]。
+// traffic_addon 为当前周期加购流量，不改变套餐与到期时间。
+const (
+	OrderKindNew          = "new"
+	OrderKindRenewal      = "renewal"
+	OrderKindUpgrade      = "upgrade"
+	OrderKindDowngrade    = "downgrade"
+	OrderKindTrafficAddon = "traffic_addon"
+)
+
+// 订单条目类型。
+const (
+	OrderItemTypePlan            = "plan"
+	OrderItemTypeProrationCredit = "proration_credit"
+	OrderItemTypeTrafficAddon    = "traffic_addon"
+)
+
+// Proration 描述当前订阅剩余价值的折算结果。
+type Proration struct {
+	SubscriptionID     uint64
+	CurrentPlanID      uint64
+	RemainingSeconds   int64
+	UnusedTrafficBytes int64
+	TimeCreditCents    int64
+	TrafficCreditCents int64
+	CreditCents        int64
+	AppliedCents       int64
+	ForfeitedCents     int64
+}
+
+// PlanQuote 描述一次购买、续费或变更套餐的报价。
+type PlanQuote struct {
+	Kind          string
+	Plan          repository.Plan
+	Quantity      int
+	Currency      string
+	Pricing       PlanPricing
+	SubtotalCents int64
+	CreditCents   int64
+	TotalCents    int64
+	ExpiresAt     time.Time
+	Subscription  *repository.Subscription
+	Proration     *Proration
+	TrafficBytes  int64
+}
+
+// CalculateProration 按当前计费周期的实付价值折算剩余价值（以 PeriodCurrency 计价）：时长按 [PeriodStartedAt, ExpiresAt]
+// 的剩余比例、流量按剩余额度比例，取两者较小值，避免流量用尽后仍获得全额抵扣。
+// 未记录实付价值的订阅（管理员发放、兑换码开通或早于计费周期记录的订阅）不产生抵扣。
+func CalculateProration(sub repository.Subscription, now time.Time) Proration {
+	result := Proration{
+		SubscriptionID: sub.ID,
+		CurrentPlanID:  sub.PlanID,
+	}
+
+	remaining := sub.ExpiresAt.Sub(now)
+	if remaining > 0 {
+		result.RemainingSeconds = int64(remaining / time.Second)
+	}
+	if unused := sub.TrafficTotalBytes - sub.TrafficUsedBytes; unused > 0 {
+		result.UnusedTrafficBytes = unused
+	}
+
+	if sub.PeriodStartedAt == nil || sub.PeriodPaidCents <= 0 || result.RemainingSeconds == 0 {
+		return result
+	}
+	period := int64(sub.ExpiresAt.Sub(*sub.PeriodStartedAt) / time.Second)
+	if period <= 0 {
+		return result
+	}
+	remainingSeconds := result.RemainingSeconds
+	if remainingSeconds > period {
+		remainingSeconds = period
+	}
+
+	paid := float64(sub.PeriodPaidCents)
+	result.TimeCreditCents = int64(math.Floor(paid * float64(remainingSeconds) / float64(period)))
+	result.CreditCents = result.TimeCreditCents
+
+	if sub.TrafficTotalBytes > 0 {
+		result.TrafficCreditCents = int64(math.Floor(paid * float64(result.UnusedTrafficBytes) / float64(sub.TrafficTotalBytes)))
+		if result.TrafficCreditCents < result.CreditCents {
+			result.CreditCents = result.TrafficCreditCents
+		}
+	}
+
+	return result
+}
+
+// SubscriptionCredit 折算订阅剩余价值并换算为 currency；无可用汇率时返回未换算的结果与 ErrCurrencyMismatch。
+func SubscriptionCredit(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, currency string, now time.Time) (Proration, error) {
+	result := CalculateProration(sub, now)
+	if result.CreditCents == 0 {
+		return result, nil
+	}
+
+	from := sub.PeriodCurrency
+	converted := result
+	for _, cents := range []*int64{&converted.TimeCreditCents, &converted.TrafficCreditCents, &converted.CreditCents} {
+		value, err := ConvertAmount(ctx, repos, *cents, from, currency)
+		if err != nil {
+			return result, err
+		}
+		*cents = value
+	}
+	return converted, nil
+}
+
+// QuotePlan 计算以 currency 计价购买指定套餐的报价（为空时使用套餐币种）；
+// subscriptionID 非零时按续费或变更处理并折算当前订阅。
+func QuotePlan(ctx context.Context, repos *repository.Repositories, userID uint64, plan repository.Plan, quantity int, subscriptionID uint64, currency string, now time.Time) (PlanQuote, error) {
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	pricing, err := ResolvePlanPrice(ctx, repos, plan, currency)
+	if err != nil {
+		return PlanQuote{}, err
+	}
+
+	quote := PlanQuote{
+		Kind:          OrderKindNew,
+		Plan:          plan,
+		Quantity:      quantity,
+		Currency:      pricing.Currency,
+		Pricing:       pricing,
+		SubtotalCents: pricing.UnitPriceCents * int64(quantity),
+	}
+	duration := time.Duration(plan.DurationDays*quantity) * 24 * time.Hour
+	quote.ExpiresAt = now.Add(duration)
+
+	if subscriptionID == 0 {
+		quote.TotalCents = quote.SubtotalCents
+		return quote, nil
+	}
+
+	sub, err := repos.Subscription.Get(ctx, subscriptionID)
+	if err != nil {
+		return PlanQuote{}, err
+	}
+	if sub.UserID != userID {
+		return PlanQuote{}, repository.ErrForbidden
+	}
+	if sub.Status == repository.SubscriptionStatusCancelled {
+		return PlanQuote{}, repository.ErrInvalidState
+	}
+	quote.Subscription = &sub
+
+	if sub.PlanID == plan.ID {
+		quote.Kind = OrderKindRenewal
+		base := now
+		if sub.ExpiresAt.After(now) {
+			base = sub.ExpiresAt
+		}
+		quote.ExpiresAt = base.Add(duration)
+		quote.TotalCents = quote.SubtotalCents
+		return quote, nil
+	}
+
+	quote.Kind = OrderKindUpgrade
+	if sub.PlanID != 0 {
+		current, err := repos.Plan.Get(ctx, sub.PlanID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return PlanQuote{}, err
+		}
+		if err == nil {
+			downgrade, err := isDowngrade(ctx, repos, current, plan, pricing)
+			if err != nil {
+				return PlanQuote{}, err
+			}
+			if downgrade {
+				quote.Kind = OrderKindDowngrade
+			}
+		}
+	}
+
+	// 剩余价值以报价币种抵扣；无法换算时放弃抵扣。
+	proration, err := SubscriptionCredit(ctx, repos, sub, quote.Currency, now)
+	if err != nil && !errors.Is(err, ErrCurrencyMismatch) {
+		return PlanQuote{}, err
+	}
+	if err != nil {
+		proration.ForfeitedCents = proration.CreditCents
+	} else {
+		proration.AppliedCents = proration.CreditCents
+		if proration.AppliedCents > quote.SubtotalCents {
+			proration.AppliedCents = quote.SubtotalCents
+		}
+		proration.ForfeitedCents = proration.CreditCents - proration.AppliedCents
+	}
+
+	quote.Proration = &proration
+	quote.CreditCents = proration.AppliedCents
+	quote.TotalCents = quote.SubtotalCents - quote.CreditCents
+
+	return quote, nil
+}
+
+// isDowngrade 以报价币种比较新旧套餐单价，无法换算时仅在同币种下比较基础价格。
+func isDowngrade(ctx context.Context, repos *repository.Repositories, current, target repository.Plan, pricing PlanPricing) (bool, error) {
+	currentPricing, err := ResolvePlanPrice(ctx, repos, current, pricing.Currency)
+	if err == nil {
+		return pricing.UnitPriceCents < currentPricing.UnitPriceCents, nil
+	}
+	if !errors.Is(err, ErrCurrencyMismatch) {
+		return false, err
+	}
+	return target.PriceCents < current.PriceCents && strings.EqualFold(planCurrency(current), planCurrency(target)), nil
+}
+
+func planCurrency(plan repository.Plan) string {
+	currency := strings.ToUpper(strings.TrimSpace(plan.Currency))
+	if currency == "" {
+		return "CNY"
+	}
+	return currency
+}
+
+// ProrationMetadata 将折算明细写入订单元数据。
+func ProrationMetadata(p Proration) map[string]any {
+	return map[string]any{
+		"subscription_id":      p.SubscriptionID,
+		"current_plan_id":      p.CurrentPlanID,
+		"remaining_seconds":    p.RemainingSeconds,
+		"unused_traffic_bytes": p.UnusedTrafficBytes,
+		"time_credit_cents":    p.TimeCreditCents,
+		"traffic_credit_cents": p.TrafficCreditCents,
+		"credit_cents":         p.CreditCents,
+		"applied_cents":        p.AppliedCents,
+		"forfeited_cents":      p.ForfeitedCents,
+	}
+}
+
+// ToOrderQuote converts a plan quote into API representation.
+func ToOrderQuote(quote PlanQuote) types.UserOrderQuoteResponse {
+	resp := types.UserOrderQuoteResponse{
+		Kind:     quote.Kind,
+		PlanID:   quote.Plan.ID,
+		PlanName: quote.Plan.Name,
+		Quantity: quote.Quantity,
+		Currency: quote.Currency,
+		Pricing: types.OrderPricing{
+			Source:         quote.Pricing.Source,
+			BaseCurrency:   quote.Pricing.BaseCurrency,
+			BasePriceCents: quote.Pricing.BasePriceCents,
+			UnitPriceCents: quote.Pricing.UnitPriceCents,
+			Rate:           quote.Pricing.Rate,
+		},
+		SubtotalCents: quote.SubtotalCents,
+		CreditCents:   quote.CreditCents,
+		TotalCents:    quote.TotalCents,
+		ExpiresAt:     quote.ExpiresAt.UTC().Unix(),
+		TrafficBytes:  quote.TrafficBytes,
+	}
+	if quote.Subscription != nil {
+		resp.SubscriptionID = quote.Subscription.ID
+	}
+	if p := quote.Proration; p != nil {
+		resp.Proration = &types.OrderProration{
+			SubscriptionID:     p.SubscriptionID,
+			CurrentPlanID:      p.CurrentPlanID,
+			RemainingSeconds:   p.RemainingSeconds,
+			UnusedTrafficBytes: p.UnusedTrafficBytes,
+			TimeCreditCents:    p.TimeCreditCents,
+			TrafficCreditCents: p.TrafficCreditCents,
+			CreditCents:        p.CreditCents,
+			AppliedCents:       p.AppliedCents,
+			ForfeitedCents:     p.ForfeitedCents,
+		}
+	}
+	return resp
+}
@@ -0,0 +1,99 @@
+package orderutil
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// QuoteTrafficAddon 计算为订阅加购 quantity 份流量包的报价。流量包规格与单价取自订阅当前套餐，
+// 按套餐币种定价并在需要时按汇率换算；加购流量只在当前重置周期内有效。
+func QuoteTrafficAddon(ctx context.Context, repos *repository.Repositories, userID, subscriptionID uint64, quantity int, currency string, now time.Time) (PlanQuote, error) {
+	if subscriptionID == 0 {
+		return PlanQuote{}, repository.ErrInvalidArgument
+	}
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	sub, err := repos.Subscription.Get(ctx, subscriptionID)
+	if err != nil {
+		return PlanQuote{}, err
+	}
+	if sub.UserID != userID {
+		return PlanQuote{}, repository.ErrForbidden
+	}
+	if sub.PlanID == 0 || !sub.ExpiresAt.After(now) || !grantableStatus(sub.Status) {
+		return PlanQuote{}, repository.ErrInvalidState
+	}
+
+	plan, err := repos.Plan.Get(ctx, sub.PlanID)
+	if err != nil {
+		return PlanQuote{}, err
+	}
+	if plan.TrafficAddonBytes <= 0 || plan.TrafficAddonPriceCents <= 0 {
+		return PlanQuote{}, repository.ErrInvalidArgument
+	}
+
+	// 套餐价目表只适用于套餐本身，流量包以 ID 为零的副本定价以跳过价目表。
+	addon := plan
+	addon.ID = 0
+	addon.PriceCents = plan.TrafficAddonPriceCents
+	pricing, err := ResolvePlanPrice(ctx, repos, addon, currency)
+	if err != nil {
+		return PlanQuote{}, err
+	}
+
+	quote := PlanQuote{
+		Kind:          OrderKindTrafficAddon,
+		Plan:          plan,
+		Quantity:      quantity,
+		Currency:      pricing.Currency,
+		Pricing:       pricing,
+		SubtotalCents: pricing.UnitPriceCents * int64(quantity),
+		ExpiresAt:     sub.ExpiresAt,
+		Subscription:  &sub,
+		TrafficBytes:  plan.TrafficAddonBytes * int64(quantity),
+	}
+	quote.TotalCents = quote.SubtotalCents
+
+	return quote, nil
+}
+
+// fulfillTrafficAddon 将已支付的流量包计入订阅当期额度，下次周期重置时清零。
+func fulfillTrafficAddon(ctx context.Context, tx *gorm.DB, order repository.Order, paidAt time.Time) (map[string]any, error) {
+	subscriptionID := uint64(metadataInt64(order.Metadata, "subscription_id"))
+	bytes := metadataInt64(order.Metadata, "traffic_bytes")
+	if subscriptionID == 0 || bytes <= 0 {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	templateRepo, err := repository.NewSubscriptionTemplateRepository(tx)
+	if err != nil {
+		return nil, err
+	}
+	subscriptionRepo, err := repository.NewSubscriptionRepository(tx, templateRepo)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := subscriptionRepo.Get(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if current.UserID != order.UserID {
+		return nil, repository.ErrForbidden
+	}
+
+	if _, err := subscriptionRepo.AddTrafficExtra(ctx, current.ID, bytes); err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"subscription_id": current.ID,
+		"fulfilled_at":    paidAt.UTC().Unix(),
+	}, nil
+}
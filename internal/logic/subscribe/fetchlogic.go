@@ -0,0 +1,101 @@
+package subscribe
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/subscriptionutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// FetchLogic 通过订阅 token 或命名访问链接拉取订阅内容。
+type FetchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewFetchLogic 构造函数。
+func NewFetchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FetchLogic {
+	return &FetchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Fetch 解析 token 并返回渲染结果；暂停或取消的订阅返回 ErrForbidden。
+// 未指定模板时按 User-Agent 识别的客户端类型选择模板，见 subscriptionutil.ResolveTemplateID。
+// 每次解析成功的拉取（含被拒绝的）都会写入拉取日志，命名访问链接另外记录最近访问信息，记录失败不影响返回。
+func (l *FetchLogic) Fetch(req *types.SubscribeRequest, ip, userAgent string) (subscriptionutil.Rendered, repository.Subscription, error) {
+	sub, access, err := l.svcCtx.Repositories.Subscription.ResolveToken(l.ctx, req.Token)
+	if err != nil {
+		return subscriptionutil.Rendered{}, repository.Subscription{}, err
+	}
+
+	now := time.Now().UTC()
+	clientType := l.clientTypeMatcher().Detect(userAgent)
+	l.recordAccess(req.Token, sub, access, ip, userAgent, clientType, now)
+
+	switch sub.Status {
+	case repository.SubscriptionStatusSuspended, repository.SubscriptionStatusCancelled:
+		return subscriptionutil.Rendered{}, repository.Subscription{}, repository.ErrForbidden
+	}
+	if req.TemplateID != 0 && !subscriptionutil.TemplateAllowed(sub, req.TemplateID) {
+		return subscriptionutil.Rendered{}, repository.Subscription{}, repository.ErrForbidden
+	}
+
+	if access != nil {
+		if _, err := l.svcCtx.Repositories.Subscription.RecordTokenAccess(l.ctx, access.ID, ip, userAgent, now); err != nil {
+			l.Errorf("subscribe: record access for token %d: %v", access.ID, err)
+		}
+	}
+
+	templateID := req.TemplateID
+	if templateID == 0 {
+		templateID, err = subscriptionutil.ResolveTemplateID(l.ctx, l.svcCtx.Repositories, sub, clientType, l.svcCtx.Config.Template.DefaultClientType)
+		if err != nil {
+			return subscriptionutil.Rendered{}, repository.Subscription{}, err
+		}
+	}
+
+	rendered, err := subscriptionutil.RenderCached(l.ctx, l.svcCtx.Cache, l.svcCtx.Repositories, sub, templateID, l.svcCtx.Config.Template.RenderCacheTTL, subscriptionutil.RenderLimits(l.svcCtx.Config.Template), now)
+	if err != nil {
+		return subscriptionutil.Rendered{}, repository.Subscription{}, err
+	}
+
+	return rendered, sub, nil
+}
+
+// clientTypeMatcher 加载 User-Agent 路由规则，读取或编译失败时只使用内置规则。
+func (l *FetchLogic) clientTypeMatcher() *subscriptionutil.ClientTypeMatcher {
+	matcher, err := subscriptionutil.LoadClientTypeMatcher(l.ctx, l.svcCtx.Repositories)
+	if err != nil {
+		l.Errorf("subscribe: load client type rules: %v", err)
+		return nil
+	}
+	return matcher
+}
+
+func (l *FetchLogic) recordAccess(token string, sub repository.Subscription, access *repository.SubscriptionToken, ip, userAgent, clientType string, now time.Time) {
+	entry := repository.SubscriptionAccessLog{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		Token:          token,
+		IP:             ip,
+		UserAgent:      userAgent,
+		ClientType:     clientType,
+		Country:        l.svcCtx.GeoIP.Country(ip),
+		CreatedAt:      now,
+	}
+	if access != nil {
+		entry.TokenID = access.ID
+	}
+	if _, err := l.svcCtx.Repositories.Subscription.RecordAccess(l.ctx, entry); err != nil {
+		l.Errorf("subscribe: record access log for subscription %d: %v", sub.ID, err)
+	}
+}
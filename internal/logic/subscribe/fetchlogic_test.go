@@ -0,0 +1,241 @@
+package subscribe
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	usersub "github.com/zero-net-panel/zero-net-panel/internal/logic/user/subscription"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+)
+
+func setupFetchTest(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:subscribefetch?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	cacheProvider, err := cache.New(cache.Config{})
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Cache:        cacheProvider,
+		Repositories: repos,
+	}
+
+	cleanup := func() {
+		_ = cacheProvider.Close()
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestFetchByTokenAndReset(t *testing.T) {
+	svcCtx, cleanup := setupFetchTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	owner := repository.User{Email: "links@test.dev", DisplayName: "Links", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+
+	tpl, err := svcCtx.Repositories.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Plain",
+		ClientType: "clash",
+		Format:     "go_template",
+		Content:    "token={{ .subscription.token }}",
+	})
+	require.NoError(t, err)
+	_, _, err = svcCtx.Repositories.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+
+	token, err := repository.GenerateSubscriptionToken()
+	require.NoError(t, err)
+	sub, err := svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+		UserID:     owner.ID,
+		Name:       "Main",
+		TemplateID: tpl.ID,
+		Token:      token,
+		ExpiresAt:  now.Add(24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	fetch := NewFetchLogic(ctx, svcCtx)
+	rendered, _, err := fetch.Fetch(&types.SubscribeRequest{Token: token}, "203.0.113.9", "clash-verge/1.0")
+	require.NoError(t, err)
+	require.Equal(t, "token="+token, rendered.Content)
+
+	// 命名访问链接记录各自的访问信息。
+	userCtx := security.WithUser(ctx, security.UserClaims{ID: owner.ID, Email: owner.Email, Roles: owner.Roles})
+	tokens := usersub.NewTokensLogic(userCtx, svcCtx)
+	phone, err := tokens.Create(&types.UserCreateSubscriptionTokenRequest{SubscriptionID: sub.ID, Name: "phone"})
+	require.NoError(t, err)
+	_, err = tokens.Create(&types.UserCreateSubscriptionTokenRequest{SubscriptionID: sub.ID, Name: "phone"})
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	for _, ip := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.1"} {
+		_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: phone.Token}, ip, "Shadowrocket/2070")
+		require.NoError(t, err)
+	}
+	list, err := tokens.List(&types.UserSubscriptionTokensRequest{SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.Len(t, list.Tokens, 1)
+	require.Equal(t, int64(3), list.Tokens[0].AccessCount)
+	require.Equal(t, "198.51.100.1", list.Tokens[0].LastIP)
+	require.Equal(t, "Shadowrocket/2070", list.Tokens[0].LastUserAgent)
+	require.Equal(t, []string{"198.51.100.1", "198.51.100.2"}, list.Tokens[0].RecentIPs)
+	require.NotNil(t, list.Tokens[0].LastAccessAt)
+
+	// 重置后旧链接失效，缓存的渲染结果被清除。
+	reset, err := tokens.Reset(&types.UserResetSubscriptionTokenRequest{SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.NotEqual(t, token, reset.Token)
+
+	// 重置访问令牌不影响节点代理凭据，凭据需单独轮换。
+	current, err := svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, sub.ProxyUUID)
+	require.Equal(t, sub.ProxyUUID, current.ProxyUUID)
+	rotated, err := tokens.ResetProxyUUID(&types.UserResetSubscriptionProxyUUIDRequest{SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.NotEmpty(t, rotated.ProxyUUID)
+	require.NotEqual(t, sub.ProxyUUID, rotated.ProxyUUID)
+
+	_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: token}, "203.0.113.9", "clash-verge/1.0")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+	rendered, _, err = fetch.Fetch(&types.SubscribeRequest{Token: reset.Token}, "203.0.113.9", "clash-verge/1.0")
+	require.NoError(t, err)
+	require.Equal(t, "token="+reset.Token, rendered.Content)
+	rendered, _, err = fetch.Fetch(&types.SubscribeRequest{Token: phone.Token}, "198.51.100.1", "Shadowrocket/2070")
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(rendered.Content, reset.Token))
+
+	revoked, err := tokens.Revoke(&types.UserRevokeSubscriptionTokenRequest{SubscriptionID: sub.ID, TokenID: phone.ID})
+	require.NoError(t, err)
+	require.Empty(t, revoked.Tokens)
+	_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: phone.Token}, "198.51.100.1", "Shadowrocket/2070")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	strangerCtx := security.WithUser(ctx, security.UserClaims{ID: owner.ID + 1, Roles: []string{"user"}})
+	_, err = usersub.NewTokensLogic(strangerCtx, svcCtx).Reset(&types.UserResetSubscriptionTokenRequest{SubscriptionID: sub.ID})
+	require.ErrorIs(t, err, repository.ErrForbidden)
+
+	_, err = svcCtx.Repositories.Subscription.TransitionStatus(ctx, sub.ID, repository.SubscriptionStatusSuspended, repository.SubscriptionReasonAdmin)
+	require.NoError(t, err)
+	_, _, err = fetch.Fetch(&types.SubscribeRequest{Token: reset.Token}, "203.0.113.9", "clash-verge/1.0")
+	require.ErrorIs(t, err, repository.ErrForbidden)
+}
+
+func TestFetchRoutesByUserAgent(t *testing.T) {
+	svcCtx, cleanup := setupFetchTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	publish := func(name, clientType string, isDefault bool) repository.SubscriptionTemplate {
+		tpl, err := svcCtx.Repositories.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+			Name:       name,
+			ClientType: clientType,
+			Content:    name,
+			IsDefault:  isDefault,
+		})
+		require.NoError(t, err)
+		tpl, _, err = svcCtx.Repositories.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+		require.NoError(t, err)
+		return tpl
+	}
+	own := publish("own", "v2rayn", false)
+	publish("clash-default", "clash", true)
+	publish("sing-default", "sing-box", true)
+	chosen := publish("clash-chosen", "clash", false)
+
+	owner := repository.User{Email: "routing@test.dev", DisplayName: "Routing", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&owner).Error)
+	token, err := repository.GenerateSubscriptionToken()
+	require.NoError(t, err)
+	_, err = svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+		UserID:     owner.ID,
+		Name:       "Routed",
+		TemplateID: own.ID,
+		Token:      token,
+		ExpiresAt:  now.Add(24 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	fetch := NewFetchLogic(ctx, svcCtx)
+	content := func(userAgent string) string {
+		rendered, _, err := fetch.Fetch(&types.SubscribeRequest{Token: token}, "203.0.113.9", userAgent)
+		require.NoError(t, err)
+		return rendered.Content
+	}
+
+	// 没有管理员规则时，内置识别的 clash-meta 与 stash 客户端没有同类型模板，回退到 clash 默认模板。
+	require.Equal(t, "clash-default", content("clash-verge/1.0"))
+	require.Equal(t, "clash-default", content("mihomo/1.18"))
+	require.Equal(t, "clash-default", content("Stash/2.4"))
+
+	_, err = svcCtx.Repositories.ClientTypeRule.Create(ctx, repository.ClientTypeRule{Pattern: `^clash-verge/`, ClientType: "clash", Enabled: true})
+	require.NoError(t, err)
+
+	// 管理员规则先于内置规则，命中的客户端类型使用其默认模板。
+	require.Equal(t, "clash-default", content("clash-verge/1.0"))
+	require.Equal(t, "sing-default", content("SFA/1.8.0 (sing-box 1.8.0)"))
+	// 未识别或没有默认模板的客户端使用订阅自身模板，配置回退类型后改用该类型的默认模板。
+	require.Equal(t, "own", content("okhttp/4.9"))
+	svcCtx.Config.Template.DefaultClientType = "clash"
+	require.Equal(t, "clash-default", content("okhttp/4.9"))
+	require.Equal(t, "own", content("v2rayN/6.23"))
+
+	// 显式指定的模板不受路由影响。
+	rendered, _, err := fetch.Fetch(&types.SubscribeRequest{Token: token, TemplateID: own.ID}, "203.0.113.9", "clash-verge/1.0")
+	require.NoError(t, err)
+	require.Equal(t, "own", rendered.Content)
+
+	var logged int64
+	require.NoError(t, svcCtx.DB.Model(&repository.SubscriptionAccessLog{}).Where("client_type = ?", "clash").Count(&logged).Error)
+	require.Equal(t, int64(2), logged)
+	require.NoError(t, svcCtx.DB.Model(&repository.SubscriptionAccessLog{}).Where("client_type = ?", "clash-meta").Count(&logged).Error)
+	require.Equal(t, int64(2), logged)
+
+	// 订阅可选模板中有匹配客户端类型的模板时优先于默认模板。
+	token2, err := repository.GenerateSubscriptionToken()
+	require.NoError(t, err)
+	_, err = svcCtx.Repositories.Subscription.Create(ctx, repository.Subscription{
+		UserID:               owner.ID,
+		Name:                 "Chosen",
+		TemplateID:           own.ID,
+		AvailableTemplateIDs: []uint64{chosen.ID},
+		Token:                token2,
+		ExpiresAt:            now.Add(24 * time.Hour),
+	})
+	require.NoError(t, err)
+	rendered, _, err = fetch.Fetch(&types.SubscribeRequest{Token: token2}, "203.0.113.9", "clash-verge/1.0")
+	require.NoError(t, err)
+	require.Equal(t, "clash-chosen", rendered.Content)
+}
@@ -0,0 +1,539 @@
+package subscriptionutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// BundleVersion 模板包格式版本，导入时拒绝其他版本。
+const BundleVersion = 1
+
+// 模板包编码格式。
+const (
+	BundleFormatJSON = "json"
+	BundleFormatYAML = "yaml"
+)
+
+// 模板包导入动作。
+const (
+	BundleActionCreate    = "create"
+	BundleActionUpdate    = "update"
+	BundleActionUnchanged = "unchanged"
+)
+
+// bundleChangelog 导入后自动发布时写入的变更说明。
+const bundleChangelog = "导入模板包"
+
+// TemplateBundle 可在环境间迁移的模板包，按 (client_type, name) 识别模板。
+type TemplateBundle struct {
+	Version    int              `json:"version" yaml:"version"`
+	ExportedAt time.Time        `json:"exported_at" yaml:"exported_at"`
+	Templates  []BundleTemplate `json:"templates" yaml:"templates"`
+}
+
+// BundleTemplate 模板草稿及可选的发布历史。
+type BundleTemplate struct {
+	Name        string                    `json:"name" yaml:"name"`
+	Description string                    `json:"description,omitempty" yaml:"description,omitempty"`
+	ClientType  string                    `json:"client_type" yaml:"client_type"`
+	Format      string                    `json:"format" yaml:"format"`
+	Content     string                    `json:"content,omitempty" yaml:"content,omitempty"`
+	Variables   map[string]BundleVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+	IsDefault   bool                      `json:"is_default,omitempty" yaml:"is_default,omitempty"`
+	// Version 为导出时的发布版本，仅供参考，导入时不使用。
+	Version uint32          `json:"version,omitempty" yaml:"version,omitempty"`
+	History []BundleHistory `json:"history,omitempty" yaml:"history,omitempty"`
+}
+
+// BundleVariable 模板变量声明。
+type BundleVariable struct {
+	ValueType    string `json:"value_type" yaml:"value_type"`
+	Required     bool   `json:"required,omitempty" yaml:"required,omitempty"`
+	Description  string `json:"description,omitempty" yaml:"description,omitempty"`
+	DefaultValue any    `json:"default_value,omitempty" yaml:"default_value,omitempty"`
+}
+
+// BundleHistory 一次发布的快照。
+type BundleHistory struct {
+	Version     uint32                    `json:"version" yaml:"version"`
+	Format      string                    `json:"format" yaml:"format"`
+	Content     string                    `json:"content,omitempty" yaml:"content,omitempty"`
+	Variables   map[string]BundleVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+	Changelog   string                    `json:"changelog,omitempty" yaml:"changelog,omitempty"`
+	PublishedAt time.Time                 `json:"published_at" yaml:"published_at"`
+	PublishedBy string                    `json:"published_by,omitempty" yaml:"published_by,omitempty"`
+}
+
+// ExportOptions 控制模板包导出范围。
+type ExportOptions struct {
+	// ClientType 为空时导出全部模板。
+	ClientType     string
+	IncludeHistory bool
+}
+
+// ImportOptions 控制模板包导入。
+type ImportOptions struct {
+	DryRun bool
+	// Publish 为 true 时，导入后草稿与最新发布快照不同（或从未发布）的模板会自动发布。
+	Publish  bool
+	Operator string
+	Limits   subtemplate.Limits
+}
+
+// BundleImportResult 单个模板的导入结果，DryRun 时描述将要执行的动作。
+type BundleImportResult struct {
+	ClientType      string
+	Name            string
+	TemplateID      uint64
+	Action          string
+	Published       bool
+	HistoryImported int
+	ContentDiff     string
+	Variables       []VariableChange
+}
+
+// NormalizeBundleFormat 规范化模板包编码格式，yml 视为 yaml。
+func NormalizeBundleFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", BundleFormatJSON:
+		return BundleFormatJSON, nil
+	case BundleFormatYAML, "yml":
+		return BundleFormatYAML, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported bundle format %q", repository.ErrInvalidArgument, format)
+	}
+}
+
+// EncodeBundle 按 json 或 yaml 编码模板包。
+func EncodeBundle(bundle TemplateBundle, format string) ([]byte, error) {
+	format, err := NormalizeBundleFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == BundleFormatYAML {
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(bundle); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// DecodeBundle 解析模板包，format 为空时按内容判断 json 或 yaml；未知字段与不支持的版本按参数错误返回。
+func DecodeBundle(data []byte, format string) (TemplateBundle, error) {
+	if strings.TrimSpace(format) == "" {
+		format = BundleFormatYAML
+		if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+			format = BundleFormatJSON
+		}
+	}
+	format, err := NormalizeBundleFormat(format)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	var bundle TemplateBundle
+	if format == BundleFormatYAML {
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&bundle)
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&bundle)
+	}
+	if err != nil {
+		return TemplateBundle{}, fmt.Errorf("%w: decode bundle: %v", repository.ErrInvalidArgument, err)
+	}
+	if bundle.Version != BundleVersion {
+		return TemplateBundle{}, fmt.Errorf("%w: unsupported bundle version %d (expected %d)", repository.ErrInvalidArgument, bundle.Version, BundleVersion)
+	}
+
+	return bundle, nil
+}
+
+// ExportBundle 导出模板草稿、变量以及可选的发布历史，按 client_type 与名称排序。
+func ExportBundle(ctx context.Context, repos *repository.Repositories, opts ExportOptions, now time.Time) (TemplateBundle, error) {
+	var templates []repository.SubscriptionTemplate
+	for page := 1; ; page++ {
+		items, total, err := repos.SubscriptionTemplate.List(ctx, repository.ListTemplatesOptions{
+			Page:          page,
+			PerPage:       100,
+			Sort:          "created_at",
+			Direction:     "asc",
+			ClientType:    opts.ClientType,
+			IncludeDrafts: true,
+		})
+		if err != nil {
+			return TemplateBundle{}, err
+		}
+		templates = append(templates, items...)
+		if len(items) == 0 || int64(len(templates)) >= total {
+			break
+		}
+	}
+	sort.SliceStable(templates, func(i, j int) bool {
+		if templates[i].ClientType != templates[j].ClientType {
+			return templates[i].ClientType < templates[j].ClientType
+		}
+		return templates[i].Name < templates[j].Name
+	})
+
+	bundle := TemplateBundle{
+		Version:    BundleVersion,
+		ExportedAt: now.UTC().Truncate(time.Second),
+		Templates:  make([]BundleTemplate, 0, len(templates)),
+	}
+	for _, tpl := range templates {
+		item := BundleTemplate{
+			Name:        tpl.Name,
+			Description: tpl.Description,
+			ClientType:  tpl.ClientType,
+			Format:      tpl.Format,
+			Content:     tpl.Content,
+			Variables:   toBundleVariables(tpl.Variables),
+			IsDefault:   tpl.IsDefault,
+			Version:     tpl.Version,
+		}
+		if opts.IncludeHistory {
+			history, err := repos.SubscriptionTemplate.History(ctx, tpl.ID)
+			if err != nil {
+				return TemplateBundle{}, err
+			}
+			// 仓储按版本倒序返回，包内按版本升序排列便于阅读。
+			for i := len(history) - 1; i >= 0; i-- {
+				entry := history[i]
+				item.History = append(item.History, BundleHistory{
+					Version:     entry.Version,
+					Format:      entry.Format,
+					Content:     entry.Content,
+					Variables:   toBundleVariables(entry.Variables),
+					Changelog:   entry.Changelog,
+					PublishedAt: entry.PublishedAt.UTC(),
+					PublishedBy: entry.PublishedBy,
+				})
+			}
+		}
+		bundle.Templates = append(bundle.Templates, item)
+	}
+
+	return bundle, nil
+}
+
+// bundleEntry 校验并规范化后的待导入模板。
+type bundleEntry struct {
+	draft   repository.SubscriptionTemplate
+	history []repository.SubscriptionTemplateHistory
+}
+
+// ImportBundle 按 (client_type, name) 幂等导入模板包：不存在的模板新建并写入历史，已存在的模板仅更新草稿，
+// 历史只随新建模板导入。整个模板包先完成校验，任一模板不合法时不会写入任何数据。
+func ImportBundle(ctx context.Context, repos *repository.Repositories, bundle TemplateBundle, opts ImportOptions, now time.Time) ([]BundleImportResult, error) {
+	entries, err := prepareBundle(bundle, opts, now)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BundleImportResult, 0, len(entries))
+	for _, entry := range entries {
+		result, err := importBundleEntry(ctx, repos, entry, opts)
+		if err != nil {
+			return results, fmt.Errorf("template %s/%s: %w", entry.draft.ClientType, entry.draft.Name, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func prepareBundle(bundle TemplateBundle, opts ImportOptions, now time.Time) ([]bundleEntry, error) {
+	if bundle.Version != BundleVersion {
+		return nil, fmt.Errorf("%w: unsupported bundle version %d (expected %d)", repository.ErrInvalidArgument, bundle.Version, BundleVersion)
+	}
+
+	seen := make(map[string]struct{}, len(bundle.Templates))
+	defaults := make(map[string]string)
+	entries := make([]bundleEntry, 0, len(bundle.Templates))
+	for i, item := range bundle.Templates {
+		name := strings.TrimSpace(item.Name)
+		clientType := strings.ToLower(strings.TrimSpace(item.ClientType))
+		if name == "" || clientType == "" {
+			return nil, fmt.Errorf("%w: templates[%d]: name and client_type are required", repository.ErrInvalidArgument, i)
+		}
+		key := clientType + "/" + strings.ToLower(name)
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%w: templates[%d]: duplicate template %s/%s", repository.ErrInvalidArgument, i, clientType, name)
+		}
+		seen[key] = struct{}{}
+		if item.IsDefault {
+			if other, ok := defaults[clientType]; ok {
+				return nil, fmt.Errorf("%w: templates %q and %q are both default for %s", repository.ErrInvalidArgument, other, name, clientType)
+			}
+			defaults[clientType] = name
+		}
+
+		vars, err := fromBundleVariables(item.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("%w: template %s/%s: %v", repository.ErrInvalidArgument, clientType, name, err)
+		}
+		format := strings.ToLower(strings.TrimSpace(item.Format))
+		if format == "" {
+			format = subtemplate.FormatGoTemplate
+		}
+		draft := repository.SubscriptionTemplate{
+			Name:        name,
+			Description: strings.TrimSpace(item.Description),
+			ClientType:  clientType,
+			Format:      format,
+			Content:     item.Content,
+			Variables:   vars,
+			IsDefault:   item.IsDefault,
+		}
+		if err := ValidateTemplate(draft.Format, draft.Content, draft.Variables); err != nil {
+			return nil, fmt.Errorf("template %s/%s: %w", clientType, name, err)
+		}
+		if opts.Publish {
+			if err := CheckPublishable(draft, opts.Limits, now); err != nil {
+				return nil, fmt.Errorf("template %s/%s: %w", clientType, name, err)
+			}
+		}
+
+		entry := bundleEntry{draft: draft}
+		versions := make(map[uint32]struct{}, len(item.History))
+		for _, h := range item.History {
+			if h.Version == 0 {
+				return nil, fmt.Errorf("%w: template %s/%s: history version must be positive", repository.ErrInvalidArgument, clientType, name)
+			}
+			if _, ok := versions[h.Version]; ok {
+				return nil, fmt.Errorf("%w: template %s/%s: duplicate history version %d", repository.ErrInvalidArgument, clientType, name, h.Version)
+			}
+			versions[h.Version] = struct{}{}
+			historyFormat := strings.ToLower(strings.TrimSpace(h.Format))
+			if !subtemplate.SupportedFormat(historyFormat) {
+				return nil, fmt.Errorf("%w: template %s/%s: history v%d has unsupported format %q", repository.ErrInvalidArgument, clientType, name, h.Version, h.Format)
+			}
+			historyVars, err := fromBundleVariables(h.Variables)
+			if err != nil {
+				return nil, fmt.Errorf("%w: template %s/%s: history v%d: %v", repository.ErrInvalidArgument, clientType, name, h.Version, err)
+			}
+			publishedAt := h.PublishedAt
+			if publishedAt.IsZero() {
+				publishedAt = now
+			}
+			entry.history = append(entry.history, repository.SubscriptionTemplateHistory{
+				Version:     h.Version,
+				Format:      historyFormat,
+				Content:     h.Content,
+				Variables:   historyVars,
+				Changelog:   h.Changelog,
+				PublishedAt: publishedAt.UTC(),
+				PublishedBy: h.PublishedBy,
+			})
+		}
+		sort.Slice(entry.history, func(i, j int) bool { return entry.history[i].Version < entry.history[j].Version })
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func importBundleEntry(ctx context.Context, repos *repository.Repositories, entry bundleEntry, opts ImportOptions) (BundleImportResult, error) {
+	draft := entry.draft
+	result := BundleImportResult{ClientType: draft.ClientType, Name: draft.Name}
+
+	existing, err := repos.SubscriptionTemplate.GetByName(ctx, draft.ClientType, draft.Name)
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return createBundleEntry(ctx, repos, entry, opts, result)
+	case err != nil:
+		return result, err
+	}
+
+	result.TemplateID = existing.ID
+	result.Action = BundleActionUnchanged
+	if !sameContent(existing, draft) || existing.Name != draft.Name || existing.Description != draft.Description || existing.IsDefault != draft.IsDefault {
+		result.Action = BundleActionUpdate
+	}
+	if result.ContentDiff, err = ContentDiff("current", "bundle", existing.Content, draft.Content); err != nil {
+		return result, err
+	}
+	result.Variables = DiffVariables(existing.Variables, draft.Variables)
+
+	if opts.Publish {
+		if existing.Version == 0 {
+			result.Published = true
+		} else {
+			published, err := repos.SubscriptionTemplate.GetPublished(ctx, existing.ID)
+			if err != nil {
+				return result, err
+			}
+			result.Published = !sameContent(published, draft)
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if result.Action == BundleActionUpdate {
+		variables := draft.Variables
+		if variables == nil {
+			// 空映射表示清空变量，nil 会被仓储视为不修改。
+			variables = map[string]repository.TemplateVariable{}
+		}
+		if _, err := repos.SubscriptionTemplate.Update(ctx, existing.ID, repository.UpdateSubscriptionTemplateInput{
+			Name:        &draft.Name,
+			Description: &draft.Description,
+			Format:      &draft.Format,
+			Content:     &draft.Content,
+			Variables:   variables,
+			IsDefault:   &draft.IsDefault,
+		}); err != nil {
+			return result, err
+		}
+	}
+	if result.Published {
+		if err := publishBundleEntry(ctx, repos, existing.ID, opts); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func createBundleEntry(ctx context.Context, repos *repository.Repositories, entry bundleEntry, opts ImportOptions, result BundleImportResult) (BundleImportResult, error) {
+	draft := entry.draft
+	result.Action = BundleActionCreate
+	result.HistoryImported = len(entry.history)
+
+	var err error
+	if result.ContentDiff, err = ContentDiff("current", "bundle", "", draft.Content); err != nil {
+		return result, err
+	}
+	result.Variables = DiffVariables(nil, draft.Variables)
+
+	if opts.Publish {
+		result.Published = true
+		if n := len(entry.history); n > 0 {
+			latest := entry.history[n-1]
+			result.Published = !sameContent(repository.SubscriptionTemplate{
+				Format:    latest.Format,
+				Content:   latest.Content,
+				Variables: latest.Variables,
+			}, draft)
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:        draft.Name,
+		Description: draft.Description,
+		ClientType:  draft.ClientType,
+		Format:      draft.Format,
+		Content:     draft.Content,
+		Variables:   draft.Variables,
+		IsDefault:   draft.IsDefault,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.TemplateID = tpl.ID
+
+	if len(entry.history) > 0 {
+		if _, err := repos.SubscriptionTemplate.ImportHistory(ctx, tpl.ID, entry.history); err != nil {
+			return result, err
+		}
+	}
+	if result.Published {
+		if err := publishBundleEntry(ctx, repos, tpl.ID, opts); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func publishBundleEntry(ctx context.Context, repos *repository.Repositories, id uint64, opts ImportOptions) error {
+	_, _, err := repos.SubscriptionTemplate.Publish(ctx, id, repository.PublishSubscriptionTemplateInput{
+		Changelog: bundleChangelog,
+		Operator:  opts.Operator,
+	})
+	return err
+}
+
+// sameContent 比较参与渲染的格式、内容与变量。
+func sameContent(a, b repository.SubscriptionTemplate) bool {
+	if a.Format != b.Format || a.Content != b.Content {
+		return false
+	}
+	if len(a.Variables) == 0 && len(b.Variables) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a.Variables, b.Variables)
+}
+
+func toBundleVariables(vars map[string]repository.TemplateVariable) map[string]BundleVariable {
+	if len(vars) == 0 {
+		return nil
+	}
+	result := make(map[string]BundleVariable, len(vars))
+	for name, v := range vars {
+		result[name] = BundleVariable{
+			ValueType:    v.ValueType,
+			Required:     v.Required,
+			Description:  v.Description,
+			DefaultValue: v.DefaultValue,
+		}
+	}
+	return result
+}
+
+// fromBundleVariables 转换变量声明，默认值经 JSON 往返后与数据库读出的类型一致（如 YAML 整数转为 float64）。
+func fromBundleVariables(vars map[string]BundleVariable) (map[string]repository.TemplateVariable, error) {
+	if len(vars) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]repository.TemplateVariable, len(vars))
+	for name, v := range vars {
+		var value any
+		if v.DefaultValue != nil {
+			data, err := json.Marshal(v.DefaultValue)
+			if err != nil {
+				return nil, fmt.Errorf("variable %s: %v", name, err)
+			}
+			if err := json.Unmarshal(data, &value); err != nil {
+				return nil, fmt.Errorf("variable %s: %v", name, err)
+			}
+		}
+		result[name] = repository.TemplateVariable{
+			ValueType:    v.ValueType,
+			Required:     v.Required,
+			Description:  v.Description,
+			DefaultValue: value,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+package subscriptionutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+func TestTemplateBundleRoundTrip(t *testing.T) {
+	_, repos, cleanup := setupRenderTest(t)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	limits := subtemplate.Limits{Timeout: time.Second, MaxBytes: 1 << 20}
+
+	clash, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Clash",
+		ClientType: "clash",
+		Content:    "v1 {{ .vars.port }}",
+		Variables:  map[string]repository.TemplateVariable{"vars.port": {ValueType: "number", DefaultValue: 7890}},
+		IsDefault:  true,
+	})
+	require.NoError(t, err)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, clash.ID, repository.PublishSubscriptionTemplateInput{Changelog: "first", Operator: "alice"})
+	require.NoError(t, err)
+	v2 := "v2 {{ .vars.port }}"
+	_, err = repos.SubscriptionTemplate.Update(ctx, clash.ID, repository.UpdateSubscriptionTemplateInput{Content: &v2})
+	require.NoError(t, err)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, clash.ID, repository.PublishSubscriptionTemplateInput{Changelog: "second", Operator: "bob"})
+	require.NoError(t, err)
+	draft := "draft {{ .vars.port }}"
+	_, err = repos.SubscriptionTemplate.Update(ctx, clash.ID, repository.UpdateSubscriptionTemplateInput{Content: &draft})
+	require.NoError(t, err)
+	_, err = repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Sing",
+		ClientType: "sing-box",
+		Content:    "sing {{ .template.name }}",
+	})
+	require.NoError(t, err)
+
+	bundle, err := ExportBundle(ctx, repos, ExportOptions{IncludeHistory: true}, now)
+	require.NoError(t, err)
+	require.Len(t, bundle.Templates, 2)
+	require.Equal(t, "Clash", bundle.Templates[0].Name)
+	require.Len(t, bundle.Templates[0].History, 2)
+	require.Equal(t, uint32(1), bundle.Templates[0].History[0].Version)
+
+	encoded, err := EncodeBundle(bundle, BundleFormatYAML)
+	require.NoError(t, err)
+	cleanup()
+
+	// 在空库中导入，YAML 解码出的整数默认值需与数据库读出的值一致才能保持幂等。
+	_, repos, cleanup = setupRenderTest(t)
+	defer cleanup()
+
+	decoded, err := DecodeBundle(encoded, "")
+	require.NoError(t, err)
+
+	results, err := ImportBundle(ctx, repos, decoded, ImportOptions{DryRun: true}, now)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, BundleActionCreate, results[0].Action)
+	require.Equal(t, 2, results[0].HistoryImported)
+	_, err = repos.SubscriptionTemplate.GetByName(ctx, "clash", "clash")
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	results, err = ImportBundle(ctx, repos, decoded, ImportOptions{}, now)
+	require.NoError(t, err)
+	require.Equal(t, BundleActionCreate, results[0].Action)
+
+	imported, err := repos.SubscriptionTemplate.GetByName(ctx, "CLASH", "clash")
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), imported.Version)
+	require.Equal(t, "bob", imported.LastPublishedBy)
+	require.True(t, imported.IsDefault)
+	require.Equal(t, draft, imported.Content)
+	published, err := repos.SubscriptionTemplate.GetPublished(ctx, imported.ID)
+	require.NoError(t, err)
+	require.Equal(t, v2, published.Content)
+
+	results, err = ImportBundle(ctx, repos, decoded, ImportOptions{}, now)
+	require.NoError(t, err)
+	for _, result := range results {
+		require.Equal(t, BundleActionUnchanged, result.Action, result.Name)
+		require.Empty(t, result.Variables, result.Name)
+	}
+
+	// 修改后的模板包：dry-run 给出内容 diff，--publish 发布与最新快照不同的草稿。
+	decoded.Templates[1].Content = "sing v2 {{ .template.name }}"
+	results, err = ImportBundle(ctx, repos, decoded, ImportOptions{DryRun: true, Publish: true, Limits: limits}, now)
+	require.NoError(t, err)
+	require.Equal(t, BundleActionUnchanged, results[0].Action)
+	require.True(t, results[0].Published)
+	require.Equal(t, BundleActionUpdate, results[1].Action)
+	require.Contains(t, results[1].ContentDiff, "+sing v2 {{ .template.name }}")
+
+	_, err = ImportBundle(ctx, repos, decoded, ImportOptions{Publish: true, Operator: "importer", Limits: limits}, now)
+	require.NoError(t, err)
+	sing, err := repos.SubscriptionTemplate.GetByName(ctx, "sing-box", "Sing")
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), sing.Version)
+	history, err := repos.SubscriptionTemplate.History(ctx, imported.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	require.Equal(t, bundleChangelog, history[0].Changelog)
+	require.Equal(t, "importer", history[0].PublishedBy)
+
+	results, err = ImportBundle(ctx, repos, decoded, ImportOptions{Publish: true, Limits: limits}, now)
+	require.NoError(t, err)
+	for _, result := range results {
+		require.Equal(t, BundleActionUnchanged, result.Action, result.Name)
+		require.False(t, result.Published, result.Name)
+	}
+
+	// 非法模板包整体拒绝。
+	decoded.Templates = append(decoded.Templates, decoded.Templates[1])
+	_, err = ImportBundle(ctx, repos, decoded, ImportOptions{}, now)
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	_, err = DecodeBundle([]byte(`{"version": 2, "templates": []}`), "")
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	_, err = DecodeBundle([]byte("version: 1\nunknown: true\n"), BundleFormatYAML)
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
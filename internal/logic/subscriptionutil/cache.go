@@ -0,0 +1,115 @@
+package subscriptionutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+	"github.com/zero-net-panel/zero-net-panel/pkg/metrics"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// renderCacheTTL 未配置 Template.RenderCacheTTL 时的渲染缓存时长。
+const renderCacheTTL = 5 * time.Minute
+
+// nodeRevisionKey 节点集合修订号，节点、接入点、分组或套餐授权变化时更新。
+const nodeRevisionKey = "znp:render:nodes:revision"
+
+// RenderCached 优先返回缓存的渲染结果，未命中时渲染并写入缓存；缓存读写失败不影响渲染。
+// 缓存键包含订阅、模板发布版本、节点集合修订号与订阅 token/代理凭据/限额摘要，
+// 因此发布模板、节点接入点变化以及订阅 token、代理凭据或限额调整后旧缓存不再命中。渲染受 limits 限制。
+func RenderCached(ctx context.Context, c cache.Cache, repos *repository.Repositories, sub repository.Subscription, templateID uint64, ttl time.Duration, limits subtemplate.Limits, now time.Time) (Rendered, error) {
+	if templateID == 0 {
+		templateID = sub.TemplateID
+	}
+
+	tpl, err := repos.SubscriptionTemplate.GetPublished(ctx, templateID)
+	if err != nil {
+		return Rendered{}, err
+	}
+	if c == nil {
+		return renderPublished(ctx, repos, sub, tpl, limits, now)
+	}
+
+	revision, err := nodeRevision(ctx, c)
+	if err != nil {
+		metrics.ObserveRenderCache("miss")
+		return renderPublished(ctx, repos, sub, tpl, limits, now)
+	}
+
+	key := renderKey(sub, tpl, revision)
+	var cached Rendered
+	if err := c.Get(ctx, key, &cached); err == nil {
+		metrics.ObserveRenderCache("hit")
+		return cached, nil
+	}
+	metrics.ObserveRenderCache("miss")
+
+	rendered, err := renderPublished(ctx, repos, sub, tpl, limits, now)
+	if err != nil {
+		return Rendered{}, err
+	}
+	if ttl <= 0 {
+		ttl = renderCacheTTL
+	}
+	_ = c.Set(ctx, key, rendered, ttl)
+	return rendered, nil
+}
+
+// InvalidateNodeRenders 更新节点集合修订号，使所有订阅的渲染缓存失效。
+func InvalidateNodeRenders(ctx context.Context, c cache.Cache) error {
+	if c == nil {
+		return nil
+	}
+	_, err := bumpNodeRevision(ctx, c)
+	return err
+}
+
+// nodeRevision 读取节点集合修订号，不存在时生成新值，避免修订号丢失后命中旧缓存。
+func nodeRevision(ctx context.Context, c cache.Cache) (string, error) {
+	var revision string
+	err := c.Get(ctx, nodeRevisionKey, &revision)
+	switch {
+	case err == nil && revision != "":
+		return revision, nil
+	case err == nil, errors.Is(err, cache.ErrNotFound):
+		return bumpNodeRevision(ctx, c)
+	default:
+		return "", err
+	}
+}
+
+func bumpNodeRevision(ctx context.Context, c cache.Cache) (string, error) {
+	revision := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := c.Set(ctx, nodeRevisionKey, revision, 0); err != nil {
+		return "", err
+	}
+	return revision, nil
+}
+
+func renderKey(sub repository.Subscription, tpl repository.SubscriptionTemplate, nodeRevision string) string {
+	return fmt.Sprintf("znp:render:subscription:%d:%d:v%d:%s:%s", sub.ID, tpl.ID, tpl.Version, nodeRevision, subscriptionDigest(sub))
+}
+
+// subscriptionDigest 摘要订阅 token、代理凭据、状态、套餐与限额，任一变化都会更换缓存键；已用流量按缓存时长刷新。
+func subscriptionDigest(sub repository.Subscription) string {
+	raw := fmt.Sprintf("%s|%s|%s|%d|%s|%d|%d|%d|%v",
+		sub.Token,
+		sub.ProxyUUID,
+		sub.Status,
+		sub.PlanID,
+		sub.PlanName,
+		sub.ExpiresAt.Unix(),
+		sub.TrafficTotalBytes,
+		sub.DevicesLimit,
+		sub.AvailableTemplateIDs,
+	)
+	hash := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(hash[:8])
+}
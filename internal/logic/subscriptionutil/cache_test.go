@@ -0,0 +1,102 @@
+package subscriptionutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+	"github.com/zero-net-panel/zero-net-panel/pkg/metrics"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+func TestRenderCachedInvalidation(t *testing.T) {
+	db, repos, cleanup := setupRenderTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	c, err := cache.New(cache.Config{Provider: "memory"})
+	require.NoError(t, err)
+
+	node := repository.Node{Name: "hk", Status: repository.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&node).Error)
+	_, err = repos.Node.RecordKernelSync(ctx, node.ID, repository.NodeKernel{Protocol: "vless", Endpoint: "hk.example.com:443", Revision: "r1"})
+	require.NoError(t, err)
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Cached",
+		ClientType: "clash",
+		Content:    `v{{ .template.version }} {{ .subscription.token }} {{ .subscription.devices_limit }}{{ range .nodes }} {{ .name }}{{ range .kernels }}@{{ .revision }}{{ end }}{{ end }}`,
+	})
+	require.NoError(t, err)
+
+	sub := repository.Subscription{ID: 1, TemplateID: tpl.ID, Token: "token-a", DevicesLimit: 2, ExpiresAt: now.Add(time.Hour)}
+
+	// 未发布的模板不写入缓存。
+	_, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+
+	hits := promtestutil.ToFloat64(metrics.SubscriptionRenderCacheTotal.WithLabelValues("hit"))
+	misses := promtestutil.ToFloat64(metrics.SubscriptionRenderCacheTotal.WithLabelValues("miss"))
+
+	rendered, err := RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v1 token-a 2 hk@r1", rendered.Content)
+
+	// 未经事件通知的节点变化不影响缓存命中。
+	require.NoError(t, db.Model(&repository.Node{}).Where("id = ?", node.ID).Update("name", "hk-renamed").Error)
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v1 token-a 2 hk@r1", rendered.Content)
+	require.Equal(t, float64(1), promtestutil.ToFloat64(metrics.SubscriptionRenderCacheTotal.WithLabelValues("hit"))-hits)
+	require.Equal(t, float64(1), promtestutil.ToFloat64(metrics.SubscriptionRenderCacheTotal.WithLabelValues("miss"))-misses)
+
+	// 接入点修订变化后更新节点修订号。
+	_, err = repos.Node.RecordKernelSync(ctx, node.ID, repository.NodeKernel{Protocol: "vless", Endpoint: "hk.example.com:443", Revision: "r2"})
+	require.NoError(t, err)
+	require.NoError(t, InvalidateNodeRenders(ctx, c))
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v1 token-a 2 hk-renamed@r2", rendered.Content)
+
+	// 发布新版本后按新版本渲染。
+	content := `v{{ .template.version }} {{ .subscription.token }}`
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{Content: &content})
+	require.NoError(t, err)
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v1 token-a 2 hk-renamed@r2", rendered.Content)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v2 token-a", rendered.Content)
+
+	// token 或限额变化使用新的缓存键。
+	sub.Token = "token-b"
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v2 token-b", rendered.Content)
+
+	content = `v{{ .template.version }} {{ .subscription.devices_limit }}`
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{Content: &content})
+	require.NoError(t, err)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v3 2", rendered.Content)
+	sub.DevicesLimit = 5
+	rendered, err = RenderCached(ctx, c, repos, sub, 0, time.Minute, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v3 5", rendered.Content)
+}
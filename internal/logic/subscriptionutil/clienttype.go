@@ -0,0 +1,189 @@
+package subscriptionutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/apierror"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// 拉取订阅的客户端类型，由 User-Agent 推断。
+const (
+	ClientTypeClashMeta    = "clash-meta"
+	ClientTypeClash        = "clash"
+	ClientTypeSingBox      = "sing-box"
+	ClientTypeV2RayN       = "v2rayn"
+	ClientTypeV2RayNG      = "v2rayng"
+	ClientTypeShadowrocket = "shadowrocket"
+	ClientTypeQuantumultX  = "quantumult-x"
+	ClientTypeSurge        = "surge"
+	ClientTypeStash        = "stash"
+	ClientTypeLoon         = "loon"
+	ClientTypeBrowser      = "browser"
+	ClientTypeUnknown      = "unknown"
+)
+
+// clientTypeRules 按顺序匹配小写 User-Agent 片段，更具体的规则需排在前面
+// （如 clash.meta 先于 clash，v2rayng 先于 v2rayn）。
+var clientTypeRules = []struct {
+	clientType string
+	needles    []string
+}{
+	{ClientTypeClashMeta, []string{"clash.meta", "clash-meta", "mihomo", "clash-verge"}},
+	{ClientTypeStash, []string{"stash"}},
+	{ClientTypeClash, []string{"clash"}},
+	{ClientTypeSingBox, []string{"sing-box", "singbox", "sfa/", "sfi/", "sfm/"}},
+	{ClientTypeV2RayNG, []string{"v2rayng"}},
+	{ClientTypeV2RayN, []string{"v2rayn"}},
+	{ClientTypeShadowrocket, []string{"shadowrocket"}},
+	{ClientTypeQuantumultX, []string{"quantumult%20x", "quantumult x", "quantumult-x", "quantumultx"}},
+	{ClientTypeSurge, []string{"surge"}},
+	{ClientTypeLoon, []string{"loon"}},
+	{ClientTypeBrowser, []string{"mozilla/"}},
+}
+
+// DetectClientType 根据内置规则由 User-Agent 推断客户端类型，无法识别时返回 unknown。
+func DetectClientType(userAgent string) string {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return ClientTypeUnknown
+	}
+	for _, rule := range clientTypeRules {
+		for _, needle := range rule.needles {
+			if strings.Contains(ua, needle) {
+				return rule.clientType
+			}
+		}
+	}
+	return ClientTypeUnknown
+}
+
+// ClientTypeMatcher 先按顺序匹配管理员维护的正则规则，均未命中时回退到内置规则。
+// nil 匹配器只使用内置规则。
+type ClientTypeMatcher struct {
+	rules []compiledClientTypeRule
+}
+
+type compiledClientTypeRule struct {
+	id         uint64
+	pattern    *regexp.Regexp
+	clientType string
+}
+
+// CompileClientTypePattern 以不区分大小写的方式编译规则正则，语法错误按 pattern 字段的参数错误返回。
+func CompileClientTypePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("(?i)" + strings.TrimSpace(pattern))
+	if err != nil {
+		return nil, apierror.Invalid(apierror.FieldError{Field: "pattern", Reason: apierror.ReasonInvalidFormat, Detail: err.Error()})
+	}
+	return re, nil
+}
+
+// NewClientTypeMatcher 按给定顺序编译已启用的规则。
+func NewClientTypeMatcher(rules []repository.ClientTypeRule) (*ClientTypeMatcher, error) {
+	matcher := &ClientTypeMatcher{rules: make([]compiledClientTypeRule, 0, len(rules))}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		re, err := CompileClientTypePattern(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("client type rule %d: %w", rule.ID, err)
+		}
+		matcher.rules = append(matcher.rules, compiledClientTypeRule{id: rule.ID, pattern: re, clientType: rule.ClientType})
+	}
+	return matcher, nil
+}
+
+// LoadClientTypeMatcher 读取全部路由规则并构造匹配器。
+func LoadClientTypeMatcher(ctx context.Context, repos *repository.Repositories) (*ClientTypeMatcher, error) {
+	rules, err := repos.ClientTypeRule.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientTypeMatcher(rules)
+}
+
+// Match 返回客户端类型及命中的规则 ID，命中内置规则或未识别时规则 ID 为 0。
+func (m *ClientTypeMatcher) Match(userAgent string) (string, uint64) {
+	ua := strings.TrimSpace(userAgent)
+	if m != nil && ua != "" {
+		for _, rule := range m.rules {
+			if rule.pattern.MatchString(ua) {
+				return rule.clientType, rule.id
+			}
+		}
+	}
+	return DetectClientType(ua), 0
+}
+
+// Detect 返回 User-Agent 对应的客户端类型。
+func (m *ClientTypeMatcher) Detect(userAgent string) string {
+	clientType, _ := m.Match(userAgent)
+	return clientType
+}
+
+// compatibleClientTypes 内置客户端类型可兼容使用的上级类型：该类型没有可用模板时按上级类型继续选择，
+// 使 clash-verge、mihomo 等识别为 clash-meta 的客户端在未配置路由规则时也能使用 clash 默认模板。
+var compatibleClientTypes = map[string]string{
+	ClientTypeClashMeta: ClientTypeClash,
+	ClientTypeStash:     ClientTypeClash,
+}
+
+// ResolveTemplateID 为未指定模板的拉取按客户端类型选择模板：
+// 订阅可用模板中有该客户端类型的已发布模板时优先使用，其次为该客户端类型的默认模板，
+// 均没有时对兼容的上级类型（如 clash-meta 对应 clash）重复上述选择，
+// 再次为 fallbackClientType 的默认模板，最后使用订阅自身的模板。
+func ResolveTemplateID(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, clientType, fallbackClientType string) (uint64, error) {
+	clientType = strings.ToLower(strings.TrimSpace(clientType))
+	if clientType != "" && clientType != ClientTypeUnknown {
+		candidates := make([]repository.SubscriptionTemplate, 0, 1+len(sub.AvailableTemplateIDs))
+		for _, id := range append([]uint64{sub.TemplateID}, sub.AvailableTemplateIDs...) {
+			if id == 0 {
+				continue
+			}
+			tpl, err := repos.SubscriptionTemplate.Get(ctx, id)
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return 0, err
+			}
+			candidates = append(candidates, tpl)
+		}
+
+		for current := clientType; current != ""; current = compatibleClientTypes[current] {
+			for _, tpl := range candidates {
+				if tpl.Version > 0 && strings.EqualFold(tpl.ClientType, current) {
+					return tpl.ID, nil
+				}
+			}
+			if id, ok, err := defaultTemplateID(ctx, repos, current); err != nil || ok {
+				return id, err
+			}
+		}
+	}
+
+	if fallback := strings.ToLower(strings.TrimSpace(fallbackClientType)); fallback != "" {
+		if id, ok, err := defaultTemplateID(ctx, repos, fallback); err != nil || ok {
+			return id, err
+		}
+	}
+
+	return sub.TemplateID, nil
+}
+
+func defaultTemplateID(ctx context.Context, repos *repository.Repositories, clientType string) (uint64, bool, error) {
+	tpl, err := repos.SubscriptionTemplate.GetDefault(ctx, clientType)
+	if errors.Is(err, repository.ErrNotFound) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return tpl.ID, true, nil
+}
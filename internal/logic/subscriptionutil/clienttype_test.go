@@ -0,0 +1,68 @@
+package subscriptionutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+func TestDetectClientType(t *testing.T) {
+	cases := map[string]string{
+		"ClashMetaForAndroid/2.10 Clash.Meta": "clash-meta",
+		"clash-verge/v1.3.8":                  "clash-meta",
+		"ClashX/1.95":                         "clash",
+		"Stash/2.4.5 Clash/1.9.0":             "stash",
+		"SFA/1.8.0 (sing-box 1.8.0)":          "sing-box",
+		"v2rayNG/1.8.5":                       "v2rayng",
+		"v2rayN/6.23":                         "v2rayn",
+		"Quantumult%20X/1.4.1":                "quantumult-x",
+		"Surge iOS/2920":                      "surge",
+		"Loon/3.1.0":                          "loon",
+		"Mozilla/5.0 (Macintosh)":             "browser",
+		"":                                    "unknown",
+		"okhttp/4.9":                          "unknown",
+	}
+	for ua, want := range cases {
+		if got := DetectClientType(ua); got != want {
+			t.Fatalf("DetectClientType(%q) = %q, want %q", ua, got, want)
+		}
+	}
+}
+
+func TestClientTypeMatcher(t *testing.T) {
+	matcher, err := NewClientTypeMatcher([]repository.ClientTypeRule{
+		{ID: 1, Pattern: `^clash-verge/`, ClientType: "clash", Enabled: true},
+		{ID: 2, Pattern: `sing-?box`, ClientType: "singbox", Enabled: true},
+		{ID: 3, Pattern: `okhttp`, ClientType: "clash", Enabled: false},
+	})
+	if err != nil {
+		t.Fatalf("NewClientTypeMatcher: %v", err)
+	}
+
+	cases := []struct {
+		ua         string
+		clientType string
+		ruleID     uint64
+	}{
+		{"Clash-Verge/v1.3.8", "clash", 1},
+		{"SFA/1.8.0 (sing-box 1.8.0)", "singbox", 2},
+		{"okhttp/4.9", "unknown", 0},
+		{"v2rayNG/1.8.5", "v2rayng", 0},
+	}
+	for _, tc := range cases {
+		clientType, ruleID := matcher.Match(tc.ua)
+		if clientType != tc.clientType || ruleID != tc.ruleID {
+			t.Fatalf("Match(%q) = (%q, %d), want (%q, %d)", tc.ua, clientType, ruleID, tc.clientType, tc.ruleID)
+		}
+	}
+
+	var builtin *ClientTypeMatcher
+	if got := builtin.Detect("clash-verge/v1.3.8"); got != "clash-meta" {
+		t.Fatalf("nil matcher Detect = %q, want clash-meta", got)
+	}
+
+	if _, err := NewClientTypeMatcher([]repository.ClientTypeRule{{ID: 4, Pattern: `(`, ClientType: "clash", Enabled: true}}); !errors.Is(err, repository.ErrInvalidArgument) {
+		t.Fatalf("invalid pattern error = %v, want ErrInvalidArgument", err)
+	}
+}
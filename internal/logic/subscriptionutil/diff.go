@@ -0,0 +1,75 @@
+package subscriptionutil
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+// 变量差异类型。
+const (
+	VariableAdded   = "added"
+	VariableRemoved = "removed"
+	VariableChanged = "changed"
+)
+
+// VariableChange 描述单个模板变量的差异。
+type VariableChange struct {
+	Name   string
+	Change string
+	Before *repository.TemplateVariable
+	After  *repository.TemplateVariable
+}
+
+// ContentDiff 输出模板内容的统一 diff，内容相同时返回空字符串。
+func ContentDiff(fromLabel, toLabel, from, to string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(from),
+		B:        splitLines(to),
+		FromFile: fromLabel,
+		ToFile:   toLabel,
+		Context:  3,
+	})
+}
+
+// splitLines 按行切分内容，空内容视为没有任何行。
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	return difflib.SplitLines(content)
+}
+
+// DiffVariables 按变量名排序列出新增、删除与定义变化的变量。
+func DiffVariables(before, after map[string]repository.TemplateVariable) []VariableChange {
+	names := make(map[string]struct{}, len(before)+len(after))
+	for name := range before {
+		names[name] = struct{}{}
+	}
+	for name := range after {
+		names[name] = struct{}{}
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	changes := make([]VariableChange, 0)
+	for _, name := range sorted {
+		prev, hadPrev := before[name]
+		next, hasNext := after[name]
+		switch {
+		case hadPrev && !hasNext:
+			changes = append(changes, VariableChange{Name: name, Change: VariableRemoved, Before: &prev})
+		case !hadPrev && hasNext:
+			changes = append(changes, VariableChange{Name: name, Change: VariableAdded, After: &next})
+		case !reflect.DeepEqual(prev, next):
+			changes = append(changes, VariableChange{Name: name, Change: VariableChanged, Before: &prev, After: &next})
+		}
+	}
+	return changes
+}
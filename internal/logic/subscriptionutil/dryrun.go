@@ -0,0 +1,86 @@
+package subscriptionutil
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// 示例订阅使用的 token 与节点代理凭据。
+const (
+	fixtureToken     = "00112233445566778899aabbccddeeff"
+	fixtureProxyUUID = "00112233-4455-6677-8899-aabbccddeeff"
+)
+
+// RenderLimits 将模板配置转换为试渲染的沙箱限制。
+func RenderLimits(cfg config.TemplateConfig) subtemplate.Limits {
+	return subtemplate.Limits{Timeout: cfg.RenderTimeout, MaxBytes: cfg.MaxOutputBytes}
+}
+
+// FixtureContext 构造不访问数据库的示例上下文，包含 vless/trojan/shadowsocks 接入点，供试渲染与发布前检查使用。
+func FixtureContext(tpl repository.SubscriptionTemplate, now time.Time) map[string]any {
+	now = now.UTC()
+	sub := repository.Subscription{
+		Name:              "示例订阅",
+		PlanName:          "示例套餐",
+		Status:            repository.SubscriptionStatusActive,
+		Token:             fixtureToken,
+		ProxyUUID:         fixtureProxyUUID,
+		ExpiresAt:         now.Add(30 * 24 * time.Hour),
+		TrafficTotalBytes: 100 << 30,
+		TrafficUsedBytes:  10 << 30,
+		DevicesLimit:      3,
+	}
+	nodes := []repository.Node{
+		{ID: 1, Name: "示例-香港", Region: "Hong Kong", Country: "HK", Tags: []string{"asia"}, Protocols: []string{"vless", "trojan"}, Status: repository.NodeStatusOnline, SortOrder: 10, UpdatedAt: now},
+		{ID: 2, Name: "示例-东京", Region: "Tokyo", Country: "JP", Tags: []string{"asia", "streaming"}, Protocols: []string{"shadowsocks"}, Status: repository.NodeStatusOnline, SortOrder: 20, UpdatedAt: now},
+	}
+	kernels := map[uint64][]repository.NodeKernel{
+		1: {
+			{NodeID: 1, Protocol: "vless", Endpoint: "hk.example.com:443", Revision: "fixture", Status: "synced", Config: map[string]any{"network": "ws", "path": "/ws", "tls": true}},
+			{NodeID: 1, Protocol: "trojan", Endpoint: "hk.example.com:8443", Revision: "fixture", Status: "synced", Config: map[string]any{"sni": "hk.example.com"}},
+		},
+		2: {
+			{NodeID: 2, Protocol: "shadowsocks", Endpoint: "jp.example.com:8388", Revision: "fixture", Status: "synced", Config: map[string]any{"method": "aes-256-gcm"}},
+		},
+	}
+	groups := map[uint64][]string{1: {"示例分组"}, 2: {"示例分组"}}
+
+	return renderContext(sub, tpl, normalizeNodeContext(nodes, kernels, groups), now)
+}
+
+// RenderSandboxed 在沙箱限制内按模板声明的变量渲染给定上下文。
+func RenderSandboxed(tpl repository.SubscriptionTemplate, data map[string]any, limits subtemplate.Limits) (string, error) {
+	return subtemplate.RenderLimited(tpl.Format, tpl.Content, data, templateVariables(tpl.Variables), limits)
+}
+
+// DryRun 使用示例上下文执行 RenderSandboxed，发布前据此拦截无法渲染的草稿。
+func DryRun(tpl repository.SubscriptionTemplate, limits subtemplate.Limits, now time.Time) (string, error) {
+	return RenderSandboxed(tpl, FixtureContext(tpl, now), limits)
+}
+
+// CheckPublishable 校验模板变量并用示例数据试渲染，无法渲染的内容不允许发布。
+func CheckPublishable(tpl repository.SubscriptionTemplate, limits subtemplate.Limits, now time.Time) error {
+	if err := ValidateTemplate(tpl.Format, tpl.Content, tpl.Variables); err != nil {
+		return err
+	}
+	if _, err := DryRun(tpl, limits, now); err != nil {
+		var varErr *subtemplate.VariableError
+		if errors.As(err, &varErr) {
+			return err
+		}
+		return fmt.Errorf("%w: draft render failed: %v", repository.ErrInvalidArgument, err)
+	}
+	return nil
+}
+
+// PublishCheck 以 CheckPublishable 作为发布事务内的检查，供 PublishSubscriptionTemplateInput.Check 使用。
+func PublishCheck(limits subtemplate.Limits, now time.Time) func(repository.SubscriptionTemplate) error {
+	return func(tpl repository.SubscriptionTemplate) error {
+		return CheckPublishable(tpl, limits, now)
+	}
+}
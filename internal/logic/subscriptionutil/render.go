@@ -0,0 +1,226 @@
+package subscriptionutil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// Rendered 订阅渲染结果。
+type Rendered struct {
+	SubscriptionID uint64 `json:"subscription_id"`
+	TemplateID     uint64 `json:"template_id"`
+	Content        string `json:"content"`
+	ContentType    string `json:"content_type"`
+	ETag           string `json:"etag"`
+	GeneratedAt    int64  `json:"generated_at"`
+}
+
+// TemplateAllowed 判断模板是否为订阅的默认模板或可选模板。
+func TemplateAllowed(sub repository.Subscription, templateID uint64) bool {
+	if templateID == sub.TemplateID {
+		return true
+	}
+	for _, id := range sub.AvailableTemplateIDs {
+		if id == templateID {
+			return true
+		}
+	}
+	return false
+}
+
+// Render 使用指定模板最新发布的版本渲染订阅，templateID 为 0 时使用订阅默认模板；
+// 草稿改动在发布前不会下发给用户，未发布的模板返回 ErrNotFound。
+// 节点列表包含订阅套餐可使用的全部 online 节点及其协议配置，按管理员设置的排序输出；渲染受 limits 限制。
+func Render(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, templateID uint64, limits subtemplate.Limits, now time.Time) (Rendered, error) {
+	if templateID == 0 {
+		templateID = sub.TemplateID
+	}
+
+	tpl, err := repos.SubscriptionTemplate.GetPublished(ctx, templateID)
+	if err != nil {
+		return Rendered{}, err
+	}
+	return renderPublished(ctx, repos, sub, tpl, limits, now)
+}
+
+// renderPublished 在沙箱限制内使用已发布的模板快照渲染订阅。
+func renderPublished(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, tpl repository.SubscriptionTemplate, limits subtemplate.Limits, now time.Time) (Rendered, error) {
+	now = now.UTC()
+	data, err := BuildContext(ctx, repos, sub, tpl, now)
+	if err != nil {
+		return Rendered{}, err
+	}
+
+	content, err := RenderSandboxed(tpl, data, limits)
+	if err != nil {
+		return Rendered{}, err
+	}
+
+	hash := sha256.Sum256([]byte(content))
+
+	return Rendered{
+		SubscriptionID: sub.ID,
+		TemplateID:     tpl.ID,
+		Content:        content,
+		ContentType:    subtemplate.ContentType(tpl.Format),
+		ETag:           hex.EncodeToString(hash[:]),
+		GeneratedAt:    now.Unix(),
+	}, nil
+}
+
+// BuildContext 构造订阅渲染上下文：订阅信息、套餐授权的 online 节点及其接入点与模板元数据。
+func BuildContext(ctx context.Context, repos *repository.Repositories, sub repository.Subscription, tpl repository.SubscriptionTemplate, now time.Time) (map[string]any, error) {
+	nodes, err := repos.Node.ListEntitled(ctx, sub.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	nodeIDs := make([]uint64, 0, len(nodes))
+	for _, node := range nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
+	kernels, err := repos.Node.ListKernelsByNodes(ctx, nodeIDs...)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := nodeGroupNames(ctx, repos)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderContext(sub, tpl, normalizeNodeContext(nodes, kernels, groups), now), nil
+}
+
+func renderContext(sub repository.Subscription, tpl repository.SubscriptionTemplate, nodes []map[string]any, now time.Time) map[string]any {
+	return map[string]any{
+		"subscription": map[string]any{
+			"id":                      sub.ID,
+			"name":                    sub.Name,
+			"plan":                    sub.PlanName,
+			"status":                  sub.Status,
+			"token":                   sub.Token,
+			"uuid":                    sub.ProxyUUID,
+			"expires_at":              sub.ExpiresAt.Format(time.RFC3339),
+			"traffic_total_bytes":     sub.TrafficTotalBytes,
+			"traffic_used_bytes":      sub.TrafficUsedBytes,
+			"traffic_remaining_bytes": maxInt64(sub.TrafficTotalBytes-sub.TrafficUsedBytes, 0),
+			"devices_limit":           sub.DevicesLimit,
+			"available_template_ids":  sub.AvailableTemplateIDs,
+		},
+		"nodes": nodes,
+		"template": map[string]any{
+			"id":      tpl.ID,
+			"name":    tpl.Name,
+			"format":  tpl.Format,
+			"version": tpl.Version,
+		},
+		"generated_at": now.UTC().Format(time.RFC3339),
+	}
+}
+
+// nodeGroupNames 返回每个节点所属分组（含标签匹配）的名称。
+func nodeGroupNames(ctx context.Context, repos *repository.Repositories) (map[uint64][]string, error) {
+	groups, err := repos.NodeGroup.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return map[uint64][]string{}, nil
+	}
+
+	ids := make([]uint64, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.ID)
+	}
+	members, err := repos.NodeGroup.ResolveNodeIDs(ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64][]string)
+	for _, group := range groups {
+		for _, nodeID := range members[group.ID] {
+			result[nodeID] = append(result[nodeID], group.Name)
+		}
+	}
+	return result, nil
+}
+
+func normalizeNodeContext(nodes []repository.Node, kernels map[uint64][]repository.NodeKernel, groups map[uint64][]string) []map[string]any {
+	result := make([]map[string]any, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, map[string]any{
+			"id":         node.ID,
+			"name":       node.Name,
+			"region":     node.Region,
+			"country":    node.Country,
+			"tags":       node.Tags,
+			"groups":     append([]string{}, groups[node.ID]...),
+			"protocols":  node.Protocols,
+			"status":     node.Status,
+			"sort_order": node.SortOrder,
+			"kernels":    normalizeKernelContext(kernels[node.ID]),
+			"updated_at": node.UpdatedAt.Format(time.RFC3339),
+		})
+	}
+	return result
+}
+
+// normalizeKernelContext 展开节点各协议的接入点与协议配置（端口、UUID、传输层等），host/port 由 endpoint 解析。
+func normalizeKernelContext(kernels []repository.NodeKernel) []map[string]any {
+	result := make([]map[string]any, 0, len(kernels))
+	for _, kernel := range kernels {
+		host, port := splitEndpoint(kernel.Endpoint)
+		config := kernel.Config
+		if config == nil {
+			config = map[string]any{}
+		}
+		result = append(result, map[string]any{
+			"protocol": kernel.Protocol,
+			"endpoint": kernel.Endpoint,
+			"host":     host,
+			"port":     port,
+			"revision": kernel.Revision,
+			"status":   kernel.Status,
+			"config":   config,
+		})
+	}
+	return result
+}
+
+// splitEndpoint 解析 scheme://host:port 或 host:port 形式的接入点，无法解析端口时 port 为 0。
+func splitEndpoint(endpoint string) (string, int) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", 0
+	}
+	if strings.Contains(endpoint, "://") {
+		parsed, err := url.Parse(endpoint)
+		if err != nil {
+			return endpoint, 0
+		}
+		port, _ := strconv.Atoi(parsed.Port())
+		return parsed.Hostname(), port
+	}
+	host, rawPort, err := net.SplitHostPort(endpoint)
+	if err != nil {
+		return endpoint, 0
+	}
+	port, _ := strconv.Atoi(rawPort)
+	return host, port
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,224 @@
+package subscriptionutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+func setupRenderTest(t *testing.T) (*gorm.DB, *repository.Repositories, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:subscriptionrender?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return db, repos, cleanup
+}
+
+func TestRenderEntitledNodes(t *testing.T) {
+	db, repos, cleanup := setupRenderTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	createNode := func(name, status string, sortOrder int) repository.Node {
+		node := repository.Node{Name: name, Status: status, SortOrder: sortOrder, CreatedAt: now, UpdatedAt: now}
+		require.NoError(t, db.Create(&node).Error)
+		return node
+	}
+	hk := createNode("hk", repository.NodeStatusOnline, 20)
+	jp := createNode("jp", repository.NodeStatusOnline, 10)
+	createNode("us", repository.NodeStatusOnline, 0)
+	sg := createNode("sg", repository.NodeStatusMaintenance, 5)
+
+	_, err := repos.Node.RecordKernelSync(ctx, hk.ID, repository.NodeKernel{
+		Protocol: "vless",
+		Endpoint: "hk.example.com:443",
+		Config:   map[string]any{"uuid": "hk-uuid", "transport": "ws"},
+	})
+	require.NoError(t, err)
+	_, err = repos.Node.RecordKernelSync(ctx, jp.ID, repository.NodeKernel{
+		Protocol: "trojan",
+		Endpoint: "https://jp.example.com:8443/path",
+		Config:   map[string]any{"uuid": "jp-uuid"},
+	})
+	require.NoError(t, err)
+	// 维护中的节点同步后仍保持维护状态。
+	_, err = repos.Node.RecordKernelSync(ctx, sg.ID, repository.NodeKernel{Protocol: "vless", Endpoint: "sg.example.com:443"})
+	require.NoError(t, err)
+
+	plan, err := repos.Plan.Create(ctx, repository.Plan{Name: "Premium", Slug: "premium", Status: "active"})
+	require.NoError(t, err)
+	_, err = repos.Plan.ReplaceNodes(ctx, plan.ID, []uint64{hk.ID, jp.ID, sg.ID})
+	require.NoError(t, err)
+	_, err = repos.Plan.ReplaceNodes(ctx, plan.ID, []uint64{hk.ID, 9999})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Nodes",
+		ClientType: "clash",
+		Format:     "go_template",
+		Content:    `{{ range .nodes }}{{ .name }}{{ range .kernels }} {{ .protocol }}@{{ .host }}:{{ .port }}/{{ .config.uuid }}{{ end }};{{ end }}`,
+	})
+	require.NoError(t, err)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+
+	sub := repository.Subscription{ID: 1, PlanID: plan.ID, TemplateID: tpl.ID, ExpiresAt: now.Add(time.Hour)}
+	rendered, err := Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "jp trojan@jp.example.com:8443/jp-uuid;hk vless@hk.example.com:443/hk-uuid;", rendered.Content)
+
+	// 未配置节点的套餐与无套餐订阅可使用全部 online 节点。
+	sub.PlanID = 0
+	rendered, err = Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "us;jp trojan@jp.example.com:8443/jp-uuid;hk vless@hk.example.com:443/hk-uuid;", rendered.Content)
+
+	// 内置格式不需要模板内容，直接由节点上下文生成。
+	builtin, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Clash builtin",
+		ClientType: "clash",
+		Format:     "clash",
+	})
+	require.NoError(t, err)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, builtin.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+	// 代理凭据取自订阅保存的 proxy_uuid，与拉取 token 无关。
+	sub.Token = "0123456789abcdef0123456789abcdef"
+	sub.ProxyUUID = "01234567-89ab-cdef-0123-456789abcdef"
+	rendered, err = Render(ctx, repos, sub, builtin.ID, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "text/yaml; charset=utf-8", rendered.ContentType)
+	require.Contains(t, rendered.Content, "- name: jp\n    type: trojan\n    server: jp.example.com\n    port: 8443\n    password: 01234567-89ab-cdef-0123-456789abcdef")
+	require.Contains(t, rendered.Content, "uuid: 01234567-89ab-cdef-0123-456789abcdef")
+	require.NotContains(t, rendered.Content, "name: us")
+
+	_, err = repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Empty",
+		ClientType: "clash",
+		Format:     "go_template",
+	})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+}
+
+func TestRenderTemplateVariables(t *testing.T) {
+	_, repos, cleanup := setupRenderTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	vars := map[string]repository.TemplateVariable{
+		"brand": {ValueType: "string", DefaultValue: "ZNP"},
+		"note":  {ValueType: "string", Required: true},
+	}
+	content := `{{ .brand }}|{{ .subscription.plan }}|{{ .note }}`
+	require.NoError(t, ValidateTemplate("go_template", content, vars))
+
+	err := ValidateTemplate("go_template", `{{ .subscription.tokne }}`, nil)
+	var varErr *subtemplate.VariableError
+	require.ErrorAs(t, err, &varErr)
+	require.Equal(t, []string{"subscription.tokne"}, varErr.Undeclared)
+	require.ErrorIs(t, ValidateTemplate("go_template", `{{ .nodes `, nil), repository.ErrInvalidArgument)
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Vars",
+		ClientType: "clash",
+		Content:    content,
+		Variables:  vars,
+	})
+	require.NoError(t, err)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+
+	// 必填变量缺失时返回结构化错误而不是输出 <no value>。
+	sub := repository.Subscription{ID: 1, PlanName: "Premium", TemplateID: tpl.ID, ExpiresAt: now.Add(time.Hour)}
+	_, err = Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.ErrorAs(t, err, &varErr)
+	require.Equal(t, []string{"note"}, varErr.Missing)
+
+	vars["note"] = repository.TemplateVariable{ValueType: "string", Required: true, DefaultValue: "hello"}
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{Variables: vars})
+	require.NoError(t, err)
+
+	// 草稿改动发布前不影响用户渲染。
+	_, err = Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.ErrorAs(t, err, &varErr)
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+	rendered, err := Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "ZNP|Premium|hello", rendered.Content)
+}
+
+func TestRenderUsesPublishedSnapshot(t *testing.T) {
+	db, repos, cleanup := setupRenderTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	tpl, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{
+		Name:       "Snapshot",
+		ClientType: "clash",
+		Content:    "v1 {{ .template.version }}",
+	})
+	require.NoError(t, err)
+
+	// 未发布的模板不可渲染。
+	sub := repository.Subscription{ID: 1, TemplateID: tpl.ID, ExpiresAt: now.Add(time.Hour)}
+	_, err = Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, _, err = repos.SubscriptionTemplate.Publish(ctx, tpl.ID, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+	require.NoError(t, err)
+	draft := "half-edited"
+	_, err = repos.SubscriptionTemplate.Update(ctx, tpl.ID, repository.UpdateSubscriptionTemplateInput{Content: &draft})
+	require.NoError(t, err)
+
+	rendered, err := Render(ctx, repos, sub, 0, subtemplate.Limits{}, now)
+	require.NoError(t, err)
+	require.Equal(t, "v1 1", rendered.Content)
+
+	// 用户渲染同样受沙箱限制，超限时失败而不是继续输出。
+	_, err = Render(ctx, repos, sub, 0, subtemplate.Limits{MaxBytes: 2}, now)
+	require.ErrorIs(t, err, subtemplate.ErrOutputTooLarge)
+
+	// 用户只能切换到已发布的模板。
+	owner := repository.User{Email: "snapshot@test.dev", DisplayName: "Snapshot", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&owner).Error)
+	persisted, err := repos.Subscription.Create(ctx, repository.Subscription{UserID: owner.ID, Name: "Mine", TemplateID: tpl.ID, Token: "snapshot-token", ExpiresAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+	unpublished, err := repos.SubscriptionTemplate.Create(ctx, repository.CreateSubscriptionTemplateInput{Name: "Draft only", ClientType: "clash", Content: "draft"})
+	require.NoError(t, err)
+	_, err = repos.Subscription.UpdateTemplate(ctx, persisted.ID, unpublished.ID, owner.ID)
+	require.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = repos.Subscription.UpdateTemplate(ctx, persisted.ID, tpl.ID, owner.ID)
+	require.NoError(t, err)
+}
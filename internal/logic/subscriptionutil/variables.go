@@ -0,0 +1,56 @@
+package subscriptionutil
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	subtemplate "github.com/zero-net-panel/zero-net-panel/pkg/subscription/template"
+)
+
+// contextVariables 渲染上下文固定提供的变量路径，模板无需声明即可引用。
+var contextVariables = []string{
+	"subscription.id",
+	"subscription.name",
+	"subscription.plan",
+	"subscription.status",
+	"subscription.token",
+	"subscription.uuid",
+	"subscription.expires_at",
+	"subscription.traffic_total_bytes",
+	"subscription.traffic_used_bytes",
+	"subscription.traffic_remaining_bytes",
+	"subscription.devices_limit",
+	"subscription.available_template_ids",
+	"nodes",
+	"template.id",
+	"template.name",
+	"template.format",
+	"template.version",
+	"generated_at",
+}
+
+// ValidateTemplate 校验模板变量声明以及模板对上下文的引用，模板语法错误按参数错误返回。
+func ValidateTemplate(format, content string, vars map[string]repository.TemplateVariable) error {
+	err := subtemplate.Validate(format, content, templateVariables(vars), contextVariables)
+	if err == nil {
+		return nil
+	}
+	var varErr *subtemplate.VariableError
+	if errors.As(err, &varErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", repository.ErrInvalidArgument, err)
+}
+
+func templateVariables(vars map[string]repository.TemplateVariable) map[string]subtemplate.Variable {
+	result := make(map[string]subtemplate.Variable, len(vars))
+	for path, variable := range vars {
+		result[path] = subtemplate.Variable{
+			ValueType:    variable.ValueType,
+			Required:     variable.Required,
+			DefaultValue: variable.DefaultValue,
+		}
+	}
+	return result
+}
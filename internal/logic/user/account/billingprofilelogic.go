@@ -0,0 +1,81 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// BillingProfileLogic 用户开票信息。
+type BillingProfileLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewBillingProfileLogic 构造函数。
+func NewBillingProfileLogic(ctx context.Context, svcCtx *svc.ServiceContext) *BillingProfileLogic {
+	return &BillingProfileLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Get 返回开票信息，未填写时返回空资料。
+func (l *BillingProfileLogic) Get() (*types.BillingProfile, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	profile, err := l.svcCtx.Repositories.Invoice.GetBillingProfile(l.ctx, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+
+	result := toBillingProfile(profile)
+	return &result, nil
+}
+
+// Update 更新开票信息，仅影响之后开具的发票。
+func (l *BillingProfileLogic) Update(req *types.UserUpdateBillingProfileRequest) (*types.BillingProfile, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	profile, err := l.svcCtx.Repositories.Invoice.GetBillingProfile(l.ctx, user.ID)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	profile.UserID = user.ID
+
+	assign := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	assign(&profile.Name, req.Name)
+	assign(&profile.CompanyName, req.CompanyName)
+	assign(&profile.TaxID, req.TaxID)
+	assign(&profile.Email, req.Email)
+	assign(&profile.Phone, req.Phone)
+	assign(&profile.Address, req.Address)
+	assign(&profile.Country, req.Country)
+
+	saved, err := l.svcCtx.Repositories.Invoice.SaveBillingProfile(l.ctx, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toBillingProfile(saved)
+	return &result, nil
+}
@@ -35,3 +35,19 @@ func toBalanceTransactionSummary(tx repository.BalanceTransaction) types.Balance
 		CreatedAt:         tx.CreatedAt.Unix(),
 	}
 }
+
+func toBillingProfile(profile repository.BillingProfile) types.BillingProfile {
+	result := types.BillingProfile{
+		Name:        profile.Name,
+		CompanyName: profile.CompanyName,
+		TaxID:       profile.TaxID,
+		Email:       profile.Email,
+		Phone:       profile.Phone,
+		Address:     profile.Address,
+		Country:     profile.Country,
+	}
+	if !profile.UpdatedAt.IsZero() {
+		result.UpdatedAt = profile.UpdatedAt.Unix()
+	}
+	return result
+}
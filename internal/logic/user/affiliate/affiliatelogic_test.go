@@ -0,0 +1,199 @@
+package affiliate
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	adminaffiliate "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/affiliate"
+	adminorders "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/orders"
+	authlogic "github.com/zero-net-panel/zero-net-panel/internal/logic/auth"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+	"github.com/zero-net-panel/zero-net-panel/pkg/auth"
+)
+
+func setupAffiliateTestContext(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:affiliate?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		Config: config.Config{
+			Registration: config.RegistrationConfig{Enable: true},
+			Affiliate: config.AffiliateConfig{
+				Enable:            true,
+				CommissionPercent: 10,
+				MinWithdrawCents:  50,
+			},
+		},
+		DB:           db,
+		Repositories: repos,
+		Auth:         auth.NewGenerator("access-secret", "refresh-secret", time.Hour, 24*time.Hour),
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestAffiliate_ReferralCommissionLifecycle(t *testing.T) {
+	svcCtx, cleanup := setupAffiliateTestContext(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	admin := repository.User{Email: "admin-affiliate@test.local", DisplayName: "Admin", Roles: []string{"admin"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&admin).Error)
+	referrer := repository.User{Email: "referrer@test.local", DisplayName: "Referrer", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&referrer).Error)
+
+	referrerCtx := security.WithUser(ctx, security.UserClaims{ID: referrer.ID, Email: referrer.Email, Roles: []string{"user"}})
+	summary, err := NewSummaryLogic(referrerCtx, svcCtx).Summary(&types.UserAffiliateRequest{})
+	require.NoError(t, err)
+	require.Len(t, summary.InviteCode, 8)
+
+	// 无效邀请码拒绝注册。
+	_, err = authlogic.NewRegisterLogic(ctx, svcCtx).Register(&types.AuthRegisterRequest{
+		Email: "invalid@test.local", Password: "password123", InviteCode: "NOPE",
+	})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	registered, err := authlogic.NewRegisterLogic(ctx, svcCtx).Register(&types.AuthRegisterRequest{
+		Email:      "Invitee@Test.local",
+		Password:   "password123",
+		InviteCode: " " + summary.InviteCode + " ",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, registered.AccessToken)
+	require.Equal(t, "invitee@test.local", registered.User.Email)
+
+	_, err = authlogic.NewRegisterLogic(ctx, svcCtx).Register(&types.AuthRegisterRequest{
+		Email: "invitee@test.local", Password: "password123",
+	})
+	require.ErrorIs(t, err, repository.ErrConflict)
+
+	referral, err := svcCtx.Repositories.Affiliate.GetReferral(ctx, registered.User.ID)
+	require.NoError(t, err)
+	require.Equal(t, referrer.ID, referral.ReferrerID)
+
+	_, _, err = svcCtx.Repositories.Balance.ApplyTransaction(ctx, registered.User.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 5000,
+		Currency:    "CNY",
+	})
+	require.NoError(t, err)
+
+	order := repository.Order{
+		Number:        repository.GenerateOrderNumber(),
+		UserID:        registered.User.ID,
+		Status:        repository.OrderStatusPendingPayment,
+		PaymentMethod: repository.PaymentMethodExternal,
+		PaymentStatus: repository.OrderPaymentStatusPending,
+		TotalCents:    3000,
+		Currency:      "CNY",
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&order).Error)
+	require.NoError(t, svcCtx.DB.Create(&repository.OrderItem{
+		OrderID:        order.ID,
+		ItemType:       "plan",
+		ItemID:         1,
+		Name:           "Premium",
+		Quantity:       1,
+		UnitPriceCents: 3000,
+		Currency:       "CNY",
+		SubtotalCents:  3000,
+		CreatedAt:      now,
+	}).Error)
+
+	adminCtx := security.WithUser(ctx, security.UserClaims{ID: admin.ID, Email: admin.Email, Roles: []string{"admin"}})
+	_, err = adminorders.NewMarkPaidLogic(adminCtx, svcCtx).MarkPaid(&types.AdminMarkOrderPaidRequest{OrderID: order.ID, ChargeBalance: true})
+	require.NoError(t, err)
+	// 重复标记不会重复计佣。
+	_, err = adminorders.NewMarkPaidLogic(adminCtx, svcCtx).MarkPaid(&types.AdminMarkOrderPaidRequest{OrderID: order.ID})
+	require.NoError(t, err)
+
+	account, err := svcCtx.Repositories.Affiliate.GetAccount(ctx, referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(300), account.BalanceCents)
+	require.Equal(t, int64(300), account.TotalEarnedCents)
+	require.Equal(t, "CNY", account.Currency)
+
+	// 部分退款按比例冲正佣金。
+	_, err = adminorders.NewRefundLogic(adminCtx, svcCtx).Refund(&types.AdminRefundOrderRequest{OrderID: order.ID, AmountCents: 1000, Reason: "partial"})
+	require.NoError(t, err)
+	account, err = svcCtx.Repositories.Affiliate.GetAccount(ctx, referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(200), account.BalanceCents)
+	require.Equal(t, int64(200), account.TotalEarnedCents)
+
+	// 低于最低提现额或超出余额均拒绝。
+	_, err = NewWithdrawLogic(referrerCtx, svcCtx).Withdraw(&types.UserWithdrawCommissionRequest{AmountCents: 10})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+	_, err = NewWithdrawLogic(referrerCtx, svcCtx).Withdraw(&types.UserWithdrawCommissionRequest{AmountCents: 500})
+	require.ErrorIs(t, err, repository.ErrInsufficientBalance)
+
+	withdrawn, err := NewWithdrawLogic(referrerCtx, svcCtx).Withdraw(&types.UserWithdrawCommissionRequest{AmountCents: 100})
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), withdrawn.Entry.AmountCents)
+	require.Equal(t, int64(100), withdrawn.Balance.BalanceCents)
+	require.Equal(t, int64(100), withdrawn.Summary.BalanceCents)
+	require.Equal(t, int64(1), withdrawn.Summary.ReferralCount)
+
+	payout, err := NewPayoutLogic(referrerCtx, svcCtx).Create(&types.UserCreatePayoutRequest{AmountCents: 100, Account: "alipay:referrer"})
+	require.NoError(t, err)
+	require.Equal(t, repository.CommissionPayoutStatusPending, payout.Status)
+	account, err = svcCtx.Repositories.Affiliate.GetAccount(ctx, referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), account.BalanceCents)
+
+	payouts := adminaffiliate.NewPayoutsLogic(adminCtx, svcCtx)
+	rejected, err := payouts.Reject(&types.AdminReviewPayoutRequest{PayoutID: payout.ID, Note: "invalid account"})
+	require.NoError(t, err)
+	require.Equal(t, repository.CommissionPayoutStatusRejected, rejected.Status)
+	require.Equal(t, admin.Email, rejected.ReviewedBy)
+	_, err = payouts.Approve(&types.AdminReviewPayoutRequest{PayoutID: payout.ID})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	account, err = svcCtx.Repositories.Affiliate.GetAccount(ctx, referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), account.BalanceCents)
+
+	// 剩余金额全部退款后冲正全部剩余佣金，余额可为负。
+	_, err = adminorders.NewRefundLogic(adminCtx, svcCtx).Refund(&types.AdminRefundOrderRequest{OrderID: order.ID, AmountCents: 2000, Reason: "rest"})
+	require.NoError(t, err)
+	account, err = svcCtx.Repositories.Affiliate.GetAccount(ctx, referrer.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(-100), account.BalanceCents)
+	require.Equal(t, int64(0), account.TotalEarnedCents)
+
+	ledger, err := NewCommissionsLogic(referrerCtx, svcCtx).List(&types.UserListCommissionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, ledger.Entries, 6)
+}
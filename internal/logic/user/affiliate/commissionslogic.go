@@ -0,0 +1,63 @@
+package affiliate
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// CommissionsLogic 佣金流水列表。
+type CommissionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewCommissionsLogic 构造函数。
+func NewCommissionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CommissionsLogic {
+	return &CommissionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回当前用户的佣金流水。
+func (l *CommissionsLogic) List(req *types.UserListCommissionsRequest) (*types.UserCommissionListResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	entries, total, err := l.svcCtx.Repositories.Affiliate.ListEntries(l.ctx, user.ID, repository.ListCommissionEntriesOptions{
+		Page:    page,
+		PerPage: perPage,
+		Type:    req.EntryType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.CommissionEntrySummary, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, orderutil.ToCommissionEntrySummary(entry))
+	}
+
+	return &types.UserCommissionListResponse{
+		Entries: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
@@ -0,0 +1,129 @@
+package affiliate
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// PayoutLogic 佣金提现申请。
+type PayoutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewPayoutLogic 构造函数。
+func NewPayoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PayoutLogic {
+	return &PayoutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Create 提交提现申请并冻结对应佣金，驳回时退回。
+func (l *PayoutLogic) Create(req *types.UserCreatePayoutRequest) (*types.CommissionPayoutSummary, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	cfg := l.svcCtx.Config.Affiliate
+	if !cfg.Enable {
+		return nil, repository.ErrForbidden
+	}
+	account := strings.TrimSpace(req.Account)
+	if req.AmountCents <= 0 || req.AmountCents < cfg.MinWithdrawCents || account == "" {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	var payout repository.CommissionPayout
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		repos, err := repository.NewRepositories(tx)
+		if err != nil {
+			return err
+		}
+
+		commission, err := repos.Affiliate.GetAccount(l.ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if commission.BalanceCents < req.AmountCents {
+			return repository.ErrInsufficientBalance
+		}
+
+		payout, err = repos.Affiliate.CreatePayout(l.ctx, repository.CommissionPayout{
+			UserID:      user.ID,
+			AmountCents: req.AmountCents,
+			Currency:    commission.Currency,
+			Account:     account,
+			Note:        strings.TrimSpace(req.Note),
+		})
+		if err != nil {
+			return err
+		}
+
+		payoutID := payout.ID
+		_, _, err = repos.Affiliate.ApplyEntry(l.ctx, repository.CommissionEntry{
+			UserID:      user.ID,
+			Type:        repository.CommissionEntryPayout,
+			AmountCents: -req.AmountCents,
+			Currency:    commission.Currency,
+			SourceKey:   fmt.Sprintf("payout:%d", payoutID),
+			PayoutID:    &payoutID,
+			Description: fmt.Sprintf("佣金提现申请 #%d", payoutID),
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := orderutil.ToCommissionPayoutSummary(payout)
+	return &resp, nil
+}
+
+// List 返回当前用户的提现申请。
+func (l *PayoutLogic) List(req *types.UserListPayoutsRequest) (*types.CommissionPayoutListResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	userID := user.ID
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	payouts, total, err := l.svcCtx.Repositories.Affiliate.ListPayouts(l.ctx, repository.ListCommissionPayoutsOptions{
+		Page:    page,
+		PerPage: perPage,
+		Status:  req.Status,
+		UserID:  &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.CommissionPayoutSummary, 0, len(payouts))
+	for _, payout := range payouts {
+		list = append(list, orderutil.ToCommissionPayoutSummary(payout))
+	}
+
+	return &types.CommissionPayoutListResponse{
+		Payouts: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
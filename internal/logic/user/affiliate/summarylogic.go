@@ -0,0 +1,83 @@
+package affiliate
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// SummaryLogic 推广概览。
+type SummaryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewSummaryLogic 构造函数。
+func NewSummaryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SummaryLogic {
+	return &SummaryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Summary 返回邀请码（首次访问时生成）、邀请人数与佣金余额。
+func (l *SummaryLogic) Summary(_ *types.UserAffiliateRequest) (*types.AffiliateSummary, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	if !l.svcCtx.Config.Affiliate.Enable {
+		return nil, repository.ErrForbidden
+	}
+
+	summary, err := buildSummary(l.ctx, l.svcCtx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &summary, nil
+}
+
+func buildSummary(ctx context.Context, svcCtx *svc.ServiceContext, userID uint64) (types.AffiliateSummary, error) {
+	repos := svcCtx.Repositories
+
+	invite, err := repos.Affiliate.GetOrCreateInviteCode(ctx, userID)
+	if err != nil {
+		return types.AffiliateSummary{}, err
+	}
+	count, err := repos.Affiliate.CountReferrals(ctx, userID)
+	if err != nil {
+		return types.AffiliateSummary{}, err
+	}
+	account, err := repos.Affiliate.GetAccount(ctx, userID)
+	if err != nil {
+		return types.AffiliateSummary{}, err
+	}
+
+	cfg := svcCtx.Config.Affiliate
+	return types.AffiliateSummary{
+		InviteCode:        invite.Code,
+		ReferralCount:     count,
+		BalanceCents:      account.BalanceCents,
+		TotalEarnedCents:  account.TotalEarnedCents,
+		Currency:          account.Currency,
+		CommissionPercent: cfg.CommissionPercent,
+		MinWithdrawCents:  cfg.MinWithdrawCents,
+	}, nil
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -0,0 +1,120 @@
+package affiliate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// commissionBalanceTxType 佣金转入钱包时的余额流水类型。
+const commissionBalanceTxType = "commission"
+
+// WithdrawLogic 佣金转入钱包余额。
+type WithdrawLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewWithdrawLogic 构造函数。
+func NewWithdrawLogic(ctx context.Context, svcCtx *svc.ServiceContext) *WithdrawLogic {
+	return &WithdrawLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Withdraw 在同一事务内扣减佣金并入账钱包，币种不同时按汇率换算。
+func (l *WithdrawLogic) Withdraw(req *types.UserWithdrawCommissionRequest) (*types.UserWithdrawCommissionResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	cfg := l.svcCtx.Config.Affiliate
+	if !cfg.Enable {
+		return nil, repository.ErrForbidden
+	}
+	if req.AmountCents <= 0 || req.AmountCents < cfg.MinWithdrawCents {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	var (
+		entry   repository.CommissionEntry
+		balance repository.UserBalance
+	)
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		repos, err := repository.NewRepositories(tx)
+		if err != nil {
+			return err
+		}
+
+		account, err := repos.Affiliate.GetAccount(l.ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		if account.BalanceCents < req.AmountCents {
+			return repository.ErrInsufficientBalance
+		}
+		wallet, err := repos.Balance.GetBalance(l.ctx, user.ID)
+		if err != nil {
+			return err
+		}
+		credited, err := orderutil.ConvertAmount(l.ctx, repos, req.AmountCents, account.Currency, wallet.Currency)
+		if err != nil {
+			return err
+		}
+
+		balanceTx, updated, err := repos.Balance.ApplyTransaction(l.ctx, user.ID, repository.BalanceTransaction{
+			Type:        commissionBalanceTxType,
+			AmountCents: credited,
+			Currency:    wallet.Currency,
+			Description: "推广佣金转入",
+			Metadata: map[string]any{
+				"commission_amount_cents": req.AmountCents,
+				"commission_currency":     account.Currency,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		balance = updated
+
+		entry, _, err = repos.Affiliate.ApplyEntry(l.ctx, repository.CommissionEntry{
+			UserID:      user.ID,
+			Type:        repository.CommissionEntryWithdrawal,
+			AmountCents: -req.AmountCents,
+			Currency:    account.Currency,
+			SourceKey:   fmt.Sprintf("wallet:%d", balanceTx.ID),
+			Description: "佣金转入钱包余额",
+			Metadata: map[string]any{
+				"balance_transaction_id": balanceTx.ID,
+				"credited_cents":         credited,
+				"credited_currency":      balanceTx.Currency,
+			},
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := buildSummary(l.ctx, l.svcCtx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.UserWithdrawCommissionResponse{
+		Entry:   orderutil.ToCommissionEntrySummary(entry),
+		Balance: orderutil.ToBalanceSnapshot(balance),
+		Summary: summary,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package invoice
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// GetLogic 用户发票详情与下载。
+type GetLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewGetLogic 构造函数。
+func NewGetLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetLogic {
+	return &GetLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Get 返回发票详情。
+func (l *GetLogic) Get(req *types.UserGetInvoiceRequest) (*types.InvoiceDetail, error) {
+	invoice, err := l.load(req.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	detail := orderutil.ToInvoiceDetail(invoice)
+	return &detail, nil
+}
+
+// Download 渲染发票文件。
+func (l *GetLogic) Download(req *types.DownloadInvoiceRequest) (orderutil.InvoiceFile, error) {
+	invoice, err := l.load(req.InvoiceID)
+	if err != nil {
+		return orderutil.InvoiceFile{}, err
+	}
+
+	return orderutil.RenderInvoiceFile(l.ctx, l.svcCtx.Config.Invoice, l.svcCtx.Repositories, invoice, req.Format)
+}
+
+func (l *GetLogic) load(id uint64) (repository.Invoice, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return repository.Invoice{}, repository.ErrUnauthorized
+	}
+
+	invoice, err := l.svcCtx.Repositories.Invoice.Get(l.ctx, id)
+	if err != nil {
+		return repository.Invoice{}, err
+	}
+	if invoice.UserID != user.ID {
+		return repository.Invoice{}, repository.ErrNotFound
+	}
+	return invoice, nil
+}
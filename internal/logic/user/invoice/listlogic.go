@@ -0,0 +1,75 @@
+package invoice
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ListLogic 用户发票列表。
+type ListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListLogic 构造函数。
+func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
+	return &ListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回当前用户的发票与红字发票。
+func (l *ListLogic) List(req *types.UserListInvoicesRequest) (*types.InvoiceListResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	userID := user.ID
+	invoices, total, err := l.svcCtx.Repositories.Invoice.List(l.ctx, repository.ListInvoicesOptions{
+		Page:    req.Page,
+		PerPage: req.PerPage,
+		Kind:    req.Kind,
+		UserID:  &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.InvoiceDetail, 0, len(invoices))
+	for _, invoice := range invoices {
+		list = append(list, orderutil.ToInvoiceDetail(invoice))
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	return &types.InvoiceListResponse{
+		Invoices: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ListLogic 查询用户站内通知。
+type ListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewListLogic 构造函数。
+func NewListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ListLogic {
+	return &ListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回通知列表，按时间倒序。
+func (l *ListLogic) List(req *types.UserListNotificationsRequest) (*types.UserNotificationListResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrForbidden
+	}
+
+	opts := repository.ListNotificationsOptions{
+		Page:       req.Page,
+		PerPage:    req.PerPage,
+		Category:   req.Category,
+		UnreadOnly: req.UnreadOnly,
+	}
+
+	notifications, total, err := l.svcCtx.Repositories.Notification.ListByUser(l.ctx, user.ID, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]types.UserNotification, 0, len(notifications))
+	for _, n := range notifications {
+		items = append(items, toUserNotification(n))
+	}
+
+	page, perPage := normalizePage(req.Page, req.PerPage)
+	return &types.UserNotificationListResponse{
+		Notifications: items,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
+
+func normalizePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 {
+		perPage = 20
+	}
+	if perPage > 100 {
+		perPage = 100
+	}
+	return page, perPage
+}
+
+func toUserNotification(n repository.Notification) types.UserNotification {
+	item := types.UserNotification{
+		ID:        n.ID,
+		Category:  n.Category,
+		Title:     n.Title,
+		Content:   n.Content,
+		Metadata:  n.Metadata,
+		CreatedAt: n.CreatedAt.Unix(),
+	}
+	if n.ReadAt != nil {
+		readAt := n.ReadAt.Unix()
+		item.ReadAt = &readAt
+	}
+	return item
+}
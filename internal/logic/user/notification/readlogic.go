@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// ReadLogic 标记通知已读。
+type ReadLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewReadLogic 构造函数。
+func NewReadLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ReadLogic {
+	return &ReadLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Read 将通知标记为已读，重复调用保持幂等。
+func (l *ReadLogic) Read(req *types.UserReadNotificationRequest) (*types.UserNotification, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrForbidden
+	}
+
+	n, err := l.svcCtx.Repositories.Notification.MarkRead(l.ctx, req.NotificationID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toUserNotification(n)
+	return &resp, nil
+}
@@ -81,31 +81,24 @@ func (l *CreateLogic) Create(req *types.UserCreateOrderRequest) (resp *types.Use
 		}
 	}
 
-	if req.PlanID == 0 {
-		return nil, repository.ErrInvalidArgument
-	}
+	quantity := normalizeQuantity(req.Quantity)
+
+	channel := strings.TrimSpace(strings.ToLower(req.PaymentChannel))
+	returnURL := strings.TrimSpace(req.PaymentReturnURL)
 
-	plan, err := l.svcCtx.Repositories.Plan.Get(l.ctx, req.PlanID)
+	currency, err := chargeCurrency(l.ctx, l.svcCtx, user.ID, method, req.Currency)
 	if err != nil {
 		return nil, err
 	}
 
-	if !plan.Visible || !strings.EqualFold(plan.Status, "active") {
-		return nil, repository.ErrInvalidArgument
-	}
-
-	quantity := req.Quantity
-	if quantity <= 0 {
-		quantity = 1
-	}
-	if quantity > 10 {
-		quantity = 10
+	quote, err := quoteOrder(l.ctx, l.svcCtx, user.ID, req.OrderKind, req.PlanID, quantity, req.SubscriptionID, currency)
+	if err != nil {
+		return nil, err
 	}
+	plan := quote.Plan
+	addon := quote.Kind == orderutil.OrderKindTrafficAddon
 
-	channel := strings.TrimSpace(strings.ToLower(req.PaymentChannel))
-	returnURL := strings.TrimSpace(req.PaymentReturnURL)
-
-	totalCents := plan.PriceCents * int64(quantity)
+	totalCents := quote.TotalCents
 	if method == repository.PaymentMethodExternal && totalCents > 0 && channel == "" {
 		return nil, repository.ErrInvalidArgument
 	}
@@ -135,30 +128,41 @@ func (l *CreateLogic) Create(req *types.UserCreateOrderRequest) (resp *types.Use
 		}
 		balance = existingBalance
 
-		currency := strings.TrimSpace(plan.Currency)
-		if currency == "" {
-			currency = strings.TrimSpace(balance.Currency)
-			if currency == "" {
-				currency = "CNY"
-			}
+		currency := quote.Currency
+		if wallet := repository.NormalizeCurrency(balance.Currency); method == repository.PaymentMethodBalance &&
+			totalCents > 0 && wallet != "" && wallet != currency {
+			// 钱包币种在报价后发生变化。
+			return orderutil.ErrCurrencyMismatch
 		}
 
 		snapshot := map[string]any{
-			"id":                  plan.ID,
-			"name":                plan.Name,
-			"slug":                plan.Slug,
-			"description":         plan.Description,
-			"price_cents":         plan.PriceCents,
-			"currency":            currency,
-			"duration_days":       plan.DurationDays,
-			"traffic_limit_bytes": plan.TrafficLimitBytes,
-			"devices_limit":       plan.DevicesLimit,
-			"features":            plan.Features,
-			"tags":                plan.Tags,
+			"id":                   plan.ID,
+			"name":                 plan.Name,
+			"slug":                 plan.Slug,
+			"description":          plan.Description,
+			"price_cents":          plan.PriceCents,
+			"currency":             quote.Pricing.BaseCurrency,
+			"duration_days":        plan.DurationDays,
+			"traffic_limit_bytes":  plan.TrafficLimitBytes,
+			"devices_limit":        plan.DevicesLimit,
+			"traffic_reset_policy": repository.NormalizeTrafficResetPolicy(plan.TrafficResetPolicy),
+			"features":             plan.Features,
+			"tags":                 plan.Tags,
 		}
 
 		metadata := map[string]any{
-			"quantity": quantity,
+			"quantity":   quantity,
+			"order_kind": quote.Kind,
+			"pricing":    orderutil.PricingMetadata(quote.Pricing),
+		}
+		if quote.Subscription != nil {
+			metadata["subscription_id"] = quote.Subscription.ID
+		}
+		if quote.Proration != nil {
+			metadata["proration"] = orderutil.ProrationMetadata(*quote.Proration)
+		}
+		if addon {
+			metadata["traffic_bytes"] = quote.TrafficBytes
 		}
 		if channel != "" {
 			metadata["payment_channel"] = channel
@@ -183,6 +187,11 @@ func (l *CreateLogic) Create(req *types.UserCreateOrderRequest) (resp *types.Use
 			UpdatedAt:      now,
 		}
 
+		description := fmt.Sprintf("购买套餐 %s", plan.Name)
+		if addon {
+			description = fmt.Sprintf("购买流量包 %s", plan.Name)
+		}
+
 		if totalCents == 0 || method == repository.PaymentMethodBalance {
 			if totalCents > 0 {
 				txRecord := repository.BalanceTransaction{
@@ -190,11 +199,12 @@ func (l *CreateLogic) Create(req *types.UserCreateOrderRequest) (resp *types.Use
 					AmountCents: -totalCents,
 					Currency:    currency,
 					Reference:   fmt.Sprintf("order:%s", orderNumber),
-					Description: fmt.Sprintf("购买套餐 %s", plan.Name),
+					Description: description,
 					Metadata: map[string]any{
 						"plan_id":      plan.ID,
 						"quantity":     quantity,
 						"order_number": orderNumber,
+						"order_kind":   quote.Kind,
 					},
 				}
 				createdTx, updatedBalance, err := balanceRepo.ApplyTransaction(l.ctx, user.ID, txRecord)
@@ -221,29 +231,67 @@ func (l *CreateLogic) Create(req *types.UserCreateOrderRequest) (resp *types.Use
 			orderModel.PaymentIntentID = intentID
 		}
 
-		item := repository.OrderItem{
-			ItemType:       "plan",
+		if orderModel.PaidAt != nil {
+			patch, err := orderutil.FulfillPaidOrder(l.ctx, tx, orderModel, *orderModel.PaidAt)
+			if err != nil {
+				return err
+			}
+			for k, v := range patch {
+				orderModel.Metadata[k] = v
+			}
+		}
+
+		items := []repository.OrderItem{{
+			ItemType:       orderutil.OrderItemTypePlan,
 			ItemID:         plan.ID,
 			Name:           plan.Name,
 			Quantity:       quantity,
-			UnitPriceCents: plan.PriceCents,
+			UnitPriceCents: quote.Pricing.UnitPriceCents,
 			Currency:       currency,
-			SubtotalCents:  totalCents,
+			SubtotalCents:  quote.SubtotalCents,
 			Metadata: map[string]any{
 				"duration_days":       plan.DurationDays,
 				"traffic_limit_bytes": plan.TrafficLimitBytes,
 				"devices_limit":       plan.DevicesLimit,
 			},
 			CreatedAt: now,
+		}}
+		if addon {
+			items[0].ItemType = orderutil.OrderItemTypeTrafficAddon
+			items[0].Name = fmt.Sprintf("%s 流量包", plan.Name)
+			items[0].Metadata = map[string]any{
+				"subscription_id":     quote.Subscription.ID,
+				"traffic_addon_bytes": plan.TrafficAddonBytes,
+				"traffic_bytes":       quote.TrafficBytes,
+			}
+		}
+		if quote.CreditCents > 0 && quote.Proration != nil {
+			items = append(items, repository.OrderItem{
+				ItemType:       orderutil.OrderItemTypeProrationCredit,
+				ItemID:         quote.Proration.SubscriptionID,
+				Name:           "当前订阅剩余价值抵扣",
+				Quantity:       1,
+				UnitPriceCents: -quote.CreditCents,
+				Currency:       currency,
+				SubtotalCents:  -quote.CreditCents,
+				Metadata:       orderutil.ProrationMetadata(*quote.Proration),
+				CreatedAt:      now,
+			})
 		}
 
-		created, items, err := orderRepo.Create(l.ctx, orderModel, []repository.OrderItem{item})
+		created, items, err := orderRepo.Create(l.ctx, orderModel, items)
 		if err != nil {
 			return err
 		}
 		createdOrder = created
 		createdItems = items
 
+		if created.Status == repository.OrderStatusPaid {
+			if err := orderutil.OnOrderPaid(l.ctx, tx, l.svcCtx.Config, created.ID); err != nil {
+				return err
+			}
+		}
+
 		if method == repository.PaymentMethodExternal && totalCents > 0 {
 			paymentMetadata := map[string]any{}
 			if channel != "" {
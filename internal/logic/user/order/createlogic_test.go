@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/internal/svc"
@@ -262,3 +263,281 @@ func TestCreateOrderIdempotent(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, txList, 1)
 }
+
+func TestCreateOrderPlanChangeAppliesProration(t *testing.T) {
+	svcCtx, cleanup := setupCreateLogicTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	user := repository.User{
+		Email:       "upgrader@test.dev",
+		DisplayName: "Upgrader",
+		Roles:       []string{"user"},
+		Status:      "active",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&user).Error)
+
+	basic := repository.Plan{
+		Name:              "Basic",
+		Slug:              "basic",
+		PriceCents:        3000,
+		Currency:          "CNY",
+		DurationDays:      30,
+		TrafficLimitBytes: 1000,
+		DevicesLimit:      1,
+		Status:            "active",
+		Visible:           true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&basic).Error)
+
+	pro := repository.Plan{
+		Name:              "Pro",
+		Slug:              "pro",
+		PriceCents:        6000,
+		Currency:          "CNY",
+		DurationDays:      30,
+		TrafficLimitBytes: 4000,
+		DevicesLimit:      3,
+		Status:            "active",
+		Visible:           true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&pro).Error)
+
+	_, _, err := svcCtx.Repositories.Balance.ApplyTransaction(ctx, user.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 20000,
+		Currency:    "CNY",
+		Reference:   "seed",
+		Description: "seed balance",
+	})
+	require.NoError(t, err)
+
+	reqCtx := security.WithUser(ctx, security.UserClaims{ID: user.ID, Email: user.Email, Roles: []string{"user"}})
+
+	first, err := NewCreateLogic(reqCtx, svcCtx).Create(&types.UserCreateOrderRequest{PlanID: basic.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.OrderStatusPaid, first.Order.Status)
+
+	subs, total, err := svcCtx.Repositories.Subscription.ListByUser(ctx, user.ID, repository.ListSubscriptionsOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+	sub := subs[0]
+	require.Equal(t, basic.ID, sub.PlanID)
+	require.NotEmpty(t, sub.Token)
+
+	require.Equal(t, basic.PriceCents, sub.PeriodPaidCents)
+	require.Equal(t, "CNY", sub.PeriodCurrency)
+
+	// 剩余一半时长、已用四分之一流量，按较小的时间折算。
+	require.NoError(t, svcCtx.DB.Model(&repository.Subscription{}).Where("id = ?", sub.ID).Updates(map[string]any{
+		"period_started_at":  now.Add(-15 * 24 * time.Hour),
+		"expires_at":         now.Add(15 * 24 * time.Hour),
+		"traffic_used_bytes": 250,
+	}).Error)
+
+	quote, err := NewQuoteLogic(reqCtx, svcCtx).Quote(&types.UserOrderQuoteRequest{PlanID: pro.ID, SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.Equal(t, "upgrade", quote.Kind)
+	require.NotNil(t, quote.Proration)
+	require.InDelta(t, 1500, quote.CreditCents, 2)
+	require.Equal(t, int64(2250), quote.Proration.TrafficCreditCents)
+	require.Equal(t, quote.SubtotalCents-quote.CreditCents, quote.TotalCents)
+
+	changed, err := NewCreateLogic(reqCtx, svcCtx).Create(&types.UserCreateOrderRequest{PlanID: pro.ID, SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.OrderStatusPaid, changed.Order.Status)
+	require.Len(t, changed.Order.Items, 2)
+	require.Equal(t, "proration_credit", changed.Order.Items[1].ItemType)
+	require.Equal(t, pro.PriceCents+changed.Order.Items[1].SubtotalCents, changed.Order.TotalCents)
+	require.InDelta(t, 1500, -changed.Order.Items[1].SubtotalCents, 2)
+
+	_, total, err = svcCtx.Repositories.Subscription.ListByUser(ctx, user.ID, repository.ListSubscriptionsOptions{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, total)
+
+	updated, err := svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, pro.ID, updated.PlanID)
+	require.Equal(t, "Pro", updated.PlanName)
+	require.Equal(t, pro.TrafficLimitBytes, updated.TrafficTotalBytes)
+	require.Zero(t, updated.TrafficUsedBytes)
+	require.Equal(t, pro.DevicesLimit, updated.DevicesLimit)
+	require.WithinDuration(t, now.Add(30*24*time.Hour), updated.ExpiresAt, time.Minute)
+
+	renewal, err := NewQuoteLogic(reqCtx, svcCtx).Quote(&types.UserOrderQuoteRequest{PlanID: pro.ID, SubscriptionID: sub.ID, Quantity: 2})
+	require.NoError(t, err)
+	require.Equal(t, "renewal", renewal.Kind)
+	require.Equal(t, int64(12000), renewal.TotalCents)
+}
+
+func TestCreateOrderConvertsToWalletCurrency(t *testing.T) {
+	svcCtx, cleanup := setupCreateLogicTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	user := repository.User{
+		Email:       "usd@test.dev",
+		DisplayName: "USD Buyer",
+		Roles:       []string{"user"},
+		Status:      "active",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&user).Error)
+
+	plan := repository.Plan{
+		Name:              "Global",
+		Slug:              "global",
+		PriceCents:        1000,
+		Currency:          "CNY",
+		DurationDays:      30,
+		TrafficLimitBytes: 1024,
+		DevicesLimit:      1,
+		Status:            "active",
+		Visible:           true,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	require.NoError(t, svcCtx.DB.Create(&plan).Error)
+
+	_, _, err := svcCtx.Repositories.Balance.ApplyTransaction(ctx, user.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 5000,
+		Currency:    "USD",
+		Reference:   "seed",
+	})
+	require.NoError(t, err)
+
+	reqCtx := security.WithUser(ctx, security.UserClaims{ID: user.ID, Email: user.Email, Roles: []string{"user"}})
+	logic := NewCreateLogic(reqCtx, svcCtx)
+
+	_, err = logic.Create(&types.UserCreateOrderRequest{PlanID: plan.ID, Quantity: 1, PaymentMethod: "balance"})
+	require.ErrorIs(t, err, orderutil.ErrCurrencyMismatch)
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	// 仅存在反向汇率时取倒数换算：1000 CNY 分 / 7.2 ≈ 139 USD 分。
+	_, err = svcCtx.Repositories.ExchangeRate.Upsert(ctx, []repository.ExchangeRate{{
+		BaseCurrency:  "usd",
+		QuoteCurrency: "cny",
+		Rate:          7.2,
+		Source:        "test",
+	}})
+	require.NoError(t, err)
+
+	resp, err := logic.Create(&types.UserCreateOrderRequest{PlanID: plan.ID, Quantity: 2, PaymentMethod: "balance"})
+	require.NoError(t, err)
+	require.Equal(t, "USD", resp.Order.Currency)
+	require.Equal(t, int64(278), resp.Order.TotalCents)
+	require.Equal(t, int64(5000-278), resp.Balance.BalanceCents)
+
+	pricing, ok := resp.Order.Metadata["pricing"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, orderutil.PriceSourceExchangeRate, pricing["source"])
+	require.Equal(t, true, pricing["rate_inverse"])
+
+	// 固定售价优先于汇率。
+	_, err = svcCtx.Repositories.Plan.ReplacePrices(ctx, plan.ID, []repository.PlanPrice{{Currency: "usd", PriceCents: 199}})
+	require.NoError(t, err)
+
+	resp, err = logic.Create(&types.UserCreateOrderRequest{PlanID: plan.ID, Quantity: 1, PaymentMethod: "balance"})
+	require.NoError(t, err)
+	require.Equal(t, int64(199), resp.Order.TotalCents)
+	require.Equal(t, int64(199), resp.Order.Items[0].UnitPriceCents)
+}
+
+func TestPlanChangeCreditRevalidatedOnFulfilment(t *testing.T) {
+	svcCtx, cleanup := setupCreateLogicTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	user := repository.User{Email: "double@test.dev", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&user).Error)
+
+	newPlan := func(slug string, price int64, traffic int64) repository.Plan {
+		plan := repository.Plan{
+			Name:              slug,
+			Slug:              slug,
+			PriceCents:        price,
+			Currency:          "CNY",
+			DurationDays:      30,
+			TrafficLimitBytes: traffic,
+			Status:            "active",
+			Visible:           true,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+		}
+		require.NoError(t, svcCtx.DB.Create(&plan).Error)
+		return plan
+	}
+	basic := newPlan("basic", 3000, 1000)
+	pro := newPlan("pro", 6000, 4000)
+	mini := newPlan("mini", 1000, 500)
+
+	_, _, err := svcCtx.Repositories.Balance.ApplyTransaction(ctx, user.ID, repository.BalanceTransaction{
+		Type:        "recharge",
+		AmountCents: 5000,
+		Currency:    "CNY",
+		Reference:   "seed",
+	})
+	require.NoError(t, err)
+	reqCtx := security.WithUser(ctx, security.UserClaims{ID: user.ID, Email: user.Email, Roles: []string{"user"}})
+
+	_, err = NewCreateLogic(reqCtx, svcCtx).Create(&types.UserCreateOrderRequest{PlanID: basic.ID})
+	require.NoError(t, err)
+	subs, _, err := svcCtx.Repositories.Subscription.ListByUser(ctx, user.ID, repository.ListSubscriptionsOptions{})
+	require.NoError(t, err)
+	sub := subs[0]
+	require.NoError(t, svcCtx.DB.Model(&repository.Subscription{}).Where("id = ?", sub.ID).Updates(map[string]any{
+		"period_started_at": now.Add(-15 * 24 * time.Hour),
+		"expires_at":        now.Add(15 * 24 * time.Hour),
+	}).Error)
+
+	// 外部支付的升级订单报价时抵扣 1500，支付前剩余价值已被另一笔降级订单转入新周期。
+	upgrade, err := NewCreateLogic(reqCtx, svcCtx).Create(&types.UserCreateOrderRequest{
+		PlanID:         pro.ID,
+		SubscriptionID: sub.ID,
+		PaymentMethod:  repository.PaymentMethodExternal,
+		PaymentChannel: "stripe",
+	})
+	require.NoError(t, err)
+	require.Equal(t, repository.OrderStatusPendingPayment, upgrade.Order.Status)
+	require.InDelta(t, 4500, upgrade.Order.TotalCents, 2)
+
+	downgrade, err := NewCreateLogic(reqCtx, svcCtx).Create(&types.UserCreateOrderRequest{PlanID: mini.ID, SubscriptionID: sub.ID})
+	require.NoError(t, err)
+	require.Equal(t, repository.OrderStatusPaid, downgrade.Order.Status)
+	require.Zero(t, downgrade.Order.TotalCents)
+
+	afterDowngrade, err := svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, mini.PriceCents, afterDowngrade.PeriodPaidCents)
+
+	pending, _, err := svcCtx.Repositories.Order.Get(ctx, upgrade.Order.ID)
+	require.NoError(t, err)
+	var patch map[string]any
+	require.NoError(t, svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		patch, err = orderutil.FulfillPaidOrder(ctx, tx, pending, now)
+		return err
+	}))
+	require.InDelta(t, 1000, patch["proration_applied_cents"], 2)
+	require.InDelta(t, 500, patch["proration_shortfall_cents"], 2)
+
+	upgraded, err := svcCtx.Repositories.Subscription.Get(ctx, sub.ID)
+	require.NoError(t, err)
+	require.Equal(t, pro.ID, upgraded.PlanID)
+	require.InDelta(t, 5500, upgraded.PeriodPaidCents, 2)
+	require.WithinDuration(t, now.Add(time.Duration(27.5*24*float64(time.Hour))), upgraded.ExpiresAt, 15*time.Minute)
+}
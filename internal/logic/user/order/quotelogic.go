@@ -0,0 +1,116 @@
+package order
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// QuoteLogic computes prices for purchases, renewals and plan changes.
+type QuoteLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewQuoteLogic constructs QuoteLogic.
+func NewQuoteLogic(ctx context.Context, svcCtx *svc.ServiceContext) *QuoteLogic {
+	return &QuoteLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Quote returns the payable amount after crediting the unused value of the current subscription.
+func (l *QuoteLogic) Quote(req *types.UserOrderQuoteRequest) (*types.UserOrderQuoteResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	method := strings.TrimSpace(strings.ToLower(req.PaymentMethod))
+	if method == "" {
+		method = repository.PaymentMethodBalance
+	}
+	currency, err := chargeCurrency(l.ctx, l.svcCtx, user.ID, method, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := quoteOrder(l.ctx, l.svcCtx, user.ID, req.OrderKind, req.PlanID, normalizeQuantity(req.Quantity), req.SubscriptionID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := orderutil.ToOrderQuote(quote)
+	return &resp, nil
+}
+
+// quoteOrder 按订单类型报价：traffic_addon 为订阅当前套餐的流量包，其余按套餐购买/续费/变更处理。
+func quoteOrder(ctx context.Context, svcCtx *svc.ServiceContext, userID uint64, kind string, planID uint64, quantity int, subscriptionID uint64, currency string) (orderutil.PlanQuote, error) {
+	now := time.Now().UTC()
+	kind = strings.TrimSpace(strings.ToLower(kind))
+	if kind == orderutil.OrderKindTrafficAddon {
+		quote, err := orderutil.QuoteTrafficAddon(ctx, svcCtx.Repositories, userID, subscriptionID, quantity, currency, now)
+		if err != nil {
+			return orderutil.PlanQuote{}, err
+		}
+		if planID != 0 && planID != quote.Plan.ID {
+			return orderutil.PlanQuote{}, repository.ErrInvalidArgument
+		}
+		return quote, nil
+	}
+
+	// 套餐订单的具体类型（新购/续费/变更）由报价推断，不接受客户端指定。
+	if kind != "" || planID == 0 {
+		return orderutil.PlanQuote{}, repository.ErrInvalidArgument
+	}
+	plan, err := svcCtx.Repositories.Plan.Get(ctx, planID)
+	if err != nil {
+		return orderutil.PlanQuote{}, err
+	}
+	if !plan.Visible || !strings.EqualFold(plan.Status, "active") {
+		return orderutil.PlanQuote{}, repository.ErrInvalidArgument
+	}
+
+	return orderutil.QuotePlan(ctx, svcCtx.Repositories, userID, plan, quantity, subscriptionID, currency, now)
+}
+
+// chargeCurrency 确定计价币种：余额支付必须使用钱包币种，外部支付默认套餐币种。
+func chargeCurrency(ctx context.Context, svcCtx *svc.ServiceContext, userID uint64, method, requested string) (string, error) {
+	requested = repository.NormalizeCurrency(requested)
+	if method != repository.PaymentMethodBalance {
+		return requested, nil
+	}
+
+	balance, err := svcCtx.Repositories.Balance.GetBalance(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	wallet := repository.NormalizeCurrency(balance.Currency)
+	if wallet == "" {
+		wallet = "CNY"
+	}
+	if requested != "" && requested != wallet {
+		return "", orderutil.ErrCurrencyMismatch
+	}
+	return wallet, nil
+}
+
+func normalizeQuantity(quantity int) int {
+	if quantity <= 0 {
+		return 1
+	}
+	if quantity > 10 {
+		return 10
+	}
+	return quantity
+}
@@ -42,9 +42,18 @@ func (l *ListLogic) List(req *types.UserPlanListRequest) (*types.UserPlanListRes
 		return nil, err
 	}
 
+	ids := make([]uint64, 0, len(plans))
+	for _, plan := range plans {
+		ids = append(ids, plan.ID)
+	}
+	prices, err := l.svcCtx.Repositories.Plan.ListPrices(l.ctx, ids...)
+	if err != nil {
+		return nil, err
+	}
+
 	result := make([]types.UserPlanSummary, 0, len(plans))
 	for _, plan := range plans {
-		result = append(result, toUserPlanSummary(plan))
+		result = append(result, toUserPlanSummary(plan, prices[plan.ID]))
 	}
 
 	return &types.UserPlanListResponse{Plans: result}, nil
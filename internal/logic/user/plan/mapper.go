@@ -5,17 +5,29 @@ import (
 	"github.com/zero-net-panel/zero-net-panel/internal/types"
 )
 
-func toUserPlanSummary(plan repository.Plan) types.UserPlanSummary {
+func toUserPlanSummary(plan repository.Plan, prices []repository.PlanPrice) types.UserPlanSummary {
+	list := make([]types.PlanPrice, 0, len(prices))
+	for _, price := range prices {
+		list = append(list, types.PlanPrice{
+			Currency:   price.Currency,
+			PriceCents: price.PriceCents,
+		})
+	}
+
 	return types.UserPlanSummary{
-		ID:                plan.ID,
-		Name:              plan.Name,
-		Description:       plan.Description,
-		Features:          append([]string(nil), plan.Features...),
-		PriceCents:        plan.PriceCents,
-		Currency:          plan.Currency,
-		DurationDays:      plan.DurationDays,
-		TrafficLimitBytes: plan.TrafficLimitBytes,
-		DevicesLimit:      plan.DevicesLimit,
-		Tags:              append([]string(nil), plan.Tags...),
+		ID:                     plan.ID,
+		Name:                   plan.Name,
+		Description:            plan.Description,
+		Features:               append([]string(nil), plan.Features...),
+		PriceCents:             plan.PriceCents,
+		Currency:               plan.Currency,
+		DurationDays:           plan.DurationDays,
+		TrafficLimitBytes:      plan.TrafficLimitBytes,
+		DevicesLimit:           plan.DevicesLimit,
+		TrafficResetPolicy:     repository.NormalizeTrafficResetPolicy(plan.TrafficResetPolicy),
+		TrafficAddonBytes:      plan.TrafficAddonBytes,
+		TrafficAddonPriceCents: plan.TrafficAddonPriceCents,
+		Tags:                   append([]string(nil), plan.Tags...),
+		Prices:                 list,
 	}
 }
@@ -0,0 +1,190 @@
+package redeem
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/orderutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// redeemBalanceTxType 兑换码充值的余额流水类型。
+const redeemBalanceTxType = "redeem"
+
+// RedeemLogic 用户兑换码。
+type RedeemLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewRedeemLogic 构造函数。
+func NewRedeemLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RedeemLogic {
+	return &RedeemLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Redeem 在同一事务内锁定兑换码、发放权益并写入兑换流水，任一步失败均回滚。
+func (l *RedeemLogic) Redeem(req *types.UserRedeemRequest) (*types.UserRedeemResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+	if repository.NormalizeRedeemCode(req.Code) == "" {
+		return nil, repository.ErrInvalidArgument
+	}
+
+	var (
+		redemption   repository.RedeemRedemption
+		balance      *repository.UserBalance
+		subscription *repository.Subscription
+	)
+	err := l.svcCtx.DB.WithContext(l.ctx).Transaction(func(tx *gorm.DB) error {
+		repos, err := repository.NewRepositories(tx)
+		if err != nil {
+			return err
+		}
+
+		code, batch, err := repos.Redeem.Consume(l.ctx, req.Code, user.ID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		record := repository.RedeemRedemption{
+			CodeID:    code.ID,
+			UserID:    user.ID,
+			BatchID:   batch.ID,
+			Code:      code.Code,
+			Kind:      batch.Kind,
+			CreatedAt: now,
+		}
+
+		switch batch.Kind {
+		case repository.RedeemKindBalance:
+			wallet, err := repos.Balance.GetBalance(l.ctx, user.ID)
+			if err != nil {
+				return err
+			}
+			credited, err := orderutil.ConvertAmount(l.ctx, repos, batch.AmountCents, batch.Currency, wallet.Currency)
+			if err != nil {
+				return err
+			}
+			balanceTx, updated, err := repos.Balance.ApplyTransaction(l.ctx, user.ID, repository.BalanceTransaction{
+				Type:        redeemBalanceTxType,
+				AmountCents: credited,
+				Currency:    wallet.Currency,
+				Reference:   code.Code,
+				Description: "兑换码充值",
+				Metadata: map[string]any{
+					"redeem_batch_id":     batch.ID,
+					"face_value_cents":    batch.AmountCents,
+					"face_value_currency": batch.Currency,
+				},
+			})
+			if err != nil {
+				return err
+			}
+			record.AmountCents = balanceTx.AmountCents
+			record.Currency = balanceTx.Currency
+			record.BalanceTransactionID = balanceTx.ID
+			balance = &updated
+		case repository.RedeemKindPlan:
+			plan, err := repos.Plan.Get(l.ctx, batch.PlanID)
+			if err != nil {
+				return err
+			}
+			sub, err := orderutil.GrantPlanDays(l.ctx, tx, user.ID, plan, batch.PlanDays, now)
+			if err != nil {
+				return err
+			}
+			record.PlanID = plan.ID
+			record.PlanDays = batch.PlanDays
+			record.SubscriptionID = sub.ID
+			record.Metadata = map[string]any{"expires_at": sub.ExpiresAt.Unix()}
+			subscription = &sub
+		case repository.RedeemKindTraffic:
+			sub, err := orderutil.GrantTraffic(l.ctx, tx, user.ID, req.SubscriptionID, batch.TrafficBytes, now)
+			if err != nil {
+				return err
+			}
+			record.TrafficBytes = batch.TrafficBytes
+			record.SubscriptionID = sub.ID
+			record.Metadata = map[string]any{"traffic_total_bytes": sub.TrafficTotalBytes}
+			subscription = &sub
+		default:
+			return repository.ErrInvalidState
+		}
+
+		redemption, err = repos.Redeem.RecordRedemption(l.ctx, record)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.UserRedeemResponse{
+		Redemption: orderutil.ToRedeemRedemptionSummary(redemption),
+	}
+	if balance != nil {
+		snapshot := orderutil.ToBalanceSnapshot(*balance)
+		resp.Balance = &snapshot
+	}
+	if subscription != nil {
+		summary := orderutil.ToUserSubscriptionSummary(*subscription)
+		resp.Subscription = &summary
+	}
+	return resp, nil
+}
+
+// List 返回当前用户的兑换记录。
+func (l *RedeemLogic) List(req *types.UserListRedemptionsRequest) (*types.RedemptionListResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrUnauthorized
+	}
+
+	page := req.Page
+	if page <= 0 {
+		page = 1
+	}
+	perPage := req.PerPage
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+
+	userID := user.ID
+	redemptions, total, err := l.svcCtx.Repositories.Redeem.ListRedemptions(l.ctx, repository.ListRedemptionsOptions{
+		Page:    page,
+		PerPage: perPage,
+		UserID:  &userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]types.RedeemRedemptionSummary, 0, len(redemptions))
+	for _, r := range redemptions {
+		list = append(list, orderutil.ToRedeemRedemptionSummary(r))
+	}
+
+	return &types.RedemptionListResponse{
+		Redemptions: list,
+		Pagination: types.PaginationMeta{
+			Page:       page,
+			PerPage:    perPage,
+			TotalCount: total,
+			HasNext:    int64(page*perPage) < total,
+			HasPrev:    page > 1,
+		},
+	}, nil
+}
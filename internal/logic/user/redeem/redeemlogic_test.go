@@ -0,0 +1,187 @@
+package redeem
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	adminredeem "github.com/zero-net-panel/zero-net-panel/internal/logic/admin/redeemcodes"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+func setupRedeemTestContext(t *testing.T) (*svc.ServiceContext, func()) {
+	t.Helper()
+
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:redeem?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+
+	_, err = migrations.Apply(context.Background(), db, 0, false)
+	require.NoError(t, err)
+
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	svcCtx := &svc.ServiceContext{
+		DB:           db,
+		Repositories: repos,
+	}
+
+	cleanup := func() {
+		sqlDB, err := db.DB()
+		if err == nil {
+			_ = sqlDB.Close()
+		}
+	}
+
+	return svcCtx, cleanup
+}
+
+func TestRedeem_BalancePlanAndTrafficCodes(t *testing.T) {
+	svcCtx, cleanup := setupRedeemTestContext(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	admin := repository.User{Email: "admin-redeem@test.local", DisplayName: "Admin", Roles: []string{"admin"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&admin).Error)
+	alice := repository.User{Email: "alice-redeem@test.local", DisplayName: "Alice", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&alice).Error)
+	bob := repository.User{Email: "bob-redeem@test.local", DisplayName: "Bob", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&bob).Error)
+	carol := repository.User{Email: "carol-redeem@test.local", DisplayName: "Carol", Roles: []string{"user"}, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&carol).Error)
+
+	plan := repository.Plan{Name: "Gift", Slug: "gift", PriceCents: 1000, Currency: "CNY", DurationDays: 30, TrafficLimitBytes: 1 << 30, DevicesLimit: 2, Status: "active", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, svcCtx.DB.Create(&plan).Error)
+
+	adminCtx := security.WithUser(ctx, security.UserClaims{ID: admin.ID, Email: admin.Email, Roles: []string{"admin"}})
+	batches := adminredeem.NewBatchLogic(adminCtx, svcCtx)
+
+	_, err := batches.Create(&types.AdminCreateRedeemBatchRequest{Kind: repository.RedeemKindPlan, PlanID: plan.ID, Quantity: 1})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	balanceBatch, err := batches.Create(&types.AdminCreateRedeemBatchRequest{
+		Name: "reseller", Kind: repository.RedeemKindBalance, AmountCents: 500, Currency: "cny", MaxUses: 2, Quantity: 3, Prefix: "gift",
+	})
+	require.NoError(t, err)
+	require.Len(t, balanceBatch.Codes, 3)
+	require.True(t, strings.HasPrefix(balanceBatch.Codes[0].Code, "GIFT-"))
+
+	planBatch, err := batches.Create(&types.AdminCreateRedeemBatchRequest{Kind: repository.RedeemKindPlan, PlanID: plan.ID, PlanDays: 7, Quantity: 1})
+	require.NoError(t, err)
+	trafficBatch, err := batches.Create(&types.AdminCreateRedeemBatchRequest{Kind: repository.RedeemKindTraffic, TrafficBytes: 1 << 20, Quantity: 1})
+	require.NoError(t, err)
+
+	filename, content, err := batches.Export(&types.AdminExportRedeemBatchRequest{BatchID: balanceBatch.Batch.ID})
+	require.NoError(t, err)
+	require.Contains(t, filename, ".csv")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "code,kind,value,max_uses,used_count,status,expires_at", lines[0])
+
+	aliceCtx := security.WithUser(ctx, security.UserClaims{ID: alice.ID, Email: alice.Email, Roles: []string{"user"}})
+	bobCtx := security.WithUser(ctx, security.UserClaims{ID: bob.ID, Email: bob.Email, Roles: []string{"user"}})
+	carolCtx := security.WithUser(ctx, security.UserClaims{ID: carol.ID, Email: carol.Email, Roles: []string{"user"}})
+	shared := balanceBatch.Codes[0].Code
+
+	// 多次码：每个用户一次，次数用尽后拒绝。
+	resp, err := NewRedeemLogic(aliceCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: strings.ToLower(shared)})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Balance)
+	require.Equal(t, int64(500), resp.Balance.BalanceCents)
+	require.NotZero(t, resp.Redemption.BalanceTransactionID)
+
+	_, err = NewRedeemLogic(aliceCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: shared})
+	require.ErrorIs(t, err, repository.ErrConflict)
+	_, err = NewRedeemLogic(bobCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: shared})
+	require.NoError(t, err)
+	_, err = NewRedeemLogic(carolCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: shared})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	// 流量码需要有效订阅，失败时不消耗次数。
+	trafficCode := trafficBatch.Codes[0].Code
+	_, err = NewRedeemLogic(aliceCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: trafficCode})
+	require.ErrorIs(t, err, repository.ErrInvalidArgument)
+
+	// 新订阅优先选用已发布的默认模板，未发布的默认模板草稿不会被选中。
+	templates := svcCtx.Repositories.SubscriptionTemplate
+	draftDefault, err := templates.Create(ctx, repository.CreateSubscriptionTemplateInput{Name: "Draft", ClientType: "clash", Content: "draft", IsDefault: true})
+	require.NoError(t, err)
+	plain, err := templates.Create(ctx, repository.CreateSubscriptionTemplateInput{Name: "Plain", ClientType: "clash", Content: "plain"})
+	require.NoError(t, err)
+	published, err := templates.Create(ctx, repository.CreateSubscriptionTemplateInput{Name: "Published", ClientType: "surge", Content: "published", IsDefault: true})
+	require.NoError(t, err)
+	for _, id := range []uint64{plain.ID, published.ID} {
+		_, _, err = templates.Publish(ctx, id, repository.PublishSubscriptionTemplateInput{Operator: "test"})
+		require.NoError(t, err)
+	}
+
+	planResp, err := NewRedeemLogic(aliceCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: planBatch.Codes[0].Code})
+	require.NoError(t, err)
+	require.NotNil(t, planResp.Subscription)
+	granted, err := svcCtx.Repositories.Subscription.Get(ctx, planResp.Subscription.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, draftDefault.ID, granted.TemplateID)
+	require.Equal(t, published.ID, granted.TemplateID)
+	require.Equal(t, int64(1<<30), planResp.Subscription.TrafficTotalBytes)
+	require.InDelta(t, now.Add(7*24*time.Hour).Unix(), planResp.Subscription.ExpiresAt, 5)
+
+	trafficResp, err := NewRedeemLogic(aliceCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: trafficCode})
+	require.NoError(t, err)
+	require.Equal(t, planResp.Subscription.ID, trafficResp.Subscription.ID)
+	require.Equal(t, int64(1<<30+1<<20), trafficResp.Subscription.TrafficTotalBytes)
+
+	// 兑换的流量计入当期额外流量，周期重置后额度回到套餐上限。
+	granted, err = svcCtx.Repositories.Subscription.Get(ctx, trafficResp.Subscription.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1<<20), granted.TrafficExtraBytes)
+	require.NoError(t, svcCtx.DB.Model(&repository.Subscription{}).Where("id = ?", granted.ID).Updates(map[string]any{
+		"traffic_reset_policy":  repository.TrafficResetMonthly,
+		"traffic_next_reset_at": now.Add(-time.Minute),
+	}).Error)
+	reset, _, err := svcCtx.Repositories.Subscription.ResetTrafficPeriod(ctx, granted.ID, now)
+	require.NoError(t, err)
+	require.Equal(t, int64(1<<30), reset.TrafficTotalBytes)
+	require.Zero(t, reset.TrafficExtraBytes)
+
+	// 禁用的兑换码不可兑换。
+	disabled := balanceBatch.Codes[1]
+	_, err = adminredeem.NewCodesLogic(adminCtx, svcCtx).Update(&types.AdminUpdateRedeemCodeRequest{CodeID: disabled.ID, Status: repository.RedeemCodeStatusDisabled})
+	require.NoError(t, err)
+	_, err = NewRedeemLogic(carolCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: disabled.Code})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	// 过期批次不可兑换。
+	expiresAt := now.Add(time.Hour).Unix()
+	expiring, err := batches.Create(&types.AdminCreateRedeemBatchRequest{Kind: repository.RedeemKindBalance, AmountCents: 100, Quantity: 1, ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	require.NoError(t, svcCtx.DB.Model(&repository.RedeemBatch{}).Where("id = ?", expiring.Batch.ID).Update("expires_at", now.Add(-time.Minute)).Error)
+	_, err = NewRedeemLogic(carolCtx, svcCtx).Redeem(&types.UserRedeemRequest{Code: expiring.Codes[0].Code})
+	require.ErrorIs(t, err, repository.ErrInvalidState)
+
+	history, err := NewRedeemLogic(aliceCtx, svcCtx).List(&types.UserListRedemptionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, history.Redemptions, 3)
+
+	codes, err := adminredeem.NewCodesLogic(adminCtx, svcCtx).List(&types.AdminListRedeemCodesRequest{Code: shared})
+	require.NoError(t, err)
+	require.Len(t, codes.Codes, 1)
+	require.Equal(t, 2, codes.Codes[0].UsedCount)
+
+	unused, err := adminredeem.NewCodesLogic(adminCtx, svcCtx).List(&types.AdminListRedeemCodesRequest{Code: trafficCode})
+	require.NoError(t, err)
+	require.Equal(t, 1, unused.Codes[0].UsedCount)
+}
@@ -0,0 +1,56 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// UpdateAutoRenewLogic 用户开关订阅自动续费。
+type UpdateAutoRenewLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewUpdateAutoRenewLogic 构造函数。
+func NewUpdateAutoRenewLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateAutoRenewLogic {
+	return &UpdateAutoRenewLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// UpdateAutoRenew 设置自动续费开关，开启时重置失败计数。
+func (l *UpdateAutoRenewLogic) UpdateAutoRenew(req *types.UserUpdateSubscriptionAutoRenewRequest) (*types.UserUpdateSubscriptionAutoRenewResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrForbidden
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Enabled && sub.PlanID == 0 {
+		// 未绑定套餐的订阅无法确定续费价格。
+		return nil, repository.ErrInvalidArgument
+	}
+
+	sub, err = l.svcCtx.Repositories.Subscription.SetAutoRenew(l.ctx, req.SubscriptionID, user.ID, req.Enabled)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.UserUpdateSubscriptionAutoRenewResponse{
+		SubscriptionID: sub.ID,
+		AutoRenew:      sub.AutoRenew,
+		UpdatedAt:      sub.UpdatedAt.Unix(),
+	}, nil
+}
@@ -0,0 +1,69 @@
+package subscription
+
+import (
+	"context"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/deviceutil"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// DevicesLogic 查询订阅当前在线设备。
+type DevicesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewDevicesLogic 构造函数。
+func NewDevicesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DevicesLogic {
+	return &DevicesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回在线窗口内节点上报过的客户端 IP。
+func (l *DevicesLogic) List(req *types.UserSubscriptionDevicesRequest) (*types.UserSubscriptionDevicesResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrForbidden
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.UserID != user.ID {
+		return nil, repository.ErrForbidden
+	}
+
+	tracker := deviceutil.NewTracker(l.svcCtx.Cache, l.svcCtx.Config.Node.OnlineWindow)
+	devices, err := tracker.Online(l.ctx, sub.ID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.UserSubscriptionDevicesResponse{
+		SubscriptionID: sub.ID,
+		DevicesLimit:   sub.DevicesLimit,
+		OnlineCount:    len(devices),
+		OverLimit:      deviceutil.OverLimit(devices, sub.DevicesLimit),
+		Devices:        make([]types.OnlineDevice, 0, len(devices)),
+	}
+	for _, device := range devices {
+		resp.Devices = append(resp.Devices, types.OnlineDevice{
+			IP:         device.IP,
+			NodeIDs:    append([]uint64(nil), device.NodeIDs...),
+			LastSeenAt: device.LastSeenAt,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,149 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// TokensLogic 管理订阅主 token、代理凭据与命名访问链接。
+type TokensLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTokensLogic 构造函数。
+func NewTokensLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TokensLogic {
+	return &TokensLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// Reset 重新生成主 token，旧链接立即失效，渲染缓存键随 token 变化不再命中；命名访问链接与代理凭据不受影响。
+func (l *TokensLogic) Reset(req *types.UserResetSubscriptionTokenRequest) (*types.UserResetSubscriptionTokenResponse, error) {
+	if _, err := l.ownedSubscription(req.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.ResetToken(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.UserResetSubscriptionTokenResponse{
+		SubscriptionID: sub.ID,
+		Token:          sub.Token,
+		UpdatedAt:      sub.UpdatedAt.Unix(),
+	}, nil
+}
+
+// ResetProxyUUID 轮换节点侧代理凭据，节点下次拉取用户列表后旧凭据失效，客户端需重新拉取订阅；拉取链接不变。
+func (l *TokensLogic) ResetProxyUUID(req *types.UserResetSubscriptionProxyUUIDRequest) (*types.UserResetSubscriptionProxyUUIDResponse, error) {
+	if _, err := l.ownedSubscription(req.SubscriptionID); err != nil {
+		return nil, err
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.RotateProxyUUID(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.UserResetSubscriptionProxyUUIDResponse{
+		SubscriptionID: sub.ID,
+		ProxyUUID:      sub.ProxyUUID,
+		UpdatedAt:      sub.UpdatedAt.Unix(),
+	}, nil
+}
+
+// List 返回订阅的命名访问链接及最近访问记录。
+func (l *TokensLogic) List(req *types.UserSubscriptionTokensRequest) (*types.UserSubscriptionTokensResponse, error) {
+	sub, err := l.ownedSubscription(req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := l.svcCtx.Repositories.Subscription.ListAccessTokens(l.ctx, sub.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.UserSubscriptionTokensResponse{
+		SubscriptionID: sub.ID,
+		Tokens:         make([]types.SubscriptionAccessToken, 0, len(tokens)),
+	}
+	for _, token := range tokens {
+		resp.Tokens = append(resp.Tokens, toAccessToken(token))
+	}
+	return resp, nil
+}
+
+// Create 新建命名访问链接，同一订阅下名称唯一。
+func (l *TokensLogic) Create(req *types.UserCreateSubscriptionTokenRequest) (*types.SubscriptionAccessToken, error) {
+	sub, err := l.ownedSubscription(req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := l.svcCtx.Repositories.Subscription.CreateAccessToken(l.ctx, sub.ID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toAccessToken(token)
+	return &resp, nil
+}
+
+// Revoke 删除命名访问链接，返回剩余链接。
+func (l *TokensLogic) Revoke(req *types.UserRevokeSubscriptionTokenRequest) (*types.UserSubscriptionTokensResponse, error) {
+	sub, err := l.ownedSubscription(req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := l.svcCtx.Repositories.Subscription.RevokeAccessToken(l.ctx, sub.ID, req.TokenID); err != nil {
+		return nil, err
+	}
+	return l.List(&types.UserSubscriptionTokensRequest{SubscriptionID: sub.ID})
+}
+
+func (l *TokensLogic) ownedSubscription(subscriptionID uint64) (repository.Subscription, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return repository.Subscription{}, repository.ErrForbidden
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, subscriptionID)
+	if err != nil {
+		return repository.Subscription{}, err
+	}
+	if sub.UserID != user.ID {
+		return repository.Subscription{}, repository.ErrForbidden
+	}
+	return sub, nil
+}
+
+func toAccessToken(token repository.SubscriptionToken) types.SubscriptionAccessToken {
+	result := types.SubscriptionAccessToken{
+		ID:            token.ID,
+		Name:          token.Name,
+		Token:         token.Token,
+		AccessCount:   token.AccessCount,
+		LastIP:        token.LastIP,
+		LastUserAgent: token.LastUserAgent,
+		RecentIPs:     append([]string{}, token.RecentIPs...),
+		CreatedAt:     token.CreatedAt.Unix(),
+	}
+	if token.LastAccessAt != nil {
+		lastAccess := token.LastAccessAt.Unix()
+		result.LastAccessAt = &lastAccess
+	}
+	return result
+}
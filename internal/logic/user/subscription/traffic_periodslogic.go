@@ -0,0 +1,74 @@
+package subscription
+
+import (
+	"context"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/security"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+	"github.com/zero-net-panel/zero-net-panel/internal/types"
+)
+
+// TrafficPeriodsLogic 查询订阅已归档的流量周期。
+type TrafficPeriodsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// NewTrafficPeriodsLogic 构造函数。
+func NewTrafficPeriodsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *TrafficPeriodsLogic {
+	return &TrafficPeriodsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// List 返回当前周期信息与最近的历史周期，按结束时间倒序。
+func (l *TrafficPeriodsLogic) List(req *types.UserSubscriptionTrafficPeriodsRequest) (*types.UserSubscriptionTrafficPeriodsResponse, error) {
+	user, ok := security.UserFromContext(l.ctx)
+	if !ok {
+		return nil, repository.ErrForbidden
+	}
+
+	sub, err := l.svcCtx.Repositories.Subscription.Get(l.ctx, req.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if sub.UserID != user.ID {
+		return nil, repository.ErrForbidden
+	}
+
+	periods, err := l.svcCtx.Repositories.Subscription.ListTrafficPeriods(l.ctx, sub.ID, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &types.UserSubscriptionTrafficPeriodsResponse{
+		SubscriptionID:     sub.ID,
+		TrafficResetPolicy: repository.NormalizeTrafficResetPolicy(sub.TrafficResetPolicy),
+		CurrentPeriodStart: sub.CreatedAt.Unix(),
+		Periods:            make([]types.SubscriptionTrafficPeriod, 0, len(periods)),
+	}
+	if sub.TrafficPeriodStartAt != nil {
+		resp.CurrentPeriodStart = sub.TrafficPeriodStartAt.Unix()
+	}
+	if sub.TrafficNextResetAt != nil {
+		next := sub.TrafficNextResetAt.Unix()
+		resp.NextResetAt = &next
+	}
+	for _, period := range periods {
+		resp.Periods = append(resp.Periods, types.SubscriptionTrafficPeriod{
+			PeriodStart: period.PeriodStart.Unix(),
+			PeriodEnd:   period.PeriodEnd.Unix(),
+			UsedBytes:   period.UsedBytes,
+			TotalBytes:  period.TotalBytes,
+			ExtraBytes:  period.ExtraBytes,
+		})
+	}
+
+	return resp, nil
+}
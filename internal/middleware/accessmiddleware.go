@@ -6,9 +6,9 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
 	"golang.org/x/time/rate"
 
+	"github.com/zero-net-panel/zero-net-panel/internal/apierror"
 	"github.com/zero-net-panel/zero-net-panel/internal/config"
 )
 
@@ -49,18 +49,14 @@ func (m *AccessMiddleware) Handler(next http.HandlerFunc) http.HandlerFunc {
 		ip := clientIP(r)
 
 		if len(m.allowedNets) > 0 && !m.ipAllowed(ip) {
-			httpx.WriteJsonCtx(r.Context(), w, http.StatusForbidden, map[string]any{
-				"message": "access denied",
-			})
+			apierror.Write(w, r, apierror.New(apierror.CodeAccessDenied, ""))
 			return
 		}
 
 		if m.limiterRPS > 0 {
 			lim := m.getLimiter(ip)
 			if lim != nil && !lim.Allow() {
-				httpx.WriteJsonCtx(r.Context(), w, http.StatusTooManyRequests, map[string]any{
-					"message": "rate limit exceeded",
-				})
+				apierror.Write(w, r, apierror.New(apierror.CodeRateLimited, ""))
 				return
 			}
 		}
@@ -5,8 +5,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
-
+	"github.com/zero-net-panel/zero-net-panel/internal/apierror"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/internal/security"
 	"github.com/zero-net-panel/zero-net-panel/pkg/auth"
@@ -29,30 +28,30 @@ func (m *AuthMiddleware) RequireRoles(roles ...string) func(http.HandlerFunc) ht
 		return func(w http.ResponseWriter, r *http.Request) {
 			token := extractBearerToken(r.Header.Get("Authorization"))
 			if token == "" {
-				writeAuthError(w, r, http.StatusUnauthorized, "missing authorization header")
+				apierror.Write(w, r, apierror.New(apierror.CodeTokenMissing, ""))
 				return
 			}
 
 			claims, err := m.generator.ParseAccessToken(token)
 			if err != nil {
-				writeAuthError(w, r, http.StatusUnauthorized, "invalid or expired token")
+				apierror.Write(w, r, apierror.New(apierror.CodeTokenInvalid, ""))
 				return
 			}
 
 			userID, err := strconv.ParseUint(claims.UserID, 10, 64)
 			if err != nil {
-				writeAuthError(w, r, http.StatusUnauthorized, "invalid subject in token")
+				apierror.Write(w, r, apierror.New(apierror.CodeTokenInvalid, "invalid subject in token"))
 				return
 			}
 
 			user, err := m.users.Get(r.Context(), userID)
 			if err != nil {
-				writeAuthError(w, r, http.StatusUnauthorized, "user not found")
+				apierror.Write(w, r, apierror.New(apierror.CodeTokenInvalid, "user not found"))
 				return
 			}
 
 			if !strings.EqualFold(user.Status, "active") {
-				writeAuthError(w, r, http.StatusForbidden, "user is disabled")
+				apierror.Write(w, r, apierror.New(apierror.CodeUserDisabled, ""))
 				return
 			}
 
@@ -70,7 +69,7 @@ func (m *AuthMiddleware) RequireRoles(roles ...string) func(http.HandlerFunc) ht
 					}
 				}
 				if !allowed {
-					writeAuthError(w, r, http.StatusForbidden, "insufficient permissions")
+					apierror.Write(w, r, apierror.New(apierror.CodeForbidden, "insufficient permissions"))
 					return
 				}
 			}
@@ -102,9 +101,3 @@ func extractBearerToken(header string) string {
 
 	return strings.TrimSpace(parts[1])
 }
-
-func writeAuthError(w http.ResponseWriter, r *http.Request, status int, message string) {
-	httpx.WriteJsonCtx(r.Context(), w, status, map[string]any{
-		"message": message,
-	})
-}
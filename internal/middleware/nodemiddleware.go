@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zeromicro/go-zero/rest/pathvar"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/apierror"
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+)
+
+const headerNodeToken = "X-ZNP-Node-Token"
+
+// NodeMiddleware validates node callbacks with the per-node token issued by admins and an optional IP allowlist.
+type NodeMiddleware struct {
+	allowedNets []*net.IPNet
+	nodes       repository.NodeRepository
+}
+
+// NewNodeMiddleware builds middleware from config.
+func NewNodeMiddleware(cfg config.NodeConfig, nodes repository.NodeRepository) *NodeMiddleware {
+	var nets []*net.IPNet
+	for _, cidr := range cfg.AllowCIDRs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err == nil && network != nil {
+			nets = append(nets, network)
+		}
+	}
+
+	return &NodeMiddleware{
+		allowedNets: nets,
+		nodes:       nodes,
+	}
+}
+
+// Handler returns the http handler middleware. The token must belong to the node named by the :id path segment,
+// so a leaked credential cannot be used to read other nodes' user lists.
+func (m *NodeMiddleware) Handler(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(m.allowedNets) > 0 && !ipAllowed(m.allowedNets, clientIP(r)) {
+			apierror.Write(w, r, apierror.New(apierror.CodeAccessDenied, ""))
+			return
+		}
+
+		token := strings.TrimSpace(r.Header.Get(headerNodeToken))
+		nodeID, err := strconv.ParseUint(pathvar.Vars(r)["id"], 10, 64)
+		if token == "" || err != nil {
+			apierror.Write(w, r, apierror.New(apierror.CodeNodeTokenInvalid, ""))
+			return
+		}
+
+		node, err := m.nodes.Get(r.Context(), nodeID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			apierror.Write(w, r, fmt.Errorf("load node: %w", err))
+			return
+		}
+		if err != nil || !node.VerifyAgentToken(token) {
+			apierror.Write(w, r, apierror.New(apierror.CodeNodeTokenInvalid, ""))
+			return
+		}
+
+		next(w, r)
+	}
+}
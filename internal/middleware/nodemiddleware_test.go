@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zeromicro/go-zero/rest/pathvar"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/bootstrap/migrations"
+	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/repository"
+	"github.com/zero-net-panel/zero-net-panel/internal/testutil"
+)
+
+func TestNodeMiddlewareBindsTokenToNode(t *testing.T) {
+	testutil.RequireSQLite(t)
+
+	db, err := gorm.Open(sqlite.Open("file:nodemiddleware?mode=memory&cache=shared"), &gorm.Config{})
+	require.NoError(t, err)
+	defer func() {
+		if sqlDB, err := db.DB(); err == nil {
+			_ = sqlDB.Close()
+		}
+	}()
+
+	ctx := context.Background()
+	_, err = migrations.Apply(ctx, db, 0, false)
+	require.NoError(t, err)
+	repos, err := repository.NewRepositories(db)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	edge := repository.Node{Name: "edge", Status: repository.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&edge).Error)
+	other := repository.Node{Name: "other", Status: repository.NodeStatusOnline, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, db.Create(&other).Error)
+
+	_, token, err := repos.Node.RotateAgentToken(ctx, edge.ID)
+	require.NoError(t, err)
+
+	handler := NewNodeMiddleware(config.NodeConfig{}, repos.Node).Handler(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	call := func(nodeID, token string) int {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/node/"+nodeID+"/users", nil)
+		r = pathvar.WithVars(r, map[string]string{"id": nodeID})
+		if token != "" {
+			r.Header.Set(headerNodeToken, token)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w.Code
+	}
+
+	edgeID := strconv.FormatUint(edge.ID, 10)
+	otherID := strconv.FormatUint(other.ID, 10)
+	require.Equal(t, http.StatusNoContent, call(edgeID, token))
+	require.Equal(t, http.StatusUnauthorized, call(otherID, token))
+	require.Equal(t, http.StatusUnauthorized, call("404", token))
+	require.Equal(t, http.StatusUnauthorized, call(edgeID, ""))
+
+	// 重新签发后旧凭据立即失效。
+	_, rotated, err := repos.Node.RotateAgentToken(ctx, edge.ID)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, call(edgeID, token))
+	require.Equal(t, http.StatusNoContent, call(edgeID, rotated))
+}
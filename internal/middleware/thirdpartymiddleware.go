@@ -9,6 +9,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -16,8 +17,7 @@ import (
 	"sync"
 	"time"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
-
+	"github.com/zero-net-panel/zero-net-panel/internal/apierror"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 )
 
@@ -50,9 +50,7 @@ func (m *ThirdPartyMiddleware) Handler(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		setting, err := m.loadSetting(r.Context())
 		if err != nil {
-			httpx.WriteJsonCtx(r.Context(), w, http.StatusInternalServerError, map[string]any{
-				"message": "failed to load security settings",
-			})
+			apierror.Write(w, r, fmt.Errorf("load security settings: %w", err))
 			return
 		}
 		if !setting.ThirdPartyAPIEnabled || setting.APIKey == "" || setting.APISecret == "" {
@@ -61,13 +59,11 @@ func (m *ThirdPartyMiddleware) Handler(next http.HandlerFunc) http.HandlerFunc {
 		}
 
 		if err := m.verifyAndPrepareRequest(r.Context(), r, setting); err != nil {
-			status := http.StatusUnauthorized
 			if errors.Is(err, context.Canceled) {
-				status = http.StatusRequestTimeout
+				apierror.Write(w, r, err)
+				return
 			}
-			httpx.WriteJsonCtx(r.Context(), w, status, map[string]any{
-				"message": err.Error(),
-			})
+			apierror.Write(w, r, apierror.New(apierror.CodeSignatureInvalid, err.Error()))
 			return
 		}
 
@@ -14,8 +14,7 @@ import (
 	"strings"
 	"time"
 
-	"github.com/zeromicro/go-zero/rest/httpx"
-
+	"github.com/zero-net-panel/zero-net-panel/internal/apierror"
 	"github.com/zero-net-panel/zero-net-panel/internal/config"
 )
 
@@ -54,24 +53,18 @@ func NewWebhookMiddleware(cfg config.WebhookConfig) *WebhookMiddleware {
 func (m *WebhookMiddleware) Handler(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if len(m.allowedNets) > 0 && !ipAllowed(m.allowedNets, clientIP(r)) {
-			httpx.WriteJsonCtx(r.Context(), w, http.StatusForbidden, map[string]any{
-				"message": "webhook access denied",
-			})
+			apierror.Write(w, r, apierror.New(apierror.CodeAccessDenied, ""))
 			return
 		}
 
 		body, err := readWebhookBody(r)
 		if err != nil {
-			httpx.WriteJsonCtx(r.Context(), w, http.StatusBadRequest, map[string]any{
-				"message": "invalid webhook payload",
-			})
+			apierror.Write(w, r, apierror.New(apierror.CodeInvalidArgument, "invalid webhook payload"))
 			return
 		}
 
 		if err := m.verify(body, r); err != nil {
-			httpx.WriteJsonCtx(r.Context(), w, http.StatusUnauthorized, map[string]any{
-				"message": err.Error(),
-			})
+			apierror.Write(w, r, apierror.New(apierror.CodeSignatureInvalid, err.Error()))
 			return
 		}
 
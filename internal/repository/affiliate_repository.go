@@ -0,0 +1,510 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 佣金流水类型。
+const (
+	CommissionEntryAccrual      = "accrual"
+	CommissionEntryReversal     = "reversal"
+	CommissionEntryWithdrawal   = "withdrawal"
+	CommissionEntryPayout       = "payout"
+	CommissionEntryPayoutReturn = "payout_return"
+)
+
+// 佣金提现申请状态。
+const (
+	CommissionPayoutStatusPending  = "pending"
+	CommissionPayoutStatusApproved = "approved"
+	CommissionPayoutStatusRejected = "rejected"
+)
+
+// InviteCode 用户专属邀请码。
+type InviteCode struct {
+	UserID    uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Code      string `gorm:"size:32;uniqueIndex"`
+	CreatedAt time.Time
+}
+
+// TableName 自定义邀请码表名。
+func (InviteCode) TableName() string { return "invite_codes" }
+
+// Referral 记录被邀请用户与邀请人的关系，每个用户至多一条。
+type Referral struct {
+	ID         uint64 `gorm:"primaryKey"`
+	UserID     uint64 `gorm:"uniqueIndex"`
+	ReferrerID uint64 `gorm:"index"`
+	InviteCode string `gorm:"size:32"`
+	CreatedAt  time.Time
+}
+
+// TableName 自定义邀请关系表名。
+func (Referral) TableName() string { return "referrals" }
+
+// CommissionAccount 佣金账户汇总，与钱包余额分开记账。
+type CommissionAccount struct {
+	UserID           uint64 `gorm:"primaryKey;autoIncrement:false"`
+	BalanceCents     int64  `gorm:"column:balance_cents"`
+	TotalEarnedCents int64  `gorm:"column:total_earned_cents"`
+	Currency         string `gorm:"size:16"`
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+}
+
+// TableName 自定义佣金账户表名。
+func (CommissionAccount) TableName() string { return "commission_accounts" }
+
+// CommissionEntry 佣金流水；SourceKey 保证同一来源只记账一次。
+type CommissionEntry struct {
+	ID                uint64         `gorm:"primaryKey"`
+	UserID            uint64         `gorm:"index"`
+	Type              string         `gorm:"size:32"`
+	AmountCents       int64          `gorm:"column:amount_cents"`
+	Currency          string         `gorm:"size:16"`
+	BalanceAfterCents int64          `gorm:"column:balance_after_cents"`
+	SourceKey         string         `gorm:"size:64;uniqueIndex"`
+	OrderID           uint64         `gorm:"index"`
+	ReferredUserID    uint64         `gorm:"column:referred_user_id"`
+	PayoutID          *uint64        `gorm:"column:payout_id"`
+	Description       string         `gorm:"size:255"`
+	Metadata          map[string]any `gorm:"serializer:json"`
+	CreatedAt         time.Time
+}
+
+// TableName 自定义佣金流水表名。
+func (CommissionEntry) TableName() string { return "commission_entries" }
+
+// CommissionPayout 需管理员审核的佣金提现申请。
+type CommissionPayout struct {
+	ID          uint64 `gorm:"primaryKey"`
+	UserID      uint64 `gorm:"index"`
+	AmountCents int64  `gorm:"column:amount_cents"`
+	Currency    string `gorm:"size:16"`
+	Status      string `gorm:"size:16;index"`
+	Account     string `gorm:"size:255"`
+	Note        string `gorm:"size:255"`
+	ReviewNote  string `gorm:"size:255"`
+	ReviewedBy  string `gorm:"size:255"`
+	ReviewedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName 自定义佣金提现表名。
+func (CommissionPayout) TableName() string { return "commission_payouts" }
+
+// ListCommissionEntriesOptions 佣金流水分页。
+type ListCommissionEntriesOptions struct {
+	Page    int
+	PerPage int
+	Type    string
+}
+
+// ListCommissionPayoutsOptions 提现申请分页与过滤。
+type ListCommissionPayoutsOptions struct {
+	Page    int
+	PerPage int
+	Status  string
+	UserID  *uint64
+}
+
+// AffiliateRepository 管理邀请码、邀请关系与佣金账本。
+type AffiliateRepository interface {
+	GetOrCreateInviteCode(ctx context.Context, userID uint64) (InviteCode, error)
+	FindInviteCode(ctx context.Context, code string) (InviteCode, error)
+	CreateReferral(ctx context.Context, referral Referral) (Referral, error)
+	GetReferral(ctx context.Context, userID uint64) (Referral, error)
+	CountReferrals(ctx context.Context, referrerID uint64) (int64, error)
+
+	GetAccount(ctx context.Context, userID uint64) (CommissionAccount, error)
+	ApplyEntry(ctx context.Context, entry CommissionEntry) (CommissionEntry, CommissionAccount, error)
+	ListEntries(ctx context.Context, userID uint64, opts ListCommissionEntriesOptions) ([]CommissionEntry, int64, error)
+	ListOrderEntries(ctx context.Context, orderID uint64) ([]CommissionEntry, error)
+
+	CreatePayout(ctx context.Context, payout CommissionPayout) (CommissionPayout, error)
+	UpdatePayoutStatus(ctx context.Context, id uint64, status, reviewer, note string) (CommissionPayout, error)
+	ListPayouts(ctx context.Context, opts ListCommissionPayoutsOptions) ([]CommissionPayout, int64, error)
+}
+
+type affiliateRepository struct {
+	db *gorm.DB
+}
+
+// NewAffiliateRepository 创建推广仓储。
+func NewAffiliateRepository(db *gorm.DB) (AffiliateRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &affiliateRepository{db: db}, nil
+}
+
+const inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+func generateInviteCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	for i := range buf {
+		buf[i] = inviteCodeAlphabet[int(buf[i])%len(inviteCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// NormalizeInviteCode 统一邀请码大小写。
+func NormalizeInviteCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func (r *affiliateRepository) GetOrCreateInviteCode(ctx context.Context, userID uint64) (InviteCode, error) {
+	if err := ctx.Err(); err != nil {
+		return InviteCode{}, err
+	}
+	if userID == 0 {
+		return InviteCode{}, ErrInvalidArgument
+	}
+
+	var existing InviteCode
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&existing).Error
+	if err == nil {
+		return existing, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return InviteCode{}, err
+	}
+
+	// 随机码冲突概率极低，冲突时重试几次。
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generateInviteCode()
+		if err != nil {
+			return InviteCode{}, err
+		}
+		invite := InviteCode{UserID: userID, Code: code, CreatedAt: time.Now().UTC()}
+		err = r.db.WithContext(ctx).Create(&invite).Error
+		if err == nil {
+			return invite, nil
+		}
+		if !errors.Is(translateError(err), ErrConflict) {
+			return InviteCode{}, err
+		}
+		// 并发创建时可能已由其他请求写入。
+		if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&existing).Error; err == nil {
+			return existing, nil
+		}
+	}
+
+	return InviteCode{}, ErrConflict
+}
+
+func (r *affiliateRepository) FindInviteCode(ctx context.Context, code string) (InviteCode, error) {
+	if err := ctx.Err(); err != nil {
+		return InviteCode{}, err
+	}
+
+	code = NormalizeInviteCode(code)
+	if code == "" {
+		return InviteCode{}, ErrInvalidArgument
+	}
+
+	var invite InviteCode
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&invite).Error; err != nil {
+		return InviteCode{}, translateError(err)
+	}
+	return invite, nil
+}
+
+func (r *affiliateRepository) CreateReferral(ctx context.Context, referral Referral) (Referral, error) {
+	if err := ctx.Err(); err != nil {
+		return Referral{}, err
+	}
+	if referral.UserID == 0 || referral.ReferrerID == 0 || referral.UserID == referral.ReferrerID {
+		return Referral{}, ErrInvalidArgument
+	}
+
+	referral.ID = 0
+	referral.InviteCode = NormalizeInviteCode(referral.InviteCode)
+	if referral.CreatedAt.IsZero() {
+		referral.CreatedAt = time.Now().UTC()
+	}
+	if err := r.db.WithContext(ctx).Create(&referral).Error; err != nil {
+		return Referral{}, translateError(err)
+	}
+	return referral, nil
+}
+
+func (r *affiliateRepository) GetReferral(ctx context.Context, userID uint64) (Referral, error) {
+	if err := ctx.Err(); err != nil {
+		return Referral{}, err
+	}
+
+	var referral Referral
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&referral).Error; err != nil {
+		return Referral{}, translateError(err)
+	}
+	return referral, nil
+}
+
+func (r *affiliateRepository) CountReferrals(ctx context.Context, referrerID uint64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&Referral{}).Where("referrer_id = ?", referrerID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *affiliateRepository) GetAccount(ctx context.Context, userID uint64) (CommissionAccount, error) {
+	if err := ctx.Err(); err != nil {
+		return CommissionAccount{}, err
+	}
+
+	var account CommissionAccount
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&account).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return CommissionAccount{UserID: userID}, nil
+	}
+	if err != nil {
+		return CommissionAccount{}, err
+	}
+	return account, nil
+}
+
+// ApplyEntry 写入佣金流水并更新账户余额。SourceKey 已存在时返回已有流水；
+// 提现类流水（withdrawal/payout）不允许余额为负，冲正允许透支。
+func (r *affiliateRepository) ApplyEntry(ctx context.Context, entry CommissionEntry) (CommissionEntry, CommissionAccount, error) {
+	if err := ctx.Err(); err != nil {
+		return CommissionEntry{}, CommissionAccount{}, err
+	}
+
+	entry.SourceKey = strings.TrimSpace(entry.SourceKey)
+	if entry.UserID == 0 || entry.AmountCents == 0 || entry.SourceKey == "" {
+		return CommissionEntry{}, CommissionAccount{}, ErrInvalidArgument
+	}
+
+	var (
+		resultEntry   CommissionEntry
+		resultAccount CommissionAccount
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing CommissionEntry
+		err := tx.Where("source_key = ?", entry.SourceKey).First(&existing).Error
+		if err == nil {
+			resultEntry = existing
+			return tx.Where("user_id = ?", existing.UserID).First(&resultAccount).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now().UTC()
+		var account CommissionAccount
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", entry.UserID).First(&account).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			account = CommissionAccount{UserID: entry.UserID, Currency: entry.Currency, CreatedAt: now, UpdatedAt: now}
+			if err := tx.Create(&account).Error; err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		if account.Currency == "" {
+			account.Currency = entry.Currency
+		}
+		if entry.Currency != "" && !strings.EqualFold(entry.Currency, account.Currency) {
+			return ErrInvalidArgument
+		}
+
+		newBalance := account.BalanceCents + entry.AmountCents
+		switch entry.Type {
+		case CommissionEntryWithdrawal, CommissionEntryPayout:
+			if entry.AmountCents > 0 {
+				return ErrInvalidArgument
+			}
+			if newBalance < 0 {
+				return ErrInsufficientBalance
+			}
+		}
+		if entry.Type == CommissionEntryAccrual || entry.Type == CommissionEntryReversal {
+			account.TotalEarnedCents += entry.AmountCents
+		}
+
+		entry.ID = 0
+		entry.Currency = account.Currency
+		entry.BalanceAfterCents = newBalance
+		entry.CreatedAt = now
+		if entry.Metadata == nil {
+			entry.Metadata = map[string]any{}
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+
+		account.BalanceCents = newBalance
+		account.UpdatedAt = now
+		if err := tx.Model(&CommissionAccount{}).Where("user_id = ?", entry.UserID).Updates(map[string]any{
+			"balance_cents":      account.BalanceCents,
+			"total_earned_cents": account.TotalEarnedCents,
+			"currency":           account.Currency,
+			"updated_at":         now,
+		}).Error; err != nil {
+			return err
+		}
+
+		resultEntry = entry
+		resultAccount = account
+		return nil
+	})
+	if err != nil {
+		return CommissionEntry{}, CommissionAccount{}, translateError(err)
+	}
+
+	return resultEntry, resultAccount, nil
+}
+
+func (r *affiliateRepository) ListEntries(ctx context.Context, userID uint64, opts ListCommissionEntriesOptions) ([]CommissionEntry, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts.Page, opts.PerPage = normalizeAffiliatePage(opts.Page, opts.PerPage)
+	base := r.db.WithContext(ctx).Model(&CommissionEntry{}).Where("user_id = ?", userID)
+	if t := strings.TrimSpace(strings.ToLower(opts.Type)); t != "" {
+		base = base.Where("type = ?", t)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []CommissionEntry{}, 0, nil
+	}
+
+	var entries []CommissionEntry
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("created_at DESC, id DESC").Limit(opts.PerPage).Offset(offset).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+func (r *affiliateRepository) ListOrderEntries(ctx context.Context, orderID uint64) ([]CommissionEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var entries []CommissionEntry
+	if err := r.db.WithContext(ctx).Where("order_id = ?", orderID).Order("id ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *affiliateRepository) CreatePayout(ctx context.Context, payout CommissionPayout) (CommissionPayout, error) {
+	if err := ctx.Err(); err != nil {
+		return CommissionPayout{}, err
+	}
+	if payout.UserID == 0 || payout.AmountCents <= 0 {
+		return CommissionPayout{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	payout.ID = 0
+	payout.Status = CommissionPayoutStatusPending
+	payout.CreatedAt = now
+	payout.UpdatedAt = now
+	if err := r.db.WithContext(ctx).Create(&payout).Error; err != nil {
+		return CommissionPayout{}, translateError(err)
+	}
+	return payout, nil
+}
+
+// UpdatePayoutStatus 仅允许 pending 申请流转为 approved/rejected。
+func (r *affiliateRepository) UpdatePayoutStatus(ctx context.Context, id uint64, status, reviewer, note string) (CommissionPayout, error) {
+	if err := ctx.Err(); err != nil {
+		return CommissionPayout{}, err
+	}
+	if status != CommissionPayoutStatusApproved && status != CommissionPayoutStatusRejected {
+		return CommissionPayout{}, ErrInvalidArgument
+	}
+
+	var payout CommissionPayout
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&payout, id).Error; err != nil {
+			return err
+		}
+		if payout.Status != CommissionPayoutStatusPending {
+			return ErrInvalidState
+		}
+
+		now := time.Now().UTC()
+		payout.Status = status
+		payout.ReviewedBy = reviewer
+		payout.ReviewNote = note
+		payout.ReviewedAt = &now
+		payout.UpdatedAt = now
+		return tx.Model(&CommissionPayout{}).Where("id = ?", id).Updates(map[string]any{
+			"status":      payout.Status,
+			"reviewed_by": payout.ReviewedBy,
+			"review_note": payout.ReviewNote,
+			"reviewed_at": now,
+			"updated_at":  now,
+		}).Error
+	})
+	if err != nil {
+		return CommissionPayout{}, translateError(err)
+	}
+	return payout, nil
+}
+
+func (r *affiliateRepository) ListPayouts(ctx context.Context, opts ListCommissionPayoutsOptions) ([]CommissionPayout, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts.Page, opts.PerPage = normalizeAffiliatePage(opts.Page, opts.PerPage)
+	base := r.db.WithContext(ctx).Model(&CommissionPayout{})
+	if status := strings.TrimSpace(strings.ToLower(opts.Status)); status != "" {
+		base = base.Where("status = ?", status)
+	}
+	if opts.UserID != nil {
+		base = base.Where("user_id = ?", *opts.UserID)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []CommissionPayout{}, 0, nil
+	}
+
+	var payouts []CommissionPayout
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("created_at DESC, id DESC").Limit(opts.PerPage).Offset(offset).Find(&payouts).Error; err != nil {
+		return nil, 0, err
+	}
+	return payouts, total, nil
+}
+
+func normalizeAffiliatePage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ClientTypeRule 管理员维护的 User-Agent 路由规则，按 Priority 升序（相同时按 ID）匹配，先于内置识别规则生效。
+type ClientTypeRule struct {
+	ID          uint64 `gorm:"primaryKey"`
+	Pattern     string `gorm:"size:255"`
+	ClientType  string `gorm:"size:64"`
+	Description string `gorm:"size:255"`
+	Priority    int    `gorm:"index"`
+	Enabled     bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName binds client type rules.
+func (ClientTypeRule) TableName() string { return "client_type_rules" }
+
+// ClientTypeRuleRepository 管理 User-Agent 路由规则。
+type ClientTypeRuleRepository interface {
+	List(ctx context.Context) ([]ClientTypeRule, error)
+	Get(ctx context.Context, id uint64) (ClientTypeRule, error)
+	Create(ctx context.Context, rule ClientTypeRule) (ClientTypeRule, error)
+	Update(ctx context.Context, id uint64, rule ClientTypeRule) (ClientTypeRule, error)
+	Delete(ctx context.Context, id uint64) error
+}
+
+type clientTypeRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewClientTypeRuleRepository 创建 User-Agent 路由规则仓储。
+func NewClientTypeRuleRepository(db *gorm.DB) (ClientTypeRuleRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &clientTypeRuleRepository{db: db}, nil
+}
+
+func (r *clientTypeRuleRepository) List(ctx context.Context) ([]ClientTypeRule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var rules []ClientTypeRule
+	if err := r.db.WithContext(ctx).Order("priority ASC").Order("id ASC").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *clientTypeRuleRepository) Get(ctx context.Context, id uint64) (ClientTypeRule, error) {
+	if err := ctx.Err(); err != nil {
+		return ClientTypeRule{}, err
+	}
+
+	var rule ClientTypeRule
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		return ClientTypeRule{}, translateError(err)
+	}
+	return rule, nil
+}
+
+func (r *clientTypeRuleRepository) Create(ctx context.Context, rule ClientTypeRule) (ClientTypeRule, error) {
+	if err := ctx.Err(); err != nil {
+		return ClientTypeRule{}, err
+	}
+
+	rule, err := normalizeClientTypeRule(rule)
+	if err != nil {
+		return ClientTypeRule{}, err
+	}
+	now := time.Now().UTC()
+	rule.ID = 0
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&rule).Error; err != nil {
+		return ClientTypeRule{}, translateError(err)
+	}
+	return rule, nil
+}
+
+func (r *clientTypeRuleRepository) Update(ctx context.Context, id uint64, rule ClientTypeRule) (ClientTypeRule, error) {
+	if err := ctx.Err(); err != nil {
+		return ClientTypeRule{}, err
+	}
+
+	rule, err := normalizeClientTypeRule(rule)
+	if err != nil {
+		return ClientTypeRule{}, err
+	}
+
+	var existing ClientTypeRule
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&existing, id).Error; err != nil {
+			return err
+		}
+
+		existing.Pattern = rule.Pattern
+		existing.ClientType = rule.ClientType
+		existing.Description = rule.Description
+		existing.Priority = rule.Priority
+		existing.Enabled = rule.Enabled
+		existing.UpdatedAt = time.Now().UTC()
+		return tx.Model(&existing).
+			Select("Pattern", "ClientType", "Description", "Priority", "Enabled", "UpdatedAt").
+			Updates(existing).Error
+	})
+	if err != nil {
+		return ClientTypeRule{}, translateError(err)
+	}
+	return existing, nil
+}
+
+func (r *clientTypeRuleRepository) Delete(ctx context.Context, id uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).Delete(&ClientTypeRule{}, id)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// maxRuleClientTypeLength 与拉取日志 client_type 列宽一致。
+const maxRuleClientTypeLength = 32
+
+// normalizeClientTypeRule 规整字段；正则语法由调用方在写入前校验。
+func normalizeClientTypeRule(rule ClientTypeRule) (ClientTypeRule, error) {
+	rule.Pattern = strings.TrimSpace(rule.Pattern)
+	rule.ClientType = strings.ToLower(strings.TrimSpace(rule.ClientType))
+	rule.Description = strings.TrimSpace(rule.Description)
+	if rule.Pattern == "" || rule.ClientType == "" || len(rule.ClientType) > maxRuleClientTypeLength {
+		return ClientTypeRule{}, ErrInvalidArgument
+	}
+	return rule, nil
+}
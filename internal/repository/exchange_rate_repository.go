@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExchangeRate 记录 1 单位 BaseCurrency 可兑换的 QuoteCurrency 数量。
+type ExchangeRate struct {
+	ID            uint64  `gorm:"primaryKey"`
+	BaseCurrency  string  `gorm:"size:16;uniqueIndex:idx_exchange_rates_pair"`
+	QuoteCurrency string  `gorm:"size:16;uniqueIndex:idx_exchange_rates_pair"`
+	Rate          float64 `gorm:"column:rate"`
+	Source        string  `gorm:"size:64"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// TableName 自定义汇率表名。
+func (ExchangeRate) TableName() string { return "exchange_rates" }
+
+// ListExchangeRatesOptions 过滤汇率列表。
+type ListExchangeRatesOptions struct {
+	BaseCurrency  string
+	QuoteCurrency string
+}
+
+// ExchangeRateRepository 管理币种汇率。
+type ExchangeRateRepository interface {
+	List(ctx context.Context, opts ListExchangeRatesOptions) ([]ExchangeRate, error)
+	Find(ctx context.Context, baseCurrency, quoteCurrency string) (ExchangeRate, error)
+	Upsert(ctx context.Context, rates []ExchangeRate) ([]ExchangeRate, error)
+}
+
+type exchangeRateRepository struct {
+	db *gorm.DB
+}
+
+// NewExchangeRateRepository 创建汇率仓储。
+func NewExchangeRateRepository(db *gorm.DB) (ExchangeRateRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &exchangeRateRepository{db: db}, nil
+}
+
+func (r *exchangeRateRepository) List(ctx context.Context, opts ListExchangeRatesOptions) ([]ExchangeRate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	query := r.db.WithContext(ctx).Model(&ExchangeRate{})
+	if base := NormalizeCurrency(opts.BaseCurrency); base != "" {
+		query = query.Where("base_currency = ?", base)
+	}
+	if quote := NormalizeCurrency(opts.QuoteCurrency); quote != "" {
+		query = query.Where("quote_currency = ?", quote)
+	}
+
+	var rates []ExchangeRate
+	if err := query.Order("base_currency ASC, quote_currency ASC").Find(&rates).Error; err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+func (r *exchangeRateRepository) Find(ctx context.Context, baseCurrency, quoteCurrency string) (ExchangeRate, error) {
+	if err := ctx.Err(); err != nil {
+		return ExchangeRate{}, err
+	}
+
+	base := NormalizeCurrency(baseCurrency)
+	quote := NormalizeCurrency(quoteCurrency)
+	if base == "" || quote == "" {
+		return ExchangeRate{}, ErrInvalidArgument
+	}
+
+	var rate ExchangeRate
+	if err := r.db.WithContext(ctx).
+		Where("base_currency = ? AND quote_currency = ?", base, quote).
+		First(&rate).Error; err != nil {
+		return ExchangeRate{}, translateError(err)
+	}
+
+	return rate, nil
+}
+
+func (r *exchangeRateRepository) Upsert(ctx context.Context, rates []ExchangeRate) ([]ExchangeRate, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(rates) == 0 {
+		return nil, ErrInvalidArgument
+	}
+
+	for i := range rates {
+		rates[i].BaseCurrency = NormalizeCurrency(rates[i].BaseCurrency)
+		rates[i].QuoteCurrency = NormalizeCurrency(rates[i].QuoteCurrency)
+		rates[i].Source = strings.TrimSpace(rates[i].Source)
+		if rates[i].BaseCurrency == "" || rates[i].QuoteCurrency == "" ||
+			rates[i].BaseCurrency == rates[i].QuoteCurrency || rates[i].Rate <= 0 {
+			return nil, ErrInvalidArgument
+		}
+	}
+
+	result := make([]ExchangeRate, 0, len(rates))
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		for _, rate := range rates {
+			var existing ExchangeRate
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("base_currency = ? AND quote_currency = ?", rate.BaseCurrency, rate.QuoteCurrency).
+				First(&existing).Error
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				rate.ID = 0
+				rate.CreatedAt = now
+				rate.UpdatedAt = now
+				if err := tx.Create(&rate).Error; err != nil {
+					return err
+				}
+				result = append(result, rate)
+			case err != nil:
+				return err
+			default:
+				existing.Rate = rate.Rate
+				existing.Source = rate.Source
+				existing.UpdatedAt = now
+				if err := tx.Model(&ExchangeRate{}).Where("id = ?", existing.ID).Updates(map[string]any{
+					"rate":       existing.Rate,
+					"source":     existing.Source,
+					"updated_at": now,
+				}).Error; err != nil {
+					return err
+				}
+				result = append(result, existing)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return result, nil
+}
+
+// NormalizeCurrency 统一币种代码为大写。
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
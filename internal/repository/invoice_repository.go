@@ -0,0 +1,305 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+// BillingProfile 用户开票信息。
+type BillingProfile struct {
+	ID          uint64 `gorm:"primaryKey"`
+	UserID      uint64 `gorm:"uniqueIndex"`
+	Name        string `gorm:"size:255"`
+	CompanyName string `gorm:"size:255"`
+	TaxID       string `gorm:"size:64"`
+	Email       string `gorm:"size:255"`
+	Phone       string `gorm:"size:64"`
+	Address     string `gorm:"size:512"`
+	Country     string `gorm:"size:64"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName 自定义开票信息表名。
+func (BillingProfile) TableName() string { return "billing_profiles" }
+
+// InvoiceLine 发票明细行。
+type InvoiceLine struct {
+	Name           string `json:"name"`
+	Quantity       int    `json:"quantity"`
+	UnitPriceCents int64  `json:"unit_price_cents"`
+	AmountCents    int64  `json:"amount_cents"`
+}
+
+// Invoice 发票或红字发票（credit note），开具后不可修改。
+type Invoice struct {
+	ID               uint64         `gorm:"primaryKey"`
+	Number           string         `gorm:"size:40;uniqueIndex"`
+	Kind             string         `gorm:"size:16;index"`
+	SourceKey        string         `gorm:"size:64;uniqueIndex"`
+	Year             int            `gorm:"column:year"`
+	Sequence         int64          `gorm:"column:sequence"`
+	UserID           uint64         `gorm:"index"`
+	OrderID          uint64         `gorm:"index"`
+	OrderNumber      string         `gorm:"size:40"`
+	RefundID         *uint64        `gorm:"column:refund_id"`
+	RelatedInvoiceID *uint64        `gorm:"column:related_invoice_id"`
+	Currency         string         `gorm:"size:16"`
+	SubtotalCents    int64          `gorm:"column:subtotal_cents"`
+	TotalCents       int64          `gorm:"column:total_cents"`
+	Seller           map[string]any `gorm:"serializer:json"`
+	Buyer            map[string]any `gorm:"serializer:json"`
+	Lines            []InvoiceLine  `gorm:"serializer:json"`
+	Note             string         `gorm:"size:255"`
+	IssuedAt         time.Time      `gorm:"index"`
+	CreatedAt        time.Time
+}
+
+// TableName 自定义发票表名。
+func (Invoice) TableName() string { return "invoices" }
+
+// InvoiceSequence 记录每个编号前缀在每年的最新序号。
+type InvoiceSequence struct {
+	Series    string `gorm:"primaryKey;size:16"`
+	Year      int    `gorm:"primaryKey;autoIncrement:false"`
+	LastValue int64  `gorm:"column:last_value"`
+	UpdatedAt time.Time
+}
+
+// TableName 自定义发票序号表名。
+func (InvoiceSequence) TableName() string { return "invoice_sequences" }
+
+// ListInvoicesOptions 控制发票列表过滤。
+type ListInvoicesOptions struct {
+	Page    int
+	PerPage int
+	Kind    string
+	UserID  *uint64
+	OrderID *uint64
+	Number  string
+}
+
+// InvoiceRepository 管理开票信息与发票。
+type InvoiceRepository interface {
+	GetBillingProfile(ctx context.Context, userID uint64) (BillingProfile, error)
+	SaveBillingProfile(ctx context.Context, profile BillingProfile) (BillingProfile, error)
+	Issue(ctx context.Context, invoice Invoice, series string) (Invoice, error)
+	Get(ctx context.Context, id uint64) (Invoice, error)
+	GetBySourceKey(ctx context.Context, sourceKey string) (Invoice, error)
+	List(ctx context.Context, opts ListInvoicesOptions) ([]Invoice, int64, error)
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+// NewInvoiceRepository 创建发票仓储。
+func NewInvoiceRepository(db *gorm.DB) (InvoiceRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &invoiceRepository{db: db}, nil
+}
+
+func (r *invoiceRepository) GetBillingProfile(ctx context.Context, userID uint64) (BillingProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return BillingProfile{}, err
+	}
+
+	var profile BillingProfile
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		return BillingProfile{}, translateError(err)
+	}
+	return profile, nil
+}
+
+func (r *invoiceRepository) SaveBillingProfile(ctx context.Context, profile BillingProfile) (BillingProfile, error) {
+	if err := ctx.Err(); err != nil {
+		return BillingProfile{}, err
+	}
+	if profile.UserID == 0 {
+		return BillingProfile{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing BillingProfile
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", profile.UserID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			profile.ID = 0
+			profile.CreatedAt = now
+			profile.UpdatedAt = now
+			return tx.Create(&profile).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		profile.ID = existing.ID
+		profile.CreatedAt = existing.CreatedAt
+		profile.UpdatedAt = now
+		return tx.Save(&profile).Error
+	})
+	if err != nil {
+		return BillingProfile{}, translateError(err)
+	}
+
+	return profile, nil
+}
+
+// Issue 分配 "<series>-<year>-<sequence>" 编号并写入发票；SourceKey 已存在时直接返回已有发票。
+func (r *invoiceRepository) Issue(ctx context.Context, invoice Invoice, series string) (Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return Invoice{}, err
+	}
+
+	series = strings.ToUpper(strings.TrimSpace(series))
+	invoice.SourceKey = strings.TrimSpace(invoice.SourceKey)
+	if series == "" || invoice.SourceKey == "" || invoice.UserID == 0 {
+		return Invoice{}, ErrInvalidArgument
+	}
+	if invoice.IssuedAt.IsZero() {
+		invoice.IssuedAt = time.Now().UTC()
+	}
+
+	var result Invoice
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing Invoice
+		err := tx.Where("source_key = ?", invoice.SourceKey).First(&existing).Error
+		if err == nil {
+			result = existing
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		now := time.Now().UTC()
+		year := invoice.IssuedAt.UTC().Year()
+		seq := InvoiceSequence{Series: series, Year: year, UpdatedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&seq).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("series = ? AND year = ?", series, year).
+			First(&seq).Error; err != nil {
+			return err
+		}
+		seq.LastValue++
+		if err := tx.Model(&InvoiceSequence{}).
+			Where("series = ? AND year = ?", series, year).
+			Updates(map[string]any{"last_value": seq.LastValue, "updated_at": now}).Error; err != nil {
+			return err
+		}
+
+		invoice.ID = 0
+		invoice.Year = year
+		invoice.Sequence = seq.LastValue
+		invoice.Number = fmt.Sprintf("%s-%d-%06d", series, year, seq.LastValue)
+		invoice.CreatedAt = now
+		if invoice.Seller == nil {
+			invoice.Seller = map[string]any{}
+		}
+		if invoice.Buyer == nil {
+			invoice.Buyer = map[string]any{}
+		}
+		if invoice.Lines == nil {
+			invoice.Lines = []InvoiceLine{}
+		}
+		if err := tx.Create(&invoice).Error; err != nil {
+			return err
+		}
+		result = invoice
+		return nil
+	})
+	if err != nil {
+		return Invoice{}, translateError(err)
+	}
+
+	return result, nil
+}
+
+func (r *invoiceRepository) Get(ctx context.Context, id uint64) (Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return Invoice{}, err
+	}
+
+	var invoice Invoice
+	if err := r.db.WithContext(ctx).First(&invoice, id).Error; err != nil {
+		return Invoice{}, translateError(err)
+	}
+	return invoice, nil
+}
+
+func (r *invoiceRepository) GetBySourceKey(ctx context.Context, sourceKey string) (Invoice, error) {
+	if err := ctx.Err(); err != nil {
+		return Invoice{}, err
+	}
+
+	var invoice Invoice
+	if err := r.db.WithContext(ctx).Where("source_key = ?", sourceKey).First(&invoice).Error; err != nil {
+		return Invoice{}, translateError(err)
+	}
+	return invoice, nil
+}
+
+func (r *invoiceRepository) List(ctx context.Context, opts ListInvoicesOptions) ([]Invoice, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts = normalizeListInvoicesOptions(opts)
+
+	base := r.db.WithContext(ctx).Model(&Invoice{})
+	if opts.Kind != "" {
+		base = base.Where("kind = ?", opts.Kind)
+	}
+	if opts.UserID != nil {
+		base = base.Where("user_id = ?", *opts.UserID)
+	}
+	if opts.OrderID != nil {
+		base = base.Where("order_id = ?", *opts.OrderID)
+	}
+	if opts.Number != "" {
+		base = base.Where("LOWER(number) LIKE ?", fmt.Sprintf("%%%s%%", opts.Number))
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []Invoice{}, 0, nil
+	}
+
+	offset := (opts.Page - 1) * opts.PerPage
+	var invoices []Invoice
+	if err := base.Session(&gorm.Session{}).Order("issued_at DESC, id DESC").Limit(opts.PerPage).Offset(offset).Find(&invoices).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, total, nil
+}
+
+func normalizeListInvoicesOptions(opts ListInvoicesOptions) ListInvoicesOptions {
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if opts.PerPage <= 0 || opts.PerPage > 100 {
+		opts.PerPage = 20
+	}
+	opts.Kind = strings.TrimSpace(strings.ToLower(opts.Kind))
+	opts.Number = strings.TrimSpace(strings.ToLower(opts.Number))
+	return opts
+}
@@ -0,0 +1,266 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PlanNode 套餐直接授权的节点。
+type PlanNode struct {
+	PlanID    uint64 `gorm:"primaryKey;autoIncrement:false"`
+	NodeID    uint64 `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
+
+// TableName binds plan node entitlements.
+func (PlanNode) TableName() string { return "plan_nodes" }
+
+// NodeEntitlement 套餐可使用的节点范围：直接授权的节点与所授权分组成员的并集。
+// 套餐既未授权节点也未授权分组时不限节点。
+type NodeEntitlement struct {
+	Unrestricted bool
+	NodeIDs      map[uint64]struct{}
+}
+
+// Allows 判断节点是否在授权范围内。
+func (e NodeEntitlement) Allows(nodeID uint64) bool {
+	if e.Unrestricted {
+		return true
+	}
+	_, ok := e.NodeIDs[nodeID]
+	return ok
+}
+
+func (r *nodeRepository) ResolveEntitlement(ctx context.Context, planID uint64) (NodeEntitlement, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeEntitlement{}, err
+	}
+	if planID == 0 {
+		return NodeEntitlement{Unrestricted: true}, nil
+	}
+
+	db := r.db.WithContext(ctx)
+	var nodeIDs []uint64
+	if err := db.Model(&PlanNode{}).Where("plan_id = ?", planID).Pluck("node_id", &nodeIDs).Error; err != nil {
+		return NodeEntitlement{}, err
+	}
+	var groupIDs []uint64
+	if err := db.Model(&PlanNodeGroup{}).Where("plan_id = ?", planID).Pluck("group_id", &groupIDs).Error; err != nil {
+		return NodeEntitlement{}, err
+	}
+	if len(nodeIDs) == 0 && len(groupIDs) == 0 {
+		return NodeEntitlement{Unrestricted: true}, nil
+	}
+
+	entitlement := NodeEntitlement{NodeIDs: make(map[uint64]struct{}, len(nodeIDs))}
+	for _, id := range nodeIDs {
+		entitlement.NodeIDs[id] = struct{}{}
+	}
+	members, err := resolveGroupNodeIDs(db, groupIDs)
+	if err != nil {
+		return NodeEntitlement{}, err
+	}
+	for _, ids := range members {
+		for _, id := range ids {
+			entitlement.NodeIDs[id] = struct{}{}
+		}
+	}
+
+	return entitlement, nil
+}
+
+// ListEntitled 返回套餐可使用的 online 节点，按 sort_order、id 升序；planID 为 0 或套餐未配置授权时返回全部 online 节点。
+func (r *nodeRepository) ListEntitled(ctx context.Context, planID uint64) ([]Node, error) {
+	entitlement, err := r.ResolveEntitlement(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.db.WithContext(ctx).Model(&Node{}).Where("LOWER(status) = ?", NodeStatusOnline)
+	if !entitlement.Unrestricted {
+		if len(entitlement.NodeIDs) == 0 {
+			return []Node{}, nil
+		}
+		ids := make([]uint64, 0, len(entitlement.NodeIDs))
+		for id := range entitlement.NodeIDs {
+			ids = append(ids, id)
+		}
+		query = query.Where("id IN ?", ids)
+	}
+
+	var nodes []Node
+	if err := query.Order("sort_order ASC, id ASC").Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+func (r *planRepository) ListNodeIDs(ctx context.Context, planIDs ...uint64) (map[uint64][]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64][]uint64, len(planIDs))
+	if len(planIDs) == 0 {
+		return result, nil
+	}
+
+	var links []PlanNode
+	if err := r.db.WithContext(ctx).
+		Where("plan_id IN ?", planIDs).
+		Order("plan_id ASC, node_id ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		result[link.PlanID] = append(result[link.PlanID], link.NodeID)
+	}
+
+	return result, nil
+}
+
+func (r *planRepository) ReplaceNodes(ctx context.Context, planID uint64, nodeIDs []uint64) ([]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ids, err := dedupeIDs(nodeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Plan{}, planID).Error; err != nil {
+			return err
+		}
+		if err := requireNodes(tx, ids); err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&PlanNode{}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		links := make([]PlanNode, 0, len(ids))
+		for _, id := range ids {
+			links = append(links, PlanNode{PlanID: planID, NodeID: id, CreatedAt: now})
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return ids, nil
+}
+
+func (r *planRepository) ListNodeGroupIDs(ctx context.Context, planIDs ...uint64) (map[uint64][]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64][]uint64, len(planIDs))
+	if len(planIDs) == 0 {
+		return result, nil
+	}
+
+	var links []PlanNodeGroup
+	if err := r.db.WithContext(ctx).
+		Where("plan_id IN ?", planIDs).
+		Order("plan_id ASC, group_id ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		result[link.PlanID] = append(result[link.PlanID], link.GroupID)
+	}
+
+	return result, nil
+}
+
+func (r *planRepository) ReplaceNodeGroups(ctx context.Context, planID uint64, groupIDs []uint64) ([]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ids, err := dedupeIDs(groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Plan{}, planID).Error; err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			var count int64
+			if err := tx.Model(&NodeGroup{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+				return err
+			}
+			if count != int64(len(ids)) {
+				return ErrInvalidArgument
+			}
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&PlanNodeGroup{}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		links := make([]PlanNodeGroup, 0, len(ids))
+		for _, id := range ids {
+			links = append(links, PlanNodeGroup{PlanID: planID, GroupID: id, CreatedAt: now})
+		}
+		return tx.Create(&links).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return ids, nil
+}
+
+// dedupeIDs 去重并保持顺序，ID 为 0 视为非法参数。
+func dedupeIDs(ids []uint64) ([]uint64, error) {
+	seen := make(map[uint64]struct{}, len(ids))
+	result := make([]uint64, 0, len(ids))
+	for _, id := range ids {
+		if id == 0 {
+			return nil, ErrInvalidArgument
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// requireNodes 校验节点全部存在。
+func requireNodes(tx *gorm.DB, ids []uint64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	var count int64
+	if err := tx.Model(&Node{}).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return err
+	}
+	if count != int64(len(ids)) {
+		return ErrInvalidArgument
+	}
+	return nil
+}
+
+func sortIDs(ids []uint64) {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+}
@@ -0,0 +1,332 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NodeGroup 节点分组。成员由静态节点与 MatchTags 动态匹配（节点带有任一标签）共同组成。
+type NodeGroup struct {
+	ID          uint64   `gorm:"primaryKey"`
+	Name        string   `gorm:"size:128;uniqueIndex"`
+	Description string   `gorm:"size:255"`
+	MatchTags   []string `gorm:"column:match_tags;serializer:json"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// TableName binds node groups.
+func (NodeGroup) TableName() string { return "node_groups" }
+
+// NodeGroupNode 节点分组的静态成员。
+type NodeGroupNode struct {
+	GroupID   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	NodeID    uint64 `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
+
+// TableName binds static node group members.
+func (NodeGroupNode) TableName() string { return "node_group_nodes" }
+
+// PlanNodeGroup 套餐可使用的节点分组，订阅通过所属套餐继承。
+type PlanNodeGroup struct {
+	PlanID    uint64 `gorm:"primaryKey;autoIncrement:false"`
+	GroupID   uint64 `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
+
+// TableName binds plan node group entitlements.
+func (PlanNodeGroup) TableName() string { return "plan_node_groups" }
+
+// NodeGroupRepository 管理节点分组。
+type NodeGroupRepository interface {
+	List(ctx context.Context) ([]NodeGroup, error)
+	Get(ctx context.Context, id uint64) (NodeGroup, error)
+	Create(ctx context.Context, group NodeGroup, nodeIDs []uint64) (NodeGroup, error)
+	Update(ctx context.Context, id uint64, group NodeGroup, nodeIDs []uint64) (NodeGroup, error)
+	Delete(ctx context.Context, id uint64) error
+	ListNodeIDs(ctx context.Context, groupIDs ...uint64) (map[uint64][]uint64, error)
+	ResolveNodeIDs(ctx context.Context, groupIDs ...uint64) (map[uint64][]uint64, error)
+	ListPlanIDs(ctx context.Context, groupIDs ...uint64) (map[uint64][]uint64, error)
+}
+
+type nodeGroupRepository struct {
+	db *gorm.DB
+}
+
+// NewNodeGroupRepository 创建节点分组仓储。
+func NewNodeGroupRepository(db *gorm.DB) (NodeGroupRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &nodeGroupRepository{db: db}, nil
+}
+
+func (r *nodeGroupRepository) List(ctx context.Context) ([]NodeGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var groups []NodeGroup
+	if err := r.db.WithContext(ctx).Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+func (r *nodeGroupRepository) Get(ctx context.Context, id uint64) (NodeGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeGroup{}, err
+	}
+
+	var group NodeGroup
+	if err := r.db.WithContext(ctx).First(&group, id).Error; err != nil {
+		return NodeGroup{}, translateError(err)
+	}
+	return group, nil
+}
+
+func (r *nodeGroupRepository) Create(ctx context.Context, group NodeGroup, nodeIDs []uint64) (NodeGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeGroup{}, err
+	}
+
+	group.Name = strings.TrimSpace(group.Name)
+	if group.Name == "" {
+		return NodeGroup{}, ErrInvalidArgument
+	}
+	group.MatchTags = normalizeMatchTags(group.MatchTags)
+	now := time.Now().UTC()
+	group.CreatedAt = now
+	group.UpdatedAt = now
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&group).Error; err != nil {
+			return err
+		}
+		return replaceGroupNodes(tx, group.ID, nodeIDs, now)
+	})
+	if err != nil {
+		return NodeGroup{}, translateError(err)
+	}
+	return group, nil
+}
+
+func (r *nodeGroupRepository) Update(ctx context.Context, id uint64, group NodeGroup, nodeIDs []uint64) (NodeGroup, error) {
+	if err := ctx.Err(); err != nil {
+		return NodeGroup{}, err
+	}
+
+	name := strings.TrimSpace(group.Name)
+	if name == "" {
+		return NodeGroup{}, ErrInvalidArgument
+	}
+
+	var existing NodeGroup
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&existing, id).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		existing.Name = name
+		existing.Description = strings.TrimSpace(group.Description)
+		existing.MatchTags = normalizeMatchTags(group.MatchTags)
+		existing.UpdatedAt = now
+		if err := tx.Model(&existing).
+			Select("Name", "Description", "MatchTags", "UpdatedAt").
+			Updates(existing).Error; err != nil {
+			return err
+		}
+
+		// nodeIDs 为 nil 时保持静态成员不变。
+		if nodeIDs == nil {
+			return nil
+		}
+		return replaceGroupNodes(tx, id, nodeIDs, now)
+	})
+	if err != nil {
+		return NodeGroup{}, translateError(err)
+	}
+	return existing, nil
+}
+
+func (r *nodeGroupRepository) Delete(ctx context.Context, id uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var group NodeGroup
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&group, id).Error; err != nil {
+			return err
+		}
+		// 仍被套餐引用的分组不可删除，避免套餐因失去全部分组而变为不限节点。
+		var plans int64
+		if err := tx.Model(&PlanNodeGroup{}).Where("group_id = ?", id).Count(&plans).Error; err != nil {
+			return err
+		}
+		if plans > 0 {
+			return ErrConflict
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&NodeGroupNode{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&group).Error
+	})
+	return translateError(err)
+}
+
+func (r *nodeGroupRepository) ListNodeIDs(ctx context.Context, groupIDs ...uint64) (map[uint64][]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return listGroupNodeIDs(r.db.WithContext(ctx), groupIDs)
+}
+
+func (r *nodeGroupRepository) ResolveNodeIDs(ctx context.Context, groupIDs ...uint64) (map[uint64][]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return resolveGroupNodeIDs(r.db.WithContext(ctx), groupIDs)
+}
+
+func (r *nodeGroupRepository) ListPlanIDs(ctx context.Context, groupIDs ...uint64) (map[uint64][]uint64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64][]uint64, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	var links []PlanNodeGroup
+	if err := r.db.WithContext(ctx).
+		Where("group_id IN ?", groupIDs).
+		Order("group_id ASC, plan_id ASC").
+		Find(&links).Error; err != nil {
+		return nil, err
+	}
+	for _, link := range links {
+		result[link.GroupID] = append(result[link.GroupID], link.PlanID)
+	}
+	return result, nil
+}
+
+// replaceGroupNodes 整体替换分组的静态成员，节点需全部存在。
+func replaceGroupNodes(tx *gorm.DB, groupID uint64, nodeIDs []uint64, now time.Time) error {
+	ids, err := dedupeIDs(nodeIDs)
+	if err != nil {
+		return err
+	}
+	if err := requireNodes(tx, ids); err != nil {
+		return err
+	}
+	if err := tx.Where("group_id = ?", groupID).Delete(&NodeGroupNode{}).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	members := make([]NodeGroupNode, 0, len(ids))
+	for _, id := range ids {
+		members = append(members, NodeGroupNode{GroupID: groupID, NodeID: id, CreatedAt: now})
+	}
+	return tx.Create(&members).Error
+}
+
+func listGroupNodeIDs(db *gorm.DB, groupIDs []uint64) (map[uint64][]uint64, error) {
+	result := make(map[uint64][]uint64, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	var members []NodeGroupNode
+	if err := db.Where("group_id IN ?", groupIDs).
+		Order("group_id ASC, node_id ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		result[member.GroupID] = append(result[member.GroupID], member.NodeID)
+	}
+	return result, nil
+}
+
+// resolveGroupNodeIDs 返回各分组的完整成员（静态成员与标签匹配的节点），不区分节点状态。
+func resolveGroupNodeIDs(db *gorm.DB, groupIDs []uint64) (map[uint64][]uint64, error) {
+	result := make(map[uint64][]uint64, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return result, nil
+	}
+
+	var groups []NodeGroup
+	if err := db.Where("id IN ?", groupIDs).Find(&groups).Error; err != nil {
+		return nil, err
+	}
+	static, err := listGroupNodeIDs(db, groupIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	dynamic := false
+	for _, group := range groups {
+		if len(group.MatchTags) > 0 {
+			dynamic = true
+			break
+		}
+	}
+	var nodes []Node
+	if dynamic {
+		if err := db.Select("id", "tags").Order("id ASC").Find(&nodes).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	for _, group := range groups {
+		seen := make(map[uint64]struct{})
+		for _, id := range static[group.ID] {
+			seen[id] = struct{}{}
+		}
+		for _, node := range nodes {
+			if nodeMatchesTags(node, group.MatchTags) {
+				seen[node.ID] = struct{}{}
+			}
+		}
+		ids := make([]uint64, 0, len(seen))
+		for id := range seen {
+			ids = append(ids, id)
+		}
+		sortIDs(ids)
+		result[group.ID] = ids
+	}
+	return result, nil
+}
+
+func nodeMatchesTags(node Node, tags []string) bool {
+	for _, tag := range tags {
+		if containsIgnoreCase(node.Tags, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeMatchTags(tags []string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || containsIgnoreCase(result, tag) {
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result
+}
@@ -2,6 +2,10 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sort"
@@ -12,7 +16,15 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-// Node 表示节点元信息。
+// 节点状态，只有 online 节点会出现在订阅渲染结果中。
+const (
+	NodeStatusOnline      = "online"
+	NodeStatusOffline     = "offline"
+	NodeStatusMaintenance = "maintenance"
+	NodeStatusDisabled    = "disabled"
+)
+
+// Node 表示节点元信息。SortOrder 由管理员设置，订阅渲染按其升序排列。
 type Node struct {
 	ID           uint64    `gorm:"primaryKey"`
 	Name         string    `gorm:"size:255;uniqueIndex"`
@@ -23,10 +35,27 @@ type Node struct {
 	Tags         []string  `gorm:"serializer:json"`
 	Protocols    []string  `gorm:"serializer:json"`
 	CapacityMbps int       `gorm:"column:capacity_mbps"`
+	SortOrder    int       `gorm:"column:sort_order"`
 	Description  string    `gorm:"type:text"`
 	LastSyncedAt time.Time `gorm:"column:last_synced_at"`
 	UpdatedAt    time.Time
 	CreatedAt    time.Time
+
+	AgentTokenHash      string     `gorm:"column:agent_token_hash;size:64"`
+	AgentTokenRotatedAt *time.Time `gorm:"column:agent_token_rotated_at"`
+}
+
+// VerifyAgentToken 校验节点回调凭据，未签发凭据的节点一律拒绝。
+func (n Node) VerifyAgentToken(token string) bool {
+	if n.AgentTokenHash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(hashAgentToken(token)), []byte(n.AgentTokenHash)) == 1
+}
+
+func hashAgentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // TableName 自定义节点表名。
@@ -63,8 +92,13 @@ type ListNodesOptions struct {
 type NodeRepository interface {
 	List(ctx context.Context, opts ListNodesOptions) ([]Node, int64, error)
 	Get(ctx context.Context, nodeID uint64) (Node, error)
+	Update(ctx context.Context, nodeID uint64, updates Node) (Node, error)
+	RotateAgentToken(ctx context.Context, nodeID uint64) (Node, string, error)
 	GetKernels(ctx context.Context, nodeID uint64) ([]NodeKernel, error)
+	ListKernelsByNodes(ctx context.Context, nodeIDs ...uint64) (map[uint64][]NodeKernel, error)
 	RecordKernelSync(ctx context.Context, nodeID uint64, kernel NodeKernel) (NodeKernel, error)
+	ResolveEntitlement(ctx context.Context, planID uint64) (NodeEntitlement, error)
+	ListEntitled(ctx context.Context, planID uint64) ([]Node, error)
 }
 
 type nodeRepository struct {
@@ -133,6 +167,69 @@ func (r *nodeRepository) Get(ctx context.Context, nodeID uint64) (Node, error) {
 	return node, nil
 }
 
+func (r *nodeRepository) Update(ctx context.Context, nodeID uint64, updates Node) (Node, error) {
+	if err := ctx.Err(); err != nil {
+		return Node{}, err
+	}
+	if !ValidNodeStatus(updates.Status) {
+		return Node{}, ErrInvalidArgument
+	}
+
+	var node Node
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&node, nodeID).Error; err != nil {
+			return err
+		}
+
+		node.SortOrder = updates.SortOrder
+		node.Status = normalizeNodeStatus(updates.Status)
+		node.UpdatedAt = time.Now().UTC()
+
+		return tx.Model(&node).
+			Select("SortOrder", "Status", "UpdatedAt").
+			Updates(node).Error
+	})
+	if err != nil {
+		return Node{}, translateError(err)
+	}
+
+	return node, nil
+}
+
+// RotateAgentToken 为节点签发新的回调凭据，只保存摘要，明文仅在本次返回；旧凭据立即失效。
+func (r *nodeRepository) RotateAgentToken(ctx context.Context, nodeID uint64) (Node, string, error) {
+	if err := ctx.Err(); err != nil {
+		return Node{}, "", err
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return Node{}, "", fmt.Errorf("repository: generate node token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	var node Node
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&node, nodeID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		node.AgentTokenHash = hashAgentToken(token)
+		node.AgentTokenRotatedAt = &now
+		node.UpdatedAt = now
+
+		return tx.Model(&node).
+			Select("AgentTokenHash", "AgentTokenRotatedAt", "UpdatedAt").
+			Updates(node).Error
+	})
+	if err != nil {
+		return Node{}, "", translateError(err)
+	}
+
+	return node, token, nil
+}
+
 func (r *nodeRepository) GetKernels(ctx context.Context, nodeID uint64) ([]NodeKernel, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -149,6 +246,30 @@ func (r *nodeRepository) GetKernels(ctx context.Context, nodeID uint64) ([]NodeK
 	return kernels, nil
 }
 
+func (r *nodeRepository) ListKernelsByNodes(ctx context.Context, nodeIDs ...uint64) (map[uint64][]NodeKernel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64][]NodeKernel, len(nodeIDs))
+	if len(nodeIDs) == 0 {
+		return result, nil
+	}
+
+	var kernels []NodeKernel
+	if err := r.db.WithContext(ctx).
+		Where("node_id IN ?", nodeIDs).
+		Order("node_id ASC, protocol ASC").
+		Find(&kernels).Error; err != nil {
+		return nil, err
+	}
+	for _, kernel := range kernels {
+		result[kernel.NodeID] = append(result[kernel.NodeID], kernel)
+	}
+
+	return result, nil
+}
+
 func (r *nodeRepository) RecordKernelSync(ctx context.Context, nodeID uint64, kernel NodeKernel) (NodeKernel, error) {
 	if err := ctx.Err(); err != nil {
 		return NodeKernel{}, err
@@ -211,7 +332,12 @@ func (r *nodeRepository) RecordKernelSync(ctx context.Context, nodeID uint64, ke
 		if kernel.UpdatedAt.After(node.UpdatedAt) {
 			node.UpdatedAt = kernel.UpdatedAt
 		}
-		node.Status = "online"
+		// 管理员手动置为维护或停用的节点不会因同步而重新上线。
+		switch normalizeNodeStatus(node.Status) {
+		case NodeStatusMaintenance, NodeStatusDisabled:
+		default:
+			node.Status = NodeStatusOnline
+		}
 
 		return tx.Save(&node).Error
 	})
@@ -254,6 +380,8 @@ func buildNodeOrderClause(field, direction string) string {
 		column = "nodes.last_synced_at"
 	case "capacity_mbps":
 		column = "nodes.capacity_mbps"
+	case "sort_order":
+		column = "nodes.sort_order"
 	}
 
 	dir := "ASC"
@@ -264,6 +392,20 @@ func buildNodeOrderClause(field, direction string) string {
 	return fmt.Sprintf("%s %s", column, dir)
 }
 
+// ValidNodeStatus 判断状态是否为已定义的节点状态。
+func ValidNodeStatus(status string) bool {
+	switch normalizeNodeStatus(status) {
+	case NodeStatusOnline, NodeStatusOffline, NodeStatusMaintenance, NodeStatusDisabled:
+		return true
+	default:
+		return false
+	}
+}
+
+func normalizeNodeStatus(status string) string {
+	return strings.TrimSpace(strings.ToLower(status))
+}
+
 func containsIgnoreCase(items []string, target string) bool {
 	target = strings.ToLower(strings.TrimSpace(target))
 	for _, item := range items {
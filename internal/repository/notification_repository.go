@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Notification 站内通知，记录续费失败等需要用户关注的事件。
+type Notification struct {
+	ID        uint64         `gorm:"primaryKey"`
+	UserID    uint64         `gorm:"index"`
+	Category  string         `gorm:"size:64;index"`
+	Title     string         `gorm:"size:255"`
+	Content   string         `gorm:"type:text"`
+	Metadata  map[string]any `gorm:"serializer:json"`
+	ReadAt    *time.Time     `gorm:"column:read_at"`
+	CreatedAt time.Time
+}
+
+// TableName 自定义通知表名。
+func (Notification) TableName() string { return "notifications" }
+
+// ListNotificationsOptions 控制通知列表分页与过滤。
+type ListNotificationsOptions struct {
+	Page       int
+	PerPage    int
+	Category   string
+	UnreadOnly bool
+}
+
+// NotificationRepository 提供站内通知读写。
+type NotificationRepository interface {
+	Create(ctx context.Context, notification Notification) (Notification, error)
+	ListByUser(ctx context.Context, userID uint64, opts ListNotificationsOptions) ([]Notification, int64, error)
+	MarkRead(ctx context.Context, id uint64, userID uint64) (Notification, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository 创建通知仓储。
+func NewNotificationRepository(db *gorm.DB) (NotificationRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &notificationRepository{db: db}, nil
+}
+
+func (r *notificationRepository) Create(ctx context.Context, notification Notification) (Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return Notification{}, err
+	}
+
+	if notification.UserID == 0 || strings.TrimSpace(notification.Title) == "" {
+		return Notification{}, ErrInvalidArgument
+	}
+	if notification.CreatedAt.IsZero() {
+		notification.CreatedAt = time.Now().UTC()
+	}
+	if notification.Metadata == nil {
+		notification.Metadata = map[string]any{}
+	}
+
+	if err := r.db.WithContext(ctx).Create(&notification).Error; err != nil {
+		return Notification{}, translateError(err)
+	}
+
+	return notification, nil
+}
+
+func (r *notificationRepository) ListByUser(ctx context.Context, userID uint64, opts ListNotificationsOptions) ([]Notification, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts = normalizeListNotificationsOptions(opts)
+
+	base := r.db.WithContext(ctx).Model(&Notification{}).Where("user_id = ?", userID)
+	if category := strings.TrimSpace(strings.ToLower(opts.Category)); category != "" {
+		base = base.Where("LOWER(category) = ?", category)
+	}
+	if opts.UnreadOnly {
+		base = base.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []Notification{}, 0, nil
+	}
+
+	offset := (opts.Page - 1) * opts.PerPage
+	var notifications []Notification
+	if err := base.Session(&gorm.Session{}).Order("created_at DESC").Order("id DESC").Limit(opts.PerPage).Offset(offset).Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, id uint64, userID uint64) (Notification, error) {
+	if err := ctx.Err(); err != nil {
+		return Notification{}, err
+	}
+
+	var notification Notification
+	if err := r.db.WithContext(ctx).First(&notification, id).Error; err != nil {
+		return Notification{}, translateError(err)
+	}
+	if notification.UserID != userID {
+		return Notification{}, ErrForbidden
+	}
+	if notification.ReadAt != nil {
+		return notification, nil
+	}
+
+	now := time.Now().UTC()
+	if err := r.db.WithContext(ctx).Model(&notification).Update("read_at", now).Error; err != nil {
+		return Notification{}, translateError(err)
+	}
+	notification.ReadAt = &now
+
+	return notification, nil
+}
+
+func normalizeListNotificationsOptions(opts ListNotificationsOptions) ListNotificationsOptions {
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if opts.PerPage <= 0 {
+		opts.PerPage = 20
+	}
+	if opts.PerPage > 100 {
+		opts.PerPage = 100
+	}
+	return opts
+}
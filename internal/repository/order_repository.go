@@ -112,6 +112,15 @@ type OrderPayment struct {
 // TableName declares order payments table mapping.
 func (OrderPayment) TableName() string { return "order_payments" }
 
+// ListPaymentsInRangeOptions filters payments by creation time for reconciliation.
+type ListPaymentsInRangeOptions struct {
+	Provider string
+	Method   string
+	Status   string
+	From     time.Time
+	To       time.Time
+}
+
 // ListOrdersOptions controls filtering.
 type ListOrdersOptions struct {
 	Page          int
@@ -136,6 +145,8 @@ type OrderRepository interface {
 	ListItems(ctx context.Context, orderIDs []uint64) (map[uint64][]OrderItem, error)
 	ListRefunds(ctx context.Context, orderIDs []uint64) (map[uint64][]OrderRefund, error)
 	ListPayments(ctx context.Context, orderIDs []uint64) (map[uint64][]OrderPayment, error)
+	ListPaymentsByKeys(ctx context.Context, intentIDs, references []string) ([]OrderPayment, error)
+	ListPaymentsInRange(ctx context.Context, opts ListPaymentsInRangeOptions) ([]OrderPayment, error)
 	UpdateStatus(ctx context.Context, id uint64, params UpdateOrderStatusParams) (Order, error)
 	AddRefund(ctx context.Context, id uint64, params AddRefundParams) (Order, error)
 	CreateRefund(ctx context.Context, refund OrderRefund) (OrderRefund, error)
@@ -405,6 +416,60 @@ func (r *orderRepository) ListPayments(ctx context.Context, orderIDs []uint64) (
 	return grouped, nil
 }
 
+// ListPaymentsByKeys returns payments whose intent_id or reference matches any given key.
+func (r *orderRepository) ListPaymentsByKeys(ctx context.Context, intentIDs, references []string) ([]OrderPayment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(intentIDs) == 0 && len(references) == 0 {
+		return []OrderPayment{}, nil
+	}
+
+	query := r.db.WithContext(ctx).Model(&OrderPayment{})
+	switch {
+	case len(intentIDs) > 0 && len(references) > 0:
+		query = query.Where("intent_id IN ? OR reference IN ?", intentIDs, references)
+	case len(intentIDs) > 0:
+		query = query.Where("intent_id IN ?", intentIDs)
+	default:
+		query = query.Where("reference IN ?", references)
+	}
+
+	var payments []OrderPayment
+	if err := query.Order("id ASC").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+// ListPaymentsInRange returns payments created within [From, To).
+func (r *orderRepository) ListPaymentsInRange(ctx context.Context, opts ListPaymentsInRangeOptions) ([]OrderPayment, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if opts.From.IsZero() || opts.To.IsZero() || !opts.To.After(opts.From) {
+		return nil, ErrInvalidArgument
+	}
+
+	query := r.db.WithContext(ctx).Model(&OrderPayment{}).
+		Where("created_at >= ? AND created_at < ?", opts.From, opts.To)
+	if provider := strings.TrimSpace(opts.Provider); provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+	if method := strings.TrimSpace(strings.ToLower(opts.Method)); method != "" {
+		query = query.Where("method = ?", method)
+	}
+	if status := strings.TrimSpace(strings.ToLower(opts.Status)); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var payments []OrderPayment
+	if err := query.Order("id ASC").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
 func (r *orderRepository) CreateRefund(ctx context.Context, refund OrderRefund) (OrderRefund, error) {
 	if err := ctx.Err(); err != nil {
 		return OrderRefund{}, err
@@ -11,28 +11,45 @@ import (
 )
 
 // Plan represents purchasable subscription bundles similar to xboard 套餐。
+// TrafficResetPolicy 在开通或续费时复制到订阅；TrafficAddon* 描述当期可加购的流量包。
 type Plan struct {
-	ID                uint64   `gorm:"primaryKey"`
-	Name              string   `gorm:"size:255"`
-	Slug              string   `gorm:"size:128;uniqueIndex"`
-	Description       string   `gorm:"type:text"`
-	Tags              []string `gorm:"serializer:json"`
-	Features          []string `gorm:"serializer:json"`
-	PriceCents        int64    `gorm:"column:price_cents"`
-	Currency          string   `gorm:"size:16"`
-	DurationDays      int      `gorm:"column:duration_days"`
-	TrafficLimitBytes int64    `gorm:"column:traffic_limit_bytes"`
-	DevicesLimit      int      `gorm:"column:devices_limit"`
-	SortOrder         int      `gorm:"column:sort_order"`
-	Status            string   `gorm:"size:32"`
-	Visible           bool     `gorm:"column:is_visible"`
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	ID                     uint64   `gorm:"primaryKey"`
+	Name                   string   `gorm:"size:255"`
+	Slug                   string   `gorm:"size:128;uniqueIndex"`
+	Description            string   `gorm:"type:text"`
+	Tags                   []string `gorm:"serializer:json"`
+	Features               []string `gorm:"serializer:json"`
+	PriceCents             int64    `gorm:"column:price_cents"`
+	Currency               string   `gorm:"size:16"`
+	DurationDays           int      `gorm:"column:duration_days"`
+	TrafficLimitBytes      int64    `gorm:"column:traffic_limit_bytes"`
+	DevicesLimit           int      `gorm:"column:devices_limit"`
+	TrafficResetPolicy     string   `gorm:"column:traffic_reset_policy;size:32;default:never"`
+	TrafficAddonBytes      int64    `gorm:"column:traffic_addon_bytes"`
+	TrafficAddonPriceCents int64    `gorm:"column:traffic_addon_price_cents"`
+	SortOrder              int      `gorm:"column:sort_order"`
+	Status                 string   `gorm:"size:32"`
+	Visible                bool     `gorm:"column:is_visible"`
+	CreatedAt              time.Time
+	UpdatedAt              time.Time
 }
 
 // TableName provides explicit table binding.
 func (Plan) TableName() string { return "plans" }
 
+// PlanPrice 套餐在其他币种下的固定售价，优先于汇率换算。
+type PlanPrice struct {
+	ID         uint64 `gorm:"primaryKey"`
+	PlanID     uint64 `gorm:"uniqueIndex:idx_plan_prices_plan_currency"`
+	Currency   string `gorm:"size:16;uniqueIndex:idx_plan_prices_plan_currency"`
+	PriceCents int64  `gorm:"column:price_cents"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName binds plan price lists.
+func (PlanPrice) TableName() string { return "plan_prices" }
+
 // ListPlansOptions controls filtering and pagination for plan listing.
 type ListPlansOptions struct {
 	Page      int
@@ -50,6 +67,12 @@ type PlanRepository interface {
 	Create(ctx context.Context, plan Plan) (Plan, error)
 	Update(ctx context.Context, id uint64, updates Plan) (Plan, error)
 	Get(ctx context.Context, id uint64) (Plan, error)
+	ListPrices(ctx context.Context, planIDs ...uint64) (map[uint64][]PlanPrice, error)
+	ReplacePrices(ctx context.Context, planID uint64, prices []PlanPrice) ([]PlanPrice, error)
+	ListNodeIDs(ctx context.Context, planIDs ...uint64) (map[uint64][]uint64, error)
+	ReplaceNodes(ctx context.Context, planID uint64, nodeIDs []uint64) ([]uint64, error)
+	ListNodeGroupIDs(ctx context.Context, planIDs ...uint64) (map[uint64][]uint64, error)
+	ReplaceNodeGroups(ctx context.Context, planID uint64, groupIDs []uint64) ([]uint64, error)
 }
 
 type planRepository struct {
@@ -116,6 +139,7 @@ func (r *planRepository) Create(ctx context.Context, plan Plan) (Plan, error) {
 	}
 	plan.UpdatedAt = now
 	plan.Slug = normalizeSlug(plan.Slug, plan.Name)
+	plan.TrafficResetPolicy = NormalizeTrafficResetPolicy(plan.TrafficResetPolicy)
 	if plan.Status == "" {
 		plan.Status = "draft"
 	}
@@ -133,23 +157,27 @@ func (r *planRepository) Update(ctx context.Context, id uint64, updates Plan) (P
 	}
 
 	updates.Slug = normalizeSlug(updates.Slug, updates.Name)
+	updates.TrafficResetPolicy = NormalizeTrafficResetPolicy(updates.TrafficResetPolicy)
 	updates.UpdatedAt = time.Now().UTC()
 
 	if err := r.db.WithContext(ctx).Model(&Plan{}).Where("id = ?", id).Updates(map[string]any{
-		"name":                updates.Name,
-		"slug":                updates.Slug,
-		"description":         updates.Description,
-		"tags":                updates.Tags,
-		"features":            updates.Features,
-		"price_cents":         updates.PriceCents,
-		"currency":            updates.Currency,
-		"duration_days":       updates.DurationDays,
-		"traffic_limit_bytes": updates.TrafficLimitBytes,
-		"devices_limit":       updates.DevicesLimit,
-		"sort_order":          updates.SortOrder,
-		"status":              updates.Status,
-		"is_visible":          updates.Visible,
-		"updated_at":          updates.UpdatedAt,
+		"name":                      updates.Name,
+		"slug":                      updates.Slug,
+		"description":               updates.Description,
+		"tags":                      updates.Tags,
+		"features":                  updates.Features,
+		"price_cents":               updates.PriceCents,
+		"currency":                  updates.Currency,
+		"duration_days":             updates.DurationDays,
+		"traffic_limit_bytes":       updates.TrafficLimitBytes,
+		"devices_limit":             updates.DevicesLimit,
+		"traffic_reset_policy":      updates.TrafficResetPolicy,
+		"traffic_addon_bytes":       updates.TrafficAddonBytes,
+		"traffic_addon_price_cents": updates.TrafficAddonPriceCents,
+		"sort_order":                updates.SortOrder,
+		"status":                    updates.Status,
+		"is_visible":                updates.Visible,
+		"updated_at":                updates.UpdatedAt,
 	}).Error; err != nil {
 		return Plan{}, translateError(err)
 	}
@@ -170,6 +198,75 @@ func (r *planRepository) Get(ctx context.Context, id uint64) (Plan, error) {
 	return plan, nil
 }
 
+func (r *planRepository) ListPrices(ctx context.Context, planIDs ...uint64) (map[uint64][]PlanPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint64][]PlanPrice, len(planIDs))
+	if len(planIDs) == 0 {
+		return result, nil
+	}
+
+	var prices []PlanPrice
+	if err := r.db.WithContext(ctx).
+		Where("plan_id IN ?", planIDs).
+		Order("plan_id ASC, currency ASC").
+		Find(&prices).Error; err != nil {
+		return nil, err
+	}
+	for _, price := range prices {
+		result[price.PlanID] = append(result[price.PlanID], price)
+	}
+
+	return result, nil
+}
+
+func (r *planRepository) ReplacePrices(ctx context.Context, planID uint64, prices []PlanPrice) ([]PlanPrice, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{}, len(prices))
+	records := make([]PlanPrice, 0, len(prices))
+	now := time.Now().UTC()
+	for _, price := range prices {
+		currency := strings.ToUpper(strings.TrimSpace(price.Currency))
+		if currency == "" || price.PriceCents < 0 {
+			return nil, ErrInvalidArgument
+		}
+		if _, ok := seen[currency]; ok {
+			return nil, ErrInvalidArgument
+		}
+		seen[currency] = struct{}{}
+		records = append(records, PlanPrice{
+			PlanID:     planID,
+			Currency:   currency,
+			PriceCents: price.PriceCents,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		})
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&Plan{}, planID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", planID).Delete(&PlanPrice{}).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return records, nil
+}
+
 func normalizeListPlansOptions(opts ListPlansOptions) ListPlansOptions {
 	if opts.Page <= 0 {
 		opts.Page = 1
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 对账差异类型。
+const (
+	ReconciliationIssueAmountMismatch      = "amount_mismatch"
+	ReconciliationIssueMissingCallback     = "missing_callback"
+	ReconciliationIssueStatusMismatch      = "status_mismatch"
+	ReconciliationIssueOrphanSettlement    = "orphan_settlement"
+	ReconciliationIssueUnsettledPayment    = "unsettled_payment"
+	ReconciliationIssueDuplicateSettlement = "duplicate_settlement"
+)
+
+// ReconciliationRun 一次对账执行的汇总。
+type ReconciliationRun struct {
+	ID             uint64         `gorm:"primaryKey"`
+	Provider       string         `gorm:"size:64;index"`
+	Source         string         `gorm:"size:32"`
+	PeriodStart    *time.Time     `gorm:"column:period_start"`
+	PeriodEnd      *time.Time     `gorm:"column:period_end"`
+	SettlementRows int            `gorm:"column:settlement_rows"`
+	MatchedCount   int            `gorm:"column:matched_count"`
+	IssueCount     int            `gorm:"column:issue_count"`
+	Summary        map[string]int `gorm:"serializer:json"`
+	CreatedBy      string         `gorm:"size:255"`
+	CreatedAt      time.Time      `gorm:"index"`
+}
+
+// TableName 自定义对账执行表名。
+func (ReconciliationRun) TableName() string { return "reconciliation_runs" }
+
+// ReconciliationIssue 对账发现的单条差异。
+type ReconciliationIssue struct {
+	ID               uint64     `gorm:"primaryKey"`
+	RunID            uint64     `gorm:"index"`
+	Kind             string     `gorm:"size:32;index"`
+	OrderID          uint64     `gorm:"index"`
+	PaymentID        uint64     `gorm:"index"`
+	IntentID         string     `gorm:"size:64"`
+	Reference        string     `gorm:"size:64"`
+	LocalStatus      string     `gorm:"size:32"`
+	SettlementStatus string     `gorm:"size:32"`
+	LocalCents       int64      `gorm:"column:local_cents"`
+	SettledCents     int64      `gorm:"column:settled_cents"`
+	LocalCurrency    string     `gorm:"size:16"`
+	SettledCurrency  string     `gorm:"size:16"`
+	SettledAt        *time.Time `gorm:"column:settled_at"`
+	Detail           string     `gorm:"size:255"`
+	CreatedAt        time.Time
+}
+
+// TableName 自定义对账差异表名。
+func (ReconciliationIssue) TableName() string { return "reconciliation_issues" }
+
+// ListReconciliationRunsOptions 控制对账记录分页。
+type ListReconciliationRunsOptions struct {
+	Page     int
+	PerPage  int
+	Provider string
+}
+
+// ReconciliationRepository 保存对账执行结果。
+type ReconciliationRepository interface {
+	CreateRun(ctx context.Context, run ReconciliationRun, issues []ReconciliationIssue) (ReconciliationRun, []ReconciliationIssue, error)
+	GetRun(ctx context.Context, id uint64) (ReconciliationRun, []ReconciliationIssue, error)
+	ListRuns(ctx context.Context, opts ListReconciliationRunsOptions) ([]ReconciliationRun, int64, error)
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+// NewReconciliationRepository 创建对账仓储。
+func NewReconciliationRepository(db *gorm.DB) (ReconciliationRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &reconciliationRepository{db: db}, nil
+}
+
+func (r *reconciliationRepository) CreateRun(ctx context.Context, run ReconciliationRun, issues []ReconciliationIssue) (ReconciliationRun, []ReconciliationIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return ReconciliationRun{}, nil, err
+	}
+
+	now := time.Now().UTC()
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = now
+	}
+	if run.Summary == nil {
+		run.Summary = map[string]int{}
+	}
+	run.IssueCount = len(issues)
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&run).Error; err != nil {
+			return err
+		}
+		for i := range issues {
+			issues[i].ID = 0
+			issues[i].RunID = run.ID
+			issues[i].CreatedAt = run.CreatedAt
+		}
+		if len(issues) > 0 {
+			if err := tx.CreateInBatches(&issues, 200).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ReconciliationRun{}, nil, translateError(err)
+	}
+
+	return run, issues, nil
+}
+
+func (r *reconciliationRepository) GetRun(ctx context.Context, id uint64) (ReconciliationRun, []ReconciliationIssue, error) {
+	if err := ctx.Err(); err != nil {
+		return ReconciliationRun{}, nil, err
+	}
+
+	var run ReconciliationRun
+	if err := r.db.WithContext(ctx).First(&run, id).Error; err != nil {
+		return ReconciliationRun{}, nil, translateError(err)
+	}
+
+	var issues []ReconciliationIssue
+	if err := r.db.WithContext(ctx).Where("run_id = ?", id).Order("id ASC").Find(&issues).Error; err != nil {
+		return ReconciliationRun{}, nil, err
+	}
+
+	return run, issues, nil
+}
+
+func (r *reconciliationRepository) ListRuns(ctx context.Context, opts ListReconciliationRunsOptions) ([]ReconciliationRun, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if opts.PerPage <= 0 || opts.PerPage > 100 {
+		opts.PerPage = 20
+	}
+
+	base := r.db.WithContext(ctx).Model(&ReconciliationRun{})
+	if provider := strings.TrimSpace(opts.Provider); provider != "" {
+		base = base.Where("provider = ?", provider)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []ReconciliationRun{}, 0, nil
+	}
+
+	var runs []ReconciliationRun
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("created_at DESC, id DESC").Limit(opts.PerPage).Offset(offset).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
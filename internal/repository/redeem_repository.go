@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 兑换码权益类型。
+const (
+	RedeemKindBalance = "balance"
+	RedeemKindPlan    = "plan"
+	RedeemKindTraffic = "traffic"
+)
+
+// 兑换码状态。
+const (
+	RedeemCodeStatusActive   = "active"
+	RedeemCodeStatusDisabled = "disabled"
+)
+
+// RedeemBatch 一批兑换码共享的权益与有效期。
+type RedeemBatch struct {
+	ID           uint64     `gorm:"primaryKey"`
+	Name         string     `gorm:"size:128"`
+	Kind         string     `gorm:"size:16;index"`
+	AmountCents  int64      `gorm:"column:amount_cents"`
+	Currency     string     `gorm:"size:16"`
+	PlanID       uint64     `gorm:"column:plan_id"`
+	PlanDays     int        `gorm:"column:plan_days"`
+	TrafficBytes int64      `gorm:"column:traffic_bytes"`
+	MaxUses      int        `gorm:"column:max_uses"`
+	Quantity     int        `gorm:"column:quantity"`
+	ExpiresAt    *time.Time `gorm:"column:expires_at"`
+	CreatedBy    string     `gorm:"size:255"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// TableName 自定义兑换批次表名。
+func (RedeemBatch) TableName() string { return "redeem_batches" }
+
+// RedeemCode 单个兑换码，MaxUses 次数内每个用户可兑换一次。
+type RedeemCode struct {
+	ID         uint64     `gorm:"primaryKey"`
+	BatchID    uint64     `gorm:"index"`
+	Code       string     `gorm:"size:32;uniqueIndex"`
+	MaxUses    int        `gorm:"column:max_uses"`
+	UsedCount  int        `gorm:"column:used_count"`
+	Status     string     `gorm:"size:16;index"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// TableName 自定义兑换码表名。
+func (RedeemCode) TableName() string { return "redeem_codes" }
+
+// RedeemRedemption 兑换流水，记录实际发放的权益。
+type RedeemRedemption struct {
+	ID                   uint64         `gorm:"primaryKey"`
+	CodeID               uint64         `gorm:"uniqueIndex:idx_redeem_redemptions_code_user"`
+	UserID               uint64         `gorm:"uniqueIndex:idx_redeem_redemptions_code_user;index"`
+	BatchID              uint64         `gorm:"index"`
+	Code                 string         `gorm:"size:32"`
+	Kind                 string         `gorm:"size:16"`
+	AmountCents          int64          `gorm:"column:amount_cents"`
+	Currency             string         `gorm:"size:16"`
+	PlanID               uint64         `gorm:"column:plan_id"`
+	PlanDays             int            `gorm:"column:plan_days"`
+	TrafficBytes         int64          `gorm:"column:traffic_bytes"`
+	SubscriptionID       uint64         `gorm:"column:subscription_id"`
+	BalanceTransactionID uint64         `gorm:"column:balance_transaction_id"`
+	Metadata             map[string]any `gorm:"serializer:json"`
+	CreatedAt            time.Time      `gorm:"index"`
+}
+
+// TableName 自定义兑换流水表名。
+func (RedeemRedemption) TableName() string { return "redeem_redemptions" }
+
+// ListRedeemBatchesOptions 兑换批次分页。
+type ListRedeemBatchesOptions struct {
+	Page    int
+	PerPage int
+	Kind    string
+}
+
+// ListRedeemCodesOptions 兑换码分页与过滤。
+type ListRedeemCodesOptions struct {
+	Page    int
+	PerPage int
+	BatchID uint64
+	Status  string
+	Code    string
+}
+
+// ListRedemptionsOptions 兑换流水分页与过滤。
+type ListRedemptionsOptions struct {
+	Page    int
+	PerPage int
+	UserID  *uint64
+	BatchID uint64
+	CodeID  uint64
+}
+
+// RedeemRepository 管理兑换码批次、兑换码与兑换流水。
+type RedeemRepository interface {
+	CreateBatch(ctx context.Context, batch RedeemBatch, prefix string) (RedeemBatch, []RedeemCode, error)
+	GetBatch(ctx context.Context, id uint64) (RedeemBatch, error)
+	ListBatches(ctx context.Context, opts ListRedeemBatchesOptions) ([]RedeemBatch, int64, error)
+	ListBatchCodes(ctx context.Context, batchID uint64) ([]RedeemCode, error)
+	ListCodes(ctx context.Context, opts ListRedeemCodesOptions) ([]RedeemCode, int64, error)
+	UpdateCodeStatus(ctx context.Context, id uint64, status string) (RedeemCode, error)
+
+	Consume(ctx context.Context, code string, userID uint64) (RedeemCode, RedeemBatch, error)
+	RecordRedemption(ctx context.Context, redemption RedeemRedemption) (RedeemRedemption, error)
+	ListRedemptions(ctx context.Context, opts ListRedemptionsOptions) ([]RedeemRedemption, int64, error)
+}
+
+type redeemRepository struct {
+	db *gorm.DB
+}
+
+// NewRedeemRepository 创建兑换码仓储。
+func NewRedeemRepository(db *gorm.DB) (RedeemRepository, error) {
+	if db == nil {
+		return nil, errors.New("repository: database connection is required")
+	}
+	return &redeemRepository{db: db}, nil
+}
+
+// NormalizeRedeemCode 统一兑换码大小写并去除空白。
+func NormalizeRedeemCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// generateRedeemCode 生成 XXXX-XXXX-XXXX 形式的随机码，可选前缀。
+func generateRedeemCode(prefix string) (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if prefix != "" {
+		b.WriteString(prefix)
+		b.WriteByte('-')
+	}
+	for i := range buf {
+		if i > 0 && i%4 == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(inviteCodeAlphabet[int(buf[i])%len(inviteCodeAlphabet)])
+	}
+	return b.String(), nil
+}
+
+func (r *redeemRepository) CreateBatch(ctx context.Context, batch RedeemBatch, prefix string) (RedeemBatch, []RedeemCode, error) {
+	if err := ctx.Err(); err != nil {
+		return RedeemBatch{}, nil, err
+	}
+	if batch.Quantity <= 0 || batch.MaxUses <= 0 {
+		return RedeemBatch{}, nil, ErrInvalidArgument
+	}
+
+	prefix = NormalizeRedeemCode(prefix)
+	now := time.Now().UTC()
+	batch.ID = 0
+	batch.CreatedAt = now
+	batch.UpdatedAt = now
+	if batch.ExpiresAt != nil {
+		expiresAt := batch.ExpiresAt.UTC()
+		batch.ExpiresAt = &expiresAt
+	}
+
+	var codes []RedeemCode
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&batch).Error; err != nil {
+			return err
+		}
+
+		seen := make(map[string]struct{}, batch.Quantity)
+		codes = make([]RedeemCode, 0, batch.Quantity)
+		for len(codes) < batch.Quantity {
+			code, err := generateRedeemCode(prefix)
+			if err != nil {
+				return err
+			}
+			if _, dup := seen[code]; dup {
+				continue
+			}
+			seen[code] = struct{}{}
+			codes = append(codes, RedeemCode{
+				BatchID:   batch.ID,
+				Code:      code,
+				MaxUses:   batch.MaxUses,
+				Status:    RedeemCodeStatusActive,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+		return tx.CreateInBatches(&codes, 200).Error
+	})
+	if err != nil {
+		return RedeemBatch{}, nil, translateError(err)
+	}
+
+	return batch, codes, nil
+}
+
+func (r *redeemRepository) GetBatch(ctx context.Context, id uint64) (RedeemBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return RedeemBatch{}, err
+	}
+
+	var batch RedeemBatch
+	if err := r.db.WithContext(ctx).First(&batch, id).Error; err != nil {
+		return RedeemBatch{}, translateError(err)
+	}
+	return batch, nil
+}
+
+func (r *redeemRepository) ListBatches(ctx context.Context, opts ListRedeemBatchesOptions) ([]RedeemBatch, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts.Page, opts.PerPage = normalizeRedeemPage(opts.Page, opts.PerPage)
+	base := r.db.WithContext(ctx).Model(&RedeemBatch{})
+	if kind := strings.TrimSpace(strings.ToLower(opts.Kind)); kind != "" {
+		base = base.Where("kind = ?", kind)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []RedeemBatch{}, 0, nil
+	}
+
+	var batches []RedeemBatch
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("created_at DESC, id DESC").Limit(opts.PerPage).Offset(offset).Find(&batches).Error; err != nil {
+		return nil, 0, err
+	}
+	return batches, total, nil
+}
+
+func (r *redeemRepository) ListBatchCodes(ctx context.Context, batchID uint64) ([]RedeemCode, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var codes []RedeemCode
+	if err := r.db.WithContext(ctx).Where("batch_id = ?", batchID).Order("id ASC").Find(&codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (r *redeemRepository) ListCodes(ctx context.Context, opts ListRedeemCodesOptions) ([]RedeemCode, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts.Page, opts.PerPage = normalizeRedeemPage(opts.Page, opts.PerPage)
+	base := r.db.WithContext(ctx).Model(&RedeemCode{})
+	if opts.BatchID != 0 {
+		base = base.Where("batch_id = ?", opts.BatchID)
+	}
+	if status := strings.TrimSpace(strings.ToLower(opts.Status)); status != "" {
+		base = base.Where("status = ?", status)
+	}
+	if code := NormalizeRedeemCode(opts.Code); code != "" {
+		base = base.Where("code = ?", code)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []RedeemCode{}, 0, nil
+	}
+
+	var codes []RedeemCode
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("id DESC").Limit(opts.PerPage).Offset(offset).Find(&codes).Error; err != nil {
+		return nil, 0, err
+	}
+	return codes, total, nil
+}
+
+func (r *redeemRepository) UpdateCodeStatus(ctx context.Context, id uint64, status string) (RedeemCode, error) {
+	if err := ctx.Err(); err != nil {
+		return RedeemCode{}, err
+	}
+	if status != RedeemCodeStatusActive && status != RedeemCodeStatusDisabled {
+		return RedeemCode{}, ErrInvalidArgument
+	}
+
+	var code RedeemCode
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&code, id).Error; err != nil {
+			return err
+		}
+		code.Status = status
+		code.UpdatedAt = time.Now().UTC()
+		return tx.Model(&RedeemCode{}).Where("id = ?", id).Updates(map[string]any{
+			"status":     code.Status,
+			"updated_at": code.UpdatedAt,
+		}).Error
+	})
+	if err != nil {
+		return RedeemCode{}, translateError(err)
+	}
+	return code, nil
+}
+
+// Consume 锁定兑换码行并占用一次使用次数；禁用、过期或次数用尽返回 ErrInvalidState，
+// 同一用户重复兑换返回 ErrConflict。需与权益发放、RecordRedemption 处于同一事务。
+func (r *redeemRepository) Consume(ctx context.Context, code string, userID uint64) (RedeemCode, RedeemBatch, error) {
+	if err := ctx.Err(); err != nil {
+		return RedeemCode{}, RedeemBatch{}, err
+	}
+
+	code = NormalizeRedeemCode(code)
+	if code == "" || userID == 0 {
+		return RedeemCode{}, RedeemBatch{}, ErrInvalidArgument
+	}
+
+	var (
+		redeemCode RedeemCode
+		batch      RedeemBatch
+	)
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("code = ?", code).First(&redeemCode).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&batch, redeemCode.BatchID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if redeemCode.Status != RedeemCodeStatusActive {
+			return ErrInvalidState
+		}
+		if batch.ExpiresAt != nil && !now.Before(*batch.ExpiresAt) {
+			return ErrInvalidState
+		}
+		if redeemCode.UsedCount >= redeemCode.MaxUses {
+			return ErrInvalidState
+		}
+
+		var used int64
+		if err := tx.Model(&RedeemRedemption{}).Where("code_id = ? AND user_id = ?", redeemCode.ID, userID).Count(&used).Error; err != nil {
+			return err
+		}
+		if used > 0 {
+			return ErrConflict
+		}
+
+		redeemCode.UsedCount++
+		redeemCode.LastUsedAt = &now
+		redeemCode.UpdatedAt = now
+		return tx.Model(&RedeemCode{}).Where("id = ?", redeemCode.ID).Updates(map[string]any{
+			"used_count":   redeemCode.UsedCount,
+			"last_used_at": now,
+			"updated_at":   now,
+		}).Error
+	})
+	if err != nil {
+		return RedeemCode{}, RedeemBatch{}, translateError(err)
+	}
+
+	return redeemCode, batch, nil
+}
+
+func (r *redeemRepository) RecordRedemption(ctx context.Context, redemption RedeemRedemption) (RedeemRedemption, error) {
+	if err := ctx.Err(); err != nil {
+		return RedeemRedemption{}, err
+	}
+	if redemption.CodeID == 0 || redemption.UserID == 0 {
+		return RedeemRedemption{}, ErrInvalidArgument
+	}
+
+	redemption.ID = 0
+	if redemption.CreatedAt.IsZero() {
+		redemption.CreatedAt = time.Now().UTC()
+	}
+	if redemption.Metadata == nil {
+		redemption.Metadata = map[string]any{}
+	}
+	if err := r.db.WithContext(ctx).Create(&redemption).Error; err != nil {
+		return RedeemRedemption{}, translateError(err)
+	}
+	return redemption, nil
+}
+
+func (r *redeemRepository) ListRedemptions(ctx context.Context, opts ListRedemptionsOptions) ([]RedeemRedemption, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts.Page, opts.PerPage = normalizeRedeemPage(opts.Page, opts.PerPage)
+	base := r.db.WithContext(ctx).Model(&RedeemRedemption{})
+	if opts.UserID != nil {
+		base = base.Where("user_id = ?", *opts.UserID)
+	}
+	if opts.BatchID != 0 {
+		base = base.Where("batch_id = ?", opts.BatchID)
+	}
+	if opts.CodeID != 0 {
+		base = base.Where("code_id = ?", opts.CodeID)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []RedeemRedemption{}, 0, nil
+	}
+
+	var redemptions []RedeemRedemption
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("created_at DESC, id DESC").Limit(opts.PerPage).Offset(offset).Find(&redemptions).Error; err != nil {
+		return nil, 0, err
+	}
+	return redemptions, total, nil
+}
+
+func normalizeRedeemPage(page, perPage int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if perPage <= 0 || perPage > 100 {
+		perPage = 20
+	}
+	return page, perPage
+}
@@ -10,7 +10,9 @@ import (
 type Repositories struct {
 	AdminModule          AdminModuleRepository
 	Node                 NodeRepository
+	NodeGroup            NodeGroupRepository
 	SubscriptionTemplate SubscriptionTemplateRepository
+	ClientTypeRule       ClientTypeRuleRepository
 	Subscription         SubscriptionRepository
 	User                 UserRepository
 	Plan                 PlanRepository
@@ -18,6 +20,12 @@ type Repositories struct {
 	Balance              BalanceRepository
 	Security             SecurityRepository
 	Order                OrderRepository
+	Notification         NotificationRepository
+	ExchangeRate         ExchangeRateRepository
+	Invoice              InvoiceRepository
+	Reconciliation       ReconciliationRepository
+	Affiliate            AffiliateRepository
+	Redeem               RedeemRepository
 }
 
 // NewRepositories 根据数据库实例创建仓储集合。
@@ -36,6 +44,11 @@ func NewRepositories(db *gorm.DB) (*Repositories, error) {
 		return nil, err
 	}
 
+	clientTypeRuleRepo, err := NewClientTypeRuleRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
 	userRepo, err := NewUserRepository(db)
 	if err != nil {
 		return nil, err
@@ -46,6 +59,11 @@ func NewRepositories(db *gorm.DB) (*Repositories, error) {
 		return nil, err
 	}
 
+	nodeGroupRepo, err := NewNodeGroupRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
 	subscriptionRepo, err := NewSubscriptionRepository(db, templateRepo)
 	if err != nil {
 		return nil, err
@@ -76,10 +94,42 @@ func NewRepositories(db *gorm.DB) (*Repositories, error) {
 		return nil, err
 	}
 
+	notificationRepo, err := NewNotificationRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	exchangeRateRepo, err := NewExchangeRateRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	invoiceRepo, err := NewInvoiceRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliationRepo, err := NewReconciliationRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	affiliateRepo, err := NewAffiliateRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
+	redeemRepo, err := NewRedeemRepository(db)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Repositories{
 		AdminModule:          adminModuleRepo,
 		Node:                 nodeRepo,
+		NodeGroup:            nodeGroupRepo,
 		SubscriptionTemplate: templateRepo,
+		ClientTypeRule:       clientTypeRuleRepo,
 		Subscription:         subscriptionRepo,
 		User:                 userRepo,
 		Plan:                 planRepo,
@@ -87,5 +137,11 @@ func NewRepositories(db *gorm.DB) (*Repositories, error) {
 		Balance:              balanceRepo,
 		Security:             securityRepo,
 		Order:                orderRepo,
+		Notification:         notificationRepo,
+		ExchangeRate:         exchangeRateRepo,
+		Invoice:              invoiceRepo,
+		Reconciliation:       reconciliationRepo,
+		Affiliate:            affiliateRepo,
+		Redeem:               redeemRepo,
 	}, nil
 }
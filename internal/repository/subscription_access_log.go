@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SubscriptionAccessLog 记录一次通过 token 拉取订阅的请求。
+type SubscriptionAccessLog struct {
+	ID             uint64    `gorm:"primaryKey"`
+	SubscriptionID uint64    `gorm:"index"`
+	UserID         uint64    `gorm:"index"`
+	TokenID        uint64    `gorm:"column:token_id"` // 命名访问链接 ID，主 token 为 0
+	Token          string    `gorm:"size:255;index"`
+	IP             string    `gorm:"column:ip;size:64"`
+	UserAgent      string    `gorm:"column:user_agent;size:255"`
+	ClientType     string    `gorm:"column:client_type;size:32"`
+	Country        string    `gorm:"size:8"`
+	CreatedAt      time.Time `gorm:"index"`
+}
+
+// TableName binds subscription access logs.
+func (SubscriptionAccessLog) TableName() string { return "subscription_access_logs" }
+
+// ListAccessLogsOptions 拉取日志查询条件。
+type ListAccessLogsOptions struct {
+	Page           int
+	PerPage        int
+	SubscriptionID uint64
+	Token          string
+}
+
+// TokenAccessStat 单个 token 在统计窗口内的拉取汇总。
+type TokenAccessStat struct {
+	Token             string
+	SubscriptionID    uint64
+	UserID            uint64
+	DistinctIPs       int64
+	DistinctCountries int64
+	Fetches           int64
+}
+
+func (r *subscriptionRepository) RecordAccess(ctx context.Context, log SubscriptionAccessLog) (SubscriptionAccessLog, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionAccessLog{}, err
+	}
+	if log.SubscriptionID == 0 {
+		return SubscriptionAccessLog{}, ErrInvalidArgument
+	}
+
+	log.UserAgent = strings.TrimSpace(log.UserAgent)
+	if len(log.UserAgent) > 255 {
+		log.UserAgent = log.UserAgent[:255]
+	}
+	if log.CreatedAt.IsZero() {
+		log.CreatedAt = time.Now().UTC()
+	}
+
+	if err := r.db.WithContext(ctx).Create(&log).Error; err != nil {
+		return SubscriptionAccessLog{}, translateError(err)
+	}
+	return log, nil
+}
+
+func (r *subscriptionRepository) ListAccessLogs(ctx context.Context, opts ListAccessLogsOptions) ([]SubscriptionAccessLog, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	opts.Page, opts.PerPage = normalizeRedeemPage(opts.Page, opts.PerPage)
+	base := r.db.WithContext(ctx).Model(&SubscriptionAccessLog{})
+	if opts.SubscriptionID != 0 {
+		base = base.Where("subscription_id = ?", opts.SubscriptionID)
+	}
+	if token := strings.TrimSpace(opts.Token); token != "" {
+		base = base.Where("token = ?", token)
+	}
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []SubscriptionAccessLog{}, 0, nil
+	}
+
+	var logs []SubscriptionAccessLog
+	offset := (opts.Page - 1) * opts.PerPage
+	if err := base.Session(&gorm.Session{}).Order("id DESC").Limit(opts.PerPage).Offset(offset).Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+func (r *subscriptionRepository) ListAccessAnomalies(ctx context.Context, since time.Time, minDistinctIPs int, limit int) ([]TokenAccessStat, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if minDistinctIPs <= 0 {
+		minDistinctIPs = 1
+	}
+
+	var stats []TokenAccessStat
+	err := r.db.WithContext(ctx).
+		Model(&SubscriptionAccessLog{}).
+		Select("token, subscription_id, user_id, COUNT(DISTINCT ip) AS distinct_ips, COUNT(DISTINCT country) AS distinct_countries, COUNT(*) AS fetches").
+		Where("created_at >= ?", since.UTC()).
+		Group("token, subscription_id, user_id").
+		Having("COUNT(DISTINCT ip) >= ?", minDistinctIPs).
+		Order("distinct_ips DESC, fetches DESC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetAccessStat 汇总订阅某个 token 自 since 起的拉取，没有记录时各计数为 0。
+func (r *subscriptionRepository) GetAccessStat(ctx context.Context, subscriptionID uint64, token string, since time.Time) (TokenAccessStat, error) {
+	if err := ctx.Err(); err != nil {
+		return TokenAccessStat{}, err
+	}
+
+	stat := TokenAccessStat{Token: token, SubscriptionID: subscriptionID}
+	err := r.db.WithContext(ctx).
+		Model(&SubscriptionAccessLog{}).
+		Select("COUNT(DISTINCT ip) AS distinct_ips, COUNT(DISTINCT country) AS distinct_countries, COUNT(*) AS fetches").
+		Where("subscription_id = ? AND token = ? AND created_at >= ?", subscriptionID, token, since.UTC()).
+		Scan(&stat).Error
+	if err != nil {
+		return TokenAccessStat{}, err
+	}
+
+	return stat, nil
+}
+
+func (r *subscriptionRepository) PruneAccessLogs(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	result := r.db.WithContext(ctx).Where("created_at < ?", before.UTC()).Delete(&SubscriptionAccessLog{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
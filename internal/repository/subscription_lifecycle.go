@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 订阅状态。
+const (
+	SubscriptionStatusActive    = "active"
+	SubscriptionStatusExpired   = "expired"
+	SubscriptionStatusExhausted = "exhausted"
+	SubscriptionStatusSuspended = "suspended"
+	SubscriptionStatusCancelled = "cancelled"
+)
+
+// 订阅状态变更原因，写入 Subscription.StatusReason 与 SubscriptionEvent.Reason。
+const (
+	SubscriptionReasonExpired          = "expired"
+	SubscriptionReasonTrafficExhausted = "traffic_exhausted"
+	SubscriptionReasonOwnerDisabled    = "owner_disabled"
+	SubscriptionReasonOwnerRestored    = "owner_restored"
+	SubscriptionReasonPlanApplied      = "plan_applied"
+	SubscriptionReasonTrafficReset     = "traffic_reset"
+	SubscriptionReasonTrafficAddon     = "traffic_addon"
+	SubscriptionReasonAdmin            = "admin"
+	SubscriptionReasonAccessAbuse      = "access_abuse"
+)
+
+// subscriptionTransitions 列出每个状态允许进入的目标状态，cancelled 为终态。
+var subscriptionTransitions = map[string][]string{
+	SubscriptionStatusActive:    {SubscriptionStatusExpired, SubscriptionStatusExhausted, SubscriptionStatusSuspended, SubscriptionStatusCancelled},
+	SubscriptionStatusExhausted: {SubscriptionStatusActive, SubscriptionStatusExpired, SubscriptionStatusSuspended, SubscriptionStatusCancelled},
+	SubscriptionStatusExpired:   {SubscriptionStatusActive, SubscriptionStatusSuspended, SubscriptionStatusCancelled},
+	SubscriptionStatusSuspended: {SubscriptionStatusActive, SubscriptionStatusExhausted, SubscriptionStatusExpired, SubscriptionStatusCancelled},
+	SubscriptionStatusCancelled: {},
+}
+
+// SubscriptionEvent 订阅状态变更事件，与状态更新在同一事务写入，由生命周期任务投递给订阅者。
+type SubscriptionEvent struct {
+	ID             uint64     `gorm:"primaryKey"`
+	SubscriptionID uint64     `gorm:"index"`
+	UserID         uint64     `gorm:"index"`
+	FromStatus     string     `gorm:"column:from_status;size:32"`
+	ToStatus       string     `gorm:"column:to_status;size:32"`
+	Reason         string     `gorm:"size:64"`
+	Attempts       int        `gorm:"column:attempts"`
+	LastError      string     `gorm:"column:last_error;size:255"`
+	DispatchedAt   *time.Time `gorm:"column:dispatched_at;index"`
+	CreatedAt      time.Time
+}
+
+// TableName binds subscription events.
+func (SubscriptionEvent) TableName() string { return "subscription_events" }
+
+// ValidSubscriptionStatus 判断状态是否为已定义的订阅状态。
+func ValidSubscriptionStatus(status string) bool {
+	_, ok := subscriptionTransitions[normalizeSubscriptionStatus(status)]
+	return ok
+}
+
+// CanTransitionSubscription 判断状态流转是否合法；未识别的历史状态按 active 处理。
+func CanTransitionSubscription(from, to string) bool {
+	from = normalizeSubscriptionStatus(from)
+	if !ValidSubscriptionStatus(from) {
+		from = SubscriptionStatusActive
+	}
+	for _, candidate := range subscriptionTransitions[from] {
+		if candidate == normalizeSubscriptionStatus(to) {
+			return true
+		}
+	}
+	return false
+}
+
+// SettledSubscriptionStatus 根据到期时间与流量用量推导订阅在未被暂停/取消时应处的状态。
+func SettledSubscriptionStatus(sub Subscription, now time.Time) string {
+	if !sub.ExpiresAt.After(now) {
+		return SubscriptionStatusExpired
+	}
+	if sub.TrafficTotalBytes > 0 && sub.TrafficUsedBytes >= sub.TrafficTotalBytes {
+		return SubscriptionStatusExhausted
+	}
+	return SubscriptionStatusActive
+}
+
+// SubscriptionServable 判断订阅当前是否可被节点服务：状态为 active 且未到期、流量未用尽，与 ListServable 的筛选条件一致。
+func SubscriptionServable(sub Subscription, now time.Time) bool {
+	return normalizeSubscriptionStatus(sub.Status) == SubscriptionStatusActive &&
+		SettledSubscriptionStatus(sub, now) == SubscriptionStatusActive
+}
+
+func normalizeSubscriptionStatus(status string) string {
+	return strings.TrimSpace(strings.ToLower(status))
+}
+
+// transitionSubscription 在已加锁的订阅上校验并执行状态流转，同时写入事件；状态未变化时不写事件。
+// 调用方负责持久化 Subscription 本身。
+func transitionSubscription(tx *gorm.DB, sub *Subscription, to, reason string, now time.Time) error {
+	from := normalizeSubscriptionStatus(sub.Status)
+	if !ValidSubscriptionStatus(from) {
+		from = SubscriptionStatusActive
+	}
+	to = normalizeSubscriptionStatus(to)
+	if from == to {
+		sub.Status = to
+		return nil
+	}
+	if !CanTransitionSubscription(from, to) {
+		return ErrInvalidState
+	}
+
+	changedAt := now.UTC()
+	sub.Status = to
+	sub.StatusReason = reason
+	sub.StatusChangedAt = &changedAt
+
+	return tx.Create(&SubscriptionEvent{
+		SubscriptionID: sub.ID,
+		UserID:         sub.UserID,
+		FromStatus:     from,
+		ToStatus:       to,
+		Reason:         reason,
+		CreatedAt:      changedAt,
+	}).Error
+}
+
+func (r *subscriptionRepository) TransitionStatus(ctx context.Context, subscriptionID uint64, to string, reason string) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+	if !ValidSubscriptionStatus(to) {
+		return Subscription{}, ErrInvalidArgument
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		before := subscription.Status
+		if err := transitionSubscription(tx, &subscription, to, reason, now); err != nil {
+			return err
+		}
+		if subscription.Status == before {
+			return nil
+		}
+		subscription.UpdatedAt = now
+
+		return tx.Model(&subscription).
+			Select("Status", "StatusReason", "StatusChangedAt", "UpdatedAt").
+			Updates(subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) ListExpiryDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []string{SubscriptionStatusActive, SubscriptionStatusExhausted}).
+		Where("expires_at <= ?", now.UTC()).
+		Order("expires_at ASC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListTrafficExhausted(ctx context.Context, now time.Time, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Where("status = ?", SubscriptionStatusActive).
+		Where("traffic_total_bytes > 0 AND traffic_used_bytes >= traffic_total_bytes").
+		Where("expires_at > ?", now.UTC()).
+		Order("id ASC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListOwnerDisabled(ctx context.Context, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = subscriptions.user_id").
+		Where("subscriptions.status IN ?", []string{SubscriptionStatusActive, SubscriptionStatusExhausted, SubscriptionStatusExpired}).
+		Where("LOWER(users.status) <> ?", "active").
+		Order("subscriptions.id ASC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListOwnerRestored(ctx context.Context, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Joins("JOIN users ON users.id = subscriptions.user_id").
+		Where("subscriptions.status = ?", SubscriptionStatusSuspended).
+		Where("subscriptions.status_reason = ?", SubscriptionReasonOwnerDisabled).
+		Where("LOWER(users.status) = ?", "active").
+		Order("subscriptions.id ASC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListServable(ctx context.Context, now time.Time, afterID uint64, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Where("status = ?", SubscriptionStatusActive).
+		Where("expires_at > ?", now.UTC()).
+		Where("(traffic_total_bytes <= 0 OR traffic_used_bytes < traffic_total_bytes)").
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ListPendingEvents(ctx context.Context, limit int) ([]SubscriptionEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var events []SubscriptionEvent
+	err := r.db.WithContext(ctx).
+		Where("dispatched_at IS NULL").
+		Order("id ASC").
+		Limit(normalizeLifecycleLimit(limit)).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (r *subscriptionRepository) RecordEventDelivery(ctx context.Context, eventID uint64, deliveryErr string, giveUp bool) (SubscriptionEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionEvent{}, err
+	}
+
+	var event SubscriptionEvent
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&event, eventID).Error; err != nil {
+			return err
+		}
+
+		event.Attempts++
+		message := strings.TrimSpace(deliveryErr)
+		if len(message) > 255 {
+			message = message[:255]
+		}
+		event.LastError = message
+		if message == "" || giveUp {
+			now := time.Now().UTC()
+			event.DispatchedAt = &now
+		}
+
+		return tx.Model(&event).
+			Select("Attempts", "LastError", "DispatchedAt").
+			Updates(event).Error
+	})
+	if err != nil {
+		return SubscriptionEvent{}, translateError(err)
+	}
+
+	return event, nil
+}
+
+func normalizeLifecycleLimit(limit int) int {
+	if limit <= 0 {
+		return 100
+	}
+	return limit
+}
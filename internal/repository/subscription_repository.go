@@ -2,29 +2,49 @@ package repository
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-// Subscription 表示用户订阅信息。
+// Subscription 表示用户订阅信息。ProxyUUID 为节点侧代理凭据（vmess/vless UUID、trojan/ss 密码），
+// 与拉取订阅用的 Token 相互独立，重置 token 不影响已连接的客户端。
 type Subscription struct {
-	ID                   uint64 `gorm:"primaryKey"`
-	UserID               uint64 `gorm:"index"`
-	Name                 string `gorm:"size:255"`
-	PlanName             string `gorm:"size:255"`
-	Status               string `gorm:"size:32"`
+	ID                   uint64     `gorm:"primaryKey"`
+	UserID               uint64     `gorm:"index"`
+	PlanID               uint64     `gorm:"index"`
+	Name                 string     `gorm:"size:255"`
+	PlanName             string     `gorm:"size:255"`
+	Status               string     `gorm:"size:32;index"`
+	StatusReason         string     `gorm:"column:status_reason;size:64"`
+	StatusChangedAt      *time.Time `gorm:"column:status_changed_at"`
 	TemplateID           uint64
 	AvailableTemplateIDs []uint64 `gorm:"serializer:json"`
-	Token                string   `gorm:"size:255"`
+	Token                string   `gorm:"size:255;index"`
+	ProxyUUID            string   `gorm:"column:proxy_uuid;size:36"`
 	ExpiresAt            time.Time
 	TrafficTotalBytes    int64
 	TrafficUsedBytes     int64
 	DevicesLimit         int
+	TrafficResetPolicy   string     `gorm:"column:traffic_reset_policy;size:32;default:never"`
+	TrafficResetDay      int        `gorm:"column:traffic_reset_day"`
+	TrafficExtraBytes    int64      `gorm:"column:traffic_extra_bytes"`
+	TrafficPeriodStartAt *time.Time `gorm:"column:traffic_period_start_at"`
+	TrafficNextResetAt   *time.Time `gorm:"column:traffic_next_reset_at;index"`
+	AutoRenew            bool       `gorm:"column:auto_renew;index"`
+	AutoRenewFailures    int        `gorm:"column:auto_renew_failures"`
+	AutoRenewNextAt      *time.Time `gorm:"column:auto_renew_next_at"`
+	AutoRenewLastError   string     `gorm:"column:auto_renew_last_error;size:255"`
+	PeriodPaidCents      int64      `gorm:"column:period_paid_cents"`
+	PeriodCurrency       string     `gorm:"column:period_currency;size:8"`
+	PeriodStartedAt      *time.Time `gorm:"column:period_started_at"`
 	LastRefreshedAt      time.Time
 	CreatedAt            time.Time
 	UpdatedAt            time.Time
@@ -47,7 +67,62 @@ type ListSubscriptionsOptions struct {
 type SubscriptionRepository interface {
 	ListByUser(ctx context.Context, userID uint64, opts ListSubscriptionsOptions) ([]Subscription, int64, error)
 	Get(ctx context.Context, id uint64) (Subscription, error)
+	GetForUpdate(ctx context.Context, id uint64) (Subscription, error)
 	UpdateTemplate(ctx context.Context, subscriptionID uint64, templateID uint64, userID uint64) (Subscription, error)
+	Create(ctx context.Context, subscription Subscription) (Subscription, error)
+	ApplyPlan(ctx context.Context, subscriptionID uint64, params ApplySubscriptionPlanParams) (Subscription, error)
+	SetAutoRenew(ctx context.Context, subscriptionID uint64, userID uint64, enabled bool) (Subscription, error)
+	ListAutoRenewDue(ctx context.Context, expiresAfter, expiresBefore time.Time, now time.Time, limit int) ([]Subscription, error)
+	RecordAutoRenewResult(ctx context.Context, subscriptionID uint64, params AutoRenewResultParams) (Subscription, error)
+	AddTrafficExtra(ctx context.Context, subscriptionID uint64, bytes int64) (Subscription, error)
+	ListTrafficResetDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	ResetTrafficPeriod(ctx context.Context, subscriptionID uint64, now time.Time) (Subscription, SubscriptionTrafficPeriod, error)
+	ListTrafficPeriods(ctx context.Context, subscriptionID uint64, limit int) ([]SubscriptionTrafficPeriod, error)
+	TransitionStatus(ctx context.Context, subscriptionID uint64, to string, reason string) (Subscription, error)
+	ListExpiryDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	ListTrafficExhausted(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	ListOwnerDisabled(ctx context.Context, limit int) ([]Subscription, error)
+	ListOwnerRestored(ctx context.Context, limit int) ([]Subscription, error)
+	ListPendingEvents(ctx context.Context, limit int) ([]SubscriptionEvent, error)
+	RecordEventDelivery(ctx context.Context, eventID uint64, deliveryErr string, giveUp bool) (SubscriptionEvent, error)
+	ListServable(ctx context.Context, now time.Time, afterID uint64, limit int) ([]Subscription, error)
+	ResetToken(ctx context.Context, subscriptionID uint64) (Subscription, error)
+	RotateProxyUUID(ctx context.Context, subscriptionID uint64) (Subscription, error)
+	ResolveToken(ctx context.Context, token string) (Subscription, *SubscriptionToken, error)
+	ListAccessTokens(ctx context.Context, subscriptionID uint64) ([]SubscriptionToken, error)
+	CreateAccessToken(ctx context.Context, subscriptionID uint64, name string) (SubscriptionToken, error)
+	RevokeAccessToken(ctx context.Context, subscriptionID, tokenID uint64) error
+	RecordTokenAccess(ctx context.Context, tokenID uint64, ip, userAgent string, at time.Time) (SubscriptionToken, error)
+	RecordAccess(ctx context.Context, log SubscriptionAccessLog) (SubscriptionAccessLog, error)
+	ListAccessLogs(ctx context.Context, opts ListAccessLogsOptions) ([]SubscriptionAccessLog, int64, error)
+	ListAccessAnomalies(ctx context.Context, since time.Time, minDistinctIPs int, limit int) ([]TokenAccessStat, error)
+	GetAccessStat(ctx context.Context, subscriptionID uint64, token string, since time.Time) (TokenAccessStat, error)
+	PruneAccessLogs(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AutoRenewResultParams 记录一次自动续费尝试的结果。
+type AutoRenewResultParams struct {
+	Succeeded bool
+	Error     string
+	NextAt    *time.Time
+	Disable   bool
+}
+
+// ApplySubscriptionPlanParams 描述套餐变更/续费后写回订阅的字段。
+// ResetTraffic 同时清空当期加购流量并以当前时间重新锚定重置周期；TrafficResetPolicy 为空时沿用原策略。
+// PeriodStartedAt 非零时开启新的计费周期：PeriodPaidCents 为 [PeriodStartedAt, ExpiresAt] 的实付价值（含转入的抵扣），
+// 用于变更套餐时折算剩余价值；为零时保留原周期记录。
+type ApplySubscriptionPlanParams struct {
+	PlanID             uint64
+	PlanName           string
+	ExpiresAt          time.Time
+	TrafficTotalBytes  int64
+	DevicesLimit       int
+	ResetTraffic       bool
+	TrafficResetPolicy string
+	PeriodPaidCents    int64
+	PeriodCurrency     string
+	PeriodStartedAt    time.Time
 }
 
 type subscriptionRepository struct {
@@ -66,6 +141,15 @@ func NewSubscriptionRepository(db *gorm.DB, templateRepo SubscriptionTemplateRep
 	return &subscriptionRepository{db: db, templateRepo: templateRepo}, nil
 }
 
+// GenerateSubscriptionToken 生成订阅鉴权 token。
+func GenerateSubscriptionToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("repository: generate subscription token: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
 func (r *subscriptionRepository) ListByUser(ctx context.Context, userID uint64, opts ListSubscriptionsOptions) ([]Subscription, int64, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, 0, err
@@ -117,6 +201,20 @@ func (r *subscriptionRepository) Get(ctx context.Context, id uint64) (Subscripti
 	return subscription, nil
 }
 
+// GetForUpdate 锁定订阅行，需在调用方事务内使用。
+func (r *subscriptionRepository) GetForUpdate(ctx context.Context, id uint64) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	var subscription Subscription
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, id).Error; err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
 func (r *subscriptionRepository) UpdateTemplate(ctx context.Context, subscriptionID uint64, templateID uint64, userID uint64) (Subscription, error) {
 	if err := ctx.Err(); err != nil {
 		return Subscription{}, err
@@ -155,6 +253,10 @@ func (r *subscriptionRepository) UpdateTemplate(ctx context.Context, subscriptio
 		if err := tx.First(&tpl, targetTemplate).Error; err != nil {
 			return err
 		}
+		// 未发布的模板不可选。
+		if tpl.Version == 0 {
+			return ErrNotFound
+		}
 
 		subscription.TemplateID = tpl.ID
 		now := time.Now().UTC()
@@ -166,7 +268,7 @@ func (r *subscriptionRepository) UpdateTemplate(ctx context.Context, subscriptio
 
 	if err != nil {
 		switch {
-		case errors.Is(err, ErrForbidden):
+		case errors.Is(err, ErrForbidden), errors.Is(err, ErrNotFound):
 			return Subscription{}, err
 		default:
 			return Subscription{}, translateError(err)
@@ -176,6 +278,207 @@ func (r *subscriptionRepository) UpdateTemplate(ctx context.Context, subscriptio
 	return subscription, nil
 }
 
+func (r *subscriptionRepository) Create(ctx context.Context, subscription Subscription) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	if subscription.UserID == 0 {
+		return Subscription{}, ErrInvalidArgument
+	}
+
+	now := time.Now().UTC()
+	if subscription.CreatedAt.IsZero() {
+		subscription.CreatedAt = now
+	}
+	subscription.UpdatedAt = now
+	if subscription.LastRefreshedAt.IsZero() {
+		subscription.LastRefreshedAt = now
+	}
+	subscription.Status = normalizeSubscriptionStatus(subscription.Status)
+	if subscription.Status == "" {
+		subscription.Status = SubscriptionStatusActive
+	}
+	if !ValidSubscriptionStatus(subscription.Status) {
+		return Subscription{}, ErrInvalidArgument
+	}
+	if subscription.AvailableTemplateIDs == nil {
+		subscription.AvailableTemplateIDs = []uint64{}
+	}
+	if subscription.ProxyUUID == "" {
+		subscription.ProxyUUID = uuid.NewString()
+	}
+	scheduleTrafficReset(&subscription, subscription.TrafficResetPolicy, now)
+
+	if err := r.db.WithContext(ctx).Create(&subscription).Error; err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) ApplyPlan(ctx context.Context, subscriptionID uint64, params ApplySubscriptionPlanParams) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	if params.PlanID == 0 || params.ExpiresAt.IsZero() {
+		return Subscription{}, ErrInvalidArgument
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+		if normalizeSubscriptionStatus(subscription.Status) == SubscriptionStatusCancelled {
+			return ErrInvalidState
+		}
+
+		now := time.Now().UTC()
+		subscription.PlanID = params.PlanID
+		if name := strings.TrimSpace(params.PlanName); name != "" {
+			subscription.PlanName = name
+		}
+		subscription.ExpiresAt = params.ExpiresAt.UTC()
+		subscription.TrafficTotalBytes = params.TrafficTotalBytes
+		subscription.DevicesLimit = params.DevicesLimit
+		if !params.PeriodStartedAt.IsZero() {
+			startedAt := params.PeriodStartedAt.UTC()
+			subscription.PeriodPaidCents = params.PeriodPaidCents
+			subscription.PeriodCurrency = NormalizeCurrency(params.PeriodCurrency)
+			subscription.PeriodStartedAt = &startedAt
+		}
+		if params.ResetTraffic {
+			subscription.TrafficUsedBytes = 0
+			subscription.TrafficExtraBytes = 0
+			subscription.TrafficResetDay = 0
+			subscription.TrafficPeriodStartAt = nil
+			subscription.TrafficNextResetAt = nil
+		}
+		policy := subscription.TrafficResetPolicy
+		if strings.TrimSpace(params.TrafficResetPolicy) != "" {
+			policy = params.TrafficResetPolicy
+		}
+		scheduleTrafficReset(&subscription, policy, now)
+		// 暂停由管理员或风控决定，开通/续费不自动解除。
+		if normalizeSubscriptionStatus(subscription.Status) != SubscriptionStatusSuspended {
+			if err := transitionSubscription(tx, &subscription, SettledSubscriptionStatus(subscription, now), SubscriptionReasonPlanApplied, now); err != nil {
+				return err
+			}
+		}
+		subscription.LastRefreshedAt = now
+		subscription.UpdatedAt = now
+
+		return tx.Save(&subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) SetAutoRenew(ctx context.Context, subscriptionID uint64, userID uint64, enabled bool) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+		if subscription.UserID != userID {
+			return ErrForbidden
+		}
+
+		subscription.AutoRenew = enabled
+		// 重新开启时清空失败计数，允许调度器立即重试。
+		subscription.AutoRenewFailures = 0
+		subscription.AutoRenewNextAt = nil
+		subscription.AutoRenewLastError = ""
+		subscription.UpdatedAt = time.Now().UTC()
+
+		return tx.Model(&subscription).
+			Select("AutoRenew", "AutoRenewFailures", "AutoRenewNextAt", "AutoRenewLastError", "UpdatedAt").
+			Updates(subscription).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrForbidden) {
+			return Subscription{}, err
+		}
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) ListAutoRenewDue(ctx context.Context, expiresAfter, expiresBefore time.Time, now time.Time, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Where("auto_renew = ?", true).
+		Where("plan_id > 0").
+		Where("status NOT IN ?", []string{SubscriptionStatusSuspended, SubscriptionStatusCancelled}).
+		Where("expires_at > ? AND expires_at <= ?", expiresAfter.UTC(), expiresBefore.UTC()).
+		Where("(auto_renew_next_at IS NULL OR auto_renew_next_at <= ?)", now.UTC()).
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) RecordAutoRenewResult(ctx context.Context, subscriptionID uint64, params AutoRenewResultParams) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+
+		if params.Succeeded {
+			subscription.AutoRenewFailures = 0
+			subscription.AutoRenewLastError = ""
+		} else {
+			subscription.AutoRenewFailures++
+			message := strings.TrimSpace(params.Error)
+			if len(message) > 255 {
+				message = message[:255]
+			}
+			subscription.AutoRenewLastError = message
+		}
+		subscription.AutoRenewNextAt = params.NextAt
+		if params.Disable {
+			subscription.AutoRenew = false
+		}
+		subscription.UpdatedAt = time.Now().UTC()
+
+		return tx.Model(&subscription).
+			Select("AutoRenew", "AutoRenewFailures", "AutoRenewNextAt", "AutoRenewLastError", "UpdatedAt").
+			Updates(subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
 func buildSubscriptionOrderClause(field, direction string) string {
 	column := "updated_at"
 	switch strings.ToLower(field) {
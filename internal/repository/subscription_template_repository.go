@@ -91,6 +91,8 @@ type UpdateSubscriptionTemplateInput struct {
 type PublishSubscriptionTemplateInput struct {
 	Changelog string
 	Operator  string
+	// Check 在发布事务内对加锁后的待发布内容执行检查，返回错误时放弃发布；为空时不检查。
+	Check func(SubscriptionTemplate) error
 }
 
 // SubscriptionTemplateRepository 定义模板操作接口。
@@ -99,8 +101,14 @@ type SubscriptionTemplateRepository interface {
 	Create(ctx context.Context, input CreateSubscriptionTemplateInput) (SubscriptionTemplate, error)
 	Update(ctx context.Context, id uint64, input UpdateSubscriptionTemplateInput) (SubscriptionTemplate, error)
 	Publish(ctx context.Context, id uint64, input PublishSubscriptionTemplateInput) (SubscriptionTemplate, SubscriptionTemplateHistory, error)
+	Rollback(ctx context.Context, id uint64, version uint32, input PublishSubscriptionTemplateInput) (SubscriptionTemplate, SubscriptionTemplateHistory, error)
 	History(ctx context.Context, id uint64) ([]SubscriptionTemplateHistory, error)
+	GetHistory(ctx context.Context, id uint64, version uint32) (SubscriptionTemplateHistory, error)
+	ImportHistory(ctx context.Context, id uint64, entries []SubscriptionTemplateHistory) (SubscriptionTemplate, error)
 	Get(ctx context.Context, id uint64) (SubscriptionTemplate, error)
+	GetByName(ctx context.Context, clientType, name string) (SubscriptionTemplate, error)
+	GetDefault(ctx context.Context, clientType string) (SubscriptionTemplate, error)
+	GetPublished(ctx context.Context, id uint64) (SubscriptionTemplate, error)
 }
 
 type subscriptionTemplateRepository struct {
@@ -166,8 +174,7 @@ func (r *subscriptionTemplateRepository) Create(ctx context.Context, input Creat
 
 	name := strings.TrimSpace(input.Name)
 	clientType := strings.ToLower(strings.TrimSpace(input.ClientType))
-	content := strings.TrimSpace(input.Content)
-	if name == "" || clientType == "" || content == "" {
+	if name == "" || clientType == "" {
 		return SubscriptionTemplate{}, ErrInvalidArgument
 	}
 
@@ -175,6 +182,10 @@ func (r *subscriptionTemplateRepository) Create(ctx context.Context, input Creat
 	if format == "" {
 		format = "go_template"
 	}
+	// 只有 go_template 使用模板内容，json 与内置格式直接由上下文生成。
+	if format == "go_template" && strings.TrimSpace(input.Content) == "" {
+		return SubscriptionTemplate{}, ErrInvalidArgument
+	}
 
 	tpl := SubscriptionTemplate{
 		Name:        name,
@@ -263,12 +274,11 @@ func (r *subscriptionTemplateRepository) Update(ctx context.Context, id uint64,
 			tpl.Format = newFormat
 		}
 		if input.Content != nil {
-			newContent := strings.TrimSpace(*input.Content)
-			if newContent == "" {
-				return ErrInvalidArgument
-			}
 			tpl.Content = *input.Content
 		}
+		if tpl.Format == "go_template" && strings.TrimSpace(tpl.Content) == "" {
+			return ErrInvalidArgument
+		}
 		if input.Variables != nil {
 			tpl.Variables = cloneTemplateVariables(input.Variables)
 		}
@@ -307,28 +317,43 @@ func (r *subscriptionTemplateRepository) Publish(ctx context.Context, id uint64,
 			return err
 		}
 
-		now := time.Now().UTC()
-		tpl.Version++
-		tpl.UpdatedAt = now
-		tpl.LastPublishedBy = strings.TrimSpace(input.Operator)
-		tpl.PublishedAt = &now
+		var err error
+		history, err = publishTemplate(tx, &tpl, input)
+		return err
+	})
+
+	if err != nil {
+		return SubscriptionTemplate{}, SubscriptionTemplateHistory{}, translateError(err)
+	}
+
+	return tpl, history, nil
+}
+
+func (r *subscriptionTemplateRepository) Rollback(ctx context.Context, id uint64, version uint32, input PublishSubscriptionTemplateInput) (SubscriptionTemplate, SubscriptionTemplateHistory, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplate{}, SubscriptionTemplateHistory{}, err
+	}
+
+	var tpl SubscriptionTemplate
+	var history SubscriptionTemplateHistory
 
-		if err := tx.Save(&tpl).Error; err != nil {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tpl, id).Error; err != nil {
 			return err
 		}
 
-		history = SubscriptionTemplateHistory{
-			TemplateID:  tpl.ID,
-			Version:     tpl.Version,
-			Content:     tpl.Content,
-			Variables:   cloneTemplateVariables(tpl.Variables),
-			Format:      tpl.Format,
-			Changelog:   strings.TrimSpace(input.Changelog),
-			PublishedAt: now,
-			PublishedBy: tpl.LastPublishedBy,
+		var source SubscriptionTemplateHistory
+		if err := tx.Where("template_id = ? AND version = ?", id, version).First(&source).Error; err != nil {
+			return err
 		}
 
-		return tx.Create(&history).Error
+		tpl.Content = source.Content
+		tpl.Format = source.Format
+		tpl.Variables = cloneTemplateVariables(source.Variables)
+
+		var err error
+		history, err = publishTemplate(tx, &tpl, input)
+		return err
 	})
 
 	if err != nil {
@@ -338,6 +363,40 @@ func (r *subscriptionTemplateRepository) Publish(ctx context.Context, id uint64,
 	return tpl, history, nil
 }
 
+// publishTemplate 在已加锁的模板上执行发布检查，通过后递增版本并写入发布快照。
+func publishTemplate(tx *gorm.DB, tpl *SubscriptionTemplate, input PublishSubscriptionTemplateInput) (SubscriptionTemplateHistory, error) {
+	if input.Check != nil {
+		if err := input.Check(*tpl); err != nil {
+			return SubscriptionTemplateHistory{}, err
+		}
+	}
+	now := time.Now().UTC()
+	tpl.Version++
+	tpl.UpdatedAt = now
+	tpl.LastPublishedBy = strings.TrimSpace(input.Operator)
+	tpl.PublishedAt = &now
+
+	if err := tx.Save(tpl).Error; err != nil {
+		return SubscriptionTemplateHistory{}, err
+	}
+
+	history := SubscriptionTemplateHistory{
+		TemplateID:  tpl.ID,
+		Version:     tpl.Version,
+		Content:     tpl.Content,
+		Variables:   cloneTemplateVariables(tpl.Variables),
+		Format:      tpl.Format,
+		Changelog:   strings.TrimSpace(input.Changelog),
+		PublishedAt: now,
+		PublishedBy: tpl.LastPublishedBy,
+	}
+	if err := tx.Create(&history).Error; err != nil {
+		return SubscriptionTemplateHistory{}, err
+	}
+
+	return history, nil
+}
+
 func (r *subscriptionTemplateRepository) History(ctx context.Context, id uint64) ([]SubscriptionTemplateHistory, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -354,6 +413,87 @@ func (r *subscriptionTemplateRepository) History(ctx context.Context, id uint64)
 	return history, nil
 }
 
+func (r *subscriptionTemplateRepository) GetHistory(ctx context.Context, id uint64, version uint32) (SubscriptionTemplateHistory, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplateHistory{}, err
+	}
+
+	var history SubscriptionTemplateHistory
+	if err := r.db.WithContext(ctx).
+		Where("template_id = ? AND version = ?", id, version).
+		First(&history).Error; err != nil {
+		return SubscriptionTemplateHistory{}, translateError(err)
+	}
+
+	return history, nil
+}
+
+// ImportHistory 为尚未发布过的模板写入外部导入的发布历史，并将版本推进到其中最新的快照。
+func (r *subscriptionTemplateRepository) ImportHistory(ctx context.Context, id uint64, entries []SubscriptionTemplateHistory) (SubscriptionTemplate, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplate{}, err
+	}
+	if len(entries) == 0 {
+		return SubscriptionTemplate{}, ErrInvalidArgument
+	}
+
+	var tpl SubscriptionTemplate
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&tpl, id).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&SubscriptionTemplateHistory{}).Where("template_id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if tpl.Version != 0 || count > 0 {
+			return ErrConflict
+		}
+
+		latest := entries[0]
+		seen := make(map[uint32]struct{}, len(entries))
+		for _, entry := range entries {
+			if entry.Version == 0 {
+				return ErrInvalidArgument
+			}
+			if _, ok := seen[entry.Version]; ok {
+				return ErrInvalidArgument
+			}
+			seen[entry.Version] = struct{}{}
+
+			history := SubscriptionTemplateHistory{
+				TemplateID:  tpl.ID,
+				Version:     entry.Version,
+				Content:     entry.Content,
+				Variables:   cloneTemplateVariables(entry.Variables),
+				Format:      strings.ToLower(strings.TrimSpace(entry.Format)),
+				Changelog:   strings.TrimSpace(entry.Changelog),
+				PublishedAt: entry.PublishedAt.UTC(),
+				PublishedBy: strings.TrimSpace(entry.PublishedBy),
+			}
+			if err := tx.Create(&history).Error; err != nil {
+				return err
+			}
+			if entry.Version > latest.Version {
+				latest = entry
+			}
+		}
+
+		publishedAt := latest.PublishedAt.UTC()
+		tpl.Version = latest.Version
+		tpl.PublishedAt = &publishedAt
+		tpl.LastPublishedBy = strings.TrimSpace(latest.PublishedBy)
+		tpl.UpdatedAt = time.Now().UTC()
+		return tx.Save(&tpl).Error
+	})
+
+	if err != nil {
+		return SubscriptionTemplate{}, translateError(err)
+	}
+
+	return tpl, nil
+}
+
 func (r *subscriptionTemplateRepository) Get(ctx context.Context, id uint64) (SubscriptionTemplate, error) {
 	if err := ctx.Err(); err != nil {
 		return SubscriptionTemplate{}, err
@@ -367,6 +507,68 @@ func (r *subscriptionTemplateRepository) Get(ctx context.Context, id uint64) (Su
 	return tpl, nil
 }
 
+// GetByName 按客户端类型与名称（不区分大小写）查找模板。
+func (r *subscriptionTemplateRepository) GetByName(ctx context.Context, clientType, name string) (SubscriptionTemplate, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplate{}, err
+	}
+
+	var tpl SubscriptionTemplate
+	if err := r.db.WithContext(ctx).
+		Where("LOWER(name) = ? AND LOWER(client_type) = ?",
+			strings.ToLower(strings.TrimSpace(name)), strings.ToLower(strings.TrimSpace(clientType))).
+		First(&tpl).Error; err != nil {
+		return SubscriptionTemplate{}, translateError(err)
+	}
+
+	return tpl, nil
+}
+
+// GetDefault 返回客户端类型下已发布的默认模板，clientType 为空时不限客户端类型、取 ID 最小者；不存在时返回 ErrNotFound。
+func (r *subscriptionTemplateRepository) GetDefault(ctx context.Context, clientType string) (SubscriptionTemplate, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplate{}, err
+	}
+
+	query := r.db.WithContext(ctx).Where("is_default = ? AND version > 0", true)
+	if client := strings.ToLower(strings.TrimSpace(clientType)); client != "" {
+		query = query.Where("LOWER(client_type) = ?", client)
+	}
+	var tpl SubscriptionTemplate
+	if err := query.Order("id ASC").First(&tpl).Error; err != nil {
+		return SubscriptionTemplate{}, translateError(err)
+	}
+
+	return tpl, nil
+}
+
+// GetPublished 返回模板最新发布版本的快照（内容、格式与变量取自发布历史），未发布的模板视为不存在。
+func (r *subscriptionTemplateRepository) GetPublished(ctx context.Context, id uint64) (SubscriptionTemplate, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionTemplate{}, err
+	}
+
+	var tpl SubscriptionTemplate
+	if err := r.db.WithContext(ctx).First(&tpl, id).Error; err != nil {
+		return SubscriptionTemplate{}, translateError(err)
+	}
+	if tpl.Version == 0 {
+		return SubscriptionTemplate{}, ErrNotFound
+	}
+
+	var history SubscriptionTemplateHistory
+	if err := r.db.WithContext(ctx).
+		Where("template_id = ? AND version = ?", tpl.ID, tpl.Version).
+		First(&history).Error; err != nil {
+		return SubscriptionTemplate{}, translateError(err)
+	}
+
+	tpl.Content = history.Content
+	tpl.Format = history.Format
+	tpl.Variables = cloneTemplateVariables(history.Variables)
+	return tpl, nil
+}
+
 func buildTemplateOrderClause(field, direction string) string {
 	column := "updated_at"
 	switch strings.ToLower(field) {
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxSubscriptionAccessTokens 单个订阅可创建的命名访问链接上限。
+const MaxSubscriptionAccessTokens = 10
+
+// maxTokenRecentIPs 每个访问链接保留的最近访问 IP 数。
+const maxTokenRecentIPs = 10
+
+// SubscriptionToken 订阅的命名访问链接（如 phone、laptop），与主 token 一样可拉取订阅，
+// 并单独记录最近访问时间、User-Agent 与 IP，便于发现链接外泄。
+type SubscriptionToken struct {
+	ID             uint64     `gorm:"primaryKey"`
+	SubscriptionID uint64     `gorm:"uniqueIndex:idx_subscription_tokens_name"`
+	UserID         uint64     `gorm:"index"`
+	Name           string     `gorm:"size:64;uniqueIndex:idx_subscription_tokens_name"`
+	Token          string     `gorm:"size:255;uniqueIndex"`
+	AccessCount    int64      `gorm:"column:access_count"`
+	LastAccessAt   *time.Time `gorm:"column:last_access_at"`
+	LastIP         string     `gorm:"column:last_ip;size:64"`
+	LastUserAgent  string     `gorm:"column:last_user_agent;size:255"`
+	RecentIPs      []string   `gorm:"column:recent_ips;serializer:json"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+// TableName binds subscription access tokens.
+func (SubscriptionToken) TableName() string { return "subscription_tokens" }
+
+func (r *subscriptionRepository) ResetToken(ctx context.Context, subscriptionID uint64) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	token, err := GenerateSubscriptionToken()
+	if err != nil {
+		return Subscription{}, err
+	}
+
+	var subscription Subscription
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+
+		subscription.Token = token
+		subscription.UpdatedAt = time.Now().UTC()
+		return tx.Model(&subscription).Select("Token", "UpdatedAt").Updates(subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+// RotateProxyUUID 重新生成节点侧代理凭据，客户端需重新拉取订阅；拉取 token 保持不变。
+func (r *subscriptionRepository) RotateProxyUUID(ctx context.Context, subscriptionID uint64) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+
+		subscription.ProxyUUID = uuid.NewString()
+		subscription.UpdatedAt = time.Now().UTC()
+		return tx.Model(&subscription).Select("ProxyUUID", "UpdatedAt").Updates(subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) ResolveToken(ctx context.Context, token string) (Subscription, *SubscriptionToken, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, nil, err
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Subscription{}, nil, ErrNotFound
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Where("token = ?", token).First(&subscription).Error
+	if err == nil {
+		return subscription, nil, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return Subscription{}, nil, err
+	}
+
+	var access SubscriptionToken
+	if err := r.db.WithContext(ctx).Where("token = ?", token).First(&access).Error; err != nil {
+		return Subscription{}, nil, translateError(err)
+	}
+	if err := r.db.WithContext(ctx).First(&subscription, access.SubscriptionID).Error; err != nil {
+		return Subscription{}, nil, translateError(err)
+	}
+
+	return subscription, &access, nil
+}
+
+func (r *subscriptionRepository) ListAccessTokens(ctx context.Context, subscriptionID uint64) ([]SubscriptionToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var tokens []SubscriptionToken
+	if err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("id ASC").
+		Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *subscriptionRepository) CreateAccessToken(ctx context.Context, subscriptionID uint64, name string) (SubscriptionToken, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionToken{}, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 64 {
+		return SubscriptionToken{}, ErrInvalidArgument
+	}
+
+	token, err := GenerateSubscriptionToken()
+	if err != nil {
+		return SubscriptionToken{}, err
+	}
+
+	var access SubscriptionToken
+	err = r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var subscription Subscription
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&SubscriptionToken{}).Where("subscription_id = ?", subscriptionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= MaxSubscriptionAccessTokens {
+			return ErrConflict
+		}
+
+		now := time.Now().UTC()
+		access = SubscriptionToken{
+			SubscriptionID: subscription.ID,
+			UserID:         subscription.UserID,
+			Name:           name,
+			Token:          token,
+			RecentIPs:      []string{},
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		return tx.Create(&access).Error
+	})
+	if err != nil {
+		return SubscriptionToken{}, translateError(err)
+	}
+
+	return access, nil
+}
+
+func (r *subscriptionRepository) RevokeAccessToken(ctx context.Context, subscriptionID, tokenID uint64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	result := r.db.WithContext(ctx).
+		Where("id = ? AND subscription_id = ?", tokenID, subscriptionID).
+		Delete(&SubscriptionToken{})
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) RecordTokenAccess(ctx context.Context, tokenID uint64, ip, userAgent string, at time.Time) (SubscriptionToken, error) {
+	if err := ctx.Err(); err != nil {
+		return SubscriptionToken{}, err
+	}
+
+	var access SubscriptionToken
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&access, tokenID).Error; err != nil {
+			return err
+		}
+
+		accessedAt := at.UTC()
+		userAgent = strings.TrimSpace(userAgent)
+		if len(userAgent) > 255 {
+			userAgent = userAgent[:255]
+		}
+		access.AccessCount++
+		access.LastAccessAt = &accessedAt
+		access.LastIP = ip
+		access.LastUserAgent = userAgent
+		access.RecentIPs = pushRecentIP(access.RecentIPs, ip)
+		access.UpdatedAt = accessedAt
+
+		return tx.Model(&access).
+			Select("AccessCount", "LastAccessAt", "LastIP", "LastUserAgent", "RecentIPs", "UpdatedAt").
+			Updates(access).Error
+	})
+	if err != nil {
+		return SubscriptionToken{}, translateError(err)
+	}
+
+	return access, nil
+}
+
+// pushRecentIP 将 ip 移到最前，去重并截断到 maxTokenRecentIPs。
+func pushRecentIP(ips []string, ip string) []string {
+	if ip == "" {
+		return ips
+	}
+	result := make([]string, 0, maxTokenRecentIPs)
+	result = append(result, ip)
+	for _, existing := range ips {
+		if existing == ip {
+			continue
+		}
+		if len(result) >= maxTokenRecentIPs {
+			break
+		}
+		result = append(result, existing)
+	}
+	return result
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 流量重置策略，定义在套餐上并在开通/续费时复制到订阅。
+const (
+	TrafficResetNever       = "never"
+	TrafficResetMonthly     = "monthly"
+	TrafficResetPurchaseDay = "purchase_day"
+)
+
+// SubscriptionTrafficPeriod 归档订阅在一个重置周期内的流量用量。
+type SubscriptionTrafficPeriod struct {
+	ID             uint64    `gorm:"primaryKey"`
+	SubscriptionID uint64    `gorm:"uniqueIndex:idx_traffic_periods_subscription_end"`
+	UserID         uint64    `gorm:"index"`
+	PlanID         uint64    `gorm:"index"`
+	PeriodStart    time.Time `gorm:"column:period_start"`
+	PeriodEnd      time.Time `gorm:"column:period_end;uniqueIndex:idx_traffic_periods_subscription_end"`
+	UsedBytes      int64     `gorm:"column:used_bytes"`
+	TotalBytes     int64     `gorm:"column:total_bytes"`
+	ExtraBytes     int64     `gorm:"column:extra_bytes"`
+	CreatedAt      time.Time
+}
+
+// TableName binds traffic period archives.
+func (SubscriptionTrafficPeriod) TableName() string { return "subscription_traffic_periods" }
+
+// NormalizeTrafficResetPolicy 归一化重置策略，空值视为 never；未知值原样返回供调用方校验。
+func NormalizeTrafficResetPolicy(policy string) string {
+	policy = strings.TrimSpace(strings.ToLower(policy))
+	if policy == "" {
+		return TrafficResetNever
+	}
+	return policy
+}
+
+// ValidTrafficResetPolicy 判断策略是否受支持。
+func ValidTrafficResetPolicy(policy string) bool {
+	switch NormalizeTrafficResetPolicy(policy) {
+	case TrafficResetNever, TrafficResetMonthly, TrafficResetPurchaseDay:
+		return true
+	default:
+		return false
+	}
+}
+
+// NextTrafficResetAt 返回 after 之后的下一次重置时间（UTC 零点）。
+// monthly 固定每月 1 日；purchase_day 为每月 anchorDay 日，月份天数不足时取当月最后一天；never 返回 nil。
+func NextTrafficResetAt(policy string, anchorDay int, after time.Time) *time.Time {
+	after = after.UTC()
+	var day int
+	switch NormalizeTrafficResetPolicy(policy) {
+	case TrafficResetMonthly:
+		day = 1
+	case TrafficResetPurchaseDay:
+		day = anchorDay
+		if day <= 0 {
+			day = after.Day()
+		}
+	default:
+		return nil
+	}
+
+	year, month := after.Year(), after.Month()
+	for {
+		candidate := resetDayInMonth(year, month, day)
+		if candidate.After(after) {
+			return &candidate
+		}
+		month++
+		if month > time.December {
+			month = time.January
+			year++
+		}
+	}
+}
+
+func resetDayInMonth(year int, month time.Month, day int) time.Time {
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > last {
+		day = last
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// scheduleTrafficReset 写入重置策略并在需要时重新计算下一次重置时间。
+// 策略未变且已排期时保留原有时间，避免跳过尚未被调度器处理的重置。
+func scheduleTrafficReset(sub *Subscription, policy string, now time.Time) {
+	policy = NormalizeTrafficResetPolicy(policy)
+	unchanged := NormalizeTrafficResetPolicy(sub.TrafficResetPolicy) == policy
+	sub.TrafficResetPolicy = policy
+
+	if sub.TrafficPeriodStartAt == nil {
+		start := now.UTC()
+		sub.TrafficPeriodStartAt = &start
+	}
+	if sub.TrafficResetDay <= 0 {
+		sub.TrafficResetDay = sub.TrafficPeriodStartAt.Day()
+	}
+	if unchanged && sub.TrafficNextResetAt != nil {
+		return
+	}
+	sub.TrafficNextResetAt = NextTrafficResetAt(policy, sub.TrafficResetDay, now)
+}
+
+func (r *subscriptionRepository) AddTrafficExtra(ctx context.Context, subscriptionID uint64, bytes int64) (Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, err
+	}
+	if bytes <= 0 {
+		return Subscription{}, ErrInvalidArgument
+	}
+
+	var subscription Subscription
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		subscription.TrafficExtraBytes += bytes
+		subscription.TrafficTotalBytes += bytes
+		subscription.UpdatedAt = now
+		if normalizeSubscriptionStatus(subscription.Status) == SubscriptionStatusExhausted {
+			if err := transitionSubscription(tx, &subscription, SettledSubscriptionStatus(subscription, now), SubscriptionReasonTrafficAddon, now); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&subscription).
+			Select("TrafficExtraBytes", "TrafficTotalBytes", "Status", "StatusReason", "StatusChangedAt", "UpdatedAt").
+			Updates(subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, translateError(err)
+	}
+
+	return subscription, nil
+}
+
+func (r *subscriptionRepository) ListTrafficResetDue(ctx context.Context, now time.Time, limit int) ([]Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 100
+	}
+
+	var subscriptions []Subscription
+	err := r.db.WithContext(ctx).
+		Where("traffic_reset_policy <> ?", TrafficResetNever).
+		Where("status <> ?", SubscriptionStatusCancelled).
+		Where("traffic_next_reset_at IS NOT NULL AND traffic_next_reset_at <= ?", now.UTC()).
+		Where("expires_at > ?", now.UTC()).
+		Order("traffic_next_reset_at ASC").
+		Limit(limit).
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (r *subscriptionRepository) ResetTrafficPeriod(ctx context.Context, subscriptionID uint64, now time.Time) (Subscription, SubscriptionTrafficPeriod, error) {
+	if err := ctx.Err(); err != nil {
+		return Subscription{}, SubscriptionTrafficPeriod{}, err
+	}
+
+	now = now.UTC()
+	var subscription Subscription
+	var period SubscriptionTrafficPeriod
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&subscription, subscriptionID).Error; err != nil {
+			return err
+		}
+		// 其他实例已处理或策略已关闭。
+		if subscription.TrafficNextResetAt == nil || subscription.TrafficNextResetAt.After(now) {
+			return ErrInvalidState
+		}
+
+		boundary := subscription.TrafficNextResetAt.UTC()
+		start := subscription.CreatedAt.UTC()
+		if subscription.TrafficPeriodStartAt != nil {
+			start = subscription.TrafficPeriodStartAt.UTC()
+		}
+		period = SubscriptionTrafficPeriod{
+			SubscriptionID: subscription.ID,
+			UserID:         subscription.UserID,
+			PlanID:         subscription.PlanID,
+			PeriodStart:    start,
+			PeriodEnd:      boundary,
+			UsedBytes:      subscription.TrafficUsedBytes,
+			TotalBytes:     subscription.TrafficTotalBytes,
+			ExtraBytes:     subscription.TrafficExtraBytes,
+			CreatedAt:      now,
+		}
+		if err := tx.Create(&period).Error; err != nil {
+			return err
+		}
+
+		// 调度中断跨越多个周期时只归档一次，新周期从最近一个边界开始。
+		next := NextTrafficResetAt(subscription.TrafficResetPolicy, subscription.TrafficResetDay, boundary)
+		for next != nil && !next.After(now) {
+			boundary = *next
+			next = NextTrafficResetAt(subscription.TrafficResetPolicy, subscription.TrafficResetDay, boundary)
+		}
+
+		subscription.TrafficTotalBytes -= subscription.TrafficExtraBytes
+		if subscription.TrafficTotalBytes < 0 {
+			subscription.TrafficTotalBytes = 0
+		}
+		subscription.TrafficUsedBytes = 0
+		subscription.TrafficExtraBytes = 0
+		subscription.TrafficPeriodStartAt = &boundary
+		subscription.TrafficNextResetAt = next
+		subscription.UpdatedAt = now
+		if normalizeSubscriptionStatus(subscription.Status) == SubscriptionStatusExhausted {
+			if err := transitionSubscription(tx, &subscription, SettledSubscriptionStatus(subscription, now), SubscriptionReasonTrafficReset, now); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&subscription).
+			Select("TrafficTotalBytes", "TrafficUsedBytes", "TrafficExtraBytes", "TrafficPeriodStartAt", "TrafficNextResetAt",
+				"Status", "StatusReason", "StatusChangedAt", "UpdatedAt").
+			Updates(subscription).Error
+	})
+	if err != nil {
+		return Subscription{}, SubscriptionTrafficPeriod{}, translateError(err)
+	}
+
+	return subscription, period, nil
+}
+
+func (r *subscriptionRepository) ListTrafficPeriods(ctx context.Context, subscriptionID uint64, limit int) ([]SubscriptionTrafficPeriod, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > 100 {
+		limit = 12
+	}
+
+	var periods []SubscriptionTrafficPeriod
+	if err := r.db.WithContext(ctx).
+		Where("subscription_id = ?", subscriptionID).
+		Order("period_end DESC").
+		Limit(limit).
+		Find(&periods).Error; err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
@@ -30,6 +30,7 @@ type UserRepository interface {
 	Get(ctx context.Context, id uint64) (User, error)
 	GetByEmail(ctx context.Context, email string) (User, error)
 	UpdateLastLogin(ctx context.Context, id uint64, ts time.Time) error
+	Create(ctx context.Context, user User) (User, error)
 }
 
 type userRepository struct {
@@ -88,3 +89,40 @@ func (r *userRepository) UpdateLastLogin(ctx context.Context, id uint64, ts time
 
 	return nil
 }
+
+// Create 新建用户，邮箱重复时返回 ErrConflict。
+func (r *userRepository) Create(ctx context.Context, user User) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+	if user.Email == "" || user.PasswordHash == "" {
+		return User{}, ErrInvalidArgument
+	}
+
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&User{}).Where("LOWER(email) = ?", user.Email).Count(&count).Error; err != nil {
+		return User{}, err
+	}
+	if count > 0 {
+		return User{}, ErrConflict
+	}
+
+	now := time.Now().UTC()
+	user.ID = 0
+	if user.Status == "" {
+		user.Status = "active"
+	}
+	if len(user.Roles) == 0 {
+		user.Roles = []string{"user"}
+	}
+	user.CreatedAt = now
+	user.UpdatedAt = now
+
+	if err := r.db.WithContext(ctx).Create(&user).Error; err != nil {
+		return User{}, translateError(err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,43 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/zero-net-panel/zero-net-panel/internal/logic/billing"
+	"github.com/zero-net-panel/zero-net-panel/internal/svc"
+)
+
+// RegisterDefaultJobs 根据配置注册内建任务。
+func RegisterDefaultJobs(s *Scheduler, svcCtx *svc.ServiceContext) {
+	cfg := svcCtx.Config.Jobs
+
+	if cfg.AutoRenew.Enable {
+		s.Register(NewJob("auto-renew", func(ctx context.Context) error {
+			_, err := billing.NewAutoRenewLogic(ctx, svcCtx).Run(time.Now().UTC())
+			return err
+		}), cfg.AutoRenew.Interval)
+	}
+
+	if cfg.TrafficReset.Enable {
+		s.Register(NewJob("traffic-reset", func(ctx context.Context) error {
+			_, err := billing.NewTrafficResetLogic(ctx, svcCtx).Run(time.Now().UTC())
+			return err
+		}), cfg.TrafficReset.Interval)
+	}
+
+	if cfg.SubscriptionLifecycle.Enable {
+		billing.RegisterSubscriptionNotifications(svcCtx)
+		s.Register(NewJob("subscription-lifecycle", func(ctx context.Context) error {
+			_, err := billing.NewSubscriptionLifecycleLogic(ctx, svcCtx).Run(time.Now().UTC())
+			return err
+		}), cfg.SubscriptionLifecycle.Interval)
+	}
+
+	if cfg.AccessAudit.Enable {
+		s.Register(NewJob("access-audit", func(ctx context.Context) error {
+			_, err := billing.NewAccessAuditLogic(ctx, svcCtx).Run(time.Now().UTC())
+			return err
+		}), cfg.AccessAudit.Interval)
+	}
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+)
+
+// lockWait 抢占任务锁的最长等待时间，超时视为锁已被其他实例持有。
+const lockWait = time.Second
+
+// Job 描述周期执行的后台任务。
+type Job interface {
+	Name() string
+	Run(ctx context.Context) error
+}
+
+type funcJob struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func (j funcJob) Name() string { return j.name }
+
+func (j funcJob) Run(ctx context.Context) error { return j.fn(ctx) }
+
+// NewJob 将普通函数包装为 Job。
+func NewJob(name string, fn func(ctx context.Context) error) Job {
+	return funcJob{name: name, fn: fn}
+}
+
+type entry struct {
+	job      Job
+	interval time.Duration
+}
+
+// Scheduler 按固定间隔运行已注册任务；同一任务串行执行，上一轮未结束不会重入。
+// 配置了锁缓存时，每轮执行前先抢占以任务名为键的锁，多副本部署下同一周期只有一个实例执行。
+type Scheduler struct {
+	mu      sync.Mutex
+	entries []entry
+	locks   cache.Cache
+}
+
+// New 创建空调度器，locks 为空时不做跨实例互斥。
+func New(locks cache.Cache) *Scheduler {
+	return &Scheduler{locks: locks}
+}
+
+// Register 注册任务，interval 非正数时忽略。
+func (s *Scheduler) Register(job Job, interval time.Duration) {
+	if job == nil || interval <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry{job: job, interval: interval})
+}
+
+// Len 返回已注册任务数量。
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Run 启动全部任务并阻塞直到 ctx 取消。
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.mu.Lock()
+	entries := append([]entry(nil), s.entries...)
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func(e entry) {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}(e)
+	}
+
+	<-ctx.Done()
+	wg.Wait()
+	return nil
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.runLocked(ctx, e)
+		}
+	}
+}
+
+// runLocked 抢占任务锁后执行一轮。锁的有效期略短于执行间隔且执行后不主动释放，
+// 使同一周期内其他实例的触发全部跳过，持有者异常退出时锁也会在下个周期前过期。
+func (s *Scheduler) runLocked(ctx context.Context, e entry) {
+	if s.locks == nil {
+		runOnce(ctx, e.job)
+		return
+	}
+
+	acquireCtx, cancel := context.WithTimeout(ctx, lockWait)
+	_, err := s.locks.AcquireLock(acquireCtx, "scheduler:job:"+e.job.Name(), lockTTL(e.interval))
+	cancel()
+	if err != nil {
+		if ctx.Err() == nil {
+			logx.WithContext(ctx).Debugf("scheduler: job %s skipped, lock not acquired: %v", e.job.Name(), err)
+		}
+		return
+	}
+	runOnce(ctx, e.job)
+}
+
+func lockTTL(interval time.Duration) time.Duration {
+	ttl := interval - interval/10
+	if ttl < time.Second {
+		ttl = time.Second
+	}
+	return ttl
+}
+
+func runOnce(ctx context.Context, job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			logx.WithContext(ctx).Errorf("scheduler: job %s panic: %v", job.Name(), r)
+		}
+	}()
+
+	start := time.Now()
+	if err := job.Run(ctx); err != nil {
+		logx.WithContext(ctx).Errorf("scheduler: job %s failed after %s: %v", job.Name(), time.Since(start), err)
+		return
+	}
+	logx.WithContext(ctx).Debugf("scheduler: job %s finished in %s", job.Name(), time.Since(start))
+}
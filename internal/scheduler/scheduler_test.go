@@ -0,0 +1,37 @@
+package scheduler
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
+)
+
+func TestRunLockedSkipsWhileAnotherInstanceHoldsLock(t *testing.T) {
+	locks, err := cache.New(cache.Config{Provider: "memory"})
+	require.NoError(t, err)
+
+	var runs atomic.Int32
+	e := entry{
+		job: NewJob("lock-test", func(ctx context.Context) error {
+			runs.Add(1)
+			return nil
+		}),
+		interval: time.Hour,
+	}
+
+	// 两个副本共享同一锁存储，同一周期内只有先抢到锁的执行。
+	first, second := New(locks), New(locks)
+	ctx := context.Background()
+	first.runLocked(ctx, e)
+	second.runLocked(ctx, e)
+	require.EqualValues(t, 1, runs.Load())
+
+	// 未配置锁时每次触发都会执行。
+	New(nil).runLocked(ctx, e)
+	require.EqualValues(t, 2, runs.Load())
+}
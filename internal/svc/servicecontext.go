@@ -8,10 +8,12 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/zero-net-panel/zero-net-panel/internal/config"
+	"github.com/zero-net-panel/zero-net-panel/internal/events"
 	"github.com/zero-net-panel/zero-net-panel/internal/repository"
 	"github.com/zero-net-panel/zero-net-panel/pkg/auth"
 	"github.com/zero-net-panel/zero-net-panel/pkg/cache"
 	"github.com/zero-net-panel/zero-net-panel/pkg/database"
+	"github.com/zero-net-panel/zero-net-panel/pkg/geoip"
 	"github.com/zero-net-panel/zero-net-panel/pkg/kernel"
 )
 
@@ -22,6 +24,8 @@ type ServiceContext struct {
 	Repositories *repository.Repositories
 	Kernel       *kernel.Registry
 	Auth         *auth.Generator
+	Events       *events.Bus
+	GeoIP        *geoip.DB
 
 	Ctx    context.Context
 	cancel context.CancelFunc
@@ -32,6 +36,15 @@ type ServiceContext struct {
 func NewServiceContext(c config.Config) (*ServiceContext, error) {
 	c.Normalize()
 
+	var geoDB *geoip.DB
+	if c.GeoIP.File != "" {
+		loaded, err := geoip.Open(c.GeoIP.File)
+		if err != nil {
+			return nil, fmt.Errorf("init geoip: %w", err)
+		}
+		geoDB = loaded
+	}
+
 	db, dbClose, err := database.NewGorm(c.Database)
 	if err != nil {
 		return nil, fmt.Errorf("init database: %w", err)
@@ -92,6 +105,8 @@ func NewServiceContext(c config.Config) (*ServiceContext, error) {
 		Repositories: repos,
 		Kernel:       kernelRegistry,
 		Auth:         authGenerator,
+		Events:       events.NewBus(),
+		GeoIP:        geoDB,
 		Ctx:          ctx,
 		cancel:       cancel,
 	}
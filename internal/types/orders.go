@@ -56,14 +56,66 @@ type OrderDetail struct {
 
 // UserCreateOrderRequest 创建订单请求。
 type UserCreateOrderRequest struct {
+	OrderKind        string `json:"order_kind,omitempty"`
 	PlanID           uint64 `json:"plan_id"`
 	Quantity         int    `json:"quantity"`
+	SubscriptionID   uint64 `json:"subscription_id,omitempty"`
 	PaymentMethod    string `json:"payment_method,omitempty"`
 	PaymentChannel   string `json:"payment_channel,omitempty"`
 	PaymentReturnURL string `json:"payment_return_url,omitempty"`
+	Currency         string `json:"currency,omitempty"`
 	IdempotencyKey   string `json:"idempotency_key,omitempty"`
 }
 
+// UserOrderQuoteRequest 套餐购买/续费/变更报价请求。
+type UserOrderQuoteRequest struct {
+	OrderKind      string `json:"order_kind,omitempty"`
+	PlanID         uint64 `json:"plan_id"`
+	Quantity       int    `json:"quantity"`
+	SubscriptionID uint64 `json:"subscription_id,omitempty"`
+	PaymentMethod  string `json:"payment_method,omitempty"`
+	Currency       string `json:"currency,omitempty"`
+}
+
+// OrderPricing 套餐单价的币种换算依据。
+type OrderPricing struct {
+	Source         string  `json:"source"`
+	BaseCurrency   string  `json:"base_currency"`
+	BasePriceCents int64   `json:"base_price_cents"`
+	UnitPriceCents int64   `json:"unit_price_cents"`
+	Rate           float64 `json:"rate"`
+}
+
+// OrderProration 当前订阅剩余价值折算明细。
+type OrderProration struct {
+	SubscriptionID     uint64 `json:"subscription_id"`
+	CurrentPlanID      uint64 `json:"current_plan_id"`
+	RemainingSeconds   int64  `json:"remaining_seconds"`
+	UnusedTrafficBytes int64  `json:"unused_traffic_bytes"`
+	TimeCreditCents    int64  `json:"time_credit_cents"`
+	TrafficCreditCents int64  `json:"traffic_credit_cents"`
+	CreditCents        int64  `json:"credit_cents"`
+	AppliedCents       int64  `json:"applied_cents"`
+	ForfeitedCents     int64  `json:"forfeited_cents"`
+}
+
+// UserOrderQuoteResponse 套餐报价响应。
+type UserOrderQuoteResponse struct {
+	Kind           string          `json:"kind"`
+	PlanID         uint64          `json:"plan_id"`
+	PlanName       string          `json:"plan_name"`
+	SubscriptionID uint64          `json:"subscription_id,omitempty"`
+	Quantity       int             `json:"quantity"`
+	Currency       string          `json:"currency"`
+	Pricing        OrderPricing    `json:"pricing"`
+	SubtotalCents  int64           `json:"subtotal_cents"`
+	CreditCents    int64           `json:"credit_cents"`
+	TotalCents     int64           `json:"total_cents"`
+	ExpiresAt      int64           `json:"expires_at"`
+	Proration      *OrderProration `json:"proration,omitempty"`
+	TrafficBytes   int64           `json:"traffic_bytes,omitempty"`
+}
+
 // UserOrderListRequest 用户订单列表查询参数。
 type UserOrderListRequest struct {
 	Page          int    `form:"page"`
@@ -23,6 +23,14 @@ type AuthLoginRequest struct {
 	Password string `json:"password"`
 }
 
+// AuthRegisterRequest 自助注册请求，invite_code 为邀请人的邀请码。
+type AuthRegisterRequest struct {
+	Email       string `json:"email"`
+	Password    string `json:"password"`
+	DisplayName string `json:"display_name,omitempty"`
+	InviteCode  string `json:"invite_code,omitempty"`
+}
+
 // AuthRefreshRequest 刷新令牌请求。
 type AuthRefreshRequest struct {
 	RefreshToken string `json:"refresh_token"`
@@ -95,9 +103,12 @@ type NodeSummary struct {
 	Tags         []string `json:"tags"`
 	Protocols    []string `json:"protocols"`
 	CapacityMbps int      `json:"capacity_mbps"`
+	SortOrder    int      `json:"sort_order"`
 	Description  string   `json:"description"`
 	LastSyncedAt int64    `json:"last_synced_at"`
 	UpdatedAt    int64    `json:"updated_at"`
+	// AgentTokenRotatedAt 回调凭据最近签发时间，0 表示尚未签发。
+	AgentTokenRotatedAt int64 `json:"agent_token_rotated_at"`
 }
 
 // AdminNodeListResponse 节点列表响应。
@@ -106,6 +117,107 @@ type AdminNodeListResponse struct {
 	Pagination PaginationMeta `json:"pagination"`
 }
 
+// AdminUpdateNodeRequest 更新节点排序与状态，未提供的字段保持不变。
+type AdminUpdateNodeRequest struct {
+	NodeID    uint64  `path:"id"`
+	SortOrder *int    `json:"sort_order"`
+	Status    *string `json:"status"`
+}
+
+// NodeGroupSummary 节点分组；resolved_node_ids 为静态成员与标签匹配节点的并集。
+type NodeGroupSummary struct {
+	ID              uint64   `json:"id"`
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	NodeIDs         []uint64 `json:"node_ids"`
+	MatchTags       []string `json:"match_tags"`
+	ResolvedNodeIDs []uint64 `json:"resolved_node_ids"`
+	PlanIDs         []uint64 `json:"plan_ids"`
+	CreatedAt       int64    `json:"created_at"`
+	UpdatedAt       int64    `json:"updated_at"`
+}
+
+// AdminNodeGroupListResponse 节点分组列表。
+type AdminNodeGroupListResponse struct {
+	Groups []NodeGroupSummary `json:"groups"`
+}
+
+// AdminCreateNodeGroupRequest 创建节点分组。
+type AdminCreateNodeGroupRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	NodeIDs     []uint64 `json:"node_ids"`
+	MatchTags   []string `json:"match_tags"`
+}
+
+// AdminUpdateNodeGroupRequest 更新节点分组，未提供的字段保持不变。
+type AdminUpdateNodeGroupRequest struct {
+	GroupID     uint64   `path:"id"`
+	Name        *string  `json:"name"`
+	Description *string  `json:"description"`
+	NodeIDs     []uint64 `json:"node_ids"`
+	MatchTags   []string `json:"match_tags"`
+}
+
+// AdminDeleteNodeGroupRequest 删除节点分组。
+type AdminDeleteNodeGroupRequest struct {
+	GroupID uint64 `path:"id"`
+}
+
+// ClientTypeRuleSummary User-Agent 路由规则，pattern 为不区分大小写的正则。
+type ClientTypeRuleSummary struct {
+	ID          uint64 `json:"id"`
+	Pattern     string `json:"pattern"`
+	ClientType  string `json:"client_type"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+	Enabled     bool   `json:"enabled"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// AdminClientTypeRuleListResponse 路由规则列表，按匹配顺序排列。
+type AdminClientTypeRuleListResponse struct {
+	Rules             []ClientTypeRuleSummary `json:"rules"`
+	DefaultClientType string                  `json:"default_client_type"`
+}
+
+// AdminCreateClientTypeRuleRequest 创建路由规则，enabled 省略时默认启用。
+type AdminCreateClientTypeRuleRequest struct {
+	Pattern     string `json:"pattern"`
+	ClientType  string `json:"client_type"`
+	Description string `json:"description"`
+	Priority    int    `json:"priority"`
+	Enabled     *bool  `json:"enabled"`
+}
+
+// AdminUpdateClientTypeRuleRequest 更新路由规则，未提供的字段保持不变。
+type AdminUpdateClientTypeRuleRequest struct {
+	RuleID      uint64  `path:"id"`
+	Pattern     *string `json:"pattern"`
+	ClientType  *string `json:"client_type"`
+	Description *string `json:"description"`
+	Priority    *int    `json:"priority"`
+	Enabled     *bool   `json:"enabled"`
+}
+
+// AdminDeleteClientTypeRuleRequest 删除路由规则。
+type AdminDeleteClientTypeRuleRequest struct {
+	RuleID uint64 `path:"id"`
+}
+
+// AdminTestClientTypeRuleRequest 测试 User-Agent 的识别结果。
+type AdminTestClientTypeRuleRequest struct {
+	UserAgent string `json:"user_agent"`
+}
+
+// AdminTestClientTypeRuleResponse User-Agent 识别结果，rule_id 为 0 表示由内置规则识别。
+type AdminTestClientTypeRuleResponse struct {
+	ClientType        string `json:"client_type"`
+	RuleID            uint64 `json:"rule_id"`
+	DefaultTemplateID uint64 `json:"default_template_id"`
+}
+
 // AdminNodeKernelsRequest 请求节点协议配置。
 type AdminNodeKernelsRequest struct {
 	NodeID uint64 `path:"id"`
@@ -127,6 +239,18 @@ type AdminNodeKernelResponse struct {
 	Kernels []NodeKernelSummary `json:"kernels"`
 }
 
+// AdminRotateNodeTokenRequest 为节点签发新的回调凭据。
+type AdminRotateNodeTokenRequest struct {
+	NodeID uint64 `path:"id"`
+}
+
+// AdminRotateNodeTokenResponse 返回新凭据明文，仅此一次可见。
+type AdminRotateNodeTokenResponse struct {
+	NodeID    uint64 `json:"node_id"`
+	Token     string `json:"token"`
+	RotatedAt int64  `json:"rotated_at"`
+}
+
 // AdminSyncNodeKernelRequest 触发节点同步请求。
 type AdminSyncNodeKernelRequest struct {
 	NodeID   uint64 `path:"id"`
@@ -219,6 +343,71 @@ type AdminPublishSubscriptionTemplateResponse struct {
 	History  SubscriptionTemplateHistoryEntry `json:"history"`
 }
 
+// AdminRenderSubscriptionTemplateRequest 试渲染模板：version 为 0 时渲染草稿，subscription_id 为 0 时使用示例数据。
+type AdminRenderSubscriptionTemplateRequest struct {
+	TemplateID     uint64 `path:"id"`
+	Version        uint32 `json:"version"`
+	SubscriptionID uint64 `json:"subscription_id"`
+}
+
+// TemplateRenderError 试渲染失败详情，行列号从模板错误中解析，无法定位时为 0。
+type TemplateRenderError struct {
+	Message    string   `json:"message"`
+	Line       int      `json:"line"`
+	Column     int      `json:"column"`
+	Undeclared []string `json:"undeclared,omitempty"`
+	Missing    []string `json:"missing,omitempty"`
+	Invalid    []string `json:"invalid,omitempty"`
+}
+
+// AdminRenderSubscriptionTemplateResponse 试渲染结果。
+type AdminRenderSubscriptionTemplateResponse struct {
+	TemplateID     uint64               `json:"template_id"`
+	Version        uint32               `json:"version"`
+	Source         string               `json:"source"`
+	SubscriptionID uint64               `json:"subscription_id"`
+	Success        bool                 `json:"success"`
+	Content        string               `json:"content"`
+	ContentType    string               `json:"content_type"`
+	SizeBytes      int                  `json:"size_bytes"`
+	DurationMs     int64                `json:"duration_ms"`
+	Error          *TemplateRenderError `json:"error,omitempty"`
+}
+
+// AdminRollbackSubscriptionTemplateRequest 将模板回滚到历史版本并发布为新版本。
+type AdminRollbackSubscriptionTemplateRequest struct {
+	TemplateID uint64 `path:"id"`
+	Version    uint32 `json:"version"`
+	Changelog  string `json:"changelog"`
+	Operator   string `json:"operator"`
+}
+
+// AdminSubscriptionTemplateDiffRequest 对比两个模板版本，版本号 0 表示当前草稿。
+type AdminSubscriptionTemplateDiffRequest struct {
+	TemplateID uint64 `path:"id"`
+	From       uint32 `form:"from"`
+	To         uint32 `form:"to"`
+}
+
+// TemplateVariableChange 模板变量差异，change 为 added、removed 或 changed。
+type TemplateVariableChange struct {
+	Name   string            `json:"name"`
+	Change string            `json:"change"`
+	Before *TemplateVariable `json:"before,omitempty"`
+	After  *TemplateVariable `json:"after,omitempty"`
+}
+
+// AdminSubscriptionTemplateDiffResponse 模板版本差异。
+type AdminSubscriptionTemplateDiffResponse struct {
+	TemplateID  uint64                   `json:"template_id"`
+	From        uint32                   `json:"from"`
+	To          uint32                   `json:"to"`
+	FromFormat  string                   `json:"from_format"`
+	ToFormat    string                   `json:"to_format"`
+	ContentDiff string                   `json:"content_diff"`
+	Variables   []TemplateVariableChange `json:"variables"`
+}
+
 // SubscriptionTemplateHistoryEntry 模板历史条目。
 type SubscriptionTemplateHistoryEntry struct {
 	Version     uint32                      `json:"version"`
@@ -239,6 +428,43 @@ type AdminSubscriptionTemplateHistoryResponse struct {
 	History    []SubscriptionTemplateHistoryEntry `json:"history"`
 }
 
+// AdminExportSubscriptionTemplatesRequest 导出模板包。
+type AdminExportSubscriptionTemplatesRequest struct {
+	Format         string `form:"format"`
+	ClientType     string `form:"client_type"`
+	IncludeHistory bool   `form:"include_history"`
+}
+
+// AdminImportSubscriptionTemplatesRequest 导入模板包。
+type AdminImportSubscriptionTemplatesRequest struct {
+	Format   string `json:"format,omitempty"`
+	Content  string `json:"content"`
+	DryRun   bool   `json:"dry_run,omitempty"`
+	Publish  bool   `json:"publish,omitempty"`
+	Operator string `json:"operator,omitempty"`
+}
+
+// SubscriptionTemplateImportResult 单个模板的导入结果，action 为 create、update 或 unchanged。
+type SubscriptionTemplateImportResult struct {
+	ClientType      string                   `json:"client_type"`
+	Name            string                   `json:"name"`
+	TemplateID      uint64                   `json:"template_id,omitempty"`
+	Action          string                   `json:"action"`
+	Published       bool                     `json:"published"`
+	HistoryImported int                      `json:"history_imported"`
+	ContentDiff     string                   `json:"content_diff"`
+	Variables       []TemplateVariableChange `json:"variables"`
+}
+
+// AdminImportSubscriptionTemplatesResponse 模板包导入结果。
+type AdminImportSubscriptionTemplatesResponse struct {
+	DryRun    bool                               `json:"dry_run"`
+	Created   int                                `json:"created"`
+	Updated   int                                `json:"updated"`
+	Unchanged int                                `json:"unchanged"`
+	Results   []SubscriptionTemplateImportResult `json:"results"`
+}
+
 // UserListSubscriptionsRequest 用户订阅列表查询。
 type UserListSubscriptionsRequest struct {
 	Page      int    `form:"page"`
@@ -255,12 +481,20 @@ type UserSubscriptionSummary struct {
 	Name                 string   `json:"name"`
 	PlanName             string   `json:"plan_name"`
 	Status               string   `json:"status"`
+	StatusReason         string   `json:"status_reason,omitempty"`
+	StatusChangedAt      *int64   `json:"status_changed_at,omitempty"`
 	TemplateID           uint64   `json:"template_id"`
 	AvailableTemplateIDs []uint64 `json:"available_template_ids"`
 	ExpiresAt            int64    `json:"expires_at"`
 	TrafficTotalBytes    int64    `json:"traffic_total_bytes"`
 	TrafficUsedBytes     int64    `json:"traffic_used_bytes"`
+	TrafficExtraBytes    int64    `json:"traffic_extra_bytes"`
+	TrafficResetPolicy   string   `json:"traffic_reset_policy"`
+	TrafficNextResetAt   *int64   `json:"traffic_next_reset_at,omitempty"`
 	DevicesLimit         int      `json:"devices_limit"`
+	AutoRenew            bool     `json:"auto_renew"`
+	AutoRenewFailures    int      `json:"auto_renew_failures"`
+	AutoRenewLastError   string   `json:"auto_renew_last_error,omitempty"`
 	LastRefreshedAt      int64    `json:"last_refreshed_at"`
 }
 
@@ -299,6 +533,160 @@ type UserUpdateSubscriptionTemplateResponse struct {
 	UpdatedAt      int64  `json:"updated_at"`
 }
 
+// UserUpdateSubscriptionAutoRenewRequest 开关订阅自动续费。
+type UserUpdateSubscriptionAutoRenewRequest struct {
+	SubscriptionID uint64 `path:"id"`
+	Enabled        bool   `json:"enabled"`
+}
+
+// UserUpdateSubscriptionAutoRenewResponse 自动续费开关结果。
+type UserUpdateSubscriptionAutoRenewResponse struct {
+	SubscriptionID uint64 `json:"subscription_id"`
+	AutoRenew      bool   `json:"auto_renew"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+// UserSubscriptionTrafficPeriodsRequest 订阅历史流量周期查询。
+type UserSubscriptionTrafficPeriodsRequest struct {
+	SubscriptionID uint64 `path:"id"`
+	Limit          int    `form:"limit"`
+}
+
+// SubscriptionTrafficPeriod 已归档的流量周期。
+type SubscriptionTrafficPeriod struct {
+	PeriodStart int64 `json:"period_start"`
+	PeriodEnd   int64 `json:"period_end"`
+	UsedBytes   int64 `json:"used_bytes"`
+	TotalBytes  int64 `json:"total_bytes"`
+	ExtraBytes  int64 `json:"extra_bytes"`
+}
+
+// UserSubscriptionTrafficPeriodsResponse 订阅流量周期列表。
+type UserSubscriptionTrafficPeriodsResponse struct {
+	SubscriptionID     uint64                      `json:"subscription_id"`
+	TrafficResetPolicy string                      `json:"traffic_reset_policy"`
+	CurrentPeriodStart int64                       `json:"current_period_start"`
+	NextResetAt        *int64                      `json:"next_reset_at,omitempty"`
+	Periods            []SubscriptionTrafficPeriod `json:"periods"`
+}
+
+// UserSubscriptionDevicesRequest 查询订阅在线设备。
+type UserSubscriptionDevicesRequest struct {
+	SubscriptionID uint64 `path:"id"`
+}
+
+// OnlineDevice 在线窗口内出现过的客户端 IP。
+type OnlineDevice struct {
+	IP         string   `json:"ip"`
+	NodeIDs    []uint64 `json:"node_ids"`
+	LastSeenAt int64    `json:"last_seen_at"`
+}
+
+// UserSubscriptionDevicesResponse 订阅在线设备。
+type UserSubscriptionDevicesResponse struct {
+	SubscriptionID uint64         `json:"subscription_id"`
+	DevicesLimit   int            `json:"devices_limit"`
+	OnlineCount    int            `json:"online_count"`
+	OverLimit      bool           `json:"over_limit"`
+	Devices        []OnlineDevice `json:"devices"`
+}
+
+// UserResetSubscriptionTokenRequest 重置订阅主 token。
+type UserResetSubscriptionTokenRequest struct {
+	SubscriptionID uint64 `path:"id"`
+}
+
+// UserResetSubscriptionTokenResponse 重置后的订阅 token。
+type UserResetSubscriptionTokenResponse struct {
+	SubscriptionID uint64 `json:"subscription_id"`
+	Token          string `json:"token"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+// UserResetSubscriptionProxyUUIDRequest 轮换订阅的代理凭据。
+type UserResetSubscriptionProxyUUIDRequest struct {
+	SubscriptionID uint64 `path:"id"`
+}
+
+// UserResetSubscriptionProxyUUIDResponse 轮换后的代理凭据。
+type UserResetSubscriptionProxyUUIDResponse struct {
+	SubscriptionID uint64 `json:"subscription_id"`
+	ProxyUUID      string `json:"proxy_uuid"`
+	UpdatedAt      int64  `json:"updated_at"`
+}
+
+// UserSubscriptionTokensRequest 查询订阅的命名访问链接。
+type UserSubscriptionTokensRequest struct {
+	SubscriptionID uint64 `path:"id"`
+}
+
+// SubscriptionAccessToken 订阅命名访问链接及其最近访问记录。
+type SubscriptionAccessToken struct {
+	ID            uint64   `json:"id"`
+	Name          string   `json:"name"`
+	Token         string   `json:"token"`
+	AccessCount   int64    `json:"access_count"`
+	LastAccessAt  *int64   `json:"last_access_at,omitempty"`
+	LastIP        string   `json:"last_ip,omitempty"`
+	LastUserAgent string   `json:"last_user_agent,omitempty"`
+	RecentIPs     []string `json:"recent_ips"`
+	CreatedAt     int64    `json:"created_at"`
+}
+
+// UserSubscriptionTokensResponse 订阅命名访问链接列表。
+type UserSubscriptionTokensResponse struct {
+	SubscriptionID uint64                    `json:"subscription_id"`
+	Tokens         []SubscriptionAccessToken `json:"tokens"`
+}
+
+// UserCreateSubscriptionTokenRequest 创建命名访问链接。
+type UserCreateSubscriptionTokenRequest struct {
+	SubscriptionID uint64 `path:"id"`
+	Name           string `json:"name"`
+}
+
+// UserRevokeSubscriptionTokenRequest 吊销命名访问链接。
+type UserRevokeSubscriptionTokenRequest struct {
+	SubscriptionID uint64 `path:"id"`
+	TokenID        uint64 `path:"token_id"`
+}
+
+// SubscribeRequest 通过 token 拉取订阅内容。
+type SubscribeRequest struct {
+	Token      string `path:"token"`
+	TemplateID uint64 `form:"template_id,optional"`
+}
+
+// UserListNotificationsRequest 用户通知列表查询。
+type UserListNotificationsRequest struct {
+	Page       int    `form:"page"`
+	PerPage    int    `form:"per_page"`
+	Category   string `form:"category"`
+	UnreadOnly bool   `form:"unread_only"`
+}
+
+// UserNotification 站内通知。
+type UserNotification struct {
+	ID        uint64         `json:"id"`
+	Category  string         `json:"category"`
+	Title     string         `json:"title"`
+	Content   string         `json:"content"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+	ReadAt    *int64         `json:"read_at,omitempty"`
+	CreatedAt int64          `json:"created_at"`
+}
+
+// UserNotificationListResponse 用户通知列表。
+type UserNotificationListResponse struct {
+	Notifications []UserNotification `json:"notifications"`
+	Pagination    PaginationMeta     `json:"pagination"`
+}
+
+// UserReadNotificationRequest 标记通知已读。
+type UserReadNotificationRequest struct {
+	NotificationID uint64 `path:"id"`
+}
+
 // AdminListPlansRequest 管理端套餐列表请求参数。
 type AdminListPlansRequest struct {
 	Page      int    `form:"page"`
@@ -310,59 +698,83 @@ type AdminListPlansRequest struct {
 	Visible   *bool  `form:"visible"`
 }
 
+// PlanPrice 套餐在其他币种下的固定售价。
+type PlanPrice struct {
+	Currency   string `json:"currency"`
+	PriceCents int64  `json:"price_cents"`
+}
+
 // AdminCreatePlanRequest 管理端创建套餐请求。
 type AdminCreatePlanRequest struct {
-	Name              string   `json:"name"`
-	Slug              string   `json:"slug"`
-	Description       string   `json:"description"`
-	Tags              []string `json:"tags"`
-	Features          []string `json:"features"`
-	PriceCents        int64    `json:"price_cents"`
-	Currency          string   `json:"currency"`
-	DurationDays      int      `json:"duration_days"`
-	TrafficLimitBytes int64    `json:"traffic_limit_bytes"`
-	DevicesLimit      int      `json:"devices_limit"`
-	SortOrder         int      `json:"sort_order"`
-	Status            string   `json:"status"`
-	Visible           bool     `json:"visible"`
+	Name                   string      `json:"name"`
+	Slug                   string      `json:"slug"`
+	Description            string      `json:"description"`
+	Tags                   []string    `json:"tags"`
+	Features               []string    `json:"features"`
+	PriceCents             int64       `json:"price_cents"`
+	Currency               string      `json:"currency"`
+	DurationDays           int         `json:"duration_days"`
+	TrafficLimitBytes      int64       `json:"traffic_limit_bytes"`
+	DevicesLimit           int         `json:"devices_limit"`
+	TrafficResetPolicy     string      `json:"traffic_reset_policy"`
+	TrafficAddonBytes      int64       `json:"traffic_addon_bytes"`
+	TrafficAddonPriceCents int64       `json:"traffic_addon_price_cents"`
+	SortOrder              int         `json:"sort_order"`
+	Status                 string      `json:"status"`
+	Visible                bool        `json:"visible"`
+	Prices                 []PlanPrice `json:"prices"`
+	NodeIDs                []uint64    `json:"node_ids"`
+	NodeGroupIDs           []uint64    `json:"node_group_ids"`
 }
 
 // AdminUpdatePlanRequest 管理端更新套餐请求。
 type AdminUpdatePlanRequest struct {
-	PlanID            uint64   `path:"id"`
-	Name              *string  `json:"name"`
-	Slug              *string  `json:"slug"`
-	Description       *string  `json:"description"`
-	Tags              []string `json:"tags"`
-	Features          []string `json:"features"`
-	PriceCents        *int64   `json:"price_cents"`
-	Currency          *string  `json:"currency"`
-	DurationDays      *int     `json:"duration_days"`
-	TrafficLimitBytes *int64   `json:"traffic_limit_bytes"`
-	DevicesLimit      *int     `json:"devices_limit"`
-	SortOrder         *int     `json:"sort_order"`
-	Status            *string  `json:"status"`
-	Visible           *bool    `json:"visible"`
+	PlanID                 uint64      `path:"id"`
+	Name                   *string     `json:"name"`
+	Slug                   *string     `json:"slug"`
+	Description            *string     `json:"description"`
+	Tags                   []string    `json:"tags"`
+	Features               []string    `json:"features"`
+	PriceCents             *int64      `json:"price_cents"`
+	Currency               *string     `json:"currency"`
+	DurationDays           *int        `json:"duration_days"`
+	TrafficLimitBytes      *int64      `json:"traffic_limit_bytes"`
+	DevicesLimit           *int        `json:"devices_limit"`
+	TrafficResetPolicy     *string     `json:"traffic_reset_policy"`
+	TrafficAddonBytes      *int64      `json:"traffic_addon_bytes"`
+	TrafficAddonPriceCents *int64      `json:"traffic_addon_price_cents"`
+	SortOrder              *int        `json:"sort_order"`
+	Status                 *string     `json:"status"`
+	Visible                *bool       `json:"visible"`
+	Prices                 []PlanPrice `json:"prices"`
+	NodeIDs                []uint64    `json:"node_ids"`
+	NodeGroupIDs           []uint64    `json:"node_group_ids"`
 }
 
 // PlanSummary 套餐概览。
 type PlanSummary struct {
-	ID                uint64   `json:"id"`
-	Name              string   `json:"name"`
-	Slug              string   `json:"slug"`
-	Description       string   `json:"description"`
-	Tags              []string `json:"tags"`
-	Features          []string `json:"features"`
-	PriceCents        int64    `json:"price_cents"`
-	Currency          string   `json:"currency"`
-	DurationDays      int      `json:"duration_days"`
-	TrafficLimitBytes int64    `json:"traffic_limit_bytes"`
-	DevicesLimit      int      `json:"devices_limit"`
-	SortOrder         int      `json:"sort_order"`
-	Status            string   `json:"status"`
-	Visible           bool     `json:"visible"`
-	CreatedAt         int64    `json:"created_at"`
-	UpdatedAt         int64    `json:"updated_at"`
+	ID                     uint64      `json:"id"`
+	Name                   string      `json:"name"`
+	Slug                   string      `json:"slug"`
+	Description            string      `json:"description"`
+	Tags                   []string    `json:"tags"`
+	Features               []string    `json:"features"`
+	PriceCents             int64       `json:"price_cents"`
+	Currency               string      `json:"currency"`
+	DurationDays           int         `json:"duration_days"`
+	TrafficLimitBytes      int64       `json:"traffic_limit_bytes"`
+	DevicesLimit           int         `json:"devices_limit"`
+	TrafficResetPolicy     string      `json:"traffic_reset_policy"`
+	TrafficAddonBytes      int64       `json:"traffic_addon_bytes"`
+	TrafficAddonPriceCents int64       `json:"traffic_addon_price_cents"`
+	SortOrder              int         `json:"sort_order"`
+	Status                 string      `json:"status"`
+	Visible                bool        `json:"visible"`
+	Prices                 []PlanPrice `json:"prices"`
+	NodeIDs                []uint64    `json:"node_ids"`
+	NodeGroupIDs           []uint64    `json:"node_group_ids"`
+	CreatedAt              int64       `json:"created_at"`
+	UpdatedAt              int64       `json:"updated_at"`
 }
 
 // AdminPlanListResponse 管理端套餐列表响应。
@@ -460,16 +872,20 @@ type UserPlanListRequest struct {
 
 // UserPlanSummary 用户侧套餐信息。
 type UserPlanSummary struct {
-	ID                uint64   `json:"id"`
-	Name              string   `json:"name"`
-	Description       string   `json:"description"`
-	Features          []string `json:"features"`
-	PriceCents        int64    `json:"price_cents"`
-	Currency          string   `json:"currency"`
-	DurationDays      int      `json:"duration_days"`
-	TrafficLimitBytes int64    `json:"traffic_limit_bytes"`
-	DevicesLimit      int      `json:"devices_limit"`
-	Tags              []string `json:"tags"`
+	ID                     uint64      `json:"id"`
+	Name                   string      `json:"name"`
+	Description            string      `json:"description"`
+	Features               []string    `json:"features"`
+	PriceCents             int64       `json:"price_cents"`
+	Currency               string      `json:"currency"`
+	DurationDays           int         `json:"duration_days"`
+	TrafficLimitBytes      int64       `json:"traffic_limit_bytes"`
+	DevicesLimit           int         `json:"devices_limit"`
+	TrafficResetPolicy     string      `json:"traffic_reset_policy"`
+	TrafficAddonBytes      int64       `json:"traffic_addon_bytes"`
+	TrafficAddonPriceCents int64       `json:"traffic_addon_price_cents"`
+	Tags                   []string    `json:"tags"`
+	Prices                 []PlanPrice `json:"prices"`
 }
 
 // UserPlanListResponse 用户套餐列表。
@@ -539,3 +955,551 @@ type BalanceSnapshot struct {
 	Currency     string `json:"currency"`
 	UpdatedAt    int64  `json:"updated_at"`
 }
+
+// ExchangeRateSummary 汇率信息，rate 表示 1 单位 base_currency 兑换的 quote_currency 数量。
+type ExchangeRateSummary struct {
+	ID            uint64  `json:"id"`
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+	Source        string  `json:"source"`
+	UpdatedAt     int64   `json:"updated_at"`
+}
+
+// AdminListExchangeRatesRequest 汇率列表过滤。
+type AdminListExchangeRatesRequest struct {
+	BaseCurrency  string `form:"base_currency"`
+	QuoteCurrency string `form:"quote_currency"`
+}
+
+// AdminExchangeRateListResponse 汇率列表。
+type AdminExchangeRateListResponse struct {
+	Rates []ExchangeRateSummary `json:"rates"`
+}
+
+// ExchangeRateInput 单条汇率。
+type ExchangeRateInput struct {
+	BaseCurrency  string  `json:"base_currency"`
+	QuoteCurrency string  `json:"quote_currency"`
+	Rate          float64 `json:"rate"`
+}
+
+// AdminUpsertExchangeRatesRequest 批量新增或更新汇率。
+type AdminUpsertExchangeRatesRequest struct {
+	Rates  []ExchangeRateInput `json:"rates"`
+	Source string              `json:"source,omitempty"`
+}
+
+// AdminImportExchangeRatesRequest 导入汇率，format 支持 csv（base,quote,rate）与 json。
+type AdminImportExchangeRatesRequest struct {
+	Format  string `json:"format"`
+	Content string `json:"content"`
+	Source  string `json:"source,omitempty"`
+}
+
+// BillingProfile 用户开票信息。
+type BillingProfile struct {
+	Name        string `json:"name"`
+	CompanyName string `json:"company_name"`
+	TaxID       string `json:"tax_id"`
+	Email       string `json:"email"`
+	Phone       string `json:"phone"`
+	Address     string `json:"address"`
+	Country     string `json:"country"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// UserUpdateBillingProfileRequest 更新开票信息，未传字段保持不变。
+type UserUpdateBillingProfileRequest struct {
+	Name        *string `json:"name"`
+	CompanyName *string `json:"company_name"`
+	TaxID       *string `json:"tax_id"`
+	Email       *string `json:"email"`
+	Phone       *string `json:"phone"`
+	Address     *string `json:"address"`
+	Country     *string `json:"country"`
+}
+
+// InvoiceLine 发票明细行。
+type InvoiceLine struct {
+	Name           string `json:"name"`
+	Quantity       int    `json:"quantity"`
+	UnitPriceCents int64  `json:"unit_price_cents"`
+	AmountCents    int64  `json:"amount_cents"`
+}
+
+// InvoiceDetail 发票或红字发票。
+type InvoiceDetail struct {
+	ID               uint64         `json:"id"`
+	Number           string         `json:"number"`
+	Kind             string         `json:"kind"`
+	UserID           uint64         `json:"user_id"`
+	OrderID          uint64         `json:"order_id"`
+	OrderNumber      string         `json:"order_number"`
+	RefundID         *uint64        `json:"refund_id,omitempty"`
+	RelatedInvoiceID *uint64        `json:"related_invoice_id,omitempty"`
+	Currency         string         `json:"currency"`
+	SubtotalCents    int64          `json:"subtotal_cents"`
+	TotalCents       int64          `json:"total_cents"`
+	Seller           map[string]any `json:"seller"`
+	Buyer            map[string]any `json:"buyer"`
+	Lines            []InvoiceLine  `json:"lines"`
+	Note             string         `json:"note,omitempty"`
+	IssuedAt         int64          `json:"issued_at"`
+}
+
+// UserListInvoicesRequest 用户发票列表。
+type UserListInvoicesRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	Kind    string `form:"kind"`
+}
+
+// UserGetInvoiceRequest 获取发票详情。
+type UserGetInvoiceRequest struct {
+	InvoiceID uint64 `path:"id"`
+}
+
+// InvoiceListResponse 发票列表。
+type InvoiceListResponse struct {
+	Invoices   []InvoiceDetail `json:"invoices"`
+	Pagination PaginationMeta  `json:"pagination"`
+}
+
+// DownloadInvoiceRequest 下载发票，format 为 html 或 pdf。
+type DownloadInvoiceRequest struct {
+	InvoiceID uint64 `path:"id"`
+	Format    string `form:"format"`
+}
+
+// AdminListInvoicesRequest 管理端发票列表。
+type AdminListInvoicesRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	Kind    string `form:"kind"`
+	UserID  uint64 `form:"user_id"`
+	OrderID uint64 `form:"order_id"`
+	Number  string `form:"number"`
+}
+
+// ReconciliationRunSummary 对账执行摘要。
+type ReconciliationRunSummary struct {
+	ID             uint64         `json:"id"`
+	Provider       string         `json:"provider"`
+	Source         string         `json:"source"`
+	PeriodStart    *int64         `json:"period_start,omitempty"`
+	PeriodEnd      *int64         `json:"period_end,omitempty"`
+	SettlementRows int            `json:"settlement_rows"`
+	MatchedCount   int            `json:"matched_count"`
+	IssueCount     int            `json:"issue_count"`
+	Summary        map[string]int `json:"summary"`
+	CreatedBy      string         `json:"created_by"`
+	CreatedAt      int64          `json:"created_at"`
+}
+
+// ReconciliationIssueSummary 对账差异明细。
+type ReconciliationIssueSummary struct {
+	ID               uint64 `json:"id"`
+	Kind             string `json:"kind"`
+	OrderID          uint64 `json:"order_id,omitempty"`
+	PaymentID        uint64 `json:"payment_id,omitempty"`
+	IntentID         string `json:"intent_id,omitempty"`
+	Reference        string `json:"reference,omitempty"`
+	LocalStatus      string `json:"local_status,omitempty"`
+	SettlementStatus string `json:"settlement_status,omitempty"`
+	LocalCents       int64  `json:"local_cents"`
+	SettledCents     int64  `json:"settled_cents"`
+	LocalCurrency    string `json:"local_currency,omitempty"`
+	SettledCurrency  string `json:"settled_currency,omitempty"`
+	SettledAt        *int64 `json:"settled_at,omitempty"`
+	Detail           string `json:"detail"`
+}
+
+// AdminReconcileRequest 上传结算文件执行对账。
+type AdminReconcileRequest struct {
+	Provider    string `json:"provider"`
+	Format      string `json:"format,omitempty"`
+	Content     string `json:"content"`
+	PeriodStart *int64 `json:"period_start,omitempty"`
+	PeriodEnd   *int64 `json:"period_end,omitempty"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+}
+
+// AdminReconciliationResponse 对账结果。
+type AdminReconciliationResponse struct {
+	Run    ReconciliationRunSummary     `json:"run"`
+	Issues []ReconciliationIssueSummary `json:"issues"`
+}
+
+// AdminListReconciliationsRequest 对账记录列表。
+type AdminListReconciliationsRequest struct {
+	Page     int    `form:"page"`
+	PerPage  int    `form:"per_page"`
+	Provider string `form:"provider"`
+}
+
+// AdminReconciliationListResponse 对账记录列表。
+type AdminReconciliationListResponse struct {
+	Runs       []ReconciliationRunSummary `json:"runs"`
+	Pagination PaginationMeta             `json:"pagination"`
+}
+
+// AdminGetReconciliationRequest 获取对账详情。
+type AdminGetReconciliationRequest struct {
+	RunID uint64 `path:"id"`
+}
+
+// AffiliateSummary 推广概览。
+type AffiliateSummary struct {
+	InviteCode        string  `json:"invite_code"`
+	ReferralCount     int64   `json:"referral_count"`
+	BalanceCents      int64   `json:"balance_cents"`
+	TotalEarnedCents  int64   `json:"total_earned_cents"`
+	Currency          string  `json:"currency"`
+	CommissionPercent float64 `json:"commission_percent"`
+	MinWithdrawCents  int64   `json:"min_withdraw_cents"`
+}
+
+// UserAffiliateRequest 推广概览请求。
+type UserAffiliateRequest struct{}
+
+// CommissionEntrySummary 佣金流水。
+type CommissionEntrySummary struct {
+	ID                uint64         `json:"id"`
+	Type              string         `json:"type"`
+	AmountCents       int64          `json:"amount_cents"`
+	Currency          string         `json:"currency"`
+	BalanceAfterCents int64          `json:"balance_after_cents"`
+	OrderID           uint64         `json:"order_id,omitempty"`
+	ReferredUserID    uint64         `json:"referred_user_id,omitempty"`
+	PayoutID          *uint64        `json:"payout_id,omitempty"`
+	Description       string         `json:"description"`
+	Metadata          map[string]any `json:"metadata,omitempty"`
+	CreatedAt         int64          `json:"created_at"`
+}
+
+// UserListCommissionsRequest 佣金流水列表。
+type UserListCommissionsRequest struct {
+	Page      int    `form:"page"`
+	PerPage   int    `form:"per_page"`
+	EntryType string `form:"entry_type"`
+}
+
+// UserCommissionListResponse 佣金流水列表。
+type UserCommissionListResponse struct {
+	Entries    []CommissionEntrySummary `json:"entries"`
+	Pagination PaginationMeta           `json:"pagination"`
+}
+
+// UserWithdrawCommissionRequest 佣金转入钱包余额。
+type UserWithdrawCommissionRequest struct {
+	AmountCents int64 `json:"amount_cents"`
+}
+
+// UserWithdrawCommissionResponse 佣金转入结果。
+type UserWithdrawCommissionResponse struct {
+	Entry   CommissionEntrySummary `json:"entry"`
+	Balance BalanceSnapshot        `json:"balance"`
+	Summary AffiliateSummary       `json:"summary"`
+}
+
+// CommissionPayoutSummary 佣金提现申请。
+type CommissionPayoutSummary struct {
+	ID          uint64 `json:"id"`
+	UserID      uint64 `json:"user_id"`
+	AmountCents int64  `json:"amount_cents"`
+	Currency    string `json:"currency"`
+	Status      string `json:"status"`
+	Account     string `json:"account"`
+	Note        string `json:"note,omitempty"`
+	ReviewNote  string `json:"review_note,omitempty"`
+	ReviewedBy  string `json:"reviewed_by,omitempty"`
+	ReviewedAt  *int64 `json:"reviewed_at,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// UserCreatePayoutRequest 申请佣金提现，需管理员审核。
+type UserCreatePayoutRequest struct {
+	AmountCents int64  `json:"amount_cents"`
+	Account     string `json:"account"`
+	Note        string `json:"note,omitempty"`
+}
+
+// UserListPayoutsRequest 用户提现申请列表。
+type UserListPayoutsRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	Status  string `form:"status"`
+}
+
+// CommissionPayoutListResponse 提现申请列表。
+type CommissionPayoutListResponse struct {
+	Payouts    []CommissionPayoutSummary `json:"payouts"`
+	Pagination PaginationMeta            `json:"pagination"`
+}
+
+// AdminListPayoutsRequest 管理端提现申请列表。
+type AdminListPayoutsRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	Status  string `form:"status"`
+	UserID  uint64 `form:"user_id"`
+}
+
+// AdminReviewPayoutRequest 审核提现申请。
+type AdminReviewPayoutRequest struct {
+	PayoutID uint64 `path:"id"`
+	Note     string `json:"note,omitempty"`
+}
+
+// RedeemBatchSummary 兑换码批次。
+type RedeemBatchSummary struct {
+	ID           uint64 `json:"id"`
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+	AmountCents  int64  `json:"amount_cents,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	PlanID       uint64 `json:"plan_id,omitempty"`
+	PlanDays     int    `json:"plan_days,omitempty"`
+	TrafficBytes int64  `json:"traffic_bytes,omitempty"`
+	MaxUses      int    `json:"max_uses"`
+	Quantity     int    `json:"quantity"`
+	ExpiresAt    *int64 `json:"expires_at,omitempty"`
+	CreatedBy    string `json:"created_by"`
+	CreatedAt    int64  `json:"created_at"`
+}
+
+// RedeemCodeSummary 兑换码。
+type RedeemCodeSummary struct {
+	ID         uint64 `json:"id"`
+	BatchID    uint64 `json:"batch_id"`
+	Code       string `json:"code"`
+	MaxUses    int    `json:"max_uses"`
+	UsedCount  int    `json:"used_count"`
+	Status     string `json:"status"`
+	LastUsedAt *int64 `json:"last_used_at,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+// RedeemRedemptionSummary 兑换流水。
+type RedeemRedemptionSummary struct {
+	ID                   uint64 `json:"id"`
+	CodeID               uint64 `json:"code_id"`
+	BatchID              uint64 `json:"batch_id"`
+	UserID               uint64 `json:"user_id"`
+	Code                 string `json:"code"`
+	Kind                 string `json:"kind"`
+	AmountCents          int64  `json:"amount_cents,omitempty"`
+	Currency             string `json:"currency,omitempty"`
+	PlanID               uint64 `json:"plan_id,omitempty"`
+	PlanDays             int    `json:"plan_days,omitempty"`
+	TrafficBytes         int64  `json:"traffic_bytes,omitempty"`
+	SubscriptionID       uint64 `json:"subscription_id,omitempty"`
+	BalanceTransactionID uint64 `json:"balance_transaction_id,omitempty"`
+	CreatedAt            int64  `json:"created_at"`
+}
+
+// AdminCreateRedeemBatchRequest 批量生成兑换码。
+type AdminCreateRedeemBatchRequest struct {
+	Name         string `json:"name"`
+	Kind         string `json:"kind"`
+	AmountCents  int64  `json:"amount_cents,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	PlanID       uint64 `json:"plan_id,omitempty"`
+	PlanDays     int    `json:"plan_days,omitempty"`
+	TrafficBytes int64  `json:"traffic_bytes,omitempty"`
+	MaxUses      int    `json:"max_uses,omitempty"`
+	Quantity     int    `json:"quantity"`
+	ExpiresAt    *int64 `json:"expires_at,omitempty"`
+	Prefix       string `json:"prefix,omitempty"`
+}
+
+// AdminCreateRedeemBatchResponse 新批次及生成的兑换码。
+type AdminCreateRedeemBatchResponse struct {
+	Batch RedeemBatchSummary  `json:"batch"`
+	Codes []RedeemCodeSummary `json:"codes"`
+}
+
+// AdminListRedeemBatchesRequest 兑换码批次列表。
+type AdminListRedeemBatchesRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	Kind    string `form:"kind"`
+}
+
+// AdminRedeemBatchListResponse 兑换码批次列表。
+type AdminRedeemBatchListResponse struct {
+	Batches    []RedeemBatchSummary `json:"batches"`
+	Pagination PaginationMeta       `json:"pagination"`
+}
+
+// AdminExportRedeemBatchRequest 导出批次兑换码 CSV。
+type AdminExportRedeemBatchRequest struct {
+	BatchID uint64 `path:"id"`
+}
+
+// AdminListRedeemCodesRequest 兑换码列表。
+type AdminListRedeemCodesRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	BatchID uint64 `form:"batch_id"`
+	Status  string `form:"status"`
+	Code    string `form:"code"`
+}
+
+// AdminRedeemCodeListResponse 兑换码列表。
+type AdminRedeemCodeListResponse struct {
+	Codes      []RedeemCodeSummary `json:"codes"`
+	Pagination PaginationMeta      `json:"pagination"`
+}
+
+// AdminUpdateRedeemCodeRequest 启用或禁用兑换码。
+type AdminUpdateRedeemCodeRequest struct {
+	CodeID uint64 `path:"id"`
+	Status string `json:"status"`
+}
+
+// AdminUpdateSubscriptionStatusRequest 管理员暂停、恢复或取消订阅。
+type AdminUpdateSubscriptionStatusRequest struct {
+	SubscriptionID uint64 `path:"id"`
+	Status         string `json:"status"`
+}
+
+// AdminSubscriptionAccessLogsRequest 订阅拉取日志。
+type AdminSubscriptionAccessLogsRequest struct {
+	SubscriptionID uint64 `path:"id"`
+	Page           int    `form:"page"`
+	PerPage        int    `form:"per_page"`
+}
+
+// SubscriptionAccessLogSummary 单次订阅拉取记录。
+type SubscriptionAccessLogSummary struct {
+	ID             uint64 `json:"id"`
+	SubscriptionID uint64 `json:"subscription_id"`
+	UserID         uint64 `json:"user_id"`
+	TokenID        uint64 `json:"token_id"`
+	Token          string `json:"token"`
+	IP             string `json:"ip"`
+	UserAgent      string `json:"user_agent"`
+	ClientType     string `json:"client_type"`
+	Country        string `json:"country,omitempty"`
+	CreatedAt      int64  `json:"created_at"`
+}
+
+// AdminSubscriptionAccessLogsResponse 订阅拉取日志列表。
+type AdminSubscriptionAccessLogsResponse struct {
+	Logs       []SubscriptionAccessLogSummary `json:"logs"`
+	Pagination PaginationMeta                 `json:"pagination"`
+}
+
+// AdminSubscriptionAccessAnomaliesRequest 按窗口统计来源 IP 异常多的 token。
+type AdminSubscriptionAccessAnomaliesRequest struct {
+	WindowHours int `form:"window_hours"`
+	MinIPs      int `form:"min_ips"`
+	Limit       int `form:"limit"`
+}
+
+// SubscriptionAccessAnomaly 单个 token 在窗口内的拉取汇总。
+type SubscriptionAccessAnomaly struct {
+	Token              string `json:"token"`
+	SubscriptionID     uint64 `json:"subscription_id"`
+	UserID             uint64 `json:"user_id"`
+	SubscriptionStatus string `json:"subscription_status"`
+	DistinctIPs        int64  `json:"distinct_ips"`
+	DistinctCountries  int64  `json:"distinct_countries"`
+	Fetches            int64  `json:"fetches"`
+}
+
+// AdminSubscriptionAccessAnomaliesResponse 拉取异常报表；suspend_threshold 为 0 表示未开启自动暂停。
+type AdminSubscriptionAccessAnomaliesResponse struct {
+	Since            int64                       `json:"since"`
+	MinIPs           int                         `json:"min_ips"`
+	SuspendThreshold int                         `json:"suspend_threshold"`
+	Anomalies        []SubscriptionAccessAnomaly `json:"anomalies"`
+}
+
+// AdminListRedemptionsRequest 兑换流水列表。
+type AdminListRedemptionsRequest struct {
+	Page    int    `form:"page"`
+	PerPage int    `form:"per_page"`
+	UserID  uint64 `form:"user_id"`
+	BatchID uint64 `form:"batch_id"`
+	CodeID  uint64 `form:"code_id"`
+}
+
+// RedemptionListResponse 兑换流水列表。
+type RedemptionListResponse struct {
+	Redemptions []RedeemRedemptionSummary `json:"redemptions"`
+	Pagination  PaginationMeta            `json:"pagination"`
+}
+
+// UserRedeemRequest 用户兑换，流量类兑换码可指定订阅。
+type UserRedeemRequest struct {
+	Code           string `json:"code"`
+	SubscriptionID uint64 `json:"subscription_id,omitempty"`
+}
+
+// UserRedeemResponse 兑换结果。
+type UserRedeemResponse struct {
+	Redemption   RedeemRedemptionSummary  `json:"redemption"`
+	Balance      *BalanceSnapshot         `json:"balance,omitempty"`
+	Subscription *UserSubscriptionSummary `json:"subscription,omitempty"`
+}
+
+// UserListRedemptionsRequest 用户兑换记录。
+type UserListRedemptionsRequest struct {
+	Page    int `form:"page"`
+	PerPage int `form:"per_page"`
+}
+
+// NodeUsersRequest 节点分页拉取可服务的用户列表，after_id 为上一页返回的 next_after_id。
+type NodeUsersRequest struct {
+	NodeID  uint64 `path:"id"`
+	AfterID uint64 `form:"after_id,optional"`
+	Limit   int    `form:"limit,optional"`
+}
+
+// NodeUser 节点侧的用户凭据与限制。
+type NodeUser struct {
+	SubscriptionID    uint64 `json:"subscription_id"`
+	UserID            uint64 `json:"user_id"`
+	Token             string `json:"token"`
+	ProxyUUID         string `json:"proxy_uuid"`
+	ExpiresAt         int64  `json:"expires_at"`
+	TrafficTotalBytes int64  `json:"traffic_total_bytes"`
+	TrafficUsedBytes  int64  `json:"traffic_used_bytes"`
+	DevicesLimit      int    `json:"devices_limit"`
+	OnlineDevices     int    `json:"online_devices"`
+	OverLimit         bool   `json:"over_limit,omitempty"`
+}
+
+// NodeUsersResponse 节点用户列表。
+type NodeUsersResponse struct {
+	NodeID      uint64     `json:"node_id"`
+	Users       []NodeUser `json:"users"`
+	Excluded    []uint64   `json:"excluded"`
+	NextAfterID uint64     `json:"next_after_id"`
+	HasMore     bool       `json:"has_more"`
+	GeneratedAt int64      `json:"generated_at"`
+}
+
+// NodeOnlineUser 单个订阅在节点上的在线 IP。
+type NodeOnlineUser struct {
+	SubscriptionID uint64   `json:"subscription_id"`
+	IPs            []string `json:"ips"`
+}
+
+// NodeOnlineReportRequest 节点上报在线 IP。
+type NodeOnlineReportRequest struct {
+	NodeID uint64           `path:"id"`
+	Users  []NodeOnlineUser `json:"users"`
+}
+
+// NodeOnlineReportResponse 在线上报结果。
+type NodeOnlineReportResponse struct {
+	NodeID     uint64   `json:"node_id"`
+	Accepted   int      `json:"accepted"`
+	OverLimit  []uint64 `json:"over_limit"`
+	ReportedAt int64    `json:"reported_at"`
+}
@@ -0,0 +1,116 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidDatabase 表示 IP 归属地文件格式无效。
+var ErrInvalidDatabase = errors.New("geoip: invalid database")
+
+// DB 基于 IP 段的离线国家库，格式兼容 DB-IP / IP2Location Lite 的 CSV：
+// 每行 start_ip,end_ip,country，多余列忽略，# 开头的行与无法解析的表头跳过。
+// IPv4 与 IPv6 统一按 16 字节形式比较。
+type DB struct {
+	ranges []ipRange
+}
+
+type ipRange struct {
+	start   net.IP
+	end     net.IP
+	country string
+}
+
+// Open 从文件加载归属地库。
+func Open(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip: open %q: %w", path, err)
+	}
+	defer file.Close()
+
+	return Load(file)
+}
+
+// Load 从 CSV 内容加载归属地库，区间按起始地址排序以便二分查找。
+func Load(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var ranges []ipRange
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDatabase, err)
+		}
+		line++
+		if len(record) < 3 {
+			return nil, fmt.Errorf("%w: line %d has %d columns", ErrInvalidDatabase, line, len(record))
+		}
+
+		start := net.ParseIP(strings.TrimSpace(record[0]))
+		end := net.ParseIP(strings.TrimSpace(record[1]))
+		if start == nil || end == nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("%w: line %d has invalid address", ErrInvalidDatabase, line)
+		}
+		start, end = start.To16(), end.To16()
+		if bytes.Compare(start, end) > 0 {
+			return nil, fmt.Errorf("%w: line %d has start after end", ErrInvalidDatabase, line)
+		}
+		ranges = append(ranges, ipRange{
+			start:   start,
+			end:     end,
+			country: strings.ToUpper(strings.TrimSpace(record[2])),
+		})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].start, ranges[j].start) < 0
+	})
+	return &DB{ranges: ranges}, nil
+}
+
+// Country 返回 IP 所属国家/地区代码，无法识别时返回空字符串；nil DB 可安全调用。
+func (d *DB) Country(ip string) string {
+	if d == nil || len(d.ranges) == 0 {
+		return ""
+	}
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return ""
+	}
+	parsed = parsed.To16()
+
+	// 找到最后一个起始地址不大于 ip 的区间。
+	idx := sort.Search(len(d.ranges), func(i int) bool {
+		return bytes.Compare(d.ranges[i].start, parsed) > 0
+	}) - 1
+	if idx < 0 || bytes.Compare(parsed, d.ranges[idx].end) > 0 {
+		return ""
+	}
+	return d.ranges[idx].country
+}
+
+// Len 返回已加载的区间数。
+func (d *DB) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.ranges)
+}
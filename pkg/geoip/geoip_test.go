@@ -0,0 +1,58 @@
+package geoip
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadAndLookup(t *testing.T) {
+	content := `# ip-to-country lite
+start_ip,end_ip,country
+1.0.0.0,1.0.0.255,au
+8.8.8.0,8.8.8.255,US
+1.0.1.0,1.0.3.255,CN
+2001:db8::,2001:db8::ffff,jp
+`
+	db, err := Load(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if db.Len() != 4 {
+		t.Fatalf("expected 4 ranges, got %d", db.Len())
+	}
+
+	cases := map[string]string{
+		"1.0.0.1":       "AU",
+		"1.0.2.9":       "CN",
+		"8.8.8.8":       "US",
+		"2001:db8::1":   "JP",
+		"9.9.9.9":       "",
+		"1.0.4.0":       "",
+		"not-an-ip":     "",
+		"0.0.0.1":       "",
+		"2001:db8::1:0": "",
+	}
+	for ip, want := range cases {
+		if got := db.Country(ip); got != want {
+			t.Fatalf("country(%s) = %q, want %q", ip, got, want)
+		}
+	}
+
+	var empty *DB
+	if empty.Country("8.8.8.8") != "" {
+		t.Fatal("nil db should resolve nothing")
+	}
+}
+
+func TestLoadRejectsInvalidRows(t *testing.T) {
+	_, err := Load(strings.NewReader("1.0.0.0,1.0.0.255,AU\n1.0.1.0,bogus,CN\n"))
+	if !errors.Is(err, ErrInvalidDatabase) {
+		t.Fatalf("expected ErrInvalidDatabase, got %v", err)
+	}
+
+	_, err = Load(strings.NewReader("1.0.0.9,1.0.0.1,AU\n"))
+	if !errors.Is(err, ErrInvalidDatabase) {
+		t.Fatalf("expected ErrInvalidDatabase for reversed range, got %v", err)
+	}
+}
@@ -45,6 +45,14 @@ var (
 		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10},
 	}, []string{"protocol", "result"})
 
+	// SubscriptionRenderCacheTotal counts subscription render cache lookups grouped by result (hit or miss).
+	SubscriptionRenderCacheTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "subscription_render_cache",
+		Name:      "requests_total",
+		Help:      "Total number of subscription render cache lookups.",
+	}, []string{"result"})
+
 	// OrderCreateTotal counts user order creation attempts grouped by payment method.
 	OrderCreateTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: namespace,
@@ -135,6 +143,11 @@ func ObserveNodeSync(protocol, result string, duration time.Duration) {
 	NodeSyncDurationSeconds.WithLabelValues(sanitizedProtocol, sanitizedResult).Observe(duration.Seconds())
 }
 
+// ObserveRenderCache records a subscription render cache lookup outcome.
+func ObserveRenderCache(result string) {
+	SubscriptionRenderCacheTotal.WithLabelValues(normalizeResult(result)).Inc()
+}
+
 // ObserveOrderCreate records an order creation attempt with duration, payment method and outcome labels.
 func ObserveOrderCreate(paymentMethod, result string, duration time.Duration) {
 	sanitizedMethod := strings.ToLower(strings.TrimSpace(paymentMethod))
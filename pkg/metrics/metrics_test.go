@@ -22,6 +22,22 @@ func TestObserveNodeSync(t *testing.T) {
 	}
 }
 
+func TestObserveRenderCache(t *testing.T) {
+	hits := testutil.ToFloat64(SubscriptionRenderCacheTotal.WithLabelValues("hit"))
+	misses := testutil.ToFloat64(SubscriptionRenderCacheTotal.WithLabelValues("miss"))
+
+	ObserveRenderCache("Hit")
+	ObserveRenderCache("miss")
+	ObserveRenderCache("miss")
+
+	if diff := testutil.ToFloat64(SubscriptionRenderCacheTotal.WithLabelValues("hit")) - hits; diff != 1 {
+		t.Fatalf("expected hit counter to increase by 1, got %.0f", diff)
+	}
+	if diff := testutil.ToFloat64(SubscriptionRenderCacheTotal.WithLabelValues("miss")) - misses; diff != 2 {
+		t.Fatalf("expected miss counter to increase by 2, got %.0f", diff)
+	}
+}
+
 func TestObserveOrderCreate(t *testing.T) {
 	const method = "test-method"
 	const result = "error"
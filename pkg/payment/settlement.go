@@ -0,0 +1,294 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 结算记录的统一状态。
+const (
+	SettlementStatusSucceeded = "succeeded"
+	SettlementStatusFailed    = "failed"
+	SettlementStatusPending   = "pending"
+	SettlementStatusRefunded  = "refunded"
+)
+
+// ErrInvalidSettlement 表示结算文件格式或内容无效。
+var ErrInvalidSettlement = errors.New("payment: invalid settlement data")
+
+// Settlement 网关侧的一条结算记录。
+type Settlement struct {
+	Provider    string    `json:"provider"`
+	IntentID    string    `json:"intent_id"`
+	Reference   string    `json:"reference"`
+	Status      string    `json:"status"`
+	AmountCents int64     `json:"amount_cents"`
+	Currency    string    `json:"currency"`
+	SettledAt   time.Time `json:"settled_at"`
+}
+
+// SettlementProvider 按时间窗口提供结算记录。目前仅有 StaticProvider（导入的结算文件），
+// 尚未对接任何网关的结算查询 API。
+type SettlementProvider interface {
+	Name() string
+	ListSettlements(ctx context.Context, from, to time.Time) ([]Settlement, error)
+}
+
+// StaticProvider 以导入的结算文件作为数据源。
+type StaticProvider struct {
+	name        string
+	settlements []Settlement
+}
+
+// NewStaticProvider 使用已解析的结算记录构造 Provider。
+func NewStaticProvider(name string, settlements []Settlement) *StaticProvider {
+	return &StaticProvider{name: strings.TrimSpace(name), settlements: settlements}
+}
+
+// Name 返回 Provider 名称。
+func (p *StaticProvider) Name() string { return p.name }
+
+// ListSettlements 返回窗口内的记录；from/to 为零值时不限制，未带结算时间的记录总是返回。
+func (p *StaticProvider) ListSettlements(ctx context.Context, from, to time.Time) ([]Settlement, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Settlement, 0, len(p.settlements))
+	for _, s := range p.settlements {
+		if !s.SettledAt.IsZero() {
+			if !from.IsZero() && s.SettledAt.Before(from) {
+				continue
+			}
+			if !to.IsZero() && s.SettledAt.After(to) {
+				continue
+			}
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// ParseSettlements 按 format（csv/json）解析结算文件。
+func ParseSettlements(format string, content []byte) ([]Settlement, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "csv":
+		return ParseSettlementsCSV(content)
+	case "json":
+		return ParseSettlementsJSON(content)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidSettlement, format)
+	}
+}
+
+// ParseSettlementsCSV 解析带表头的 CSV。可识别列：intent_id、reference、status、
+// amount_cents 或 amount（两位小数）、currency、settled_at（RFC3339 或 Unix 秒）、provider。
+func ParseSettlementsCSV(content []byte) ([]Settlement, error) {
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return []Settlement{}, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSettlement, err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, hasIntent := columns["intent_id"]
+	_, hasReference := columns["reference"]
+	if !hasIntent && !hasReference {
+		return nil, fmt.Errorf("%w: header must contain intent_id or reference", ErrInvalidSettlement)
+	}
+
+	field := func(record []string, name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	settlements := make([]Settlement, 0)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettlement, err)
+		}
+
+		amount, err := parseAmount(field(record, "amount_cents"), field(record, "amount"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidSettlement, line, err)
+		}
+		settledAt, err := parseTime(field(record, "settled_at"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidSettlement, line, err)
+		}
+
+		s := Settlement{
+			Provider:    field(record, "provider"),
+			IntentID:    field(record, "intent_id"),
+			Reference:   field(record, "reference"),
+			Status:      field(record, "status"),
+			AmountCents: amount,
+			Currency:    field(record, "currency"),
+			SettledAt:   settledAt,
+		}
+		if err := normalizeSettlement(&s); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidSettlement, line, err)
+		}
+		settlements = append(settlements, s)
+	}
+
+	return settlements, nil
+}
+
+type jsonSettlement struct {
+	Provider    string          `json:"provider"`
+	IntentID    string          `json:"intent_id"`
+	Reference   string          `json:"reference"`
+	Status      string          `json:"status"`
+	AmountCents *int64          `json:"amount_cents"`
+	Amount      json.RawMessage `json:"amount"`
+	Currency    string          `json:"currency"`
+	SettledAt   json.RawMessage `json:"settled_at"`
+}
+
+// ParseSettlementsJSON 解析数组或 {"settlements": [...]} 形式的 JSON，字段同 CSV。
+func ParseSettlementsJSON(content []byte) ([]Settlement, error) {
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) == 0 {
+		return []Settlement{}, nil
+	}
+
+	var rows []jsonSettlement
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &rows); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettlement, err)
+		}
+	} else {
+		var wrapper struct {
+			Settlements []jsonSettlement `json:"settlements"`
+		}
+		if err := json.Unmarshal(trimmed, &wrapper); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettlement, err)
+		}
+		rows = wrapper.Settlements
+	}
+
+	settlements := make([]Settlement, 0, len(rows))
+	for i, row := range rows {
+		var amount int64
+		var err error
+		if row.AmountCents != nil {
+			amount = *row.AmountCents
+		} else {
+			amount, err = parseAmount("", strings.Trim(string(row.Amount), `"`))
+			if err != nil {
+				return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidSettlement, i, err)
+			}
+		}
+		settledAt, err := parseTime(strings.Trim(string(row.SettledAt), `"`))
+		if err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidSettlement, i, err)
+		}
+
+		s := Settlement{
+			Provider:    row.Provider,
+			IntentID:    row.IntentID,
+			Reference:   row.Reference,
+			Status:      row.Status,
+			AmountCents: amount,
+			Currency:    row.Currency,
+			SettledAt:   settledAt,
+		}
+		if err := normalizeSettlement(&s); err != nil {
+			return nil, fmt.Errorf("%w: item %d: %v", ErrInvalidSettlement, i, err)
+		}
+		settlements = append(settlements, s)
+	}
+
+	return settlements, nil
+}
+
+// NormalizeSettlementStatus 将网关常见状态别名归一为 Settlement 状态。
+func NormalizeSettlementStatus(status string) string {
+	switch strings.ToLower(strings.TrimSpace(status)) {
+	case "", "succeeded", "success", "paid", "settled", "completed", "captured":
+		return SettlementStatusSucceeded
+	case "failed", "failure", "declined", "canceled", "cancelled", "expired":
+		return SettlementStatusFailed
+	case "pending", "processing":
+		return SettlementStatusPending
+	case "refunded", "reversed", "chargeback":
+		return SettlementStatusRefunded
+	default:
+		return strings.ToLower(strings.TrimSpace(status))
+	}
+}
+
+func normalizeSettlement(s *Settlement) error {
+	s.Provider = strings.TrimSpace(s.Provider)
+	s.IntentID = strings.TrimSpace(s.IntentID)
+	s.Reference = strings.TrimSpace(s.Reference)
+	s.Currency = strings.ToUpper(strings.TrimSpace(s.Currency))
+	s.Status = NormalizeSettlementStatus(s.Status)
+	if s.IntentID == "" && s.Reference == "" {
+		return errors.New("intent_id or reference is required")
+	}
+	if s.AmountCents < 0 {
+		return errors.New("amount must not be negative")
+	}
+	return nil
+}
+
+func parseAmount(cents, decimal string) (int64, error) {
+	if cents != "" {
+		value, err := strconv.ParseInt(cents, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount_cents %q", cents)
+		}
+		return value, nil
+	}
+	if decimal == "" || decimal == "null" {
+		return 0, errors.New("amount or amount_cents is required")
+	}
+	value, err := strconv.ParseFloat(decimal, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", decimal)
+	}
+	return int64(math.Round(value * 100)), nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "null" {
+		return time.Time{}, nil
+	}
+	if unix, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(unix, 0).UTC(), nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid settled_at %q", value)
+	}
+	return parsed.UTC(), nil
+}
@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSettlementsCSV(t *testing.T) {
+	content := []byte(`# exported from gateway
+intent_id,reference,amount,currency,status,settled_at
+pi_1,,12.34,usd,paid,2026-05-01T10:00:00Z
+,ref_2,5,cny,declined,1777600000
+`)
+	settlements, err := ParseSettlementsCSV(content)
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(settlements) != 2 {
+		t.Fatalf("expected 2 settlements, got %d", len(settlements))
+	}
+	first := settlements[0]
+	if first.IntentID != "pi_1" || first.AmountCents != 1234 || first.Currency != "USD" || first.Status != SettlementStatusSucceeded {
+		t.Fatalf("unexpected first settlement: %+v", first)
+	}
+	if !first.SettledAt.Equal(time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected settled_at: %v", first.SettledAt)
+	}
+	second := settlements[1]
+	if second.Reference != "ref_2" || second.AmountCents != 500 || second.Status != SettlementStatusFailed {
+		t.Fatalf("unexpected second settlement: %+v", second)
+	}
+
+	if _, err := ParseSettlementsCSV([]byte("amount\n1.00\n")); !errors.Is(err, ErrInvalidSettlement) {
+		t.Fatalf("expected ErrInvalidSettlement for missing key columns, got %v", err)
+	}
+	if _, err := ParseSettlementsCSV([]byte("intent_id,amount\npi_1,abc\n")); !errors.Is(err, ErrInvalidSettlement) {
+		t.Fatalf("expected ErrInvalidSettlement for invalid amount, got %v", err)
+	}
+}
+
+func TestParseSettlementsJSON(t *testing.T) {
+	wrapped := []byte(`{"settlements":[{"intent_id":"pi_1","amount_cents":900,"currency":"EUR","status":"refunded"}]}`)
+	settlements, err := ParseSettlements("json", wrapped)
+	if err != nil {
+		t.Fatalf("parse json: %v", err)
+	}
+	if len(settlements) != 1 || settlements[0].AmountCents != 900 || settlements[0].Status != SettlementStatusRefunded {
+		t.Fatalf("unexpected settlements: %+v", settlements)
+	}
+
+	array := []byte(`[{"reference":"ref_1","amount":"3.50","settled_at":"2026-05-02T00:00:00Z"}]`)
+	settlements, err = ParseSettlementsJSON(array)
+	if err != nil {
+		t.Fatalf("parse json array: %v", err)
+	}
+	if settlements[0].AmountCents != 350 || settlements[0].Status != SettlementStatusSucceeded {
+		t.Fatalf("unexpected settlement: %+v", settlements[0])
+	}
+
+	if _, err := ParseSettlements("xml", nil); !errors.Is(err, ErrInvalidSettlement) {
+		t.Fatalf("expected ErrInvalidSettlement for unsupported format, got %v", err)
+	}
+}
+
+func TestStaticProviderFiltersWindow(t *testing.T) {
+	base := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	provider := NewStaticProvider("file", []Settlement{
+		{IntentID: "early", SettledAt: base.Add(-time.Hour)},
+		{IntentID: "inside", SettledAt: base.Add(time.Hour)},
+		{IntentID: "undated"},
+	})
+
+	settlements, err := provider.ListSettlements(context.Background(), base, base.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("list settlements: %v", err)
+	}
+	if len(settlements) != 2 || settlements[0].IntentID != "inside" || settlements[1].IntentID != "undated" {
+		t.Fatalf("unexpected settlements: %+v", settlements)
+	}
+}
@@ -0,0 +1,137 @@
+// Package pdf 提供一个无第三方依赖的纯文本 PDF 生成器，用于发票等单据下载。
+// 字体使用 Adobe 亚洲字体包中的 STSong-Light（UniGB-UCS2-H 编码），阅读器可直接显示中英文。
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	pageWidth  = 595.0 // A4, pt
+	pageHeight = 842.0
+	margin     = 56.0
+)
+
+// Document 按行排版的 A4 文档，超出一页时自动分页。
+type Document struct {
+	FontSize   float64
+	LineHeight float64
+	lines      []string
+}
+
+// NewDocument 创建默认 10pt 字号的文档。
+func NewDocument() *Document {
+	return &Document{FontSize: 10, LineHeight: 15}
+}
+
+// AddLines 追加文本行，行内换行符会被拆分为多行。
+func (d *Document) AddLines(lines ...string) {
+	for _, line := range lines {
+		d.lines = append(d.lines, strings.Split(strings.ReplaceAll(line, "\r\n", "\n"), "\n")...)
+	}
+}
+
+// Bytes 输出完整的 PDF 文件内容。
+func (d *Document) Bytes() []byte {
+	fontSize := d.FontSize
+	if fontSize <= 0 {
+		fontSize = 10
+	}
+	lineHeight := d.LineHeight
+	if lineHeight <= 0 {
+		lineHeight = fontSize * 1.5
+	}
+
+	perPage := int((pageHeight - 2*margin) / lineHeight)
+	if perPage < 1 {
+		perPage = 1
+	}
+	var pages [][]string
+	for start := 0; start < len(d.lines); start += perPage {
+		end := start + perPage
+		if end > len(d.lines) {
+			end = len(d.lines)
+		}
+		pages = append(pages, d.lines[start:end])
+	}
+	if len(pages) == 0 {
+		pages = [][]string{{}}
+	}
+
+	w := &writer{}
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1 catalog, 2 pages, 3-5 font; 每页占用 page + content 两个对象。
+	kids := make([]string, 0, len(pages))
+	for i := range pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 6+i*2))
+	}
+	w.object(1, "<< /Type /Catalog /Pages 2 0 R >>")
+	w.object(2, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	w.object(3, "<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UCS2-H /DescendantFonts [4 0 R] >>")
+	w.object(4, "<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 2 >> "+
+		"/FontDescriptor 5 0 R /DW 1000 /W [1 95 500] >>")
+	w.object(5, "<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] "+
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	for i, lines := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT\n/F1 %s Tf\n%s TL\n%s %s Td\n", num(fontSize), num(lineHeight), num(margin), num(pageHeight-margin-fontSize))
+		for _, line := range lines {
+			fmt.Fprintf(&content, "<%s> Tj T*\n", encodeUCS2(line))
+		}
+		content.WriteString("ET\n")
+
+		pageID := 6 + i*2
+		w.object(pageID, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			num(pageWidth), num(pageHeight), pageID+1))
+		w.object(pageID+1, fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()))
+	}
+
+	w.finish(1)
+	return w.buf.Bytes()
+}
+
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) object(id int, body string) {
+	for len(w.offsets) < id {
+		w.offsets = append(w.offsets, 0)
+	}
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+func (w *writer) finish(root int) {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, offset := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, xref)
+}
+
+// encodeUCS2 将文本编码为 UTF-16BE 十六进制串，BMP 以外的字符替换为 '?'。
+func encodeUCS2(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r == '\t' {
+			r = ' '
+		}
+		if r > 0xFFFF || r < 0x20 {
+			r = '?'
+		}
+		fmt.Fprintf(&b, "%04X", r)
+	}
+	return b.String()
+}
+
+func num(v float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.2f", v), "0"), ".")
+}
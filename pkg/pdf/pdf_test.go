@@ -0,0 +1,57 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocumentBytesStructure(t *testing.T) {
+	doc := NewDocument()
+	doc.AddLines("Invoice INV-2026-000001", "发票\n合计 ¥12.00")
+
+	out := doc.Bytes()
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4")) {
+		t.Fatalf("missing pdf header")
+	}
+	if !bytes.HasSuffix(out, []byte("%%EOF\n")) {
+		t.Fatalf("missing eof marker")
+	}
+	if !bytes.Contains(out, []byte("/Count 1")) {
+		t.Fatalf("expected single page")
+	}
+	// "发" = U+53D1
+	if !bytes.Contains(out, []byte("53D1")) {
+		t.Fatalf("expected ucs2 encoded cjk text")
+	}
+
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatalf("missing startxref")
+	}
+	offset, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(out[offset:], []byte("xref")) {
+		t.Fatalf("startxref offset %d does not point at xref table", offset)
+	}
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	for i, entry := range entries {
+		pos, _ := strconv.Atoi(string(entry[1]))
+		want := strconv.Itoa(i+1) + " 0 obj"
+		if !bytes.HasPrefix(out[pos:], []byte(want)) {
+			t.Fatalf("xref entry %d points at %q", i+1, string(out[pos:pos+10]))
+		}
+	}
+}
+
+func TestDocumentPaginates(t *testing.T) {
+	doc := NewDocument()
+	doc.AddLines(strings.Repeat("line\n", 120))
+
+	out := doc.Bytes()
+	if !bytes.Contains(out, []byte("/Count 3")) {
+		t.Fatalf("expected 3 pages for 121 lines")
+	}
+}
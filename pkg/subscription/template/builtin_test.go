@@ -0,0 +1,156 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files under testdata")
+
+// builtinTestContext 覆盖四种协议与 ws/grpc/tcp 传输，以及不支持协议与缺少端口的接入点。
+func builtinTestContext() map[string]any {
+	return map[string]any{
+		"subscription": map[string]any{
+			"id":    uint64(7),
+			"name":  "Premium",
+			"token": "0123456789abcdef0123456789abcdef",
+			"uuid":  "01234567-89ab-cdef-0123-456789abcdef",
+		},
+		"nodes": []map[string]any{
+			{
+				"id":   uint64(1),
+				"name": "HK 01",
+				"kernels": []map[string]any{
+					{
+						"protocol": "vmess",
+						"host":     "hk.example.com",
+						"port":     443,
+						"config":   map[string]any{"transport": "ws", "path": "/ray", "host": "cdn.example.com", "tls": true},
+					},
+					{
+						"protocol": "trojan",
+						"host":     "hk.example.com",
+						"port":     8443,
+						"config":   map[string]any{"sni": "trojan.example.com", "skip_cert_verify": true},
+					},
+				},
+			},
+			{
+				"id":   uint64(2),
+				"name": "JP",
+				"kernels": []map[string]any{
+					{
+						"protocol": "vless",
+						"host":     "jp.example.com",
+						"port":     443,
+						"config":   map[string]any{"network": "grpc", "service_name": "vless-grpc", "security": "tls", "flow": ""},
+					},
+				},
+			},
+			{
+				"id":   uint64(3),
+				"name": "US",
+				"kernels": []map[string]any{
+					{"protocol": "ss", "host": "us.example.com", "port": 8388, "config": map[string]any{"method": "aes-256-gcm"}},
+					{"protocol": "http", "host": "us.example.com", "port": 80, "config": map[string]any{}},
+					{"protocol": "vmess", "host": "us.example.com", "port": 0, "config": map[string]any{}},
+				},
+			},
+		},
+	}
+}
+
+func TestBuiltinFormatsGolden(t *testing.T) {
+	cases := map[string]string{
+		FormatClash:       "clash.yaml",
+		FormatSingBox:     "sing-box.json",
+		FormatV2RayBase64: "v2ray-base64.txt",
+		FormatSurge:       "surge.conf",
+	}
+
+	for format, file := range cases {
+		got, err := Render(format, "", builtinTestContext())
+		if err != nil {
+			t.Fatalf("render %s: %v", format, err)
+		}
+		if format == FormatV2RayBase64 {
+			// 解码后比对，便于审阅分享链接。
+			decoded, err := base64.StdEncoding.DecodeString(got)
+			if err != nil {
+				t.Fatalf("decode %s: %v", format, err)
+			}
+			got = string(decoded) + "\n"
+		}
+
+		path := filepath.Join("testdata", file)
+		if *updateGolden {
+			if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+				t.Fatalf("write golden %s: %v", path, err)
+			}
+			continue
+		}
+		want, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read golden %s: %v", path, err)
+		}
+		if got != string(want) {
+			t.Fatalf("%s output mismatch (run go test -update to refresh)\n--- got ---\n%s\n--- want ---\n%s", format, got, want)
+		}
+	}
+}
+
+func TestBuiltinFormatsAcceptJSONContext(t *testing.T) {
+	raw, err := json.Marshal(builtinTestContext())
+	if err != nil {
+		t.Fatalf("marshal context: %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal context: %v", err)
+	}
+
+	for _, format := range []string{FormatClash, FormatSingBox, FormatV2RayBase64, FormatSurge} {
+		fromJSON, err := Render(format, "", decoded)
+		if err != nil {
+			t.Fatalf("render %s from json context: %v", format, err)
+		}
+		direct, err := Render(format, "", builtinTestContext())
+		if err != nil {
+			t.Fatalf("render %s: %v", format, err)
+		}
+		if fromJSON != direct {
+			t.Fatalf("%s output differs between typed and json context", format)
+		}
+	}
+}
+
+func TestFormatHelpers(t *testing.T) {
+	if NormalizeFormat(" Base64 ") != FormatV2RayBase64 || NormalizeFormat("") != FormatGoTemplate || NormalizeFormat("singbox") != FormatSingBox {
+		t.Fatalf("unexpected format aliases")
+	}
+	if !SupportedFormat("clash") || !SupportedFormat("json") || SupportedFormat("xml") {
+		t.Fatalf("unexpected supported formats")
+	}
+	if IsBuiltinFormat("go_template") || !IsBuiltinFormat("surge") {
+		t.Fatalf("unexpected builtin formats")
+	}
+	if ContentType("sing-box") != "application/json" || !strings.HasPrefix(ContentType("clash"), "text/yaml") {
+		t.Fatalf("unexpected content types")
+	}
+	if _, err := Render("xml", "", nil); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+
+	if got := SubscriptionUUID("0123456789abcdef0123456789abcdef"); got != "01234567-89ab-cdef-0123-456789abcdef" {
+		t.Fatalf("hex token uuid = %s", got)
+	}
+	derived := SubscriptionUUID("not-a-uuid")
+	if derived == "" || derived != SubscriptionUUID("not-a-uuid") || derived == SubscriptionUUID("other") {
+		t.Fatalf("derived uuid should be stable and distinct, got %s", derived)
+	}
+}
@@ -0,0 +1,131 @@
+package template
+
+import (
+	"bytes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// clashGroupName 内置 Clash/Surge 配置中的选择分组名称。
+const clashGroupName = "Proxy"
+
+type clashConfig struct {
+	Proxies     []clashProxy      `yaml:"proxies"`
+	ProxyGroups []clashProxyGroup `yaml:"proxy-groups"`
+	Rules       []string          `yaml:"rules"`
+}
+
+type clashProxy struct {
+	Name           string         `yaml:"name"`
+	Type           string         `yaml:"type"`
+	Server         string         `yaml:"server"`
+	Port           int            `yaml:"port"`
+	UUID           string         `yaml:"uuid,omitempty"`
+	AlterID        *int           `yaml:"alterId,omitempty"`
+	Cipher         string         `yaml:"cipher,omitempty"`
+	Password       string         `yaml:"password,omitempty"`
+	Flow           string         `yaml:"flow,omitempty"`
+	UDP            bool           `yaml:"udp"`
+	Network        string         `yaml:"network,omitempty"`
+	TLS            bool           `yaml:"tls,omitempty"`
+	ServerName     string         `yaml:"servername,omitempty"`
+	SNI            string         `yaml:"sni,omitempty"`
+	SkipCertVerify bool           `yaml:"skip-cert-verify,omitempty"`
+	WSOpts         *clashWSOpts   `yaml:"ws-opts,omitempty"`
+	GRPCOpts       *clashGRPCOpts `yaml:"grpc-opts,omitempty"`
+}
+
+type clashWSOpts struct {
+	Path    string            `yaml:"path,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+}
+
+type clashGRPCOpts struct {
+	ServiceName string `yaml:"grpc-service-name,omitempty"`
+}
+
+type clashProxyGroup struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	Proxies []string `yaml:"proxies"`
+}
+
+// renderClash 输出 Clash（Meta 内核兼容）YAML：全部代理、一个手动选择分组与兜底规则。
+func renderClash(data map[string]any) (string, error) {
+	proxies := collectProxies(data)
+
+	config := clashConfig{
+		Proxies: make([]clashProxy, 0, len(proxies)),
+		Rules:   []string{"MATCH," + clashGroupName},
+	}
+	for _, item := range proxies {
+		config.Proxies = append(config.Proxies, toClashProxy(item))
+	}
+
+	members := proxyNames(proxies)
+	members = append(members, "DIRECT")
+	config.ProxyGroups = []clashProxyGroup{{Name: clashGroupName, Type: "select", Proxies: members}}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(config); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func toClashProxy(item proxy) clashProxy {
+	result := clashProxy{
+		Name:           item.Name,
+		Server:         item.Server,
+		Port:           item.Port,
+		UDP:            true,
+		SkipCertVerify: item.Insecure,
+	}
+
+	switch item.Protocol {
+	case ProtocolVMess:
+		alterID := item.AlterID
+		result.Type = "vmess"
+		result.UUID = item.UUID
+		result.AlterID = &alterID
+		result.Cipher = "auto"
+		result.TLS = item.TLS
+	case ProtocolVLESS:
+		result.Type = "vless"
+		result.UUID = item.UUID
+		result.Flow = item.Flow
+		result.TLS = item.TLS
+	case ProtocolTrojan:
+		result.Type = "trojan"
+		result.Password = item.Password
+		result.SNI = item.tlsServerName()
+	case ProtocolShadowsocks:
+		result.Type = "ss"
+		result.Cipher = item.Method
+		result.Password = item.Password
+	}
+	if result.TLS {
+		result.ServerName = item.tlsServerName()
+	}
+
+	if item.Protocol != ProtocolShadowsocks && item.Network != "tcp" {
+		result.Network = item.Network
+	}
+	switch item.Network {
+	case "ws":
+		opts := &clashWSOpts{Path: item.Path}
+		if item.HostHeader != "" {
+			opts.Headers = map[string]string{"Host": item.HostHeader}
+		}
+		result.WSOpts = opts
+	case "grpc":
+		result.GRPCOpts = &clashGRPCOpts{ServiceName: item.ServiceName}
+	}
+
+	return result
+}
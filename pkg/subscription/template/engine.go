@@ -0,0 +1,88 @@
+package template
+
+import (
+	"io"
+	"text/template"
+)
+
+// Engine 模板引擎。所有引擎接收相同的渲染上下文，按格式注册，方式与 cache.Register 一致。
+type Engine interface {
+	// Parse 校验模板内容的语法，保存模板时调用。
+	Parse(content string) error
+	// Execute 渲染模板内容并写入 w；w 拒绝写入（超出输出上限或渲染超时）时须立即中止并返回该错误。
+	Execute(w io.Writer, content string, data map[string]any) error
+	// References 返回模板内容从根上下文引用的变量路径（去重排序），用于变量声明校验与渲染前检查；
+	// 无法静态分析或不使用模板内容的引擎返回空。
+	References(content string) ([]string, error)
+}
+
+var engines = map[string]Engine{}
+
+func init() {
+	RegisterEngine(FormatGoTemplate, goTemplateEngine{})
+	for format, render := range builtinRenderers {
+		RegisterEngine(format, builtinEngine(render))
+	}
+}
+
+// RegisterEngine 注册模板格式对应的引擎，同名格式后注册者覆盖先注册者；应在 init 中调用。
+func RegisterEngine(format string, engine Engine) {
+	engines[NormalizeFormat(format)] = engine
+}
+
+func lookupEngine(format string) (Engine, bool) {
+	engine, ok := engines[NormalizeFormat(format)]
+	return engine, ok
+}
+
+// goTemplateEngine 基于 text/template，提供 funcMap 中的函数。
+type goTemplateEngine struct{}
+
+func (goTemplateEngine) Parse(content string) error {
+	_, err := parseGoTemplate(content)
+	return err
+}
+
+func (goTemplateEngine) References(content string) ([]string, error) {
+	return ReferencedVariables(content)
+}
+
+func (goTemplateEngine) Execute(w io.Writer, content string, data map[string]any) error {
+	tmpl, err := parseGoTemplate(content)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// parseGoTemplate 解析模板并拒绝循环次数超过 maxRangeIterations 的整数字面量 range。
+func parseGoTemplate(content string) (*template.Template, error) {
+	tmpl, err := template.New("subscription").Funcs(funcMap).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			if err := checkRangeLiterals(t.Tree, t.Tree.Root, 1); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tmpl, nil
+}
+
+// builtinEngine 将内置格式适配为引擎，忽略模板内容。
+type builtinEngine func(map[string]any) (string, error)
+
+func (builtinEngine) Parse(string) error { return nil }
+
+func (builtinEngine) References(string) ([]string, error) { return nil, nil }
+
+func (e builtinEngine) Execute(w io.Writer, _ string, data map[string]any) error {
+	out, err := e(data)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, out)
+	return err
+}
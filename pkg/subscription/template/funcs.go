@@ -0,0 +1,285 @@
+package template
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// funcMap 模板可用的函数。刻意不提供读取文件、环境变量或执行命令的函数，
+// 时区只接受固定偏移，避免依赖宿主机的时区数据库与 TZ 环境变量。
+var (
+	titleCaser = cases.Title(language.Und)
+
+	funcMap = template.FuncMap{
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"title": func(s string) string {
+			return titleCaser.String(s)
+		},
+		"trim": strings.TrimSpace,
+		"join": strings.Join,
+		"now":  time.Now,
+		"toJSON": func(v any) (string, error) {
+			buf, err := json.MarshalIndent(v, "", "  ")
+			if err != nil {
+				return "", err
+			}
+			return string(buf), nil
+		},
+		"urlquery": func(v string) string {
+			return url.QueryEscape(v)
+		},
+		"b64enc":       b64enc,
+		"b64dec":       b64dec,
+		"toYAML":       toYAML,
+		"humanBytes":   humanBytes,
+		"formatTime":   formatTime,
+		"dict":         dict,
+		"list":         list,
+		"default":      defaultValue,
+		"coalesce":     coalesce,
+		"sha256":       sha256Hex,
+		"regexReplace": regexReplace,
+		"uuidv5":       uuidv5,
+	}
+)
+
+// b64enc 标准 base64 编码。
+func b64enc(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// b64dec 解码 base64，兼容 URL 安全字符与缺省填充。
+func b64dec(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	s = strings.NewReplacer("-", "+", "_", "/").Replace(s)
+	s = strings.TrimRight(s, "=")
+	decoded, err := base64.RawStdEncoding.DecodeString(s)
+	if err != nil {
+		return "", fmt.Errorf("b64dec: %w", err)
+	}
+	return string(decoded), nil
+}
+
+// toYAML 以两空格缩进输出 YAML，去除末尾换行便于嵌入模板。
+func toYAML(v any) (string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// humanBytes 将字节数格式化为 1024 进制单位，如 1.50 GiB。
+func humanBytes(v any) string {
+	size := float64(intValue(v))
+	units := []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB"}
+	sign := ""
+	if size < 0 {
+		sign = "-"
+		size = -size
+	}
+	unit := 0
+	for size >= 1024 && unit < len(units)-1 {
+		size /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%s%d B", sign, int64(size))
+	}
+	return fmt.Sprintf("%s%.2f %s", sign, size, units[unit])
+}
+
+// formatTime 按 Go 时间布局格式化时间，value 可为 time.Time、RFC3339 字符串或 Unix 秒；
+// zone 可选，仅支持 UTC 与 +08:00、-0530、UTC+8 形式的固定偏移。
+func formatTime(layout string, value any, zone ...string) (string, error) {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v == nil {
+			return "", nil
+		}
+		t = *v
+	case string:
+		parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(v))
+		if err != nil {
+			return "", fmt.Errorf("formatTime: %w", err)
+		}
+		t = parsed
+	default:
+		if !isNumber(value) {
+			return "", fmt.Errorf("formatTime: unsupported value %T", value)
+		}
+		t = time.Unix(int64(intValue(value)), 0)
+	}
+
+	loc := time.UTC
+	if len(zone) > 0 {
+		parsed, err := fixedZone(zone[0])
+		if err != nil {
+			return "", err
+		}
+		loc = parsed
+	}
+	return t.In(loc).Format(layout), nil
+}
+
+func fixedZone(zone string) (*time.Location, error) {
+	name := strings.TrimSpace(zone)
+	offset := strings.TrimPrefix(strings.TrimPrefix(strings.ToUpper(name), "UTC"), "GMT")
+	if offset == "" || offset == "Z" {
+		return time.UTC, nil
+	}
+	if len(offset) < 2 || (offset[0] != '+' && offset[0] != '-') {
+		return nil, fmt.Errorf("formatTime: unsupported zone %q, use a fixed offset such as +08:00", zone)
+	}
+	digits := strings.ReplaceAll(offset[1:], ":", "")
+	var hours, minutes int
+	var err error
+	switch {
+	case len(digits) <= 2:
+		hours, err = strconv.Atoi(digits)
+	case len(digits) == 4:
+		hours, err = strconv.Atoi(digits[:2])
+		if err == nil {
+			minutes, err = strconv.Atoi(digits[2:])
+		}
+	default:
+		err = fmt.Errorf("invalid offset")
+	}
+	if err != nil || hours > 14 || minutes > 59 {
+		return nil, fmt.Errorf("formatTime: unsupported zone %q, use a fixed offset such as +08:00", zone)
+	}
+	seconds := hours*3600 + minutes*60
+	if offset[0] == '-' {
+		seconds = -seconds
+	}
+	return time.FixedZone(name, seconds), nil
+}
+
+// dict 以键值对构造 map，键须为字符串。
+func dict(pairs ...any) (map[string]any, error) {
+	if len(pairs)%2 != 0 {
+		return nil, fmt.Errorf("dict: odd number of arguments")
+	}
+	result := make(map[string]any, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key, ok := pairs[i].(string)
+		if !ok {
+			return nil, fmt.Errorf("dict: key %v is not a string", pairs[i])
+		}
+		result[key] = pairs[i+1]
+	}
+	return result, nil
+}
+
+// list 将参数收集为切片。
+func list(items ...any) []any {
+	return append([]any{}, items...)
+}
+
+// defaultValue 在 value 为空值时返回 fallback，用法：{{ .x | default "y" }}。
+func defaultValue(fallback any, value ...any) any {
+	if len(value) == 0 || isEmpty(value[0]) {
+		return fallback
+	}
+	return value[0]
+}
+
+// coalesce 返回第一个非空参数，全部为空时返回 nil。
+func coalesce(values ...any) any {
+	for _, value := range values {
+		if !isEmpty(value) {
+			return value
+		}
+	}
+	return nil
+}
+
+// sha256Hex 返回字符串 SHA-256 的十六进制摘要。
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// regexReplace 使用 RE2 正则替换（线性时间，无回溯），用法：{{ .name | regexReplace "\\s+" "-" }}。
+func regexReplace(pattern, replacement, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("regexReplace: %w", err)
+	}
+	return re.ReplaceAllString(s, replacement), nil
+}
+
+// uuidv5 基于命名空间生成确定性 UUID；namespace 可为 UUID 或 dns、url、oid、x500。
+func uuidv5(namespace, name string) (string, error) {
+	var ns uuid.UUID
+	switch strings.ToLower(strings.TrimSpace(namespace)) {
+	case "dns":
+		ns = uuid.NameSpaceDNS
+	case "url":
+		ns = uuid.NameSpaceURL
+	case "oid":
+		ns = uuid.NameSpaceOID
+	case "x500":
+		ns = uuid.NameSpaceX500
+	default:
+		parsed, err := uuid.Parse(namespace)
+		if err != nil {
+			return "", fmt.Errorf("uuidv5: invalid namespace %q", namespace)
+		}
+		ns = parsed
+	}
+	return uuid.NewSHA1(ns, []byte(name)).String(), nil
+}
+
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	default:
+		return value.IsZero()
+	}
+}
+
+func isNumber(v any) bool {
+	if _, ok := v.(json.Number); ok {
+		return true
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
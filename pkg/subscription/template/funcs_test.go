@@ -0,0 +1,154 @@
+package template
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func renderString(t *testing.T, content string, data map[string]any) string {
+	t.Helper()
+	got, err := Render(FormatGoTemplate, content, data)
+	if err != nil {
+		t.Fatalf("render %q: %v", content, err)
+	}
+	return got
+}
+
+func TestFuncStringHelpers(t *testing.T) {
+	data := map[string]any{"tags": []string{"asia", "hk"}, "node": map[string]any{"name": "hk"}}
+	got := renderString(t, `{{ upper "hk" }}|{{ lower "HK" }}|{{ title "hong kong" }}|{{ trim "  x " }}|{{ join .tags "," }}|{{ urlquery "a b&c" }}`, data)
+	if got != "HK|hk|Hong Kong|x|asia,hk|a+b%26c" {
+		t.Fatalf("string helpers = %q", got)
+	}
+	if got := renderString(t, `{{ toJSON .node }}`, data); got != "{\n  \"name\": \"hk\"\n}" {
+		t.Fatalf("toJSON = %q", got)
+	}
+	if got := renderString(t, `{{ now.Year }}`, nil); got != time.Now().Format("2006") {
+		t.Fatalf("now = %q", got)
+	}
+}
+
+func TestFuncB64(t *testing.T) {
+	if got := renderString(t, `{{ b64enc "user:pass" }}`, nil); got != "dXNlcjpwYXNz" {
+		t.Fatalf("b64enc = %q", got)
+	}
+	if got := renderString(t, `{{ "dXNlcjpwYXNz" | b64dec }}|{{ "aGk_" | b64dec }}|{{ "aGk" | b64dec }}`, nil); got != "user:pass|hi?|hi" {
+		t.Fatalf("b64dec = %q", got)
+	}
+	if _, err := Render(FormatGoTemplate, `{{ b64dec "***" }}`, nil); err == nil {
+		t.Fatalf("expected b64dec error")
+	}
+}
+
+func TestFuncToYAML(t *testing.T) {
+	got := renderString(t, `{{ toYAML (dict "name" "hk" "ports" (list 443 8443)) }}`, nil)
+	want := "name: hk\nports:\n  - 443\n  - 8443"
+	if got != want {
+		t.Fatalf("toYAML = %q, want %q", got, want)
+	}
+}
+
+func TestFuncHumanBytes(t *testing.T) {
+	data := map[string]any{"used": int64(1536), "total": int64(100 << 30)}
+	got := renderString(t, `{{ humanBytes 512 }}|{{ humanBytes .used }}|{{ humanBytes .total }}|{{ humanBytes -2048 }}`, data)
+	if got != "512 B|1.50 KiB|100.00 GiB|-2.00 KiB" {
+		t.Fatalf("humanBytes = %q", got)
+	}
+}
+
+func TestFuncFormatTime(t *testing.T) {
+	data := map[string]any{
+		"expires": "2024-05-01T16:30:00Z",
+		"at":      time.Date(2024, 5, 1, 16, 30, 0, 0, time.UTC),
+		"unix":    int64(1714581000),
+	}
+	got := renderString(t, `{{ formatTime "2006-01-02 15:04" .expires "+08:00" }}|{{ formatTime "15:04 -0700" .at "UTC-0530" }}|{{ formatTime "2006-01-02T15:04Z07:00" .unix }}`, data)
+	if got != "2024-05-02 00:30|11:00 -0530|2024-05-01T16:30Z" {
+		t.Fatalf("formatTime = %q", got)
+	}
+	for _, content := range []string{
+		`{{ formatTime "2006" .expires "Asia/Shanghai" }}`,
+		`{{ formatTime "2006" "yesterday" }}`,
+		`{{ formatTime "2006" true }}`,
+	} {
+		if _, err := Render(FormatGoTemplate, content, data); err == nil {
+			t.Fatalf("expected formatTime error for %s", content)
+		}
+	}
+}
+
+func TestFuncDictAndList(t *testing.T) {
+	got := renderString(t, `{{ $d := dict "a" 1 "b" (list "x" "y") }}{{ $d.a }}-{{ index $d.b 1 }}-{{ len (list 1 2 3) }}`, nil)
+	if got != "1-y-3" {
+		t.Fatalf("dict/list = %q", got)
+	}
+	if _, err := Render(FormatGoTemplate, `{{ dict "a" }}`, nil); err == nil {
+		t.Fatalf("expected odd argument error")
+	}
+	if _, err := Render(FormatGoTemplate, `{{ dict 1 2 }}`, nil); err == nil {
+		t.Fatalf("expected non-string key error")
+	}
+}
+
+func TestFuncDefault(t *testing.T) {
+	data := map[string]any{"empty": "", "zero": 0, "name": "hk", "items": []string{}}
+	got := renderString(t, `{{ .empty | default "x" }}|{{ .zero | default 7 }}|{{ .name | default "x" }}|{{ .items | default "none" }}`, data)
+	if got != "x|7|hk|none" {
+		t.Fatalf("default = %q", got)
+	}
+}
+
+func TestFuncCoalesce(t *testing.T) {
+	data := map[string]any{"a": "", "b": nil, "c": "third"}
+	if got := renderString(t, `{{ coalesce .a .b .c "fallback" }}`, data); got != "third" {
+		t.Fatalf("coalesce = %q", got)
+	}
+	if got := renderString(t, `{{ coalesce .a .b }}`, data); got != "<no value>" {
+		t.Fatalf("coalesce all empty = %q", got)
+	}
+}
+
+func TestFuncSHA256(t *testing.T) {
+	got := renderString(t, `{{ sha256 "abc" }}`, nil)
+	if got != "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad" {
+		t.Fatalf("sha256 = %q", got)
+	}
+}
+
+func TestFuncRegexReplace(t *testing.T) {
+	if got := renderString(t, `{{ "HK  01 Premium" | regexReplace "\\s+" "-" | lower }}`, nil); got != "hk-01-premium" {
+		t.Fatalf("regexReplace = %q", got)
+	}
+	if got := renderString(t, `{{ regexReplace "(\\w+)@(\\w+)" "$2/$1" "user@host" }}`, nil); got != "host/user" {
+		t.Fatalf("regexReplace groups = %q", got)
+	}
+	if _, err := Render(FormatGoTemplate, `{{ regexReplace "(" "" "x" }}`, nil); err == nil {
+		t.Fatalf("expected invalid pattern error")
+	}
+}
+
+func TestFuncUUIDv5(t *testing.T) {
+	got := renderString(t, `{{ uuidv5 "dns" "example.com" }}`, nil)
+	if got != "cfbff0d1-9375-5685-968c-48ce8b15ae17" {
+		t.Fatalf("uuidv5 dns = %q", got)
+	}
+	custom := renderString(t, `{{ .subscription.token | uuidv5 "6ba7b811-9dad-11d1-80b4-00c04fd430c8" }}`, map[string]any{"subscription": map[string]any{"token": "abc"}})
+	if custom != renderString(t, `{{ uuidv5 "url" "abc" }}`, nil) {
+		t.Fatalf("uuid namespace string and alias should match, got %q", custom)
+	}
+	if _, err := Render(FormatGoTemplate, `{{ uuidv5 "nope" "abc" }}`, nil); err == nil {
+		t.Fatalf("expected invalid namespace error")
+	}
+}
+
+func TestFuncMapExcludesHostAccess(t *testing.T) {
+	for name := range funcMap {
+		lowered := strings.ToLower(name)
+		for _, banned := range []string{"env", "file", "exec", "read", "glob", "shell"} {
+			if strings.Contains(lowered, banned) {
+				t.Fatalf("function %q must not access the host", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+// jinjaPrefix 关闭 HTML 转义，订阅内容不是 HTML。
+const (
+	jinjaPrefix = "{% autoescape off %}"
+	jinjaSuffix = "{% endautoescape %}"
+)
+
+// jinjaBannedTags 会读取文件或加载其他模板的标签。
+var jinjaBannedTags = []string{"include", "extends", "import", "ssi"}
+
+var errTemplateLoading = errors.New("subscription template: loading other templates is disabled")
+
+func init() {
+	RegisterEngine(FormatJinja, newJinjaEngine())
+}
+
+// jinjaEngine 基于 pongo2 的 Jinja/Django 风格引擎。模板集不挂载任何文件加载器，
+// 并禁用 include/extends/import/ssi；funcMap 中的部分函数以全局函数提供，如 {{ b64enc(subscription.token) }}。
+type jinjaEngine struct {
+	set *pongo2.TemplateSet
+}
+
+func newJinjaEngine() *jinjaEngine {
+	set := pongo2.NewSet("subscription", denyLoader{})
+	for _, tag := range jinjaBannedTags {
+		if err := set.BanTag(tag); err != nil {
+			panic(fmt.Sprintf("subscription template: ban jinja tag %s: %v", tag, err))
+		}
+	}
+	set.Globals = pongo2.Context{
+		"b64enc":     b64enc,
+		"b64dec":     b64dec,
+		"toJSON":     funcMap["toJSON"],
+		"toYAML":     toYAML,
+		"humanBytes": humanBytes,
+		"formatTime": formatTime,
+		"sha256":     sha256Hex,
+		"uuidv5":     uuidv5,
+	}
+	return &jinjaEngine{set: set}
+}
+
+func (e *jinjaEngine) Parse(content string) error {
+	_, err := e.compile(content)
+	return err
+}
+
+func (e *jinjaEngine) Execute(w io.Writer, content string, data map[string]any) error {
+	tpl, err := e.compile(content)
+	if err != nil {
+		return err
+	}
+	return executeAborting(tpl, w, data)
+}
+
+// executeAborting 执行模板，首次写出失败时中止渲染并返回该错误。
+// pongo2 忽略写出错误且会继续执行循环，abortWriter 以 panic 跳出执行，pongo2 内部不做 recover。
+func executeAborting(tpl *pongo2.Template, w io.Writer, data map[string]any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			abort, ok := r.(writeAbort)
+			if !ok {
+				panic(r)
+			}
+			err = abort.err
+		}
+	}()
+	if err := tpl.ExecuteWriterUnbuffered(pongo2.Context(data), abortWriter{w: w}); err != nil {
+		return jinjaError(err)
+	}
+	return nil
+}
+
+func (e *jinjaEngine) compile(content string) (*pongo2.Template, error) {
+	tpl, err := e.set.FromString(jinjaPrefix + content + jinjaSuffix)
+	if err != nil {
+		return nil, jinjaError(err)
+	}
+	return tpl, nil
+}
+
+// jinjaError 扣除首行前缀带来的列偏移，使 ErrorPosition 指向原始模板位置。
+func jinjaError(err error) error {
+	var perr *pongo2.Error
+	if !errors.As(err, &perr) {
+		return err
+	}
+	if perr.Line == 1 && perr.Column > len(jinjaPrefix) {
+		perr.Column -= len(jinjaPrefix)
+	}
+	return perr
+}
+
+// denyLoader 拒绝加载任何外部模板。
+type denyLoader struct{}
+
+func (denyLoader) Abs(_, name string) string { return name }
+
+func (denyLoader) Get(string) (io.Reader, error) { return nil, errTemplateLoading }
+
+// writeAbort 携带中止渲染的写出错误。
+type writeAbort struct {
+	err error
+}
+
+// abortWriter 写出失败时以 writeAbort panic，由 executeAborting 恢复。
+type abortWriter struct {
+	w io.Writer
+}
+
+func (a abortWriter) Write(p []byte) (int, error) {
+	n, err := a.w.Write(p)
+	if err != nil {
+		panic(writeAbort{err: err})
+	}
+	return n, nil
+}
@@ -0,0 +1,113 @@
+package template
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestJinjaEngineRendersSharedContext(t *testing.T) {
+	content := `{% for node in nodes %}{% for k in node.kernels %}{{ node.name }}|{{ k.protocol|upper }}|{{ k.host }}:{{ k.port }}
+{% endfor %}{% endfor %}{{ subscription.name|default:"none" }} <{{ b64enc(subscription.token) }}>`
+
+	got, err := Render("jinja2", content, builtinTestContext())
+	if err != nil {
+		t.Fatalf("render jinja: %v", err)
+	}
+	want := "HK 01|VMESS|hk.example.com:443\nHK 01|TROJAN|hk.example.com:8443\nJP|VLESS|jp.example.com:443\n" +
+		"US|SS|us.example.com:8388\nUS|HTTP|us.example.com:80\nUS|VMESS|us.example.com:0\n" +
+		"Premium <" + b64enc("0123456789abcdef0123456789abcdef") + ">"
+	if got != want {
+		t.Fatalf("jinja output mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	// 订阅内容不是 HTML，不做转义。
+	got, err = Render(FormatJinja, `{{ value }}`, map[string]any{"value": "a&b <c>"})
+	if err != nil || got != "a&b <c>" {
+		t.Fatalf("autoescape should be off, got %q (%v)", got, err)
+	}
+}
+
+func TestJinjaEngineSandbox(t *testing.T) {
+	for _, content := range []string{
+		`{% include "/etc/passwd" %}`,
+		`{% ssi "/etc/passwd" %}`,
+		`{% extends "base.html" %}`,
+		`{% import "macros.html" m %}`,
+	} {
+		if _, err := Render(FormatJinja, content, nil); err == nil {
+			t.Fatalf("expected %s to be rejected", content)
+		}
+	}
+
+	_, err := RenderLimited(FormatJinja, `{% for i in items %}0123456789{% endfor %}`, map[string]any{"items": make([]int, 500)}, nil, Limits{MaxBytes: 100})
+	if !errors.Is(err, ErrOutputTooLarge) {
+		t.Fatalf("expected output limit, got %v", err)
+	}
+}
+
+func TestJinjaErrorPosition(t *testing.T) {
+	_, err := Render(FormatJinja, "{{ a }} {% if %}", nil)
+	if line, column := ErrorPosition(err); line != 1 || column != 12 {
+		t.Fatalf("first line error position = %d:%d (%v)", line, column, err)
+	}
+	_, err = Render(FormatJinja, "ok\n  {% endfor %}", nil)
+	if line, _ := ErrorPosition(err); line != 2 {
+		t.Fatalf("second line error position = %d (%v)", line, err)
+	}
+}
+
+func TestValidateChecksEngineSyntax(t *testing.T) {
+	if err := Validate(FormatJinja, `{{ subscription.name }}`, nil, []string{"subscription"}); err != nil {
+		t.Fatalf("valid jinja: %v", err)
+	}
+	if err := Validate(FormatJinja, `{% for x in %}`, nil, nil); err == nil {
+		t.Fatalf("expected jinja syntax error")
+	}
+	if err := Validate(FormatJinja, "  ", nil, nil); err == nil || !strings.Contains(err.Error(), "content is required") {
+		t.Fatalf("expected content required error, got %v", err)
+	}
+	if err := Validate("xml", "x", nil, nil); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+	if err := Validate(FormatJSON, "", nil, nil); err != nil {
+		t.Fatalf("json needs no content: %v", err)
+	}
+}
+
+type upperEngine struct{}
+
+func (upperEngine) Parse(content string) error {
+	if strings.Contains(content, "!") {
+		return errors.New("bang not allowed")
+	}
+	return nil
+}
+
+func (upperEngine) References(string) ([]string, error) { return nil, nil }
+
+func (upperEngine) Execute(w io.Writer, content string, data map[string]any) error {
+	_, err := io.WriteString(w, strings.ToUpper(content)+stringValue(data["suffix"]))
+	return err
+}
+
+func TestRegisterEngine(t *testing.T) {
+	const format = "unit-upper"
+	RegisterEngine(" Unit-Upper ", upperEngine{})
+	defer delete(engines, format)
+
+	if !SupportedFormat(format) || !UsesContent(format) || IsBuiltinFormat(format) {
+		t.Fatalf("registered engine should be a supported content format")
+	}
+	got, err := Render(format, "hello", map[string]any{"suffix": "?"})
+	if err != nil || got != "HELLO?" {
+		t.Fatalf("render = %q, %v", got, err)
+	}
+	if err := Validate(format, "hi!", nil, nil); err == nil {
+		t.Fatalf("expected engine parse error")
+	}
+	if SupportedFormat("unit-missing") || UsesContent("clash") {
+		t.Fatalf("unexpected format support")
+	}
+}
@@ -0,0 +1,258 @@
+package template
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// jinjaKeywords 表达式中的关键字与字面量，不视为变量引用。
+var jinjaKeywords = map[string]struct{}{
+	"and": {}, "or": {}, "not": {}, "in": {}, "is": {}, "as": {},
+	"true": {}, "false": {}, "True": {}, "False": {}, "none": {}, "None": {}, "nil": {},
+	"reversed": {}, "sorted": {}, "export": {},
+}
+
+// jinjaOpaqueTags 参数不是变量表达式的标签。
+var jinjaOpaqueTags = map[string]struct{}{
+	"autoescape": {}, "endautoescape": {}, "block": {}, "endblock": {}, "filter": {}, "endfilter": {},
+	"lorem": {}, "now": {}, "templatetag": {}, "spaceless": {}, "endspaceless": {},
+}
+
+// jinjaSkippedBlocks 内容不参与渲染的块标签及其结束标签。
+var jinjaSkippedBlocks = map[string]*regexp.Regexp{
+	"comment":  regexp.MustCompile(`\{%-?\s*endcomment\s*-?%\}`),
+	"verbatim": regexp.MustCompile(`\{%-?\s*endverbatim\s*-?%\}`),
+}
+
+// References 返回 jinja 模板从根上下文引用的变量路径（去重排序）。
+// pongo2 不公开语法树，语法校验通过后逐个扫描 {{ }} 与 {% %} 中的表达式：
+// for/with/set/macro 绑定的名称、forloop、全局函数与过滤器名不计入，数字下标及之后的路径段被截去。
+func (e *jinjaEngine) References(content string) ([]string, error) {
+	if _, err := e.compile(content); err != nil {
+		return nil, err
+	}
+
+	scanner := &jinjaScanner{globals: e.set.Globals, scopes: []map[string]struct{}{{}}, seen: map[string]struct{}{}}
+	scanner.scan(content)
+
+	refs := make([]string, 0, len(scanner.seen))
+	for ref := range scanner.seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+type jinjaTokenKind int
+
+const (
+	jinjaIdent jinjaTokenKind = iota
+	jinjaLiteral
+	jinjaSymbol
+)
+
+type jinjaToken struct {
+	kind jinjaTokenKind
+	text string
+}
+
+// jinjaScanner 记录作用域中绑定的名称与收集到的根上下文引用。
+type jinjaScanner struct {
+	globals map[string]any
+	scopes  []map[string]struct{}
+	seen    map[string]struct{}
+}
+
+func (s *jinjaScanner) scan(content string) {
+	for pos := 0; pos < len(content); {
+		open := strings.IndexByte(content[pos:], '{')
+		if open < 0 || pos+open+1 >= len(content) {
+			return
+		}
+		pos += open
+		switch content[pos+1] {
+		case '#':
+			end := strings.Index(content[pos:], "#}")
+			if end < 0 {
+				return
+			}
+			pos += end + 2
+		case '{':
+			tokens, next := tokenizeJinja(content, pos+2, "}}")
+			s.collect(tokens)
+			pos = next
+		case '%':
+			tokens, next := tokenizeJinja(content, pos+2, "%}")
+			pos = next
+			if len(tokens) == 0 || tokens[0].kind != jinjaIdent {
+				continue
+			}
+			if end, ok := jinjaSkippedBlocks[tokens[0].text]; ok {
+				loc := end.FindStringIndex(content[pos:])
+				if loc == nil {
+					return
+				}
+				pos += loc[1]
+				continue
+			}
+			s.tag(tokens[0].text, tokens[1:])
+		default:
+			pos++
+		}
+	}
+}
+
+// tag 处理标签：绑定名称的标签开启或更新作用域，其余标签按表达式收集引用。
+func (s *jinjaScanner) tag(name string, args []jinjaToken) {
+	if _, ok := jinjaOpaqueTags[name]; ok {
+		return
+	}
+	switch name {
+	case "for":
+		scope := map[string]struct{}{"forloop": {}}
+		for i, tok := range args {
+			if tok.kind == jinjaIdent && tok.text == "in" {
+				s.collect(args[i+1:])
+				break
+			}
+			if tok.kind == jinjaIdent {
+				scope[tok.text] = struct{}{}
+			}
+		}
+		s.scopes = append(s.scopes, scope)
+	case "with":
+		scope := map[string]struct{}{}
+		var exprs []jinjaToken
+		for i := 0; i < len(args); i++ {
+			tok := args[i]
+			switch {
+			case tok.kind == jinjaIdent && tok.text == "as" && i+1 < len(args):
+				scope[args[i+1].text] = struct{}{}
+				i++
+			case tok.kind == jinjaIdent && i+1 < len(args) && args[i+1].text == "=":
+				scope[tok.text] = struct{}{}
+				i++
+			default:
+				exprs = append(exprs, tok)
+			}
+		}
+		s.collect(exprs)
+		s.scopes = append(s.scopes, scope)
+	case "set":
+		if len(args) > 0 && args[0].kind == jinjaIdent {
+			s.collect(args[1:])
+			s.scopes[len(s.scopes)-1][args[0].text] = struct{}{}
+		}
+	case "macro":
+		scope := map[string]struct{}{}
+		if len(args) > 0 && args[0].kind == jinjaIdent {
+			s.scopes[len(s.scopes)-1][args[0].text] = struct{}{}
+		}
+		for i, tok := range args {
+			if tok.kind == jinjaIdent && i > 0 && (args[i-1].text == "(" || args[i-1].text == ",") {
+				scope[tok.text] = struct{}{}
+			}
+		}
+		s.scopes = append(s.scopes, scope)
+	case "endfor", "endwith", "endmacro":
+		if len(s.scopes) > 1 {
+			s.scopes = s.scopes[:len(s.scopes)-1]
+		}
+	default:
+		s.collect(args)
+	}
+}
+
+// collect 收集表达式中从根上下文引用的路径。
+func (s *jinjaScanner) collect(tokens []jinjaToken) {
+	for i, tok := range tokens {
+		if tok.kind != jinjaIdent {
+			continue
+		}
+		if _, ok := jinjaKeywords[tok.text]; ok {
+			continue
+		}
+		if i > 0 && tokens[i-1].text == "|" {
+			continue
+		}
+		segments := strings.Split(tok.text, ".")
+		if i+1 < len(tokens) && tokens[i+1].text == "(" && len(segments) == 1 {
+			if _, ok := s.globals[tok.text]; ok {
+				continue
+			}
+		}
+		path := make([]string, 0, len(segments))
+		for _, segment := range segments {
+			if segment == "" || unicode.IsDigit(rune(segment[0])) {
+				break
+			}
+			path = append(path, segment)
+		}
+		if len(path) == 0 || s.bound(path[0]) {
+			continue
+		}
+		s.seen[strings.Join(path, ".")] = struct{}{}
+	}
+}
+
+func (s *jinjaScanner) bound(name string) bool {
+	for _, scope := range s.scopes {
+		if _, ok := scope[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeJinja 从 pos 开始切分表达式直至字符串外的 end，返回记号与 end 之后的位置。
+func tokenizeJinja(content string, pos int, end string) ([]jinjaToken, int) {
+	if pos < len(content) && content[pos] == '-' {
+		pos++
+	}
+	var tokens []jinjaToken
+	for pos < len(content) {
+		if strings.HasPrefix(content[pos:], end) {
+			return tokens, pos + len(end)
+		}
+		c := content[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+		case c == '"' || c == '\'':
+			start := pos
+			pos++
+			for pos < len(content) && content[pos] != c {
+				if content[pos] == '\\' {
+					pos++
+				}
+				pos++
+			}
+			pos++
+			if pos > len(content) {
+				pos = len(content)
+			}
+			tokens = append(tokens, jinjaToken{kind: jinjaLiteral, text: content[start:pos]})
+		case isJinjaIdentByte(c):
+			start := pos
+			for pos < len(content) && (isJinjaIdentByte(content[pos]) || (content[pos] == '.' && pos+1 < len(content) && isJinjaIdentByte(content[pos+1]))) {
+				pos++
+			}
+			text := content[start:pos]
+			kind := jinjaIdent
+			if c >= '0' && c <= '9' {
+				kind = jinjaLiteral
+			}
+			tokens = append(tokens, jinjaToken{kind: kind, text: text})
+		default:
+			tokens = append(tokens, jinjaToken{kind: jinjaSymbol, text: string(c)})
+			pos++
+		}
+	}
+	return tokens, pos
+}
+
+func isJinjaIdentByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,173 @@
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"text/template/parse"
+	"time"
+
+	"github.com/flosch/pongo2/v6"
+)
+
+var (
+	// ErrRenderTimeout 渲染超过时间限制。
+	ErrRenderTimeout = errors.New("subscription template: render timed out")
+	// ErrOutputTooLarge 渲染结果超过大小限制。
+	ErrOutputTooLarge = errors.New("subscription template: output exceeds size limit")
+)
+
+// maxRangeIterations go_template 中整数字面量 range（含嵌套相乘）允许的最大循环次数。
+const maxRangeIterations = 100000
+
+// Limits 渲染的沙箱限制，零值表示不限制。
+type Limits struct {
+	Timeout  time.Duration
+	MaxBytes int
+}
+
+// RenderLimited 在时间与输出大小限制内执行 RenderWithVariables。
+// 写出超过 MaxBytes 时引擎立即中止并返回 ErrOutputTooLarge；设置 Timeout 时渲染在独立 goroutine 中执行，
+// 到期即返回 ErrRenderTimeout，不依赖模板是否产生输出，仍在执行的渲染在下次写出时中止。
+// go_template 中循环次数过大的整数字面量 range 在解析时即被拒绝。
+func RenderLimited(format, content string, data map[string]any, vars map[string]Variable, limits Limits) (string, error) {
+	return renderWithVariables(format, content, data, vars, limits)
+}
+
+// executeWithin 在独立 goroutine 中执行引擎，超过 timeout 时立即返回 ErrRenderTimeout；
+// 引擎 panic 转为错误返回，避免后台 goroutine 崩溃进程。
+func executeWithin(engine Engine, w io.Writer, content string, data map[string]any, timeout time.Duration) error {
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("subscription template: render panic: %v", r)
+			}
+		}()
+		done <- engine.Execute(w, content, data)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return ErrRenderTimeout
+	}
+}
+
+// checkRangeLiterals 检查以整数字面量为对象的 range，嵌套时循环次数相乘，超过 maxRangeIterations 返回带位置的错误。
+func checkRangeLiterals(tree *parse.Tree, node parse.Node, iterations int64) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkRangeLiterals(tree, child, iterations); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		return checkBranches(tree, n.List, n.ElseList, iterations)
+	case *parse.WithNode:
+		return checkBranches(tree, n.List, n.ElseList, iterations)
+	case *parse.RangeNode:
+		inner := iterations
+		if count, ok := rangeLiteral(n.Pipe); ok {
+			inner *= max(count, 1)
+			if count > maxRangeIterations || inner > maxRangeIterations {
+				location, _ := tree.ErrorContext(n)
+				return fmt.Errorf("template: %s: range over %d exceeds the limit of %d iterations", location, count, maxRangeIterations)
+			}
+		}
+		if err := checkRangeLiterals(tree, n.List, inner); err != nil {
+			return err
+		}
+		return checkRangeLiterals(tree, n.ElseList, iterations)
+	}
+	return nil
+}
+
+func checkBranches(tree *parse.Tree, list, elseList *parse.ListNode, iterations int64) error {
+	if err := checkRangeLiterals(tree, list, iterations); err != nil {
+		return err
+	}
+	return checkRangeLiterals(tree, elseList, iterations)
+}
+
+// rangeLiteral 返回 range 对象为单个整数字面量时的循环次数。
+func rangeLiteral(pipe *parse.PipeNode) (int64, bool) {
+	if pipe == nil || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return 0, false
+	}
+	number, ok := pipe.Cmds[0].Args[0].(*parse.NumberNode)
+	if !ok || !number.IsInt {
+		return 0, false
+	}
+	return number.Int64, true
+}
+
+// errorPosition 匹配 text/template 错误中的 "template: name:line:col:" 位置前缀。
+var errorPosition = regexp.MustCompile(`template: [^:\s]+:(\d+)(?::(\d+))?:`)
+
+// ErrorPosition 从 go_template 或 jinja 模板的解析、执行错误中提取行号与列号，无法识别时返回 0。
+func ErrorPosition(err error) (line, column int) {
+	if err == nil {
+		return 0, 0
+	}
+	var jinjaErr *pongo2.Error
+	if errors.As(err, &jinjaErr) {
+		return jinjaErr.Line, jinjaErr.Column
+	}
+	match := errorPosition.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0, 0
+	}
+	line, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		column, _ = strconv.Atoi(match[2])
+	}
+	return line, column
+}
+
+// limitedBuffer 超过 limit 字节或 deadline 后拒绝写入，零值不限制；
+// 超时后仍在后台执行的渲染借此在下次写出时中止。
+type limitedBuffer struct {
+	bytes.Buffer
+	limit    int
+	deadline time.Time
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if err := b.check(len(p)); err != nil {
+		return 0, err
+	}
+	return b.Buffer.Write(p)
+}
+
+// WriteString 覆盖 bytes.Buffer.WriteString，避免 io.WriteString 绕过限制。
+func (b *limitedBuffer) WriteString(s string) (int, error) {
+	if err := b.check(len(s)); err != nil {
+		return 0, err
+	}
+	return b.Buffer.WriteString(s)
+}
+
+func (b *limitedBuffer) check(n int) error {
+	if b.limit > 0 && b.Len()+n > b.limit {
+		return ErrOutputTooLarge
+	}
+	if b.expired() {
+		return ErrRenderTimeout
+	}
+	return nil
+}
+
+func (b *limitedBuffer) expired() bool {
+	return !b.deadline.IsZero() && time.Now().After(b.deadline)
+}
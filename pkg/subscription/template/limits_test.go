@@ -0,0 +1,100 @@
+package template
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderLimited(t *testing.T) {
+	data := map[string]any{"items": make([]int, 5000)}
+
+	start := time.Now()
+	_, err := RenderLimited(FormatGoTemplate, `{{ range .items }}{{ range $.items }}.{{ end }}{{ end }}`, data, nil, Limits{Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, ErrRenderTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	// 渲染在写出时被中止，而不是在后台继续执行。
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("render was not aborted, took %s", elapsed)
+	}
+	start = time.Now()
+	_, err = RenderLimited(FormatJinja, `{% for a in items %}{% for b in items %}.{% endfor %}{% endfor %}`, data, nil, Limits{Timeout: 20 * time.Millisecond})
+	if !errors.Is(err, ErrRenderTimeout) {
+		t.Fatalf("expected jinja timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("jinja render was not aborted, took %s", elapsed)
+	}
+
+	_, err = RenderLimited(FormatGoTemplate, `{{ range .items }}0123456789{{ end }}`, data, nil, Limits{MaxBytes: 1024})
+	if !errors.Is(err, ErrOutputTooLarge) {
+		t.Fatalf("expected output limit, got %v", err)
+	}
+	_, err = RenderLimited(FormatJinja, `{% for a in items %}0123456789{% endfor %}`, data, nil, Limits{MaxBytes: 1024})
+	if !errors.Is(err, ErrOutputTooLarge) {
+		t.Fatalf("expected output limit for jinja, got %v", err)
+	}
+	_, err = RenderLimited(FormatJSON, "", data, nil, Limits{MaxBytes: 16})
+	if !errors.Is(err, ErrOutputTooLarge) {
+		t.Fatalf("expected output limit for json, got %v", err)
+	}
+
+	out, err := RenderLimited(FormatGoTemplate, `{{ len .items }}`, data, nil, Limits{Timeout: time.Second, MaxBytes: 16})
+	if err != nil || out != "5000" {
+		t.Fatalf("render = %q, %v", out, err)
+	}
+}
+
+func TestRenderLimitedWithoutOutput(t *testing.T) {
+	// 不产生输出的循环同样在时间限制内返回。
+	data := map[string]any{"items": make([]int, 10000)}
+	start := time.Now()
+	_, err := RenderLimited(FormatGoTemplate, `{{ range .items }}{{ range $.items }}{{ end }}{{ end }}ok`, data, nil, Limits{Timeout: 100 * time.Millisecond})
+	if !errors.Is(err, ErrRenderTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("render did not return within the limit, took %s", elapsed)
+	}
+
+	// 整数字面量 range 在解析时按循环次数拒绝，嵌套时相乘。
+	start = time.Now()
+	_, err = RenderLimited(FormatGoTemplate, `{{range 300000000}}{{end}}ok`, nil, nil, Limits{Timeout: 100 * time.Millisecond})
+	if err == nil || !strings.Contains(err.Error(), "exceeds the limit") {
+		t.Fatalf("expected range limit error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("range literal was executed, took %s", elapsed)
+	}
+	if line, column := ErrorPosition(err); line != 1 || column == 0 {
+		t.Fatalf("range limit position = %d:%d (%v)", line, column, err)
+	}
+	if err := (goTemplateEngine{}).Parse(`{{ define "x" }}{{ range 1000 }}{{ range 1000 }}{{ end }}{{ end }}{{ end }}`); err == nil {
+		t.Fatalf("expected nested range limit error")
+	}
+	out, err := RenderLimited(FormatGoTemplate, `{{ range 3 }}{{ . }}{{ end }}`, nil, nil, Limits{Timeout: time.Second})
+	if err != nil || out != "012" {
+		t.Fatalf("render = %q, %v", out, err)
+	}
+}
+
+func TestErrorPosition(t *testing.T) {
+	_, err := Render(FormatGoTemplate, "line one\n{{ .a }} {{ .b", nil)
+	if line, column := ErrorPosition(err); line != 2 || column != 0 {
+		t.Fatalf("parse error position = %d:%d (%v)", line, column, err)
+	}
+
+	_, err = Render(FormatGoTemplate, "ok\n  {{ index .items 3 }}", map[string]any{"items": []int{1}})
+	if line, column := ErrorPosition(err); line != 2 || column != 5 {
+		t.Fatalf("exec error position = %d:%d (%v)", line, column, err)
+	}
+	if !strings.Contains(err.Error(), "index out of range") {
+		t.Fatalf("unexpected exec error %v", err)
+	}
+
+	if line, column := ErrorPosition(ErrRenderTimeout); line != 0 || column != 0 {
+		t.Fatalf("expected no position for timeout")
+	}
+}
@@ -0,0 +1,266 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// 内置格式支持的代理协议。
+const (
+	ProtocolVMess       = "vmess"
+	ProtocolVLESS       = "vless"
+	ProtocolTrojan      = "trojan"
+	ProtocolShadowsocks = "shadowsocks"
+)
+
+// defaultShadowsocksMethod 内核配置未指定加密方式时使用。
+const defaultShadowsocksMethod = "chacha20-ietf-poly1305"
+
+// proxy 内置格式的归一化代理条目，由渲染上下文中每个节点的内核接入点展开。
+type proxy struct {
+	Name        string
+	Protocol    string
+	Server      string
+	Port        int
+	UUID        string
+	Password    string
+	Method      string
+	AlterID     int
+	Flow        string
+	Network     string
+	Path        string
+	HostHeader  string
+	ServiceName string
+	TLS         bool
+	SNI         string
+	Insecure    bool
+}
+
+// SubscriptionUUID 按旧版规则由 token 派生 UUID：32 位十六进制 token 直接按 UUID 排版，其他 token 使用 UUIDv5 派生。
+// 代理凭据现已按订阅单独保存（subscription.uuid），此函数仅用于为存量订阅回填同样的值。
+func SubscriptionUUID(token string) string {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return ""
+	}
+	if id, err := uuid.Parse(token); err == nil {
+		return id.String()
+	}
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(token)).String()
+}
+
+// collectProxies 从上下文 nodes[].kernels[] 展开代理列表，跳过不支持的协议与缺少地址的接入点。
+// 用户凭据取自 subscription.uuid（与节点用户列表的 proxy_uuid 一致）；节点只有一个可用接入点时以节点名命名，否则追加协议后缀。
+func collectProxies(data map[string]any) []proxy {
+	credential := strings.TrimSpace(stringValue(mapValue(data["subscription"])["uuid"]))
+	proxies := make([]proxy, 0)
+	used := make(map[string]int)
+
+	for _, node := range mapSlice(data["nodes"]) {
+		nodeName := strings.TrimSpace(stringValue(node["name"]))
+		if nodeName == "" {
+			nodeName = "node-" + stringValue(node["id"])
+		}
+
+		entries := make([]proxy, 0)
+		for _, kernel := range mapSlice(node["kernels"]) {
+			item, ok := buildProxy(kernel, credential)
+			if !ok {
+				continue
+			}
+			entries = append(entries, item)
+		}
+
+		for _, item := range entries {
+			name := nodeName
+			if len(entries) > 1 {
+				name = nodeName + "-" + item.Protocol
+			}
+			used[name]++
+			if used[name] > 1 {
+				name = fmt.Sprintf("%s %d", name, used[name])
+			}
+			item.Name = name
+			proxies = append(proxies, item)
+		}
+	}
+
+	return proxies
+}
+
+func buildProxy(kernel map[string]any, credential string) (proxy, bool) {
+	protocol := normalizeProtocol(stringValue(kernel["protocol"]))
+	server := strings.TrimSpace(stringValue(kernel["host"]))
+	port := intValue(kernel["port"])
+	if protocol == "" || server == "" || port <= 0 {
+		return proxy{}, false
+	}
+
+	config := mapValue(kernel["config"])
+	item := proxy{
+		Protocol:    protocol,
+		Server:      server,
+		Port:        port,
+		Network:     strings.ToLower(firstString(config, "network", "transport")),
+		Path:        firstString(config, "path"),
+		HostHeader:  firstString(config, "host"),
+		ServiceName: firstString(config, "service_name", "serviceName"),
+		SNI:         firstString(config, "sni", "server_name"),
+		Insecure:    boolValue(config["insecure"]) || boolValue(config["skip_cert_verify"]),
+		Flow:        firstString(config, "flow"),
+		AlterID:     intValue(config["alter_id"]),
+	}
+	switch item.Network {
+	case "tcp", "ws", "grpc", "http":
+	default:
+		// 未配置或客户端支持不一的传输层（如 quic）按 tcp 处理。
+		item.Network = "tcp"
+	}
+	item.TLS = boolValue(config["tls"]) || strings.EqualFold(firstString(config, "security"), "tls")
+
+	switch protocol {
+	case ProtocolVMess, ProtocolVLESS:
+		item.UUID = credential
+	case ProtocolTrojan:
+		item.Password = credential
+		item.TLS = true
+	case ProtocolShadowsocks:
+		item.Password = credential
+		item.Method = strings.ToLower(firstString(config, "method", "cipher"))
+		if item.Method == "" {
+			item.Method = defaultShadowsocksMethod
+		}
+		item.Network = "tcp"
+		item.TLS = false
+	}
+	if item.Password == "" && item.UUID == "" {
+		return proxy{}, false
+	}
+
+	return item, true
+}
+
+func normalizeProtocol(protocol string) string {
+	switch strings.ToLower(strings.TrimSpace(protocol)) {
+	case "vmess":
+		return ProtocolVMess
+	case "vless":
+		return ProtocolVLESS
+	case "trojan":
+		return ProtocolTrojan
+	case "ss", "shadowsocks":
+		return ProtocolShadowsocks
+	default:
+		return ""
+	}
+}
+
+// tlsServerName 返回 TLS 握手使用的 SNI，未配置时回退到 Host 头或服务器地址。
+func (p proxy) tlsServerName() string {
+	if p.SNI != "" {
+		return p.SNI
+	}
+	if p.HostHeader != "" {
+		return p.HostHeader
+	}
+	return p.Server
+}
+
+func proxyNames(proxies []proxy) []string {
+	names := make([]string, 0, len(proxies))
+	for _, item := range proxies {
+		names = append(names, item.Name)
+	}
+	return names
+}
+
+// 以下辅助函数兼容 subscriptionutil 构造的强类型上下文与 JSON 反序列化得到的上下文。
+
+func mapValue(v any) map[string]any {
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{}
+}
+
+func mapSlice(v any) []map[string]any {
+	switch items := v.(type) {
+	case []map[string]any:
+		return items
+	case []any:
+		result := make([]map[string]any, 0, len(items))
+		for _, item := range items {
+			if m, ok := item.(map[string]any); ok {
+				result = append(result, m)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+func firstString(m map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(stringValue(m[key])); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func stringValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case fmt.Stringer:
+		return value.String()
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func intValue(v any) int {
+	switch value := v.(type) {
+	case int:
+		return value
+	case int32:
+		return int(value)
+	case int64:
+		return int(value)
+	case uint:
+		return int(value)
+	case uint32:
+		return int(value)
+	case uint64:
+		return int(value)
+	case float64:
+		return int(value)
+	case json.Number:
+		n, _ := value.Int64()
+		return int(n)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(value))
+		return n
+	default:
+		return 0
+	}
+}
+
+func boolValue(v any) bool {
+	switch value := v.(type) {
+	case bool:
+		return value
+	case string:
+		parsed, _ := strconv.ParseBool(strings.TrimSpace(value))
+		return parsed
+	default:
+		return false
+	}
+}
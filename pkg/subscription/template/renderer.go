@@ -1,69 +1,110 @@
 package template
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
-	"text/template"
 	"time"
+)
 
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+// 模板格式。go_template 与 jinja 使用模板内容渲染；json 与 clash、sing-box、v2ray-base64、surge
+// 为内置格式，由上下文直接生成，不使用模板内容。
+const (
+	FormatGoTemplate  = "go_template"
+	FormatJinja       = "jinja"
+	FormatJSON        = "json"
+	FormatClash       = "clash"
+	FormatSingBox     = "sing-box"
+	FormatV2RayBase64 = "v2ray-base64"
+	FormatSurge       = "surge"
 )
 
-var (
-	titleCaser = cases.Title(language.Und)
+var builtinRenderers = map[string]func(map[string]any) (string, error){
+	FormatJSON:        renderJSON,
+	FormatClash:       renderClash,
+	FormatSingBox:     renderSingBox,
+	FormatV2RayBase64: renderShareLinks,
+	FormatSurge:       renderSurge,
+}
 
-	funcMap = template.FuncMap{
-		"upper": strings.ToUpper,
-		"lower": strings.ToLower,
-		"title": func(s string) string {
-			return titleCaser.String(s)
-		},
-		"trim": strings.TrimSpace,
-		"join": strings.Join,
-		"now":  time.Now,
-		"toJSON": func(v any) (string, error) {
-			buf, err := json.MarshalIndent(v, "", "  ")
-			if err != nil {
-				return "", err
-			}
-			return string(buf), nil
-		},
-		"urlquery": func(v string) string {
-			return url.QueryEscape(v)
-		},
+// NormalizeFormat 归一化格式名称并展开别名，空值视为 go_template；未知格式原样返回。
+func NormalizeFormat(format string) string {
+	switch value := strings.ToLower(strings.TrimSpace(format)); value {
+	case "", "go_template", "gotemplate", "text/template":
+		return FormatGoTemplate
+	case "jinja2", "pongo2", "django":
+		return FormatJinja
+	case "singbox", "sing_box":
+		return FormatSingBox
+	case "v2ray", "base64", "v2ray_base64":
+		return FormatV2RayBase64
+	default:
+		return value
 	}
-)
+}
 
-// Render 根据模板格式渲染订阅内容。
-func Render(format, content string, data map[string]any) (string, error) {
-	switch strings.ToLower(strings.TrimSpace(format)) {
-	case "", "go_template", "gotemplate", "text/template":
-		return renderGoTemplate(content, data)
-	case "json":
-		buf, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return "", err
-		}
-		return string(buf), nil
+// SupportedFormat 判断格式是否已注册引擎。
+func SupportedFormat(format string) bool {
+	_, ok := lookupEngine(format)
+	return ok
+}
+
+// IsBuiltinFormat 判断是否为无需模板内容的内置格式。
+func IsBuiltinFormat(format string) bool {
+	_, ok := builtinRenderers[NormalizeFormat(format)]
+	return ok
+}
+
+// UsesContent 判断格式是否依赖模板内容，依赖时保存模板须提供 content。
+func UsesContent(format string) bool {
+	return SupportedFormat(format) && !IsBuiltinFormat(format)
+}
+
+// ContentType 返回格式对应的 HTTP Content-Type。
+func ContentType(format string) string {
+	switch NormalizeFormat(format) {
+	case FormatJSON, FormatSingBox:
+		return "application/json"
+	case FormatClash:
+		return "text/yaml; charset=utf-8"
 	default:
-		return "", fmt.Errorf("subscription template: unsupported format %s", format)
+		return "text/plain; charset=utf-8"
 	}
 }
 
-func renderGoTemplate(content string, data map[string]any) (string, error) {
-	tmpl, err := template.New("subscription").Funcs(funcMap).Parse(content)
-	if err != nil {
-		return "", err
+// Render 根据模板格式选择引擎渲染订阅内容。
+func Render(format, content string, data map[string]any) (string, error) {
+	return render(format, content, data, Limits{})
+}
+
+// render 渲染并按 limits 限制输出大小与耗时：写出超限时引擎立即中止，超时由 executeWithin 计时返回。
+func render(format, content string, data map[string]any, limits Limits) (string, error) {
+	engine, ok := lookupEngine(format)
+	if !ok {
+		return "", fmt.Errorf("subscription template: unsupported format %s", NormalizeFormat(format))
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	buf := &limitedBuffer{limit: limits.MaxBytes}
+	if limits.Timeout <= 0 {
+		if err := engine.Execute(buf, content, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+	buf.deadline = time.Now().Add(limits.Timeout)
+	if err := executeWithin(engine, buf, content, data, limits.Timeout); err != nil {
 		return "", err
 	}
-
+	if buf.expired() {
+		return "", ErrRenderTimeout
+	}
 	return buf.String(), nil
 }
+
+func renderJSON(data map[string]any) (string, error) {
+	buf, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
@@ -0,0 +1,122 @@
+package template
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// vmessShare v2rayN 约定的 vmess:// 分享链接 JSON 结构，字段顺序即输出顺序。
+type vmessShare struct {
+	V    string `json:"v"`
+	PS   string `json:"ps"`
+	Add  string `json:"add"`
+	Port string `json:"port"`
+	ID   string `json:"id"`
+	Aid  string `json:"aid"`
+	Scy  string `json:"scy"`
+	Net  string `json:"net"`
+	Type string `json:"type"`
+	Host string `json:"host"`
+	Path string `json:"path"`
+	TLS  string `json:"tls"`
+	SNI  string `json:"sni"`
+}
+
+// renderShareLinks 输出 V2Ray 系客户端通用的订阅：每行一个 vmess/vless/trojan/ss 分享链接，整体 base64 编码。
+func renderShareLinks(data map[string]any) (string, error) {
+	proxies := collectProxies(data)
+
+	links := make([]string, 0, len(proxies))
+	for _, item := range proxies {
+		link, err := shareLink(item)
+		if err != nil {
+			return "", err
+		}
+		links = append(links, link)
+	}
+
+	return base64.StdEncoding.EncodeToString([]byte(strings.Join(links, "\n"))), nil
+}
+
+func shareLink(item proxy) (string, error) {
+	address := net.JoinHostPort(item.Server, strconv.Itoa(item.Port))
+	fragment := "#" + url.PathEscape(item.Name)
+
+	switch item.Protocol {
+	case ProtocolVMess:
+		share := vmessShare{
+			V:    "2",
+			PS:   item.Name,
+			Add:  item.Server,
+			Port: strconv.Itoa(item.Port),
+			ID:   item.UUID,
+			Aid:  strconv.Itoa(item.AlterID),
+			Scy:  "auto",
+			Net:  item.Network,
+			Type: "none",
+			Host: item.HostHeader,
+			Path: item.Path,
+		}
+		if item.Network == "grpc" {
+			share.Path = item.ServiceName
+		}
+		if item.TLS {
+			share.TLS = "tls"
+			share.SNI = item.tlsServerName()
+		}
+		buf, err := json.Marshal(share)
+		if err != nil {
+			return "", err
+		}
+		return "vmess://" + base64.StdEncoding.EncodeToString(buf), nil
+	case ProtocolVLESS:
+		query := transportQuery(item)
+		query.Set("encryption", "none")
+		if item.Flow != "" {
+			query.Set("flow", item.Flow)
+		}
+		return "vless://" + url.PathEscape(item.UUID) + "@" + address + "?" + query.Encode() + fragment, nil
+	case ProtocolTrojan:
+		query := transportQuery(item)
+		return "trojan://" + url.PathEscape(item.Password) + "@" + address + "?" + query.Encode() + fragment, nil
+	case ProtocolShadowsocks:
+		// SIP002：userinfo 为 method:password 的 URL 安全 base64（无填充）。
+		userInfo := base64.RawURLEncoding.EncodeToString([]byte(item.Method + ":" + item.Password))
+		return "ss://" + userInfo + "@" + address + fragment, nil
+	default:
+		return "", nil
+	}
+}
+
+// transportQuery 生成 vless/trojan 链接中传输层与 TLS 相关的查询参数。
+func transportQuery(item proxy) url.Values {
+	query := url.Values{}
+	query.Set("type", item.Network)
+	switch item.Network {
+	case "ws", "http":
+		if item.Path != "" {
+			query.Set("path", item.Path)
+		}
+		if item.HostHeader != "" {
+			query.Set("host", item.HostHeader)
+		}
+	case "grpc":
+		if item.ServiceName != "" {
+			query.Set("serviceName", item.ServiceName)
+		}
+	}
+	if item.TLS {
+		query.Set("security", "tls")
+		query.Set("sni", item.tlsServerName())
+		if item.Insecure {
+			query.Set("allowInsecure", "1")
+		}
+	} else {
+		query.Set("security", "none")
+	}
+	return query
+}
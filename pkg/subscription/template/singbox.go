@@ -0,0 +1,112 @@
+package template
+
+import (
+	"encoding/json"
+)
+
+type singBoxConfig struct {
+	Outbounds []singBoxOutbound `json:"outbounds"`
+	Route     singBoxRoute      `json:"route"`
+}
+
+type singBoxOutbound struct {
+	Type       string            `json:"type"`
+	Tag        string            `json:"tag"`
+	Outbounds  []string          `json:"outbounds,omitempty"`
+	Server     string            `json:"server,omitempty"`
+	ServerPort int               `json:"server_port,omitempty"`
+	UUID       string            `json:"uuid,omitempty"`
+	Security   string            `json:"security,omitempty"`
+	AlterID    int               `json:"alter_id,omitempty"`
+	Flow       string            `json:"flow,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	Password   string            `json:"password,omitempty"`
+	TLS        *singBoxTLS       `json:"tls,omitempty"`
+	Transport  *singBoxTransport `json:"transport,omitempty"`
+}
+
+type singBoxTLS struct {
+	Enabled    bool   `json:"enabled"`
+	ServerName string `json:"server_name,omitempty"`
+	Insecure   bool   `json:"insecure,omitempty"`
+}
+
+type singBoxTransport struct {
+	Type        string            `json:"type"`
+	Path        string            `json:"path,omitempty"`
+	Host        []string          `json:"host,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	ServiceName string            `json:"service_name,omitempty"`
+}
+
+type singBoxRoute struct {
+	Final string `json:"final"`
+}
+
+// renderSingBox 输出 sing-box JSON 出站配置：selector 分组、全部代理与 direct 出站，路由兜底到 selector。
+func renderSingBox(data map[string]any) (string, error) {
+	proxies := collectProxies(data)
+
+	members := proxyNames(proxies)
+	members = append(members, "direct")
+
+	outbounds := make([]singBoxOutbound, 0, len(proxies)+2)
+	outbounds = append(outbounds, singBoxOutbound{Type: "selector", Tag: "proxy", Outbounds: members})
+	for _, item := range proxies {
+		outbounds = append(outbounds, toSingBoxOutbound(item))
+	}
+	outbounds = append(outbounds, singBoxOutbound{Type: "direct", Tag: "direct"})
+
+	buf, err := json.MarshalIndent(singBoxConfig{Outbounds: outbounds, Route: singBoxRoute{Final: "proxy"}}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(buf) + "\n", nil
+}
+
+func toSingBoxOutbound(item proxy) singBoxOutbound {
+	result := singBoxOutbound{
+		Type:       item.Protocol,
+		Tag:        item.Name,
+		Server:     item.Server,
+		ServerPort: item.Port,
+	}
+
+	switch item.Protocol {
+	case ProtocolVMess:
+		result.UUID = item.UUID
+		result.Security = "auto"
+		result.AlterID = item.AlterID
+	case ProtocolVLESS:
+		result.UUID = item.UUID
+		result.Flow = item.Flow
+	case ProtocolTrojan:
+		result.Password = item.Password
+	case ProtocolShadowsocks:
+		result.Method = item.Method
+		result.Password = item.Password
+	}
+
+	if item.TLS {
+		result.TLS = &singBoxTLS{Enabled: true, ServerName: item.tlsServerName(), Insecure: item.Insecure}
+	}
+
+	switch item.Network {
+	case "ws":
+		transport := &singBoxTransport{Type: "ws", Path: item.Path}
+		if item.HostHeader != "" {
+			transport.Headers = map[string]string{"Host": item.HostHeader}
+		}
+		result.Transport = transport
+	case "http":
+		transport := &singBoxTransport{Type: "http", Path: item.Path}
+		if item.HostHeader != "" {
+			transport.Host = []string{item.HostHeader}
+		}
+		result.Transport = transport
+	case "grpc":
+		result.Transport = &singBoxTransport{Type: "grpc", ServiceName: item.ServiceName}
+	}
+
+	return result
+}
@@ -0,0 +1,88 @@
+package template
+
+import (
+	"strconv"
+	"strings"
+)
+
+// renderSurge 输出 Surge 配置片段：[Proxy]、[Proxy Group] 与兜底规则。
+// Surge 不支持 vless 与 gRPC 传输，对应接入点会被跳过。
+func renderSurge(data map[string]any) (string, error) {
+	proxies := collectProxies(data)
+
+	var b strings.Builder
+	b.WriteString("[General]\n")
+	b.WriteString("loglevel = notify\n")
+	b.WriteString("skip-proxy = 127.0.0.1, 192.168.0.0/16, 10.0.0.0/8, 172.16.0.0/12, localhost, *.local\n")
+
+	b.WriteString("\n[Proxy]\n")
+	members := make([]string, 0, len(proxies)+1)
+	for _, item := range proxies {
+		line, ok := surgeProxyLine(item)
+		if !ok {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		members = append(members, surgeName(item.Name))
+	}
+	members = append(members, "DIRECT")
+
+	b.WriteString("\n[Proxy Group]\n")
+	b.WriteString(clashGroupName + " = select, " + strings.Join(members, ", ") + "\n")
+
+	b.WriteString("\n[Rule]\n")
+	b.WriteString("FINAL," + clashGroupName + "\n")
+
+	return b.String(), nil
+}
+
+func surgeProxyLine(item proxy) (string, bool) {
+	if item.Network == "grpc" {
+		return "", false
+	}
+
+	fields := []string{"", item.Server, strconv.Itoa(item.Port)}
+	switch item.Protocol {
+	case ProtocolVMess:
+		fields[0] = "vmess"
+		fields = append(fields, "username="+item.UUID)
+	case ProtocolTrojan:
+		fields[0] = "trojan"
+		fields = append(fields, "password="+item.Password)
+	case ProtocolShadowsocks:
+		fields[0] = "ss"
+		fields = append(fields, "encrypt-method="+item.Method, "password="+item.Password)
+	default:
+		return "", false
+	}
+
+	if item.Network == "ws" {
+		fields = append(fields, "ws=true")
+		if item.Path != "" {
+			fields = append(fields, "ws-path="+item.Path)
+		}
+		if item.HostHeader != "" {
+			fields = append(fields, "ws-headers=Host:"+item.HostHeader)
+		}
+	}
+	if item.TLS {
+		if item.Protocol == ProtocolVMess {
+			fields = append(fields, "tls=true")
+		}
+		fields = append(fields, "sni="+item.tlsServerName())
+		if item.Insecure {
+			fields = append(fields, "skip-cert-verify=true")
+		}
+	}
+	if item.Protocol == ProtocolShadowsocks {
+		fields = append(fields, "udp-relay=true")
+	}
+
+	return surgeName(item.Name) + " = " + strings.Join(fields, ", "), true
+}
+
+// surgeName 去掉会破坏 Surge 行格式的字符。
+func surgeName(name string) string {
+	return strings.NewReplacer(",", " ", "=", " ", "\n", " ").Replace(name)
+}
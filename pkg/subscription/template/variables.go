@@ -0,0 +1,401 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// 变量类型，对应 TemplateVariable.ValueType；空值与 any 不做类型检查。
+const (
+	ValueTypeAny    = "any"
+	ValueTypeString = "string"
+	ValueTypeNumber = "number"
+	ValueTypeBool   = "bool"
+	ValueTypeArray  = "array"
+	ValueTypeObject = "object"
+)
+
+// Variable 模板声明的变量，键为点号分隔的上下文路径（如 subscription.token）。
+type Variable struct {
+	ValueType    string
+	Required     bool
+	DefaultValue any
+}
+
+// VariableError 模板变量校验失败的明细，路径均为点号分隔且已排序。
+type VariableError struct {
+	Undeclared []string `json:"undeclared,omitempty"`
+	Missing    []string `json:"missing,omitempty"`
+	Invalid    []string `json:"invalid,omitempty"`
+}
+
+func (e *VariableError) Error() string {
+	parts := make([]string, 0, 3)
+	if len(e.Undeclared) > 0 {
+		parts = append(parts, "undeclared variables: "+strings.Join(e.Undeclared, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing variables: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		parts = append(parts, "invalid variables: "+strings.Join(e.Invalid, ", "))
+	}
+	return "subscription template: " + strings.Join(parts, "; ")
+}
+
+func (e *VariableError) orNil() error {
+	if len(e.Undeclared) == 0 && len(e.Missing) == 0 && len(e.Invalid) == 0 {
+		return nil
+	}
+	sort.Strings(e.Undeclared)
+	sort.Strings(e.Missing)
+	sort.Strings(e.Invalid)
+	return e
+}
+
+// NormalizeValueType 归一化变量类型并展开别名，未知类型原样返回。
+func NormalizeValueType(valueType string) string {
+	switch value := strings.ToLower(strings.TrimSpace(valueType)); value {
+	case "", "any":
+		return ValueTypeAny
+	case "int", "integer", "float":
+		return ValueTypeNumber
+	case "boolean":
+		return ValueTypeBool
+	case "list", "slice":
+		return ValueTypeArray
+	case "map":
+		return ValueTypeObject
+	default:
+		return value
+	}
+}
+
+// Validate 校验格式、模板语法与变量声明：格式须已注册引擎，依赖内容的格式须提供可解析的内容；
+// 变量类型须受支持且默认值与类型一致；模板（go_template、jinja 等）经引擎 References 得到的根上下文引用须在 builtin 或 vars 中声明。
+// builtin 为渲染方固定提供的上下文路径。
+func Validate(format, content string, vars map[string]Variable, builtin []string) error {
+	engine, ok := lookupEngine(format)
+	if !ok {
+		return fmt.Errorf("subscription template: unsupported format %s", NormalizeFormat(format))
+	}
+	if UsesContent(format) {
+		if strings.TrimSpace(content) == "" {
+			return fmt.Errorf("subscription template: content is required for format %s", NormalizeFormat(format))
+		}
+		if err := engine.Parse(content); err != nil {
+			return err
+		}
+	}
+
+	result := &VariableError{}
+	for path, variable := range vars {
+		valueType := NormalizeValueType(variable.ValueType)
+		switch valueType {
+		case ValueTypeAny, ValueTypeString, ValueTypeNumber, ValueTypeBool, ValueTypeArray, ValueTypeObject:
+		default:
+			result.Invalid = append(result.Invalid, fmt.Sprintf("%s: unknown value type %s", path, variable.ValueType))
+			continue
+		}
+		if variable.DefaultValue != nil && !matchesValueType(valueType, variable.DefaultValue) {
+			result.Invalid = append(result.Invalid, fmt.Sprintf("%s: default value is not %s", path, valueType))
+		}
+	}
+
+	if UsesContent(format) {
+		refs, err := engine.References(content)
+		if err != nil {
+			return err
+		}
+		declared := make([]string, 0, len(builtin)+len(vars))
+		declared = append(declared, builtin...)
+		for path := range vars {
+			declared = append(declared, path)
+		}
+		for _, ref := range refs {
+			if !pathDeclared(ref, declared) {
+				result.Undeclared = append(result.Undeclared, ref)
+			}
+		}
+	}
+
+	return result.orNil()
+}
+
+// RenderWithVariables 按声明注入默认值并检查类型后渲染：缺失且必填的变量、
+// 以及模板引用但上下文中不存在的路径会以 VariableError 返回，而不是输出 <no value> 或空值。
+func RenderWithVariables(format, content string, data map[string]any, vars map[string]Variable) (string, error) {
+	return renderWithVariables(format, content, data, vars, Limits{})
+}
+
+func renderWithVariables(format, content string, data map[string]any, vars map[string]Variable, limits Limits) (string, error) {
+	if data == nil {
+		data = map[string]any{}
+	}
+	result := &VariableError{}
+
+	paths := make([]string, 0, len(vars))
+	for path := range vars {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		variable := vars[path]
+		valueType := NormalizeValueType(variable.ValueType)
+		value, ok := lookupPath(data, path)
+		if !ok || value == nil {
+			switch {
+			case variable.DefaultValue != nil:
+				value = variable.DefaultValue
+			case variable.Required:
+				result.Missing = append(result.Missing, path)
+				continue
+			default:
+				value = zeroValue(valueType)
+			}
+			if value != nil && !assignPath(data, path, value) {
+				result.Invalid = append(result.Invalid, fmt.Sprintf("%s: parent is not an object", path))
+				continue
+			}
+		}
+		if value != nil && !matchesValueType(valueType, value) {
+			result.Invalid = append(result.Invalid, fmt.Sprintf("%s: expected %s", path, valueType))
+		}
+	}
+
+	if engine, ok := lookupEngine(format); ok {
+		refs, err := engine.References(content)
+		if err != nil {
+			return "", err
+		}
+		for _, ref := range refs {
+			if _, ok := lookupPath(data, ref); !ok && !containsString(result.Missing, ref) {
+				result.Missing = append(result.Missing, ref)
+			}
+		}
+	}
+
+	if err := result.orNil(); err != nil {
+		return "", err
+	}
+	return render(format, content, data, limits)
+}
+
+// ReferencedVariables 解析 go_template 内容，返回从根上下文引用的变量路径（去重排序）。
+// 包括 range/with 外的 .a.b 与任意位置的 $.a.b；range/with 内相对当前元素的引用不计入。
+// 以根上下文（. 或 $）调用的 define/block 模板体同样视为根上下文，其余模板体中的引用不计入。
+func ReferencedVariables(content string) ([]string, error) {
+	tmpl, err := template.New("subscription").Funcs(funcMap).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+		return nil, nil
+	}
+
+	c := &referenceCollector{seen: make(map[string]struct{}), visited: make(map[string]bool)}
+	c.walk(tmpl.Tree.Root, true, true)
+	for len(c.pending) > 0 {
+		name := c.pending[0]
+		c.pending = c.pending[1:]
+		if called := tmpl.Lookup(name); called != nil && called.Tree != nil {
+			c.walk(called.Tree.Root, true, true)
+		}
+	}
+
+	refs := make([]string, 0, len(c.seen))
+	for ref := range c.seen {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	return refs, nil
+}
+
+// referenceCollector 收集根上下文引用，并记录以根上下文调用、待遍历的模板。
+type referenceCollector struct {
+	seen    map[string]struct{}
+	visited map[string]bool
+	pending []string
+}
+
+// walk 遍历节点；atRoot 表示 . 为根上下文，dollarRoot 表示 $ 为根上下文（模板体中 $ 为调用时传入的数据）。
+func (c *referenceCollector) walk(node parse.Node, atRoot, dollarRoot bool) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			c.walk(child, atRoot, dollarRoot)
+		}
+	case *parse.ActionNode:
+		c.walk(n.Pipe, atRoot, dollarRoot)
+	case *parse.IfNode:
+		c.walk(n.Pipe, atRoot, dollarRoot)
+		c.walk(n.List, atRoot, dollarRoot)
+		c.walk(n.ElseList, atRoot, dollarRoot)
+	case *parse.RangeNode:
+		c.walk(n.Pipe, atRoot, dollarRoot)
+		c.walk(n.List, false, dollarRoot)
+		c.walk(n.ElseList, atRoot, dollarRoot)
+	case *parse.WithNode:
+		c.walk(n.Pipe, atRoot, dollarRoot)
+		c.walk(n.List, false, dollarRoot)
+		c.walk(n.ElseList, atRoot, dollarRoot)
+	case *parse.TemplateNode:
+		c.walk(n.Pipe, atRoot, dollarRoot)
+		if passesRoot(n.Pipe, atRoot, dollarRoot) && !c.visited[n.Name] {
+			c.visited[n.Name] = true
+			c.pending = append(c.pending, n.Name)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			c.walk(cmd, atRoot, dollarRoot)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			c.walk(arg, atRoot, dollarRoot)
+		}
+	case *parse.ChainNode:
+		c.walk(n.Node, atRoot, dollarRoot)
+	case *parse.FieldNode:
+		if atRoot && len(n.Ident) > 0 {
+			c.seen[strings.Join(n.Ident, ".")] = struct{}{}
+		}
+	case *parse.VariableNode:
+		if dollarRoot && len(n.Ident) > 1 && n.Ident[0] == "$" {
+			c.seen[strings.Join(n.Ident[1:], ".")] = struct{}{}
+		}
+	}
+}
+
+// passesRoot 判断 {{template "x" pipe}} 是否把根上下文原样传给模板体。
+func passesRoot(pipe *parse.PipeNode, atRoot, dollarRoot bool) bool {
+	if pipe == nil || len(pipe.Decl) > 0 || len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.DotNode:
+		return atRoot
+	case *parse.VariableNode:
+		return dollarRoot && len(arg.Ident) == 1 && arg.Ident[0] == "$"
+	}
+	return false
+}
+
+// pathDeclared 判断引用是否被声明覆盖：与声明相同、为声明的子路径（对象整体声明），或为声明的父路径。
+func pathDeclared(ref string, declared []string) bool {
+	for _, path := range declared {
+		if ref == path || strings.HasPrefix(ref, path+".") || strings.HasPrefix(path, ref+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func lookupPath(data map[string]any, path string) (any, bool) {
+	var current any = data
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			// 非 map 的中间值（结构体等）无法静态检查，视为存在。
+			return current, current != nil
+		}
+		current, ok = m[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func assignPath(data map[string]any, path string, value any) bool {
+	keys := strings.Split(path, ".")
+	current := data
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key]
+		if !ok || next == nil {
+			child := map[string]any{}
+			current[key] = child
+			current = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return false
+		}
+		current = child
+	}
+	current[keys[len(keys)-1]] = value
+	return true
+}
+
+func zeroValue(valueType string) any {
+	switch valueType {
+	case ValueTypeString:
+		return ""
+	case ValueTypeNumber:
+		return 0
+	case ValueTypeBool:
+		return false
+	case ValueTypeArray:
+		return []any{}
+	case ValueTypeObject:
+		return map[string]any{}
+	default:
+		return nil
+	}
+}
+
+func matchesValueType(valueType string, value any) bool {
+	if value == nil {
+		return true
+	}
+	switch valueType {
+	case ValueTypeString:
+		_, ok := value.(string)
+		return ok
+	case ValueTypeNumber:
+		if _, ok := value.(json.Number); ok {
+			return true
+		}
+		switch reflect.TypeOf(value).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return true
+		}
+		return false
+	case ValueTypeBool:
+		_, ok := value.(bool)
+		return ok
+	case ValueTypeArray:
+		kind := reflect.TypeOf(value).Kind()
+		return kind == reflect.Slice || kind == reflect.Array
+	case ValueTypeObject:
+		kind := reflect.TypeOf(value).Kind()
+		return kind == reflect.Map || kind == reflect.Struct
+	default:
+		return true
+	}
+}
+
+func containsString(items []string, target string) bool {
+	for _, item := range items {
+		if item == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package template
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReferencedVariablesSkipsRangeScope(t *testing.T) {
+	content := `{{ .subscription.name }}{{ range .nodes }}{{ .name }}{{ $.brand.title }}{{ end }}` +
+		`{{ with .template }}{{ .version }}{{ else }}{{ .fallback }}{{ end }}{{ if .footer }}{{ upper .footer }}{{ end }}`
+
+	refs, err := ReferencedVariables(content)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{"brand.title", "fallback", "footer", "nodes", "subscription.name", "template"}
+	if !reflect.DeepEqual(refs, want) {
+		t.Fatalf("refs = %v, want %v", refs, want)
+	}
+
+	if _, err := ReferencedVariables(`{{ .broken `); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestReferencedVariablesFollowsDefinedTemplates(t *testing.T) {
+	content := `{{ define "header" }}{{ .brand.title }}{{ $.footer }}{{ template "inner" . }}{{ end }}` +
+		`{{ define "inner" }}{{ .undeclared }}{{ end }}` +
+		`{{ define "node" }}{{ .name }}{{ $.port }}{{ end }}` +
+		`{{ define "unused" }}{{ .never }}{{ end }}` +
+		`{{ template "header" . }}{{ range .nodes }}{{ template "node" . }}{{ end }}{{ block "tail" $ }}{{ .tail }}{{ end }}`
+
+	refs, err := ReferencedVariables(content)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := []string{"brand.title", "footer", "nodes", "tail", "undeclared"}
+	if !reflect.DeepEqual(refs, want) {
+		t.Fatalf("refs = %v, want %v", refs, want)
+	}
+
+	// define 中引用的未声明变量同样被校验与渲染前检查发现。
+	content = `{{ define "x" }}{{ .undeclared }}{{ end }}{{ template "x" . }}`
+	err = Validate(FormatGoTemplate, content, nil, []string{"subscription.name"})
+	var varErr *VariableError
+	if !errors.As(err, &varErr) || !reflect.DeepEqual(varErr.Undeclared, []string{"undeclared"}) {
+		t.Fatalf("expected undeclared variable in define, got %v", err)
+	}
+	_, err = RenderWithVariables(FormatGoTemplate, content, map[string]any{}, map[string]Variable{"undeclared": {ValueType: "string", Required: true}})
+	if !errors.As(err, &varErr) || !reflect.DeepEqual(varErr.Missing, []string{"undeclared"}) {
+		t.Fatalf("expected missing variable in define, got %v", err)
+	}
+}
+
+func TestJinjaReferencesSkipLocalNames(t *testing.T) {
+	content := `{{ subscription.name|default:brand.fallback }}{# {{ commented }} #}` +
+		`{% for node in nodes %}{{ node.name }}{{ forloop.Counter }}{{ brand.title|upper }}{% endfor %}` +
+		`{% set port = vars.port %}{{ port }}{% with t=template.version %}{{ t }}{% endwith %}` +
+		`{{ b64enc(subscription.token) }}{{ nodes.0.name }}{{ "{{ literal }}" }}{% if footer %}{{ footer }}{% endif %}`
+
+	engine, _ := lookupEngine(FormatJinja)
+	refs, err := engine.References(content)
+	if err != nil {
+		t.Fatalf("references: %v", err)
+	}
+	want := []string{"brand.fallback", "brand.title", "footer", "nodes", "subscription.name", "subscription.token", "template.version", "vars.port"}
+	if !reflect.DeepEqual(refs, want) {
+		t.Fatalf("refs = %v, want %v", refs, want)
+	}
+
+	if _, err := engine.References(`{% for x in %}`); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestValidateDeclarations(t *testing.T) {
+	builtin := []string{"subscription.name", "subscription.token", "nodes"}
+	vars := map[string]Variable{
+		"brand":       {ValueType: "object"},
+		"footer":      {ValueType: "string", DefaultValue: "bye"},
+		"port":        {ValueType: "integer", DefaultValue: 443.0},
+		"bad_type":    {ValueType: "date"},
+		"bad_default": {ValueType: "bool", DefaultValue: "yes"},
+	}
+
+	err := Validate(FormatGoTemplate, `{{ .subscription }}{{ .subscription.tokne }}{{ .brand.title }}{{ .footer }}{{ .unknown }}`, vars, builtin)
+	var varErr *VariableError
+	if !errors.As(err, &varErr) {
+		t.Fatalf("expected variable error, got %v", err)
+	}
+	if !reflect.DeepEqual(varErr.Undeclared, []string{"subscription.tokne", "unknown"}) {
+		t.Fatalf("undeclared = %v", varErr.Undeclared)
+	}
+	if !reflect.DeepEqual(varErr.Invalid, []string{"bad_default: default value is not bool", "bad_type: unknown value type date"}) {
+		t.Fatalf("invalid = %v", varErr.Invalid)
+	}
+
+	err = Validate(FormatJinja, `{{ subscription.tokne }}{% for n in nodes %}{{ n.name }}{% endfor %}{{ brand.title }}`, vars, builtin)
+	if !errors.As(err, &varErr) || !reflect.DeepEqual(varErr.Undeclared, []string{"subscription.tokne"}) {
+		t.Fatalf("expected jinja undeclared variable, got %v", err)
+	}
+
+	// 内置格式不检查模板引用。
+	if err := Validate(FormatClash, `{{ .unknown }}`, nil, builtin); err != nil {
+		t.Fatalf("builtin format: %v", err)
+	}
+}
+
+func TestRenderWithVariables(t *testing.T) {
+	vars := map[string]Variable{
+		"brand.title":       {ValueType: "string", DefaultValue: "ZNP"},
+		"footer":            {ValueType: "string"},
+		"subscription.name": {ValueType: "string", Required: true},
+	}
+	data := map[string]any{"subscription": map[string]any{"name": "Premium"}}
+
+	got, err := RenderWithVariables(FormatGoTemplate, `{{ .brand.title }}/{{ .subscription.name }}/{{ .footer }}.`, data, vars)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if got != "ZNP/Premium/." {
+		t.Fatalf("got %q", got)
+	}
+
+	_, err = RenderWithVariables(FormatGoTemplate, `{{ .subscription.name }}{{ .extra }}`, map[string]any{}, vars)
+	var varErr *VariableError
+	if !errors.As(err, &varErr) {
+		t.Fatalf("expected variable error, got %v", err)
+	}
+	if !reflect.DeepEqual(varErr.Missing, []string{"extra", "subscription.name"}) {
+		t.Fatalf("missing = %v", varErr.Missing)
+	}
+
+	// jinja 引用的缺失路径同样报错，而不是渲染为空值。
+	_, err = RenderWithVariables(FormatJinja, `{{ subscription.name }}{{ extra }}`, map[string]any{"subscription": map[string]any{"name": "Premium"}}, vars)
+	if !errors.As(err, &varErr) || !reflect.DeepEqual(varErr.Missing, []string{"extra"}) {
+		t.Fatalf("expected jinja missing variable, got %v", err)
+	}
+
+	_, err = RenderWithVariables(FormatGoTemplate, `{{ .subscription.name }}`, map[string]any{"subscription": map[string]any{"name": 42}}, vars)
+	if !errors.As(err, &varErr) || !reflect.DeepEqual(varErr.Invalid, []string{"subscription.name: expected string"}) {
+		t.Fatalf("expected type error, got %v", err)
+	}
+}